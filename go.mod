@@ -6,6 +6,7 @@ require (
 	github.com/NorskHelsenett/ror v1.8.0
 	k8s.io/apimachinery v0.34.1
 	sigs.k8s.io/controller-runtime v0.22.4
+	sigs.k8s.io/yaml v1.6.0
 )
 
 require (
@@ -0,0 +1,277 @@
+// Package clusternet resolves the network of a Kubernetes cluster from its
+// KubernetesNetworkConfig: the IP families, the pod and service CIDRs per
+// family, the node CIDR mask sizes and the DNS service IP, for single-stack,
+// dual-stack and IPv6-only clusters. The deprecated single-stack podCIDR and
+// serviceCIDR fields keep working.
+package clusternet
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+// IP family policies.
+const (
+	PolicySingleStack      = "SingleStack"
+	PolicyPreferDualStack  = "PreferDualStack"
+	PolicyRequireDualStack = "RequireDualStack"
+)
+
+// Default node CIDR mask sizes.
+const (
+	DefaultNodeCIDRMaskSizeIPv4 = 24
+	DefaultNodeCIDRMaskSizeIPv6 = 64
+)
+
+// maxNodeMaskDiff is the largest difference between the prefix lengths of an
+// IPv6 pod CIDR and a node CIDR that kube-controller-manager accepts.
+const maxNodeMaskDiff = 16
+
+// ErrIPv6Unsupported is returned when a cluster needs IPv6 and its machine
+// provider does not support it.
+var ErrIPv6Unsupported = errors.New("IPv6 is not supported")
+
+// Network is the resolved network of a cluster.
+type Network struct {
+	// Policy is the IP family policy, PolicyPreferDualStack resolved to
+	// PolicySingleStack or PolicyRequireDualStack.
+	Policy string
+	// Families are the IP families, primary first.
+	Families []string
+	// PodCIDRs and ServiceCIDRs in the order of Families, empty when left to
+	// the provider.
+	PodCIDRs     []netip.Prefix
+	ServiceCIDRs []netip.Prefix
+	// NodeCIDRMaskSizeIPv4 and NodeCIDRMaskSizeIPv6 with defaults applied.
+	NodeCIDRMaskSizeIPv4 int
+	NodeCIDRMaskSizeIPv6 int
+	// DNSServiceIP is the zero Addr when unset.
+	DNSServiceIP netip.Addr
+}
+
+// DualStack tells whether the cluster has both IP families.
+func (n *Network) DualStack() bool {
+	return len(n.Families) == 2
+}
+
+// IPv6Only tells whether the cluster only has IPv6.
+func (n *Network) IPv6Only() bool {
+	return len(n.Families) == 1 && n.Families[0] == v1alpha1.IPFamilyIPv6
+}
+
+// PodCIDRs returns spec.podCIDRs followed by the deprecated spec.podCIDR when
+// it is set and not listed.
+func PodCIDRs(cfg *v1alpha1.KubernetesNetworkConfig) []string {
+	return withLegacy(cfg.PodCIDRs, cfg.PodCIDR)
+}
+
+// ServiceCIDRs returns spec.serviceCIDRs followed by the deprecated
+// spec.serviceCIDR when it is set and not listed.
+func ServiceCIDRs(cfg *v1alpha1.KubernetesNetworkConfig) []string {
+	return withLegacy(cfg.ServiceCIDRs, cfg.ServiceCIDR)
+}
+
+func withLegacy(list []string, legacy string) []string {
+	out := slices.Clone(list)
+	if legacy != "" && !slices.Contains(out, legacy) {
+		out = append(out, legacy)
+	}
+	return out
+}
+
+// Convert moves the deprecated podCIDR and serviceCIDR into podCIDRs and
+// serviceCIDRs and clears them. A manifest resolves the same before and
+// after. The configuration is only changed when every CIDR parses.
+func Convert(cfg *v1alpha1.KubernetesNetworkConfig) error {
+	_, podErrs := parseCIDRs("podCIDRs", PodCIDRs(cfg))
+	_, serviceErrs := parseCIDRs("serviceCIDRs", ServiceCIDRs(cfg))
+	if err := errors.Join(append(podErrs, serviceErrs...)...); err != nil {
+		return err
+	}
+	cfg.PodCIDRs, cfg.PodCIDR = PodCIDRs(cfg), ""
+	cfg.ServiceCIDRs, cfg.ServiceCIDR = ServiceCIDRs(cfg), ""
+	return nil
+}
+
+// Resolve validates a cluster network and applies its defaults. The machine
+// provider, when given, must support IPv6 for clusters using it; under
+// PolicyPreferDualStack a cluster without IPv6 support falls back to its IPv4
+// family. The returned error joins one error per problem.
+func Resolve(cfg *v1alpha1.KubernetesNetworkConfig, mp *v1alpha1.MachineProvider) (*Network, error) {
+	var errs []error
+	pods, err := parseCIDRs("podCIDRs", PodCIDRs(cfg))
+	errs = append(errs, err...)
+	services, err := parseCIDRs("serviceCIDRs", ServiceCIDRs(cfg))
+	errs = append(errs, err...)
+
+	n := &Network{Policy: cfg.IPFamilyPolicy}
+	for _, f := range cfg.IPFamilies {
+		if f != v1alpha1.IPFamilyIPv4 && f != v1alpha1.IPFamilyIPv6 {
+			errs = append(errs, fmt.Errorf("invalid IP family %q", f))
+		} else if slices.Contains(n.Families, f) {
+			errs = append(errs, fmt.Errorf("IP family %s is listed twice", f))
+		} else {
+			n.Families = append(n.Families, f)
+		}
+	}
+	if len(n.Families) == 0 {
+		n.Families = families(pods)
+	}
+	if len(n.Families) == 0 {
+		n.Families = families(services)
+	}
+	if len(n.Families) == 0 {
+		n.Families = []string{v1alpha1.IPFamilyIPv4}
+	}
+
+	switch n.Policy {
+	case "":
+		n.Policy = PolicySingleStack
+		if len(n.Families) == 2 {
+			n.Policy = PolicyRequireDualStack
+		}
+	case PolicySingleStack:
+		if len(n.Families) == 2 {
+			errs = append(errs, errors.New("SingleStack takes one IP family"))
+		}
+	case PolicyPreferDualStack, PolicyRequireDualStack:
+		if len(n.Families) == 1 {
+			n.Families = append(n.Families, other(n.Families[0]))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid IP family policy %q", n.Policy))
+	}
+
+	if mp != nil && !mp.Spec.Network.IPv6Support && slices.Contains(n.Families, v1alpha1.IPFamilyIPv6) {
+		if n.Policy == PolicyPreferDualStack && n.Families[0] == v1alpha1.IPFamilyIPv4 {
+			n.Families = n.Families[:1]
+		} else {
+			errs = append(errs, fmt.Errorf("MachineProvider %s: %w", mp.Name, ErrIPv6Unsupported))
+		}
+	}
+	fellBack := false
+	if n.Policy == PolicyPreferDualStack {
+		n.Policy = PolicyRequireDualStack
+		if len(n.Families) == 1 {
+			n.Policy = PolicySingleStack
+			fellBack = true
+		}
+	}
+
+	var cidrErrs []error
+	n.PodCIDRs, cidrErrs = byFamily("podCIDRs", pods, n.Families, fellBack)
+	errs = append(errs, cidrErrs...)
+	n.ServiceCIDRs, cidrErrs = byFamily("serviceCIDRs", services, n.Families, fellBack)
+	errs = append(errs, cidrErrs...)
+
+	n.NodeCIDRMaskSizeIPv4 = int(cfg.NodeCIDRMaskSizeIPv4)
+	if n.NodeCIDRMaskSizeIPv4 == 0 {
+		n.NodeCIDRMaskSizeIPv4 = DefaultNodeCIDRMaskSizeIPv4
+	}
+	n.NodeCIDRMaskSizeIPv6 = int(cfg.NodeCIDRMaskSizeIPv6)
+	if n.NodeCIDRMaskSizeIPv6 == 0 {
+		n.NodeCIDRMaskSizeIPv6 = DefaultNodeCIDRMaskSizeIPv6
+	}
+	for _, p := range n.PodCIDRs {
+		mask, field := n.NodeCIDRMaskSizeIPv4, "nodeCIDRMaskSizeIPv4"
+		if p.Addr().Is6() {
+			mask, field = n.NodeCIDRMaskSizeIPv6, "nodeCIDRMaskSizeIPv6"
+		}
+		switch {
+		case mask < p.Bits():
+			errs = append(errs, fmt.Errorf("%s %d is shorter than pod CIDR %s", field, mask, p))
+		case p.Addr().Is6() && mask-p.Bits() > maxNodeMaskDiff:
+			errs = append(errs, fmt.Errorf("%s %d is more than %d bits longer than pod CIDR %s", field, mask, maxNodeMaskDiff, p))
+		}
+	}
+
+	if cfg.DNSServiceIP != "" {
+		dns, err := netip.ParseAddr(strings.TrimSpace(cfg.DNSServiceIP))
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("invalid DNS service IP %q", cfg.DNSServiceIP))
+		case family(dns) != n.Families[0]:
+			errs = append(errs, fmt.Errorf("DNS service IP %s is not of the primary IP family %s", dns, n.Families[0]))
+		case len(n.ServiceCIDRs) > 0 && n.ServiceCIDRs[0].Addr().Is4() == dns.Is4() && !n.ServiceCIDRs[0].Contains(dns):
+			errs = append(errs, fmt.Errorf("DNS service IP %s is not inside service CIDR %s", dns, n.ServiceCIDRs[0]))
+		}
+		n.DNSServiceIP = dns
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// parseCIDRs parses CIDRs, at most one per family.
+func parseCIDRs(field string, cidrs []string) ([]netip.Prefix, []error) {
+	var out []netip.Prefix
+	var errs []error
+	for _, s := range cidrs {
+		p, err := netip.ParsePrefix(strings.TrimSpace(s))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid CIDR %q", field, s))
+			continue
+		}
+		p = p.Masked()
+		if i := slices.IndexFunc(out, func(o netip.Prefix) bool { return o.Addr().Is4() == p.Addr().Is4() }); i >= 0 {
+			errs = append(errs, fmt.Errorf("%s: %s and %s are both %s, use one CIDR per family", field, out[i], p, family(p.Addr())))
+			continue
+		}
+		out = append(out, p)
+	}
+	return out, errs
+}
+
+// byFamily orders CIDRs by the families of the cluster. Every family needs a
+// CIDR unless there are none. CIDRs of other families are errors, or dropped
+// when the cluster fell back from PolicyPreferDualStack.
+func byFamily(field string, cidrs []netip.Prefix, families []string, fellBack bool) ([]netip.Prefix, []error) {
+	if len(cidrs) == 0 {
+		return nil, nil
+	}
+	var out []netip.Prefix
+	var errs []error
+	for _, f := range families {
+		i := slices.IndexFunc(cidrs, func(p netip.Prefix) bool { return family(p.Addr()) == f })
+		if i < 0 {
+			errs = append(errs, fmt.Errorf("%s: no %s CIDR", field, f))
+			continue
+		}
+		out = append(out, cidrs[i])
+	}
+	for _, p := range cidrs {
+		if !slices.Contains(families, family(p.Addr())) && !fellBack {
+			errs = append(errs, fmt.Errorf("%s: %s is not of an IP family of the cluster", field, p))
+		}
+	}
+	return out, errs
+}
+
+func families(prefixes []netip.Prefix) []string {
+	var out []string
+	for _, p := range prefixes {
+		out = append(out, family(p.Addr()))
+	}
+	return out
+}
+
+func family(a netip.Addr) string {
+	if a.Is4() {
+		return v1alpha1.IPFamilyIPv4
+	}
+	return v1alpha1.IPFamilyIPv6
+}
+
+func other(f string) string {
+	if f == v1alpha1.IPFamilyIPv4 {
+		return v1alpha1.IPFamilyIPv6
+	}
+	return v1alpha1.IPFamilyIPv4
+}
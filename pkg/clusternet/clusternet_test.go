@@ -0,0 +1,314 @@
+package clusternet
+
+import (
+	"errors"
+	"net/netip"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	v4 = v1alpha1.IPFamilyIPv4
+	v6 = v1alpha1.IPFamilyIPv6
+)
+
+func machineProvider(ipv6 bool) *v1alpha1.MachineProvider {
+	mp := &v1alpha1.MachineProvider{ObjectMeta: metav1.ObjectMeta{Name: "proxmox"}}
+	mp.Spec.Network.IPv6Support = ipv6
+	return mp
+}
+
+func prefixes(cidrs ...string) []netip.Prefix {
+	var out []netip.Prefix
+	for _, s := range cidrs {
+		out = append(out, netip.MustParsePrefix(s))
+	}
+	return out
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  v1alpha1.KubernetesNetworkConfig
+		mp   *v1alpha1.MachineProvider
+		want Network
+	}{
+		{
+			name: "defaults",
+			want: Network{Policy: PolicySingleStack, Families: []string{v4}, NodeCIDRMaskSizeIPv4: 24, NodeCIDRMaskSizeIPv6: 64},
+		},
+		{
+			name: "families of the pod CIDRs",
+			cfg:  v1alpha1.KubernetesNetworkConfig{PodCIDRs: []string{"fd00:10:244::/56", "10.244.0.0/16"}, ServiceCIDRs: []string{"10.96.0.0/12", "fd00:10:96::/112"}},
+			want: Network{
+				Policy: PolicyRequireDualStack, Families: []string{v6, v4},
+				PodCIDRs: prefixes("fd00:10:244::/56", "10.244.0.0/16"), ServiceCIDRs: prefixes("fd00:10:96::/112", "10.96.0.0/12"),
+				NodeCIDRMaskSizeIPv4: 24, NodeCIDRMaskSizeIPv6: 64,
+			},
+		},
+		{
+			name: "families of the service CIDRs",
+			cfg:  v1alpha1.KubernetesNetworkConfig{ServiceCIDRs: []string{"fd00:10:96::/112"}},
+			want: Network{Policy: PolicySingleStack, Families: []string{v6}, ServiceCIDRs: prefixes("fd00:10:96::/112"), NodeCIDRMaskSizeIPv4: 24, NodeCIDRMaskSizeIPv6: 64},
+		},
+		{
+			name: "prefer dual-stack with IPv6 support",
+			cfg:  v1alpha1.KubernetesNetworkConfig{IPFamilyPolicy: PolicyPreferDualStack, PodCIDRs: []string{"10.244.0.0/16", "fd00:10:244::/56"}},
+			mp:   machineProvider(true),
+			want: Network{
+				Policy: PolicyRequireDualStack, Families: []string{v4, v6}, PodCIDRs: prefixes("10.244.0.0/16", "fd00:10:244::/56"),
+				NodeCIDRMaskSizeIPv4: 24, NodeCIDRMaskSizeIPv6: 64,
+			},
+		},
+		{
+			name: "prefer dual-stack falls back to IPv4",
+			cfg: v1alpha1.KubernetesNetworkConfig{
+				IPFamilyPolicy: PolicyPreferDualStack,
+				PodCIDRs:       []string{"10.244.0.0/16", "fd00:10:244::/56"},
+				ServiceCIDRs:   []string{"10.96.0.0/12", "fd00:10:96::/112"},
+				DNSServiceIP:   "10.96.0.10",
+			},
+			mp: machineProvider(false),
+			want: Network{
+				Policy: PolicySingleStack, Families: []string{v4}, PodCIDRs: prefixes("10.244.0.0/16"), ServiceCIDRs: prefixes("10.96.0.0/12"),
+				NodeCIDRMaskSizeIPv4: 24, NodeCIDRMaskSizeIPv6: 64, DNSServiceIP: netip.MustParseAddr("10.96.0.10"),
+			},
+		},
+		{
+			name: "prefer dual-stack adds the other family",
+			cfg:  v1alpha1.KubernetesNetworkConfig{IPFamilyPolicy: PolicyPreferDualStack, IPFamilies: []string{v4}},
+			want: Network{Policy: PolicyRequireDualStack, Families: []string{v4, v6}, NodeCIDRMaskSizeIPv4: 24, NodeCIDRMaskSizeIPv6: 64},
+		},
+		{
+			name: "legacy fields merged by family",
+			cfg: v1alpha1.KubernetesNetworkConfig{
+				PodCIDRs: []string{"fd00:10:244::/56"}, PodCIDR: "10.244.0.0/16",
+				ServiceCIDRs: []string{"fd00:10:96::/112", "10.96.0.0/12"}, ServiceCIDR: "10.96.0.0/12",
+				IPFamilies: []string{v4, v6},
+			},
+			mp: machineProvider(true),
+			want: Network{
+				Policy: PolicyRequireDualStack, Families: []string{v4, v6},
+				PodCIDRs: prefixes("10.244.0.0/16", "fd00:10:244::/56"), ServiceCIDRs: prefixes("10.96.0.0/12", "fd00:10:96::/112"),
+				NodeCIDRMaskSizeIPv4: 24, NodeCIDRMaskSizeIPv6: 64,
+			},
+		},
+		{
+			name: "node masks",
+			cfg: v1alpha1.KubernetesNetworkConfig{
+				PodCIDRs:             []string{"10.244.0.0/16", "fd00:10:244::/48"},
+				NodeCIDRMaskSizeIPv4: 16,
+				NodeCIDRMaskSizeIPv6: 64,
+			},
+			want: Network{
+				Policy: PolicyRequireDualStack, Families: []string{v4, v6}, PodCIDRs: prefixes("10.244.0.0/16", "fd00:10:244::/48"),
+				NodeCIDRMaskSizeIPv4: 16, NodeCIDRMaskSizeIPv6: 64,
+			},
+		},
+		{
+			name: "IPv6-only",
+			cfg:  v1alpha1.KubernetesNetworkConfig{IPFamilies: []string{v6}, ServiceCIDRs: []string{"fd00:10:96::/112"}, DNSServiceIP: "fd00:10:96::a"},
+			mp:   machineProvider(true),
+			want: Network{
+				Policy: PolicySingleStack, Families: []string{v6}, ServiceCIDRs: prefixes("fd00:10:96::/112"),
+				NodeCIDRMaskSizeIPv4: 24, NodeCIDRMaskSizeIPv6: 64, DNSServiceIP: netip.MustParseAddr("fd00:10:96::a"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(&tt.cfg, tt.mp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("network = %+v\nwant %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  v1alpha1.KubernetesNetworkConfig
+		mp   *v1alpha1.MachineProvider
+		// is is the sentinel the error wraps, if any.
+		is   error
+		want string
+	}{
+		{
+			name: "IPv6-only on an IPv4-only provider",
+			cfg:  v1alpha1.KubernetesNetworkConfig{IPFamilies: []string{v6}},
+			mp:   machineProvider(false),
+			is:   ErrIPv6Unsupported,
+			want: "MachineProvider proxmox: IPv6 is not supported",
+		},
+		{
+			name: "IPv6-primary on an IPv4-only provider",
+			cfg:  v1alpha1.KubernetesNetworkConfig{IPFamilyPolicy: PolicyPreferDualStack, IPFamilies: []string{v6, v4}},
+			mp:   machineProvider(false),
+			is:   ErrIPv6Unsupported,
+			want: "MachineProvider proxmox: IPv6 is not supported",
+		},
+		{
+			name: "required dual-stack on an IPv4-only provider",
+			cfg:  v1alpha1.KubernetesNetworkConfig{IPFamilyPolicy: PolicyRequireDualStack},
+			mp:   machineProvider(false),
+			is:   ErrIPv6Unsupported,
+			want: "IPv6 is not supported",
+		},
+		{
+			name: "single-stack with two families",
+			cfg:  v1alpha1.KubernetesNetworkConfig{IPFamilyPolicy: PolicySingleStack, IPFamilies: []string{v4, v6}},
+			want: "SingleStack takes one IP family",
+		},
+		{
+			name: "invalid policy",
+			cfg:  v1alpha1.KubernetesNetworkConfig{IPFamilyPolicy: "DualStack"},
+			want: `invalid IP family policy "DualStack"`,
+		},
+		{
+			name: "invalid family",
+			cfg:  v1alpha1.KubernetesNetworkConfig{IPFamilies: []string{"ipv4"}},
+			want: `invalid IP family "ipv4"`,
+		},
+		{
+			name: "family listed twice",
+			cfg:  v1alpha1.KubernetesNetworkConfig{IPFamilies: []string{v4, v4}},
+			want: "IP family IPv4 is listed twice",
+		},
+		{
+			name: "legacy CIDR of a listed family",
+			cfg:  v1alpha1.KubernetesNetworkConfig{PodCIDRs: []string{"10.244.0.0/16"}, PodCIDR: "10.245.0.0/16"},
+			want: "podCIDRs: 10.244.0.0/16 and 10.245.0.0/16 are both IPv4, use one CIDR per family",
+		},
+		{
+			name: "invalid CIDR",
+			cfg:  v1alpha1.KubernetesNetworkConfig{ServiceCIDR: "10.96.0.0/33"},
+			want: `serviceCIDRs: invalid CIDR "10.96.0.0/33"`,
+		},
+		{
+			name: "family without a CIDR",
+			cfg:  v1alpha1.KubernetesNetworkConfig{IPFamilyPolicy: PolicyRequireDualStack, PodCIDRs: []string{"10.244.0.0/16"}},
+			want: "podCIDRs: no IPv6 CIDR",
+		},
+		{
+			name: "CIDR of another family",
+			cfg:  v1alpha1.KubernetesNetworkConfig{IPFamilies: []string{v4}, PodCIDRs: []string{"10.244.0.0/16", "fd00:10:244::/56"}},
+			want: "podCIDRs: fd00:10:244::/56 is not of an IP family of the cluster",
+		},
+		{
+			name: "IPv4 node mask shorter than the pod CIDR",
+			cfg:  v1alpha1.KubernetesNetworkConfig{PodCIDRs: []string{"10.244.0.0/16"}, NodeCIDRMaskSizeIPv4: 12},
+			want: "nodeCIDRMaskSizeIPv4 12 is shorter than pod CIDR 10.244.0.0/16",
+		},
+		{
+			name: "IPv6 node mask too far from the pod CIDR",
+			cfg:  v1alpha1.KubernetesNetworkConfig{PodCIDRs: []string{"fd00:10:200::/40"}},
+			want: "nodeCIDRMaskSizeIPv6 64 is more than 16 bits longer than pod CIDR fd00:10:200::/40",
+		},
+		{
+			name: "DNS service IP of the secondary family",
+			cfg: v1alpha1.KubernetesNetworkConfig{
+				ServiceCIDRs: []string{"10.96.0.0/12", "fd00:10:96::/112"},
+				DNSServiceIP: "fd00:10:96::a",
+			},
+			want: "DNS service IP fd00:10:96::a is not of the primary IP family IPv4",
+		},
+		{
+			name: "DNS service IP outside the service CIDR",
+			cfg:  v1alpha1.KubernetesNetworkConfig{ServiceCIDR: "10.96.0.0/12", DNSServiceIP: "10.200.0.10"},
+			want: "DNS service IP 10.200.0.10 is not inside service CIDR 10.96.0.0/12",
+		},
+		{
+			name: "invalid DNS service IP",
+			cfg:  v1alpha1.KubernetesNetworkConfig{DNSServiceIP: "10.96.0"},
+			want: `invalid DNS service IP "10.96.0"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := Resolve(&tt.cfg, tt.mp)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want %q", err, tt.want)
+			}
+			if n != nil {
+				t.Errorf("network = %+v, want none", n)
+			}
+			if tt.is != nil && !errors.Is(err, tt.is) {
+				t.Errorf("error = %v, want it to wrap %v", err, tt.is)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	manifests := map[string]v1alpha1.KubernetesNetworkConfig{
+		"IPv4 single-stack":   {PodCIDR: "10.244.0.0/16", ServiceCIDR: "10.96.0.0/12", DNSServiceIP: "10.96.0.10"},
+		"IPv6 single-stack":   {PodCIDR: "fd00:10:244::/56", ServiceCIDR: "fd00:10:96::/112", DNSServiceIP: "fd00:10:96::a"},
+		"only a service CIDR": {ServiceCIDR: "10.96.0.0/12"},
+		"legacy also listed":  {PodCIDRs: []string{"10.244.0.0/16"}, PodCIDR: "10.244.0.0/16", ServiceCIDR: "10.96.0.0/12"},
+		"legacy adds a family": {
+			PodCIDRs: []string{"10.244.0.0/16"}, PodCIDR: "fd00:10:244::/56",
+			ServiceCIDRs: []string{"10.96.0.0/12"}, ServiceCIDR: "fd00:10:96::/112",
+		},
+		"explicit policy": {IPFamilyPolicy: PolicySingleStack, IPFamilies: []string{v4}, PodCIDR: "10.244.0.0/16"},
+	}
+	for name, cfg := range manifests {
+		t.Run(name, func(t *testing.T) {
+			before, err := Resolve(&cfg, machineProvider(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			converted := *cfg.DeepCopy()
+			if err := Convert(&converted); err != nil {
+				t.Fatal(err)
+			}
+			if converted.PodCIDR != "" || converted.ServiceCIDR != "" {
+				t.Errorf("legacy fields %q and %q were not cleared", converted.PodCIDR, converted.ServiceCIDR)
+			}
+			if !slices.Equal(converted.PodCIDRs, PodCIDRs(&cfg)) || !slices.Equal(converted.ServiceCIDRs, ServiceCIDRs(&cfg)) {
+				t.Errorf("converted CIDRs %q and %q, want %q and %q", converted.PodCIDRs, converted.ServiceCIDRs, PodCIDRs(&cfg), ServiceCIDRs(&cfg))
+			}
+			after, err := Resolve(&converted, machineProvider(true))
+			if err != nil {
+				t.Fatalf("converted manifest is invalid: %v", err)
+			}
+			if !reflect.DeepEqual(before, after) {
+				t.Errorf("resolved %+v before and %+v after converting", before, after)
+			}
+		})
+	}
+
+	// Nothing changes when a CIDR does not parse.
+	cfg := v1alpha1.KubernetesNetworkConfig{PodCIDR: "10.244.0.0/16", ServiceCIDR: "10.96.0.0"}
+	if err := Convert(&cfg); err == nil {
+		t.Error("an invalid CIDR was converted")
+	}
+	if cfg.PodCIDR != "10.244.0.0/16" || cfg.PodCIDRs != nil {
+		t.Errorf("configuration changed: %+v", cfg)
+	}
+}
+
+func TestByFamily(t *testing.T) {
+	cidrs := prefixes("fd00:10:244::/56", "10.244.0.0/16")
+	got, errs := byFamily("podCIDRs", cidrs, []string{v4, v6}, false)
+	if len(errs) != 0 || !slices.Equal(got, prefixes("10.244.0.0/16", "fd00:10:244::/56")) {
+		t.Errorf("byFamily = %v, %v, want the CIDRs in family order", got, errs)
+	}
+	if got, errs := byFamily("podCIDRs", cidrs, []string{v4}, true); len(errs) != 0 || !slices.Equal(got, prefixes("10.244.0.0/16")) {
+		t.Errorf("byFamily after a fallback = %v, %v, want the IPv6 CIDR dropped", got, errs)
+	}
+	if _, errs := byFamily("podCIDRs", cidrs, []string{v4}, false); len(errs) != 1 {
+		t.Errorf("byFamily = %v, want the IPv6 CIDR reported", errs)
+	}
+	if got, errs := byFamily("podCIDRs", nil, []string{v4, v6}, false); got != nil || errs != nil {
+		t.Errorf("byFamily without CIDRs = %v, %v, want nothing", got, errs)
+	}
+}
@@ -0,0 +1,190 @@
+// Package dhcp exports the DHCP reservations of NetworkConfigurations, the
+// network interfaces marked dhcpReserved, as ISC Kea subnet reservations and
+// dnsmasq dhcp-host lines for the DHCP servers of a datacenter. Duplicate MAC
+// and IP addresses are reported instead of being handed out.
+package dhcp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/vitistack/crds/pkg/netconfig"
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+var (
+	// ErrDuplicate is wrapped by errors of MAC or IP addresses reserved for
+	// several interfaces.
+	ErrDuplicate = errors.New("duplicate DHCP reservation")
+	// ErrIncomplete is wrapped by errors of reserved interfaces without a
+	// usable MAC address, IPv4 address or subnet.
+	ErrIncomplete = errors.New("incomplete DHCP reservation")
+)
+
+// Reservation is the DHCP reservation of a network interface.
+type Reservation struct {
+	// Namespace and Name of the NetworkConfiguration.
+	Namespace string
+	Name      string
+	// Interface is the name of the network interface.
+	Interface string
+	// Hostname handed out with the address.
+	Hostname string
+	MAC      net.HardwareAddr
+	// Address is the reserved IPv4 address with the prefix length of its
+	// subnet.
+	Address netip.Prefix
+	// Gateway is the zero Addr when unset.
+	Gateway netip.Addr
+	DNS     []netip.Addr
+}
+
+// Subnet returns the subnet of the reserved address.
+func (r *Reservation) Subnet() netip.Prefix {
+	return r.Address.Masked()
+}
+
+func (r *Reservation) String() string {
+	return fmt.Sprintf("NetworkConfiguration %s/%s interface %s", r.Namespace, r.Name, r.Interface)
+}
+
+var hostnamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// Reservations returns the reservations of the NetworkConfigurations of a
+// datacenter, sorted by subnet and address. A reserved network interface
+// needs a MAC address, in spec or else in status, and one IPv4 address with a
+// prefix length or subnet. The hostname is the spec name of the
+// NetworkConfiguration, followed by the interface name when several of its
+// interfaces are reserved.
+//
+// A MAC or IP address reserved for several interfaces is an error wrapping
+// ErrDuplicate, and none of these interfaces get a reservation. The
+// reservations are returned together with an error that joins one error per
+// problem.
+func Reservations(datacenter string, ncs []v1alpha1.NetworkConfiguration) ([]Reservation, error) {
+	var out []Reservation
+	var errs []error
+	for i := range ncs {
+		nc := &ncs[i]
+		if nc.Spec.DatacenterIdentifier != datacenter {
+			continue
+		}
+		var reserved []*v1alpha1.NetworkConfigurationInterface
+		for j := range nc.Spec.NetworkInterfaces {
+			if nic := &nc.Spec.NetworkInterfaces[j]; nic.DHCPReserved {
+				reserved = append(reserved, nic)
+			}
+		}
+		for _, nic := range reserved {
+			r, err := reservation(nc, nic, len(reserved) > 1)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			out = append(out, r)
+		}
+	}
+
+	// Leave out every reservation sharing a MAC or IP address, so that no
+	// interface gets the address of another.
+	duplicate := make([]bool, len(out))
+	for i := range out {
+		for j := i + 1; j < len(out); j++ {
+			a, b := &out[i], &out[j]
+			var what string
+			switch {
+			case slices.Equal(a.MAC, b.MAC):
+				what = "MAC address " + a.MAC.String()
+			case a.Address.Addr() == b.Address.Addr():
+				what = "IP address " + a.Address.Addr().String()
+			default:
+				continue
+			}
+			errs = append(errs, fmt.Errorf("%w: %s of %s is also reserved for %s", ErrDuplicate, what, b, a))
+			duplicate[i], duplicate[j] = true, true
+		}
+	}
+	var unique []Reservation
+	for i := range out {
+		if !duplicate[i] {
+			unique = append(unique, out[i])
+		}
+	}
+	slices.SortFunc(unique, func(a, b Reservation) int {
+		if c := a.Subnet().Addr().Compare(b.Subnet().Addr()); c != 0 {
+			return c
+		}
+		return a.Address.Addr().Compare(b.Address.Addr())
+	})
+	return unique, errors.Join(errs...)
+}
+
+func reservation(nc *v1alpha1.NetworkConfiguration, nic *v1alpha1.NetworkConfigurationInterface, several bool) (Reservation, error) {
+	r := Reservation{Namespace: nc.Namespace, Name: nc.Name, Interface: nic.Name}
+	fail := func(format string, args ...any) (Reservation, error) {
+		return Reservation{}, fmt.Errorf("%w: %s: %s", ErrIncomplete, &r, fmt.Sprintf(format, args...))
+	}
+
+	mac := nic.MacAddress
+	if mac == "" {
+		for j := range nc.Status.NetworkInterfaces {
+			if s := &nc.Status.NetworkInterfaces[j]; s.Name == nic.Name && nic.Name != "" {
+				mac = s.MacAddress
+			}
+		}
+	}
+	if mac == "" {
+		return fail("no MAC address")
+	}
+	hw, err := net.ParseMAC(mac)
+	if err != nil || len(hw) != 6 {
+		return fail("invalid MAC address %q", mac)
+	}
+	r.MAC = hw
+
+	a := netconfig.InterfaceAddressing(nic)
+	a.IPv6Addresses, a.IPv6Subnet = nil, ""
+	prefixes, err := netconfig.Addresses(&a)
+	switch {
+	case err != nil:
+		return fail("%v", err)
+	case len(prefixes) == 0:
+		return fail("no IPv4 address")
+	case len(prefixes) > 1:
+		return fail("%d IPv4 addresses, a reservation holds one", len(prefixes))
+	}
+	r.Address = prefixes[0]
+
+	if nic.IPv4Gateway != "" {
+		gw, err := netip.ParseAddr(nic.IPv4Gateway)
+		if err != nil || !gw.Is4() || !r.Subnet().Contains(gw) {
+			return fail("gateway %q is not in subnet %s", nic.IPv4Gateway, r.Subnet())
+		}
+		r.Gateway = gw
+	}
+	for _, s := range nic.DNS {
+		dns, err := netip.ParseAddr(s)
+		if err != nil {
+			return fail("invalid DNS server %q", s)
+		}
+		// DHCPv4 hands out IPv4 DNS servers only.
+		if dns.Is4() {
+			r.DNS = append(r.DNS, dns)
+		}
+	}
+
+	r.Hostname = strings.ToLower(nc.Spec.Name)
+	if several {
+		r.Hostname += "-" + strings.ToLower(nic.Name)
+	}
+	r.Hostname = strings.ReplaceAll(r.Hostname, "_", "-")
+	if !hostnamePattern.MatchString(r.Hostname) {
+		r.Hostname = ""
+	}
+	return r, nil
+}
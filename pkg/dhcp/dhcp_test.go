@@ -0,0 +1,173 @@
+package dhcp
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func newNetworkConfiguration(namespace, name string, nics ...v1alpha1.NetworkConfigurationInterface) v1alpha1.NetworkConfiguration {
+	return v1alpha1.NetworkConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       v1alpha1.NetworkConfigurationSpec{Name: name, DatacenterIdentifier: "no-west-az1", NetworkInterfaces: nics},
+	}
+}
+
+// goldenNetworkConfigurations reserve addresses in two subnets, for machines
+// of the same name in two namespaces, a machine with two reserved interfaces
+// and one with its MAC address in status.
+func goldenNetworkConfigurations() []v1alpha1.NetworkConfiguration {
+	db := newNetworkConfiguration("tenant-a", "db",
+		v1alpha1.NetworkConfigurationInterface{Name: "eth0", MacAddress: "52:54:00:00:00:03", IPv4Addresses: []string{"10.20.0.30/24"}, DHCPReserved: true},
+		v1alpha1.NetworkConfigurationInterface{Name: "eth1", MacAddress: "52:54:00:00:00:04", IPv4Addresses: []string{"10.30.0.30/24"}, DHCPReserved: true},
+		v1alpha1.NetworkConfigurationInterface{Name: "eth2", MacAddress: "52:54:00:00:00:05", IPv4Addresses: []string{"10.40.0.30/24"}},
+	)
+	status := newNetworkConfiguration("tenant-b", "Cache_1",
+		v1alpha1.NetworkConfigurationInterface{Name: "eth0", IPv4Addresses: []string{"10.30.0.5"}, IPv4Subnet: "10.30.0.0/24", IPv4Gateway: "10.30.0.1", DHCPReserved: true},
+	)
+	status.Status.NetworkInterfaces = []v1alpha1.NetworkConfigurationInterface{{Name: "eth0", MacAddress: "52:54:00:00:00:06"}}
+	return []v1alpha1.NetworkConfiguration{
+		newNetworkConfiguration("tenant-b", "web-1",
+			v1alpha1.NetworkConfigurationInterface{
+				Name: "eth0", MacAddress: "52:54:00:00:00:02", IPv4Addresses: []string{"10.20.0.22"}, IPv4Subnet: "10.20.0.0/24",
+				IPv4Gateway: "10.20.0.1", DNS: []string{"10.20.0.2", "2001:db8::53"}, DHCPReserved: true,
+			}),
+		newNetworkConfiguration("tenant-a", "web-1",
+			v1alpha1.NetworkConfigurationInterface{
+				Name: "eth0", MacAddress: "52:54:00:00:00:01", IPv4Addresses: []string{"10.20.0.21"}, IPv4Subnet: "10.20.0.0/24",
+				IPv4Gateway: "10.20.0.1", DNS: []string{"10.20.0.2", "10.20.0.3"}, DHCPReserved: true,
+			}),
+		db,
+		status,
+	}
+}
+
+func TestRenderGolden(t *testing.T) {
+	reservations, err := Reservations("no-west-az1", goldenNetworkConfigurations())
+	if err != nil {
+		t.Fatal(err)
+	}
+	kea, err := Kea("no-west-az1", reservations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "kea.json", string(kea))
+	checkGolden(t, "dnsmasq.conf", Dnsmasq("no-west-az1", reservations))
+}
+
+func TestReservations(t *testing.T) {
+	reservations, err := Reservations("no-west-az1", goldenNetworkConfigurations())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range reservations {
+		got = append(got, r.Namespace+"/"+r.Interface+" "+r.Hostname+" "+r.Address.String())
+	}
+	want := []string{
+		"tenant-a/eth0 web-1 10.20.0.21/24",
+		"tenant-b/eth0 web-1 10.20.0.22/24",
+		"tenant-a/eth0 db-eth0 10.20.0.30/24",
+		"tenant-b/eth0 cache-1 10.30.0.5/24",
+		"tenant-a/eth1 db-eth1 10.30.0.30/24",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("reservations = %q, want %q", got, want)
+	}
+
+	// Other datacenters are skipped.
+	if reservations, err := Reservations("no-east-az1", goldenNetworkConfigurations()); err != nil || len(reservations) != 0 {
+		t.Errorf("reservations of another datacenter = %v, %v, want none", reservations, err)
+	}
+}
+
+func TestReservationsErrors(t *testing.T) {
+	nic := func(mac string, ipv4 ...string) v1alpha1.NetworkConfigurationInterface {
+		return v1alpha1.NetworkConfigurationInterface{Name: "eth0", MacAddress: mac, IPv4Addresses: ipv4, DHCPReserved: true}
+	}
+	tests := []struct {
+		name    string
+		ncs     []v1alpha1.NetworkConfiguration
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "duplicate MAC address",
+			ncs: []v1alpha1.NetworkConfiguration{
+				newNetworkConfiguration("a", "web", nic("52:54:00:00:00:01", "10.0.0.1/24")),
+				newNetworkConfiguration("b", "web", nic("52-54-00-00-00-01", "10.0.0.2/24")),
+				newNetworkConfiguration("c", "api", nic("52:54:00:00:00:03", "10.0.0.3/24")),
+			},
+			want:    []string{"c/api"},
+			wantErr: ErrDuplicate,
+		},
+		{
+			name: "duplicate IP address",
+			ncs: []v1alpha1.NetworkConfiguration{
+				newNetworkConfiguration("a", "web", nic("52:54:00:00:00:01", "10.0.0.1/24")),
+				newNetworkConfiguration("a", "api", nic("52:54:00:00:00:02", "10.0.0.1/16")),
+			},
+			wantErr: ErrDuplicate,
+		},
+		{
+			name: "incomplete",
+			ncs: []v1alpha1.NetworkConfiguration{
+				newNetworkConfiguration("a", "no-mac", nic("", "10.0.0.1/24")),
+				newNetworkConfiguration("a", "bad-mac", nic("52:54:00:00:00:00:00:02", "10.0.0.2/24")),
+				newNetworkConfiguration("a", "no-address", nic("52:54:00:00:00:03")),
+				newNetworkConfiguration("a", "two-addresses", nic("52:54:00:00:00:04", "10.0.0.4/24", "10.0.0.5/24")),
+				newNetworkConfiguration("a", "no-prefix", nic("52:54:00:00:00:06", "10.0.0.6")),
+				newNetworkConfiguration("a", "web", nic("52:54:00:00:00:07", "10.0.0.7/24")),
+			},
+			want:    []string{"a/web"},
+			wantErr: ErrIncomplete,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reservations, err := Reservations("no-west-az1", tt.ncs)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, r := range reservations {
+				got = append(got, r.Namespace+"/"+r.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("reservations = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	gateway := newNetworkConfiguration("a", "web", nic("52:54:00:00:00:01", "10.0.0.1/24"))
+	gateway.Spec.NetworkInterfaces[0].IPv4Gateway = "10.1.0.1"
+	if _, err := Reservations("no-west-az1", []v1alpha1.NetworkConfiguration{gateway}); !errors.Is(err, ErrIncomplete) {
+		t.Errorf("gateway outside the subnet: %v, want ErrIncomplete", err)
+	}
+}
+
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s, run go test -update to accept it:\n%s", path, got)
+	}
+}
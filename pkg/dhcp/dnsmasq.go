@@ -0,0 +1,37 @@
+package dhcp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dnsmasq renders reservations as a dnsmasq configuration file of dhcp-host
+// lines, for conf-dir or conf-file. A reservation with a gateway or DNS
+// servers is tagged with its MAC address, which unlike the hostname is unique
+// among reservations, and gets them through tagged dhcp-option lines.
+func Dnsmasq(datacenter string, reservations []Reservation) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated from NetworkConfigurations of datacenter %s, do not edit.\n", datacenter)
+	for i := range reservations {
+		r := &reservations[i]
+		fmt.Fprintf(&b, "\n# %s/%s %s\n", r.Namespace, r.Name, r.Interface)
+		fields := []string{r.MAC.String()}
+		tag := ""
+		if r.Gateway.IsValid() || len(r.DNS) > 0 {
+			tag = strings.ReplaceAll(r.MAC.String(), ":", "")
+			fields = append(fields, "set:"+tag)
+		}
+		fields = append(fields, r.Address.Addr().String())
+		if r.Hostname != "" {
+			fields = append(fields, r.Hostname)
+		}
+		fmt.Fprintf(&b, "dhcp-host=%s\n", strings.Join(fields, ","))
+		if r.Gateway.IsValid() {
+			fmt.Fprintf(&b, "dhcp-option=tag:%s,option:router,%s\n", tag, r.Gateway)
+		}
+		if len(r.DNS) > 0 {
+			fmt.Fprintf(&b, "dhcp-option=tag:%s,option:dns-server,%s\n", tag, join(r.DNS, ","))
+		}
+	}
+	return b.String()
+}
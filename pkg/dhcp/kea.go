@@ -0,0 +1,83 @@
+package dhcp
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// KeaSubnetID returns the Kea subnet ID of a subnet: its network address as
+// a number. Subnets of a datacenter do not overlap, so their IDs differ, and
+// the ID of a subnet never changes.
+func KeaSubnetID(subnet netip.Prefix) uint32 {
+	b := subnet.Masked().Addr().As4()
+	return binary.BigEndian.Uint32(b[:])
+}
+
+// Kea renders reservations as the "Dhcp4" section of a Kea configuration,
+// with one subnet4 entry per subnet in the order of the reservations, to be
+// merged into the server configuration.
+func Kea(datacenter string, reservations []Reservation) ([]byte, error) {
+	subnets := []any{}
+	bySubnet := map[string]map[string]any{}
+	for i := range reservations {
+		r := &reservations[i]
+		if !r.Address.Addr().Is4() {
+			return nil, fmt.Errorf("%s: %s is not an IPv4 address", r, r.Address.Addr())
+		}
+		subnet := r.Subnet().String()
+		current, ok := bySubnet[subnet]
+		if !ok {
+			current = map[string]any{
+				"id":           KeaSubnetID(r.Subnet()),
+				"subnet":       subnet,
+				"reservations": []any{},
+			}
+			subnets = append(subnets, current)
+			bySubnet[subnet] = current
+		}
+		host := map[string]any{
+			"hw-address": r.MAC.String(),
+			"ip-address": r.Address.Addr().String(),
+			"user-context": map[string]any{
+				"networkConfiguration": r.Namespace + "/" + r.Name,
+				"interface":            r.Interface,
+			},
+		}
+		if r.Hostname != "" {
+			host["hostname"] = r.Hostname
+		}
+		var options []any
+		if r.Gateway.IsValid() {
+			options = append(options, map[string]any{"name": "routers", "data": r.Gateway.String()})
+		}
+		if len(r.DNS) > 0 {
+			options = append(options, map[string]any{"name": "domain-name-servers", "data": join(r.DNS, ", ")})
+		}
+		if options != nil {
+			host["option-data"] = options
+		}
+		current["reservations"] = append(current["reservations"].([]any), host)
+	}
+
+	out, err := json.MarshalIndent(map[string]any{
+		"Dhcp4": map[string]any{
+			"comment": fmt.Sprintf("Generated from NetworkConfigurations of datacenter %s, do not edit.", datacenter),
+			"subnet4": subnets,
+		},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+func join(addresses []netip.Addr, sep string) string {
+	s := make([]string, len(addresses))
+	for i, a := range addresses {
+		s[i] = a.String()
+	}
+	return strings.Join(s, sep)
+}
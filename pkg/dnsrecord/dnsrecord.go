@@ -0,0 +1,391 @@
+// Package dnsrecord derives DNSRecords for Machines, LoadBalancers and the API
+// endpoints of KubernetesProviders under the domain of a Vitistack, writes
+// them as RFC 1035 zone files and sends them to a DNS server as RFC 2136
+// dynamic updates.
+package dnsrecord
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultTTL is the TTL of generated records unless set in the options.
+const DefaultTTL int32 = 300
+
+// VitistackLabel is set on generated DNSRecords to the name of their
+// Vitistack.
+const VitistackLabel = "vitistack.io/vitistack"
+
+// Source kinds.
+const (
+	KindMachine            = "Machine"
+	KindLoadBalancer       = "LoadBalancer"
+	KindKubernetesProvider = "KubernetesProvider"
+)
+
+// ErrConflict is wrapped by errors of names claimed by several objects.
+var ErrConflict = errors.New("DNS name conflict")
+
+// Sources are the objects records are generated for. Any of them may be
+// empty.
+type Sources struct {
+	Machines            []v1alpha1.Machine
+	LoadBalancers       []v1alpha1.LoadBalancer
+	KubernetesProviders []v1alpha1.KubernetesProvider
+}
+
+// Options tune generated records.
+type Options struct {
+	// TTL of the records, default DefaultTTL.
+	TTL int32
+	// Reverse adds PTR records in /24 reverse zones for IPv4 and /64 reverse
+	// zones for IPv6. An address of several objects points at the first of
+	// them, Machines before LoadBalancers before KubernetesProviders.
+	Reverse bool
+}
+
+var labelPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// Generate derives the records of the objects under the domain of a
+// Vitistack:
+//
+//   - <hostname>.<namespace>.<domain> for a Machine, with the first label of
+//     its hostname or else its name
+//   - <name>.lb.<namespace>.<domain> for a LoadBalancer
+//   - api.<name>.<domain> for the API endpoint of a KubernetesProvider, a
+//     CNAME when the endpoint is a host name
+//
+// Objects without addresses get no records. A name claimed by several objects
+// is an error wrapping ErrConflict, and none of these objects get records, so
+// that a name never points at the wrong object. The records are sorted by
+// zone, name and type and returned together with an error that joins one
+// error per problem.
+func Generate(vs *v1alpha1.Vitistack, src *Sources, opts Options) ([]v1alpha1.DNSRecord, error) {
+	zone := CanonicalName(vs.Spec.Networking.DNS.Domain)
+	if zone == "" {
+		return nil, fmt.Errorf("Vitistack %s has no DNS domain", vs.Name)
+	}
+	if opts.TTL == 0 {
+		opts.TTL = DefaultTTL
+	}
+	g := &generator{vitistack: vs.Name, zone: zone, ttl: opts.TTL, owners: map[string]v1alpha1.DNSRecordSource{}}
+
+	for i := range src.Machines {
+		m := &src.Machines[i]
+		label := m.Name
+		if m.Status.Hostname != "" {
+			label, _, _ = strings.Cut(m.Status.Hostname, ".")
+		}
+		source := v1alpha1.DNSRecordSource{Kind: KindMachine, Namespace: m.Namespace, Name: m.Name}
+		addresses := slices.Concat(m.Status.IPAddresses, m.Status.IPv6Addresses)
+		g.addresses(source, []string{strings.ToLower(label), m.Namespace}, addresses)
+	}
+	for i := range src.LoadBalancers {
+		lb := &src.LoadBalancers[i]
+		source := v1alpha1.DNSRecordSource{Kind: KindLoadBalancer, Namespace: lb.Namespace, Name: lb.Name}
+		g.addresses(source, []string{lb.Name, "lb", lb.Namespace}, lb.Status.LoadBalancerIps)
+	}
+	for i := range src.KubernetesProviders {
+		kp := &src.KubernetesProviders[i]
+		endpoint := kp.Status.Endpoints.APIServer
+		if endpoint == "" {
+			continue
+		}
+		source := v1alpha1.DNSRecordSource{Kind: KindKubernetesProvider, Name: kp.Name}
+		host := endpointHost(endpoint)
+		if _, err := netip.ParseAddr(host); err == nil {
+			g.addresses(source, []string{"api", kp.Name}, []string{host})
+			continue
+		}
+		target := CanonicalName(host)
+		if !validName(target) {
+			g.errs = append(g.errs, fmt.Errorf("%s: API endpoint %q has no valid host", describe(source), endpoint))
+			continue
+		}
+		name, ok := g.name(source, []string{"api", kp.Name})
+		if ok && target != name+"."+zone {
+			g.add(source, zone, name, v1alpha1.DNSRecordTypeCNAME, []string{target + "."})
+		}
+	}
+
+	if opts.Reverse {
+		g.reverse()
+	}
+
+	var records []v1alpha1.DNSRecord
+	for _, r := range g.records {
+		if !g.conflicting[owner(r.Spec.Source)] {
+			records = append(records, r)
+		}
+	}
+	slices.SortFunc(records, compare)
+	return records, errors.Join(g.errs...)
+}
+
+type generator struct {
+	vitistack string
+	zone      string
+	ttl       int32
+	records   []v1alpha1.DNSRecord
+	// owners maps the fully qualified names to the object claiming them.
+	owners map[string]v1alpha1.DNSRecordSource
+	// pointers maps the addresses to the names pointing at them, in order.
+	pointers    map[netip.Addr][]pointer
+	conflicting map[string]bool
+	errs        []error
+}
+
+type pointer struct {
+	source v1alpha1.DNSRecordSource
+	fqdn   string
+}
+
+// name joins labels into a name relative to the zone and claims it for an
+// object.
+func (g *generator) name(source v1alpha1.DNSRecordSource, labels []string) (string, bool) {
+	for _, l := range labels {
+		if !labelPattern.MatchString(l) {
+			g.errs = append(g.errs, fmt.Errorf("%s: %q is not a valid DNS label", describe(source), l))
+			return "", false
+		}
+	}
+	name := strings.Join(labels, ".")
+	fqdn := name + "." + g.zone
+	if other, ok := g.owners[fqdn]; ok && other != source {
+		g.conflict(source, other, "%s: name %s is also claimed by %s", describe(source), fqdn, describe(other))
+		return "", false
+	}
+	g.owners[fqdn] = source
+	return name, true
+}
+
+// addresses adds the A and AAAA records of an object.
+func (g *generator) addresses(source v1alpha1.DNSRecordSource, labels []string, addresses []string) {
+	var v4, v6 []netip.Addr
+	for _, s := range addresses {
+		a, err := netip.ParseAddr(strings.TrimSpace(s))
+		if err != nil {
+			g.errs = append(g.errs, fmt.Errorf("%s: %q is not an address", describe(source), s))
+			continue
+		}
+		a = a.Unmap()
+		if a.Is4() {
+			v4 = append(v4, a)
+		} else {
+			v6 = append(v6, a.WithZone(""))
+		}
+	}
+	if len(v4) == 0 && len(v6) == 0 {
+		return
+	}
+	name, ok := g.name(source, labels)
+	if !ok {
+		return
+	}
+	for _, set := range []struct {
+		rrtype    string
+		addresses []netip.Addr
+	}{
+		{v1alpha1.DNSRecordTypeA, v4},
+		{v1alpha1.DNSRecordTypeAAAA, v6},
+	} {
+		if len(set.addresses) == 0 {
+			continue
+		}
+		slices.SortFunc(set.addresses, netip.Addr.Compare)
+		set.addresses = slices.Compact(set.addresses)
+		values := make([]string, len(set.addresses))
+		for i, a := range set.addresses {
+			values[i] = a.String()
+			if g.pointers == nil {
+				g.pointers = map[netip.Addr][]pointer{}
+			}
+			g.pointers[a] = append(g.pointers[a], pointer{source, name + "." + g.zone})
+		}
+		g.add(source, g.zone, name, set.rrtype, values)
+	}
+}
+
+// reverse adds the PTR records of the addresses, pointing at the first name
+// of an object not in a conflict.
+func (g *generator) reverse() {
+	addresses := make([]netip.Addr, 0, len(g.pointers))
+	for a := range g.pointers {
+		addresses = append(addresses, a)
+	}
+	slices.SortFunc(addresses, netip.Addr.Compare)
+	for _, a := range addresses {
+		i := slices.IndexFunc(g.pointers[a], func(p pointer) bool { return !g.conflicting[owner(&p.source)] })
+		if i < 0 {
+			continue
+		}
+		p := g.pointers[a][i]
+		zone, name := ReverseName(a)
+		g.add(p.source, zone, name, v1alpha1.DNSRecordTypePTR, []string{p.fqdn + "."})
+	}
+}
+
+func (g *generator) conflict(a, b v1alpha1.DNSRecordSource, format string, args ...any) {
+	g.errs = append(g.errs, fmt.Errorf("%w: "+format, append([]any{ErrConflict}, args...)...))
+	if g.conflicting == nil {
+		g.conflicting = map[string]bool{}
+	}
+	g.conflicting[owner(&a)] = true
+	g.conflicting[owner(&b)] = true
+}
+
+func (g *generator) add(source v1alpha1.DNSRecordSource, zone, name, rrtype string, values []string) {
+	fqdn := name + "." + zone
+	g.records = append(g.records, v1alpha1.DNSRecord{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "DNSRecord",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: strings.ToLower(fqdn + "-" + rrtype),
+			Labels: map[string]string{
+				VitistackLabel:                g.vitistack,
+				v1alpha1.DNSRecordSourceLabel: source.Kind,
+			},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			Zone:   zone,
+			Name:   name,
+			Type:   rrtype,
+			Values: values,
+			TTL:    g.ttl,
+			Source: &source,
+		},
+	})
+}
+
+// ReverseName returns the reverse zone and the name in it of an address: a
+// /24 in-addr.arpa zone for IPv4 and a /64 ip6.arpa zone for IPv6.
+func ReverseName(a netip.Addr) (zone, name string) {
+	a = a.Unmap()
+	if a.Is4() {
+		b := a.As4()
+		return fmt.Sprintf("%d.%d.%d.in-addr.arpa", b[2], b[1], b[0]), fmt.Sprintf("%d", b[3])
+	}
+	b := a.As16()
+	nibbles := make([]string, 0, 32)
+	for i := len(b) - 1; i >= 0; i-- {
+		nibbles = append(nibbles, fmt.Sprintf("%x", b[i]&0xf), fmt.Sprintf("%x", b[i]>>4))
+	}
+	return strings.Join(nibbles[16:], ".") + ".ip6.arpa", strings.Join(nibbles[:16], ".")
+}
+
+// CanonicalName returns a domain name in lower case without the trailing
+// dot.
+func CanonicalName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
+// FQDN returns the fully qualified name of a record without the trailing
+// dot.
+func FQDN(r *v1alpha1.DNSRecord) string {
+	zone := CanonicalName(r.Spec.Zone)
+	if r.Spec.Name == "@" || r.Spec.Name == "" {
+		return zone
+	}
+	return CanonicalName(r.Spec.Name) + "." + zone
+}
+
+// Validate checks the values of a record against its type: IPv4 addresses
+// for A, IPv6 addresses for AAAA, a single absolute domain name for CNAME and
+// PTR.
+func Validate(r *v1alpha1.DNSRecord) error {
+	what := fmt.Sprintf("DNSRecord %s %s", FQDN(r), r.Spec.Type)
+	if !validName(FQDN(r)) {
+		return fmt.Errorf("DNSRecord %s: %q is not a valid name in zone %q", r.Name, r.Spec.Name, r.Spec.Zone)
+	}
+	if len(r.Spec.Values) == 0 {
+		return fmt.Errorf("%s has no values", what)
+	}
+	var errs []error
+	switch r.Spec.Type {
+	case v1alpha1.DNSRecordTypeA, v1alpha1.DNSRecordTypeAAAA:
+		for _, v := range r.Spec.Values {
+			a, err := netip.ParseAddr(v)
+			if err != nil || a.Zone() != "" || a.Is4() != (r.Spec.Type == v1alpha1.DNSRecordTypeA) || a.Is4In6() {
+				errs = append(errs, fmt.Errorf("%s: %q is not an %s address", what, v, family(r.Spec.Type)))
+			}
+		}
+	case v1alpha1.DNSRecordTypeCNAME, v1alpha1.DNSRecordTypePTR:
+		if len(r.Spec.Values) > 1 {
+			errs = append(errs, fmt.Errorf("%s takes one value", what))
+		}
+		for _, v := range r.Spec.Values {
+			if !strings.HasSuffix(v, ".") || !validName(CanonicalName(v)) {
+				errs = append(errs, fmt.Errorf("%s: %q is not an absolute domain name", what, v))
+			}
+		}
+	default:
+		errs = append(errs, fmt.Errorf("DNSRecord %s: unsupported type %q", FQDN(r), r.Spec.Type))
+	}
+	if r.Spec.TTL < 0 {
+		errs = append(errs, fmt.Errorf("%s: negative TTL %d", what, r.Spec.TTL))
+	}
+	return errors.Join(errs...)
+}
+
+func family(rrtype string) string {
+	if rrtype == v1alpha1.DNSRecordTypeA {
+		return "IPv4"
+	}
+	return "IPv6"
+}
+
+// validName tells whether a name without the trailing dot can be encoded.
+func validName(name string) bool {
+	if name == "" || len(name) > 253 {
+		return false
+	}
+	for _, l := range strings.Split(name, ".") {
+		if l == "" || len(l) > 63 {
+			return false
+		}
+	}
+	return true
+}
+
+// endpointHost returns the host of an endpoint URL or host:port.
+func endpointHost(endpoint string) string {
+	endpoint = strings.TrimSpace(endpoint)
+	if strings.Contains(endpoint, "://") {
+		if u, err := url.Parse(endpoint); err == nil {
+			return u.Hostname()
+		}
+	}
+	if host, _, err := net.SplitHostPort(endpoint); err == nil {
+		return host
+	}
+	return strings.Trim(endpoint, "[]")
+}
+
+func describe(s v1alpha1.DNSRecordSource) string {
+	if s.Namespace == "" {
+		return s.Kind + " " + s.Name
+	}
+	return fmt.Sprintf("%s %s/%s", s.Kind, s.Namespace, s.Name)
+}
+
+func owner(s *v1alpha1.DNSRecordSource) string {
+	return s.Kind + "/" + s.Namespace + "/" + s.Name
+}
+
+func compare(a, b v1alpha1.DNSRecord) int {
+	return slices.Compare(
+		[]string{a.Spec.Zone, a.Spec.Name, a.Spec.Type},
+		[]string{b.Spec.Zone, b.Spec.Name, b.Spec.Type},
+	)
+}
@@ -0,0 +1,337 @@
+package dnsrecord
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+// TSIG algorithms.
+const (
+	HMACSHA256 = "hmac-sha256"
+	HMACSHA512 = "hmac-sha512"
+)
+
+// DefaultFudge is the TSIG time window in seconds.
+const DefaultFudge = 300
+
+// ErrRefused is wrapped by errors of updates the server did not apply.
+var ErrRefused = errors.New("DNS update refused")
+
+// DNS wire constants.
+const (
+	opcodeUpdate = 5
+	classIN      = 1
+	classANY     = 255
+	typeSOA      = 6
+	typeTSIG     = 250
+)
+
+var rrTypes = map[string]uint16{
+	v1alpha1.DNSRecordTypeA:     1,
+	v1alpha1.DNSRecordTypeCNAME: 5,
+	v1alpha1.DNSRecordTypePTR:   12,
+	v1alpha1.DNSRecordTypeAAAA:  28,
+}
+
+var rcodes = []string{"NOERROR", "FORMERR", "SERVFAIL", "NXDOMAIN", "NOTIMP", "REFUSED", "YXDOMAIN", "YXRRSET", "NXRRSET", "NOTAUTH", "NOTZONE"}
+
+// RRSet is every value of one type at one name.
+type RRSet struct {
+	// Name is fully qualified, without the trailing dot.
+	Name   string
+	Type   string
+	TTL    int32
+	Values []string
+}
+
+// Update is an RFC 2136 dynamic update of one zone. Deleted RRsets are
+// removed before added ones are added, so changing an RRset deletes and adds
+// it.
+type Update struct {
+	Zone   string
+	Delete []RRSet
+	Add    []RRSet
+}
+
+// Empty tells whether the update changes nothing.
+func (u *Update) Empty() bool {
+	return len(u.Delete) == 0 && len(u.Add) == 0
+}
+
+// Diff returns the update that turns the old records of a zone into the
+// current ones. Records of other zones are skipped; the current records must
+// be valid, see ZoneFile. A record without a TTL gets DefaultTTL.
+func Diff(zone string, old, current []v1alpha1.DNSRecord) (*Update, error) {
+	zone = CanonicalName(zone)
+	var own []v1alpha1.DNSRecord
+	for i := range current {
+		if CanonicalName(current[i].Spec.Zone) == zone {
+			own = append(own, current[i])
+		}
+	}
+	if err := checkZone(own); err != nil {
+		return nil, err
+	}
+	before, after := rrsets(zone, old), rrsets(zone, own)
+	u := &Update{Zone: zone}
+	for key, set := range before {
+		if _, ok := after[key]; !ok {
+			u.Delete = append(u.Delete, set)
+		}
+	}
+	for key, set := range after {
+		prev, ok := before[key]
+		switch {
+		case !ok:
+			u.Add = append(u.Add, set)
+		case prev.TTL != set.TTL || !slices.Equal(prev.Values, set.Values):
+			u.Delete = append(u.Delete, prev)
+			u.Add = append(u.Add, set)
+		}
+	}
+	for _, sets := range [][]RRSet{u.Delete, u.Add} {
+		slices.SortFunc(sets, func(a, b RRSet) int {
+			return slices.Compare([]string{a.Name, a.Type}, []string{b.Name, b.Type})
+		})
+	}
+	return u, nil
+}
+
+// rrsets returns the RRsets of the records of a zone by name and type, with
+// sorted values.
+func rrsets(zone string, records []v1alpha1.DNSRecord) map[string]RRSet {
+	out := map[string]RRSet{}
+	for i := range records {
+		r := &records[i]
+		if CanonicalName(r.Spec.Zone) != zone {
+			continue
+		}
+		ttl := r.Spec.TTL
+		if ttl == 0 {
+			ttl = DefaultTTL
+		}
+		values := make([]string, len(r.Spec.Values))
+		for j, v := range r.Spec.Values {
+			values[j] = canonicalValue(r.Spec.Type, v)
+		}
+		slices.Sort(values)
+		set := RRSet{Name: FQDN(r), Type: r.Spec.Type, TTL: ttl, Values: slices.Compact(values)}
+		out[set.Name+" "+set.Type] = set
+	}
+	return out
+}
+
+func canonicalValue(rrtype, v string) string {
+	switch rrtype {
+	case v1alpha1.DNSRecordTypeA, v1alpha1.DNSRecordTypeAAAA:
+		if a, err := netip.ParseAddr(v); err == nil {
+			return a.String()
+		}
+		return v
+	}
+	return absolute(v)
+}
+
+// TSIGKey signs updates as in RFC 8945.
+type TSIGKey struct {
+	// Name of the key as configured on the server.
+	Name string
+	// Algorithm, HMACSHA256 or HMACSHA512. Default HMACSHA256.
+	Algorithm string
+	// Secret is the decoded key.
+	Secret []byte
+}
+
+// Pack encodes an update as a DNS message with an ID, signed with a TSIG key
+// at time now when one is given.
+func (u *Update) Pack(id uint16, key *TSIGKey, now time.Time) ([]byte, error) {
+	var errs []error
+	msg := binary.BigEndian.AppendUint16(nil, id)
+	msg = binary.BigEndian.AppendUint16(msg, opcodeUpdate<<11)
+	msg = binary.BigEndian.AppendUint16(msg, 1) // zone
+	msg = binary.BigEndian.AppendUint16(msg, 0) // prerequisites
+	updates := 0
+	for _, set := range u.Add {
+		updates += len(set.Values)
+	}
+	msg = binary.BigEndian.AppendUint16(msg, uint16(len(u.Delete)+updates))
+	msg = binary.BigEndian.AppendUint16(msg, 0) // additional
+
+	msg, err := appendName(msg, u.Zone)
+	if err != nil {
+		return nil, err
+	}
+	msg = binary.BigEndian.AppendUint16(msg, typeSOA)
+	msg = binary.BigEndian.AppendUint16(msg, classIN)
+
+	// Deleting an RRset is an RR of class ANY with no data.
+	for _, set := range u.Delete {
+		if msg, err = appendRR(msg, set.Name, set.Type, classANY, 0, nil); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, set := range u.Add {
+		for _, v := range set.Values {
+			data, err := rdata(set.Type, v)
+			if err == nil {
+				msg, err = appendRR(msg, set.Name, set.Type, classIN, uint32(set.TTL), data)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", set.Name, set.Type, err))
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return msg, nil
+	}
+	return sign(msg, key, now)
+}
+
+func appendRR(msg []byte, name, rrtype string, class uint16, ttl uint32, data []byte) ([]byte, error) {
+	code, ok := rrTypes[rrtype]
+	if !ok {
+		return msg, fmt.Errorf("unsupported type %q", rrtype)
+	}
+	msg, err := appendName(msg, name)
+	if err != nil {
+		return msg, err
+	}
+	msg = binary.BigEndian.AppendUint16(msg, code)
+	msg = binary.BigEndian.AppendUint16(msg, class)
+	msg = binary.BigEndian.AppendUint32(msg, ttl)
+	msg = binary.BigEndian.AppendUint16(msg, uint16(len(data)))
+	return append(msg, data...), nil
+}
+
+func rdata(rrtype, value string) ([]byte, error) {
+	switch rrtype {
+	case v1alpha1.DNSRecordTypeA, v1alpha1.DNSRecordTypeAAAA:
+		a, err := netip.ParseAddr(value)
+		if err != nil || a.Is4() != (rrtype == v1alpha1.DNSRecordTypeA) {
+			return nil, fmt.Errorf("%q is not an %s address", value, family(rrtype))
+		}
+		return a.AsSlice(), nil
+	}
+	return appendName(nil, value)
+}
+
+// appendName encodes a domain name without compression.
+func appendName(msg []byte, name string) ([]byte, error) {
+	name = CanonicalName(name)
+	if !validName(name) {
+		return msg, fmt.Errorf("invalid domain name %q", name)
+	}
+	for _, label := range strings.Split(name, ".") {
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	return append(msg, 0), nil
+}
+
+// sign appends a TSIG record to a message.
+func sign(msg []byte, key *TSIGKey, now time.Time) ([]byte, error) {
+	var h func() hash.Hash
+	name := key.Algorithm
+	switch name {
+	case HMACSHA256, "":
+		h, name = sha256.New, HMACSHA256
+	case HMACSHA512:
+		h = sha512.New
+	default:
+		return nil, fmt.Errorf("unsupported TSIG algorithm %q", key.Algorithm)
+	}
+	keyName, err := appendName(nil, key.Name)
+	if err != nil {
+		return nil, fmt.Errorf("TSIG key: %w", err)
+	}
+	algorithm, _ := appendName(nil, name)
+	signed := uint64(now.Unix())
+	timers := binary.BigEndian.AppendUint16(nil, uint16(signed>>32))
+	timers = binary.BigEndian.AppendUint32(timers, uint32(signed))
+	timers = binary.BigEndian.AppendUint16(timers, DefaultFudge)
+
+	// The MAC covers the message and the TSIG variables of RFC 8945 4.3.3.
+	mac := hmac.New(h, key.Secret)
+	mac.Write(msg)
+	mac.Write(keyName)
+	mac.Write(binary.BigEndian.AppendUint16(nil, classANY))
+	mac.Write(binary.BigEndian.AppendUint32(nil, 0))
+	mac.Write(algorithm)
+	mac.Write(timers)
+	mac.Write([]byte{0, 0, 0, 0}) // error, other length
+	sum := mac.Sum(nil)
+
+	data := slices.Concat(algorithm, timers)
+	data = binary.BigEndian.AppendUint16(data, uint16(len(sum)))
+	data = append(data, sum...)
+	data = append(data, msg[0], msg[1]) // original ID
+	data = append(data, 0, 0, 0, 0)     // error, other length
+	out := slices.Clone(msg)
+	binary.BigEndian.PutUint16(out[10:], binary.BigEndian.Uint16(out[10:])+1)
+	out = append(out, keyName...)
+	out = binary.BigEndian.AppendUint16(out, typeTSIG)
+	out = binary.BigEndian.AppendUint16(out, classANY)
+	out = binary.BigEndian.AppendUint32(out, 0)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(data)))
+	return append(out, data...), nil
+}
+
+// Send sends a packed update to a server over TCP, host:port, and waits for
+// the answer. An answer other than NOERROR is an error wrapping ErrRefused.
+// The TSIG record of the answer is not verified.
+func Send(ctx context.Context, server string, msg []byte) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", server)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	if _, err := conn.Write(binary.BigEndian.AppendUint16(nil, uint16(len(msg)))); err != nil {
+		return err
+	}
+	if _, err := conn.Write(msg); err != nil {
+		return err
+	}
+	var length [2]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		return fmt.Errorf("reading answer of %s: %w", server, err)
+	}
+	answer := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(conn, answer); err != nil {
+		return fmt.Errorf("reading answer of %s: %w", server, err)
+	}
+	if len(answer) < 12 || answer[0] != msg[0] || answer[1] != msg[1] || answer[2]&0x80 == 0 {
+		return fmt.Errorf("%s sent an invalid answer", server)
+	}
+	if rcode := int(answer[3] & 0x0f); rcode != 0 {
+		name := fmt.Sprintf("RCODE %d", rcode)
+		if rcode < len(rcodes) {
+			name = rcodes[rcode]
+		}
+		return fmt.Errorf("%w by %s: %s", ErrRefused, server, name)
+	}
+	return nil
+}
@@ -0,0 +1,391 @@
+package dnsrecord
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+func TestDiff(t *testing.T) {
+	old := []v1alpha1.DNSRecord{
+		record("example.com", "www", v1alpha1.DNSRecordTypeA, 0, "192.0.2.80", "192.0.2.81"),
+		record("example.com", "gone", v1alpha1.DNSRecordTypeA, 0, "192.0.2.9"),
+		record("example.com", "api", v1alpha1.DNSRecordTypeAAAA, 0, "2001:db8::1"),
+		record("example.com", "mail", v1alpha1.DNSRecordTypeCNAME, 0, "mail.example.net."),
+		record("example.com", "ftp", v1alpha1.DNSRecordTypeCNAME, 0, "files.example.net."),
+		record("example.org", "www", v1alpha1.DNSRecordTypeA, 0, "192.0.2.1"),
+	}
+	current := []v1alpha1.DNSRecord{
+		// Order, case and spelling of values do not matter.
+		record("example.com", "www", v1alpha1.DNSRecordTypeA, DefaultTTL, "192.0.2.81", "192.0.2.80", "192.0.2.81"),
+		record("Example.com.", "MAIL", v1alpha1.DNSRecordTypeCNAME, 0, "Mail.Example.NET."),
+		// A changed TTL or value replaces the RRset.
+		record("example.com", "api", v1alpha1.DNSRecordTypeAAAA, 60, "2001:DB8:0::1"),
+		record("example.com", "ftp", v1alpha1.DNSRecordTypeCNAME, 0, "ftp.example.net."),
+		record("example.com", "new", v1alpha1.DNSRecordTypeAAAA, 0, "2001:db8::2"),
+		record("example.com", "new", v1alpha1.DNSRecordTypeA, 0, "192.0.2.2"),
+		record("example.com", "@", v1alpha1.DNSRecordTypeA, 0, "192.0.2.1"),
+		// Other zones are skipped, even when invalid.
+		record("example.org", "www", v1alpha1.DNSRecordTypeCNAME, 0, "example.com."),
+		record("example.org", "www", v1alpha1.DNSRecordTypeA, 0, "192.0.2.1"),
+	}
+
+	u, err := Diff("example.com.", old, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDelete := []RRSet{
+		{Name: "api.example.com", Type: "AAAA", TTL: DefaultTTL, Values: []string{"2001:db8::1"}},
+		{Name: "ftp.example.com", Type: "CNAME", TTL: DefaultTTL, Values: []string{"files.example.net."}},
+		{Name: "gone.example.com", Type: "A", TTL: DefaultTTL, Values: []string{"192.0.2.9"}},
+	}
+	wantAdd := []RRSet{
+		{Name: "api.example.com", Type: "AAAA", TTL: 60, Values: []string{"2001:db8::1"}},
+		{Name: "example.com", Type: "A", TTL: DefaultTTL, Values: []string{"192.0.2.1"}},
+		{Name: "ftp.example.com", Type: "CNAME", TTL: DefaultTTL, Values: []string{"ftp.example.net."}},
+		{Name: "new.example.com", Type: "A", TTL: DefaultTTL, Values: []string{"192.0.2.2"}},
+		{Name: "new.example.com", Type: "AAAA", TTL: DefaultTTL, Values: []string{"2001:db8::2"}},
+	}
+	if !slices.EqualFunc(u.Delete, wantDelete, equalRRSet) {
+		t.Errorf("Delete = %+v\nwant %+v", u.Delete, wantDelete)
+	}
+	if !slices.EqualFunc(u.Add, wantAdd, equalRRSet) {
+		t.Errorf("Add = %+v\nwant %+v", u.Add, wantAdd)
+	}
+
+	if u, err := Diff("example.com", current, current); err != nil || !u.Empty() {
+		t.Errorf("Diff without changes = %+v, %v, want an empty update", u, err)
+	}
+	conflict := append(slices.Clone(current), record("example.com", "www", v1alpha1.DNSRecordTypeCNAME, 0, "lb.example.com."))
+	if _, err := Diff("example.com", old, conflict); !errors.Is(err, ErrConflict) {
+		t.Errorf("error = %v, want ErrConflict", err)
+	}
+}
+
+func equalRRSet(a, b RRSet) bool {
+	return a.Name == b.Name && a.Type == b.Type && a.TTL == b.TTL && slices.Equal(a.Values, b.Values)
+}
+
+func TestSend(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	u := &Update{
+		Zone: "example.com",
+		Delete: []RRSet{
+			{Name: "api.example.com", Type: "AAAA"},
+			{Name: "gone.example.com", Type: "A"},
+		},
+		Add: []RRSet{
+			{Name: "api.example.com", Type: "AAAA", TTL: 60, Values: []string{"2001:db8::1"}},
+			{Name: "www.example.com", Type: "A", TTL: 300, Values: []string{"192.0.2.80", "192.0.2.81"}},
+			{Name: "ftp.example.com", Type: "CNAME", TTL: 300, Values: []string{"ftp.example.net."}},
+			{Name: "80.2.0.192.in-addr.arpa", Type: "PTR", TTL: 300, Values: []string{"www.example.com."}},
+		},
+	}
+	// Deletes come before adds, in the order of the update.
+	wantUpdates := []string{
+		"ANY 0 api.example.com AAAA",
+		"ANY 0 gone.example.com A",
+		"IN 60 api.example.com AAAA 2001:db8::1",
+		"IN 300 www.example.com A 192.0.2.80",
+		"IN 300 www.example.com A 192.0.2.81",
+		"IN 300 ftp.example.com CNAME ftp.example.net",
+		"IN 300 80.2.0.192.in-addr.arpa PTR www.example.com",
+	}
+
+	tests := []struct {
+		name    string
+		key     *TSIGKey
+		wantErr bool
+	}{
+		{name: "hmac-sha256", key: &TSIGKey{Name: "update-key", Secret: secret}},
+		{name: "hmac-sha512", key: &TSIGKey{Name: "Update-Key.", Algorithm: HMACSHA512, Secret: secret}},
+		{name: "wrong secret", key: &TSIGKey{Name: "update-key", Secret: []byte("wrong")}, wantErr: true},
+		{name: "wrong key name", key: &TSIGKey{Name: "other-key", Secret: secret}, wantErr: true},
+		{name: "unsigned", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := startServer(t, "update-key", secret)
+			msg, err := u.Pack(4242, tt.key, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			err = Send(ctx, srv.addr, msg)
+			if tt.wantErr {
+				if !errors.Is(err, ErrRefused) || !strings.Contains(err.Error(), "NOTAUTH") {
+					t.Errorf("error = %v, want NOTAUTH", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := <-srv.updates
+			if got.zone != "example.com" || !slices.Equal(got.records, wantUpdates) {
+				t.Errorf("server got zone %s, updates\n%s\nwant\n%s", got.zone, strings.Join(got.records, "\n"), strings.Join(wantUpdates, "\n"))
+			}
+		})
+	}
+}
+
+func TestPackErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		u    Update
+		key  *TSIGKey
+	}{
+		{name: "invalid zone", u: Update{Zone: "example..com"}},
+		{name: "unsupported type", u: Update{Zone: "example.com", Delete: []RRSet{{Name: "example.com", Type: "MX"}}}},
+		{name: "IPv6 address in an A record", u: Update{Zone: "example.com", Add: []RRSet{{Name: "www.example.com", Type: "A", Values: []string{"2001:db8::1"}}}}},
+		{name: "unsupported algorithm", u: Update{Zone: "example.com"}, key: &TSIGKey{Name: "k", Algorithm: "hmac-md5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.u.Pack(1, tt.key, time.Now()); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// server is an RFC 2136 server that accepts updates signed with one TSIG key
+// and answers NOTAUTH to others.
+type server struct {
+	addr    string
+	key     string
+	secret  []byte
+	updates chan received
+}
+
+// received is an update as the server parsed it.
+type received struct {
+	zone    string
+	records []string
+}
+
+func startServer(t *testing.T, key string, secret []byte) *server {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	s := &server{addr: l.Addr().String(), key: key, secret: secret, updates: make(chan received, 1)}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			s.serve(t, conn)
+		}
+	}()
+	return s
+}
+
+func (s *server) serve(t *testing.T, conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	var length [2]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		t.Error(err)
+		return
+	}
+	msg := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(conn, msg); err != nil {
+		t.Error(err)
+		return
+	}
+
+	rcode := 0
+	update, err := s.parse(msg)
+	switch {
+	case errors.Is(err, errNotAuth):
+		rcode = 9
+	case err != nil:
+		t.Errorf("server: %v", err)
+		rcode = 1
+	default:
+		s.updates <- update
+	}
+	// The answer is a header without records, behind its length.
+	answer := binary.BigEndian.AppendUint16(nil, 12)
+	answer = append(answer, msg[0], msg[1])
+	answer = binary.BigEndian.AppendUint16(answer, 1<<15|opcodeUpdate<<11|uint16(rcode))
+	answer = append(answer, make([]byte, 8)...)
+	if _, err := conn.Write(answer); err != nil {
+		t.Error(err)
+	}
+}
+
+var errNotAuth = errors.New("not authenticated")
+
+// parse parses an update and verifies its TSIG record as in RFC 8945 5.2.
+func (s *server) parse(msg []byte) (received, error) {
+	var out received
+	if len(msg) < 12 || msg[2]>>3&0x0f != opcodeUpdate {
+		return out, errors.New("not an update")
+	}
+	zones, prerequisites := binary.BigEndian.Uint16(msg[4:]), binary.BigEndian.Uint16(msg[6:])
+	updates, additional := binary.BigEndian.Uint16(msg[8:]), binary.BigEndian.Uint16(msg[10:])
+	if zones != 1 || prerequisites != 0 {
+		return out, fmt.Errorf("%d zones and %d prerequisites", zones, prerequisites)
+	}
+	zone, off, err := readName(msg, 12)
+	if err != nil {
+		return out, err
+	}
+	if binary.BigEndian.Uint16(msg[off:]) != typeSOA || binary.BigEndian.Uint16(msg[off+2:]) != classIN {
+		return out, errors.New("zone section is not SOA IN")
+	}
+	out.zone = zone
+	off += 4
+	for range updates {
+		var rr resourceRecord
+		if rr, off, err = readRR(msg, off); err != nil {
+			return out, err
+		}
+		out.records = append(out.records, rr.String())
+	}
+
+	if additional != 1 {
+		return out, errNotAuth
+	}
+	signedEnd := off
+	tsig, off, err := readRR(msg, off)
+	if err != nil {
+		return out, err
+	}
+	if off != len(msg) || tsig.rrtype != typeTSIG || tsig.class != classANY {
+		return out, errors.New("the TSIG record is not the last record")
+	}
+	if tsig.name != s.key {
+		return out, errNotAuth
+	}
+	algorithm, n, err := readName(tsig.data, 0)
+	if err != nil {
+		return out, err
+	}
+	var h func() hash.Hash
+	switch algorithm {
+	case HMACSHA256:
+		h = sha256.New
+	case HMACSHA512:
+		h = sha512.New
+	default:
+		return out, errNotAuth
+	}
+	timers := tsig.data[n : n+8]
+	signed := int64(binary.BigEndian.Uint16(timers))<<32 | int64(binary.BigEndian.Uint32(timers[2:]))
+	fudge := int64(binary.BigEndian.Uint16(timers[6:]))
+	macSize := int(binary.BigEndian.Uint16(tsig.data[n+8:]))
+	got := tsig.data[n+10 : n+10+macSize]
+	originalID := tsig.data[n+10+macSize : n+12+macSize]
+	if now := time.Now().Unix(); signed < now-fudge || signed > now+fudge {
+		return out, errNotAuth
+	}
+
+	// The MAC covers the message without the TSIG record, with the original
+	// ID and additional count, followed by the TSIG variables.
+	unsigned := slices.Clone(msg[:signedEnd])
+	copy(unsigned, originalID)
+	binary.BigEndian.PutUint16(unsigned[10:], additional-1)
+	mac := hmac.New(h, s.secret)
+	mac.Write(unsigned)
+	mac.Write(msg[signedEnd : signedEnd+len(tsig.name)+2]) // key name
+	mac.Write(binary.BigEndian.AppendUint16(nil, tsig.class))
+	mac.Write(binary.BigEndian.AppendUint32(nil, tsig.ttl))
+	mac.Write(tsig.data[:n])                          // algorithm
+	mac.Write(timers)                                 // time signed, fudge
+	mac.Write(tsig.data[n+12+macSize : n+16+macSize]) // error, other length
+	if !hmac.Equal(mac.Sum(nil), got) {
+		return out, errNotAuth
+	}
+	return out, nil
+}
+
+type resourceRecord struct {
+	name          string
+	rrtype, class uint16
+	ttl           uint32
+	data          []byte
+}
+
+func (rr resourceRecord) String() string {
+	class := "IN"
+	if rr.class == classANY {
+		class = "ANY"
+	}
+	var rrtype string
+	for name, code := range rrTypes {
+		if code == rr.rrtype {
+			rrtype = name
+		}
+	}
+	s := fmt.Sprintf("%s %d %s %s", class, rr.ttl, rr.name, rrtype)
+	switch {
+	case len(rr.data) == 0:
+		return s
+	case rrtype == v1alpha1.DNSRecordTypeA || rrtype == v1alpha1.DNSRecordTypeAAAA:
+		a, _ := netip.AddrFromSlice(rr.data)
+		return s + " " + a.String()
+	}
+	name, _, _ := readName(rr.data, 0)
+	return s + " " + name
+}
+
+// readName reads an uncompressed domain name at off.
+func readName(msg []byte, off int) (string, int, error) {
+	var labels []string
+	for {
+		if off >= len(msg) {
+			return "", 0, errors.New("truncated name")
+		}
+		n := int(msg[off])
+		off++
+		if n == 0 {
+			return strings.Join(labels, "."), off, nil
+		}
+		if n > 63 || off+n > len(msg) {
+			return "", 0, errors.New("invalid label")
+		}
+		labels = append(labels, string(msg[off:off+n]))
+		off += n
+	}
+}
+
+func readRR(msg []byte, off int) (resourceRecord, int, error) {
+	var rr resourceRecord
+	var err error
+	if rr.name, off, err = readName(msg, off); err != nil {
+		return rr, 0, err
+	}
+	if off+10 > len(msg) {
+		return rr, 0, errors.New("truncated record")
+	}
+	rr.rrtype = binary.BigEndian.Uint16(msg[off:])
+	rr.class = binary.BigEndian.Uint16(msg[off+2:])
+	rr.ttl = binary.BigEndian.Uint32(msg[off+4:])
+	n := int(binary.BigEndian.Uint16(msg[off+8:]))
+	off += 10
+	if off+n > len(msg) {
+		return rr, 0, errors.New("truncated record data")
+	}
+	rr.data = msg[off : off+n]
+	return rr, off + n, nil
+}
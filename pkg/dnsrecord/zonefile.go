@@ -0,0 +1,172 @@
+package dnsrecord
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+// Default SOA timers, in seconds.
+const (
+	DefaultRefresh uint32 = 3600
+	DefaultRetry   uint32 = 600
+	DefaultExpire  uint32 = 1209600
+	DefaultMinimum uint32 = 300
+)
+
+// Zone describes a zone file. Without name servers the file holds only the
+// records and can be included in a zone with $INCLUDE.
+type Zone struct {
+	// Name of the zone, e.g. example.com.
+	Name string
+	// Serial of the SOA record, e.g. a timestamp in YYYYMMDDnn form.
+	Serial uint32
+	// NameServers of the zone, absolute. The first is the primary.
+	NameServers []string
+	// Admin is the mailbox of the zone administrator, e.g.
+	// hostmaster@example.com. Default hostmaster in the zone.
+	Admin string
+	// TTL of records without one, default DefaultTTL.
+	TTL int32
+	// SOA timers, the defaults above when zero.
+	Refresh, Retry, Expire, Minimum uint32
+}
+
+// Zones returns the zones of records, sorted.
+func Zones(records []v1alpha1.DNSRecord) []string {
+	var out []string
+	for i := range records {
+		out = append(out, CanonicalName(records[i].Spec.Zone))
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// ZoneFile writes the records of a zone as an RFC 1035 zone file. Records of
+// other zones are skipped. Invalid records and a CNAME next to other records
+// of the same name are errors.
+func ZoneFile(z Zone, records []v1alpha1.DNSRecord) (string, error) {
+	origin := CanonicalName(z.Name)
+	if !validName(origin) {
+		return "", fmt.Errorf("invalid zone name %q", z.Name)
+	}
+	ttl := z.TTL
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+
+	var own []v1alpha1.DNSRecord
+	for i := range records {
+		if CanonicalName(records[i].Spec.Zone) == origin {
+			own = append(own, records[i])
+		}
+	}
+	if err := checkZone(own); err != nil {
+		return "", err
+	}
+	slices.SortFunc(own, func(a, b v1alpha1.DNSRecord) int {
+		return slices.Compare([]string{relative(&a), a.Spec.Type}, []string{relative(&b), b.Spec.Type})
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "; Generated from DNSRecords of zone %s, do not edit.\n", origin)
+	fmt.Fprintf(&b, "$ORIGIN %s.\n", origin)
+	fmt.Fprintf(&b, "$TTL %d\n", ttl)
+	if len(z.NameServers) > 0 {
+		admin := z.Admin
+		if admin == "" {
+			admin = "hostmaster@" + origin
+		}
+		fmt.Fprintf(&b, "@\tIN\tSOA\t%s %s (\n", absolute(z.NameServers[0]), mailbox(admin))
+		for _, f := range []struct {
+			value   uint32
+			comment string
+		}{
+			{z.Serial, "serial"},
+			{orDefault(z.Refresh, DefaultRefresh), "refresh"},
+			{orDefault(z.Retry, DefaultRetry), "retry"},
+			{orDefault(z.Expire, DefaultExpire), "expire"},
+			{orDefault(z.Minimum, DefaultMinimum), "minimum"},
+		} {
+			fmt.Fprintf(&b, "\t\t%d\t; %s\n", f.value, f.comment)
+		}
+		b.WriteString("\t)\n")
+		for _, ns := range z.NameServers {
+			fmt.Fprintf(&b, "@\tIN\tNS\t%s\n", absolute(ns))
+		}
+	}
+	for i := range own {
+		r := &own[i]
+		// A record without a TTL gets the $TTL of the file.
+		owner := relative(r)
+		if r.Spec.TTL != 0 {
+			owner = fmt.Sprintf("%s\t%d", owner, r.Spec.TTL)
+		}
+		for _, v := range r.Spec.Values {
+			fmt.Fprintf(&b, "%s\tIN\t%s\t%s\n", owner, r.Spec.Type, v)
+		}
+	}
+	return b.String(), nil
+}
+
+// checkZone validates the records of a zone: each valid, one record per name
+// and type, and a CNAME alone at its name.
+func checkZone(records []v1alpha1.DNSRecord) error {
+	var errs []error
+	types := map[string][]string{}
+	var names []string
+	for i := range records {
+		r := &records[i]
+		if err := Validate(r); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		name := FQDN(r)
+		if _, ok := types[name]; !ok {
+			names = append(names, name)
+		}
+		if slices.Contains(types[name], r.Spec.Type) {
+			errs = append(errs, fmt.Errorf("%w: %s %s is defined twice", ErrConflict, name, r.Spec.Type))
+			continue
+		}
+		types[name] = append(types[name], r.Spec.Type)
+	}
+	for _, name := range names {
+		if len(types[name]) > 1 && slices.Contains(types[name], v1alpha1.DNSRecordTypeCNAME) {
+			errs = append(errs, fmt.Errorf("%w: %s has a CNAME and other records", ErrConflict, name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// relative returns the owner name of a record in its zone file.
+func relative(r *v1alpha1.DNSRecord) string {
+	if r.Spec.Name == "" || r.Spec.Name == "@" {
+		return "@"
+	}
+	return CanonicalName(r.Spec.Name)
+}
+
+func absolute(name string) string {
+	return CanonicalName(name) + "."
+}
+
+// mailbox returns the domain name form of a mailbox, e.g.
+// hostmaster.example.com. for hostmaster@example.com.
+func mailbox(admin string) string {
+	local, domain, ok := strings.Cut(admin, "@")
+	if !ok {
+		return absolute(admin)
+	}
+	return strings.ReplaceAll(local, ".", `\.`) + "." + absolute(domain)
+}
+
+func orDefault(v, def uint32) uint32 {
+	if v == 0 {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,142 @@
+package dnsrecord
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func record(zone, name, rrtype string, ttl int32, values ...string) v1alpha1.DNSRecord {
+	return v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Name: name + "-" + rrtype},
+		Spec:       v1alpha1.DNSRecordSpec{Zone: zone, Name: name, Type: rrtype, TTL: ttl, Values: values},
+	}
+}
+
+// zoneRecords are the records of example.com, in no order, and one of
+// another zone.
+var zoneRecords = []v1alpha1.DNSRecord{
+	record("example.com", "www", v1alpha1.DNSRecordTypeAAAA, 0, "2001:db8::80"),
+	record("example.com", "www", v1alpha1.DNSRecordTypeA, 0, "192.0.2.80", "192.0.2.81"),
+	record("example.com", "api.prod", v1alpha1.DNSRecordTypeCNAME, 60, "lb.prod.example.com."),
+	record("example.com.", "@", v1alpha1.DNSRecordTypeA, 3600, "192.0.2.1"),
+	record("Example.COM", "LB.Prod", v1alpha1.DNSRecordTypeA, 0, "192.0.2.10"),
+	record("2.0.192.in-addr.arpa", "80", v1alpha1.DNSRecordTypePTR, 0, "www.example.com."),
+}
+
+func TestZoneFileGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		zone Zone
+	}{
+		{
+			name: "example.com.zone",
+			zone: Zone{Name: "example.com", Serial: 2026101801, NameServers: []string{"ns1.example.com", "ns2.example.net."}, Admin: "dns.admin@example.com"},
+		},
+		{
+			name: "example.com.include",
+			zone: Zone{Name: "example.com.", TTL: 600},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ZoneFile(tt.zone, zoneRecords)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, tt.name, got)
+		})
+	}
+}
+
+func TestZoneFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		zone    string
+		records []v1alpha1.DNSRecord
+		// wantErr is wrapped by the error, nil for any error.
+		wantErr error
+	}{
+		{
+			name: "CNAME next to other records",
+			zone: "example.com",
+			records: []v1alpha1.DNSRecord{
+				record("example.com", "www", v1alpha1.DNSRecordTypeCNAME, 0, "lb.example.com."),
+				record("example.com", "WWW", v1alpha1.DNSRecordTypeAAAA, 0, "2001:db8::80"),
+			},
+			wantErr: ErrConflict,
+		},
+		{
+			name: "CNAME at the apex",
+			zone: "example.com",
+			records: []v1alpha1.DNSRecord{
+				record("example.com", "@", v1alpha1.DNSRecordTypeA, 0, "192.0.2.1"),
+				record("example.com", "", v1alpha1.DNSRecordTypeCNAME, 0, "example.net."),
+			},
+			wantErr: ErrConflict,
+		},
+		{
+			name: "type defined twice",
+			zone: "example.com",
+			records: []v1alpha1.DNSRecord{
+				record("example.com", "www", v1alpha1.DNSRecordTypeA, 0, "192.0.2.80"),
+				record("example.com", "www", v1alpha1.DNSRecordTypeA, 0, "192.0.2.81"),
+			},
+			wantErr: ErrConflict,
+		},
+		{
+			name:    "relative CNAME",
+			zone:    "example.com",
+			records: []v1alpha1.DNSRecord{record("example.com", "www", v1alpha1.DNSRecordTypeCNAME, 0, "lb")},
+		},
+		{
+			name:    "IPv6 address in an A record",
+			zone:    "example.com",
+			records: []v1alpha1.DNSRecord{record("example.com", "www", v1alpha1.DNSRecordTypeA, 0, "2001:db8::80")},
+		},
+		{name: "invalid zone", zone: "example..com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ZoneFile(Zone{Name: tt.zone}, tt.records)
+			if err == nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Conflicts in other zones do not matter.
+	records := []v1alpha1.DNSRecord{
+		record("example.net", "www", v1alpha1.DNSRecordTypeCNAME, 0, "lb.example.net."),
+		record("example.net", "www", v1alpha1.DNSRecordTypeA, 0, "192.0.2.80"),
+		record("example.com", "www", v1alpha1.DNSRecordTypeA, 0, "192.0.2.80"),
+	}
+	if _, err := ZoneFile(Zone{Name: "example.com"}, records); err != nil {
+		t.Errorf("conflict in another zone: %v", err)
+	}
+}
+
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s, run go test -update to accept it:\n%s", path, got)
+	}
+}
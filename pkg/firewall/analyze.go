@@ -0,0 +1,257 @@
+package firewall
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+	"strings"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+// ErrShadowedRule is wrapped by findings of rules that never take effect.
+var ErrShadowedRule = errors.New("shadowed firewall rule")
+
+// Kind is the kind of a finding.
+type Kind string
+
+const (
+	// KindInvalid rules cannot be parsed and are left out of the policy.
+	KindInvalid Kind = "Invalid"
+	// KindShadowed rules never take effect: an earlier rule with the other
+	// action matches all of their traffic.
+	KindShadowed Kind = "Shadowed"
+	// KindRedundant rules can be removed without changing the policy: an
+	// earlier rule with the same action, or the default policy, already
+	// decides all of their traffic.
+	KindRedundant Kind = "Redundant"
+	// KindConflicting rules partly overlap an earlier rule with the other
+	// action, neither containing the other, so their order decides part of
+	// the traffic.
+	KindConflicting Kind = "Conflicting"
+)
+
+// Finding is a problem with a firewall rule.
+type Finding struct {
+	Kind Kind
+	// Rule is the name of the rule, empty for problems of the firewall as a
+	// whole, such as an invalid default policy or named set.
+	Rule string
+	// Index of the rule in the spec, -1 for problems of the firewall.
+	Index int
+	// Other is the name of the earlier rule involved, if any.
+	Other   string
+	Message string
+}
+
+func (f Finding) Error() string {
+	if f.Rule == "" {
+		return f.Message
+	}
+	return fmt.Sprintf("%s firewall rule %q: %s", strings.ToLower(string(f.Kind)), f.Rule, f.Message)
+}
+
+// Unwrap returns ErrInvalidRule or ErrShadowedRule for invalid and shadowed
+// rules.
+func (f Finding) Unwrap() error {
+	switch {
+	case f.Rule == "":
+		return nil
+	case f.Kind == KindInvalid:
+		return ErrInvalidRule
+	case f.Kind == KindShadowed:
+		return ErrShadowedRule
+	}
+	return nil
+}
+
+// Findings are the findings of a firewall, in rule order.
+type Findings []Finding
+
+// Err joins the invalid and shadowed findings, nil when there are none. A
+// validating webhook rejects a Vitistack with them.
+func (fs Findings) Err() error {
+	var errs []error
+	for _, f := range fs {
+		if f.Kind == KindInvalid || f.Kind == KindShadowed {
+			errs = append(errs, f)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Warnings returns the redundant and conflicting findings as messages, fit for
+// the warnings of a validating webhook.
+func (fs Findings) Warnings() []string {
+	var out []string
+	for _, f := range fs {
+		if f.Kind == KindRedundant || f.Kind == KindConflicting {
+			out = append(out, f.Error())
+		}
+	}
+	return out
+}
+
+// Analyze reports the invalid, shadowed, redundant and conflicting rules of
+// the firewall of a Vitistack. Sets are named sets as given to Compile. Rules
+// are compared pairwise, so a rule matched by several earlier rules together
+// is not reported as shadowed.
+func Analyze(vs *v1alpha1.Vitistack, sets map[string][]string) Findings {
+	p, findings := compile(vs, sets)
+	for j := range p.Rules {
+		r := &p.Rules[j]
+		if f, ok := covered(p, j); ok {
+			findings = append(findings, f)
+			continue
+		}
+		for i := range p.Rules[:j] {
+			earlier := &p.Rules[i]
+			if earlier.Action == r.Action || !overlaps(earlier, r) || covers(r, earlier) {
+				continue
+			}
+			findings = append(findings, Finding{
+				Kind: KindConflicting, Rule: r.Name, Index: r.Index, Other: earlier.Name,
+				Message: fmt.Sprintf("partly overlaps rule %q, which %s the common traffic first", earlier.Name, verb(earlier.Action)),
+			})
+		}
+		if r.Action == p.DefaultAction && !slices.ContainsFunc(p.Rules[j+1:], func(later Rule) bool {
+			return later.Action != r.Action && overlaps(r, &later)
+		}) {
+			findings = append(findings, Finding{
+				Kind: KindRedundant, Rule: r.Name, Index: r.Index,
+				Message: fmt.Sprintf("the default policy %s its traffic too", verb(p.DefaultAction)),
+			})
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Index < findings[j].Index })
+	return findings
+}
+
+// covered returns a shadowed or redundant finding when an earlier rule
+// matches all the traffic of rule j.
+func covered(p *Policy, j int) (Finding, bool) {
+	r := &p.Rules[j]
+	for i := range p.Rules[:j] {
+		earlier := &p.Rules[i]
+		if !covers(earlier, r) {
+			continue
+		}
+		f := Finding{Kind: KindShadowed, Rule: r.Name, Index: r.Index, Other: earlier.Name}
+		switch {
+		case earlier.Action != r.Action:
+			f.Message = fmt.Sprintf("rule %q %s all of its traffic first", earlier.Name, verb(earlier.Action))
+		case covers(r, earlier):
+			f.Kind = KindRedundant
+			f.Message = fmt.Sprintf("duplicates rule %q", earlier.Name)
+		default:
+			f.Kind = KindRedundant
+			f.Message = fmt.Sprintf("rule %q already %s all of its traffic", earlier.Name, verb(earlier.Action))
+		}
+		return f, true
+	}
+	return Finding{}, false
+}
+
+func verb(action string) string {
+	if action == ActionDeny {
+		return "denies"
+	}
+	return "allows"
+}
+
+// covers tells whether outer matches all the traffic of inner.
+func covers(outer, inner *Rule) bool {
+	return trafficCovers(outer, inner) &&
+		addressesCover(outer.Sources, addresses(inner, inner.Sources)) &&
+		addressesCover(outer.Destinations, addresses(inner, inner.Destinations))
+}
+
+// overlaps tells whether some traffic matches both rules.
+func overlaps(a, b *Rule) bool {
+	return trafficOverlaps(a, b) &&
+		addressesOverlap(addresses(a, a.Sources), addresses(b, b.Sources)) &&
+		addressesOverlap(addresses(a, a.Destinations), addresses(b, b.Destinations))
+}
+
+// addresses returns the sources or destinations of a rule, any address being
+// every address of the families the rule matches.
+func addresses(r *Rule, prefixes []netip.Prefix) []netip.Prefix {
+	if prefixes != nil {
+		return prefixes
+	}
+	families := r.Families()
+	return slices.DeleteFunc(anyAddress(), func(p netip.Prefix) bool { return !slices.Contains(families, prefixFamily(p)) })
+}
+
+// addressesCover tells whether every prefix of inner is inside one of outer,
+// nil outer being any address.
+func addressesCover(outer, inner []netip.Prefix) bool {
+	if outer == nil {
+		return true
+	}
+	for _, p := range inner {
+		if !slices.ContainsFunc(outer, func(o netip.Prefix) bool { return o.Bits() <= p.Bits() && o.Contains(p.Addr()) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// trafficOverlaps tells whether some protocol and port match both rules.
+func trafficOverlaps(a, b *Rule) bool {
+	if a.Protocol != ProtocolAll && b.Protocol != ProtocolAll && a.Protocol != b.Protocol {
+		return false
+	}
+	// With ports, "all" means TCP and UDP.
+	if a.Protocol == ProtocolICMP && b.Ports != nil || b.Protocol == ProtocolICMP && a.Ports != nil {
+		return false
+	}
+	if a.Ports == nil || b.Ports == nil {
+		return true
+	}
+	for _, x := range a.Ports {
+		for _, y := range b.Ports {
+			if x.First <= y.Last && y.First <= x.Last {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// trafficCovers tells whether every protocol and port of inner match outer.
+func trafficCovers(outer, inner *Rule) bool {
+	if outer.Protocol != ProtocolAll && outer.Protocol != inner.Protocol {
+		return false
+	}
+	if outer.Ports == nil {
+		return true
+	}
+	if inner.Ports == nil {
+		return false
+	}
+	// Both lists are sorted and merged, so each inner range must sit inside
+	// one outer range.
+	for _, y := range inner.Ports {
+		if !slices.ContainsFunc(outer.Ports, func(x PortRange) bool { return x.First <= y.First && y.Last <= x.Last }) {
+			return false
+		}
+	}
+	return true
+}
+
+// addressesOverlap tells whether some address is in both lists, nil being any
+// address.
+func addressesOverlap(a, b []netip.Prefix) bool {
+	if a == nil || b == nil {
+		return true
+	}
+	for _, x := range a {
+		if slices.ContainsFunc(b, x.Overlaps) {
+			return true
+		}
+	}
+	return false
+}
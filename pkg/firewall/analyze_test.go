@@ -0,0 +1,146 @@
+package firewall
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+// finding is the part of a Finding the tests compare.
+type finding struct {
+	Kind  Kind
+	Rule  string
+	Other string
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name          string
+		defaultPolicy string
+		rules         []v1alpha1.VitistackFirewallRule
+		want          []finding
+		// wantErr is wrapped by the error of the findings, nil for none.
+		wantErr error
+	}{
+		{
+			name: "shadowed by an earlier allow",
+			rules: []v1alpha1.VitistackFirewallRule{
+				rule("ssh", ActionAllow, ProtocolTCP, "22", "", ""),
+				rule("block-ssh", ActionDeny, ProtocolTCP, "22", "10.0.0.0/8", ""),
+			},
+			want:    []finding{{KindShadowed, "block-ssh", "ssh"}},
+			wantErr: ErrShadowedRule,
+		},
+		{
+			name: "shadowed by an earlier deny of every protocol",
+			rules: []v1alpha1.VitistackFirewallRule{
+				rule("quarantine", ActionDeny, ProtocolAll, "", "web", ""),
+				rule("https", ActionAllow, ProtocolTCP, "443", "10.10.1.128/25", ""),
+			},
+			want:    []finding{{KindShadowed, "https", "quarantine"}},
+			wantErr: ErrShadowedRule,
+		},
+		{
+			name: "conflicting ports",
+			rules: []v1alpha1.VitistackFirewallRule{
+				rule("deny-lan", ActionDeny, ProtocolAll, "80-90", "10.0.0.0/8", ""),
+				rule("web", ActionAllow, ProtocolTCP, "85-100", "10.1.0.0/16", ""),
+			},
+			want: []finding{{KindConflicting, "web", "deny-lan"}},
+		},
+		{
+			name: "conflicting addresses",
+			rules: []v1alpha1.VitistackFirewallRule{
+				rule("office", ActionAllow, ProtocolTCP, "22", "office", ""),
+				rule("guests", ActionDeny, ProtocolTCP, "22", "192.0.2.0/23", ""),
+				rule("admins", ActionAllow, ProtocolTCP, "22", "192.0.2.0/23", ""),
+			},
+			want:    []finding{{KindConflicting, "guests", "office"}, {KindShadowed, "admins", "guests"}},
+			wantErr: ErrShadowedRule,
+		},
+		{
+			name: "duplicate",
+			rules: []v1alpha1.VitistackFirewallRule{
+				rule("office-ssh", ActionAllow, ProtocolTCP, "22", "office", ""),
+				rule("ssh", ActionAllow, "", "22", "192.0.2.0/24, 2001:db8:1::/48", "any"),
+			},
+			want: []finding{{KindRedundant, "ssh", "office-ssh"}},
+		},
+		{
+			name: "inside an earlier rule",
+			rules: []v1alpha1.VitistackFirewallRule{
+				rule("lan", ActionAllow, ProtocolAll, "", "10.0.0.0/8", ""),
+				rule("https", ActionAllow, ProtocolTCP, "443", "prod", ""),
+			},
+			want: []finding{{KindRedundant, "https", "lan"}},
+		},
+		{
+			name: "redundant with the default policy",
+			rules: []v1alpha1.VitistackFirewallRule{
+				rule("telnet", ActionDeny, ProtocolTCP, "23", "", ""),
+				rule("smtp", ActionDeny, ProtocolTCP, "25", "", ""),
+				rule("lan", ActionAllow, ProtocolTCP, "25,587", "10.0.0.0/8", ""),
+			},
+			want: []finding{{KindRedundant, "telnet", ""}, {KindConflicting, "lan", "smtp"}},
+		},
+		{
+			name:          "no overlap across families, protocols and ports",
+			defaultPolicy: ActionAllow,
+			rules: []v1alpha1.VitistackFirewallRule{
+				rule("lan", ActionAllow, ProtocolTCP, "80", "10.0.0.0/8", ""),
+				rule("v6", ActionDeny, ProtocolAll, "", "2001:db8::/32", ""),
+				rule("ping", ActionDeny, ProtocolICMP, "", "", ""),
+				rule("dns", ActionDeny, ProtocolUDP, "53", "", ""),
+			},
+			want: []finding{{KindRedundant, "lan", ""}},
+		},
+		{
+			// Rules are compared pairwise, so admins is not reported although
+			// the two deny rules together match all of its traffic.
+			name: "covered by several rules",
+			rules: []v1alpha1.VitistackFirewallRule{
+				rule("low", ActionDeny, ProtocolTCP, "22", "10.0.0.0/9", ""),
+				rule("high", ActionDeny, ProtocolTCP, "22", "10.128.0.0/9", ""),
+				rule("admins", ActionAllow, ProtocolTCP, "22", "10.0.0.0/8", ""),
+			},
+		},
+		{
+			name:          "invalid",
+			defaultPolicy: "reject",
+			rules: []v1alpha1.VitistackFirewallRule{
+				rule("", ActionAllow, "", "", "", ""),
+				rule("dup", ActionAllow, "", "22", "", ""),
+				rule("dup", ActionAllow, "", "23", "", ""),
+				rule("partners", ActionAllow, "", "", "partners", ""),
+			},
+			want:    []finding{{KindInvalid, "", ""}, {KindInvalid, "", ""}, {KindInvalid, "dup", ""}, {KindInvalid, "partners", ""}},
+			wantErr: ErrInvalidRule,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := Analyze(newVitistack(tt.defaultPolicy, tt.rules...), sets)
+			var got []finding
+			warnings := 0
+			for _, f := range findings {
+				got = append(got, finding{f.Kind, f.Rule, f.Other})
+				if f.Kind == KindRedundant || f.Kind == KindConflicting {
+					warnings++
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("findings = %+v, want %+v", got, tt.want)
+			}
+
+			err := findings.Err()
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Err() = %v, want %v", err, tt.wantErr)
+			}
+			if len(findings.Warnings()) != warnings {
+				t.Errorf("Warnings() = %q, want %d", findings.Warnings(), warnings)
+			}
+		})
+	}
+}
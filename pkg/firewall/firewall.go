@@ -0,0 +1,374 @@
+// Package firewall compiles the firewall of a Vitistack into enforceable
+// configuration: an nftables ruleset for hosts, and NetworkPolicy and
+// AdminNetworkPolicy objects for clusters. Rules are evaluated in order and
+// the first match decides; traffic no rule matches gets the default policy.
+// Analyze reports invalid, shadowed, redundant and conflicting rules.
+// The output only depends on the input. The Kubernetes objects are
+// unstructured, so their API types are not needed.
+package firewall
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+// Actions.
+const (
+	ActionAllow = "allow"
+	ActionDeny  = "deny"
+)
+
+// Protocols.
+const (
+	ProtocolTCP  = "tcp"
+	ProtocolUDP  = "udp"
+	ProtocolICMP = "icmp"
+	ProtocolAll  = "all"
+)
+
+// ErrInvalidRule is wrapped by the errors of rules that cannot be compiled.
+var ErrInvalidRule = errors.New("invalid firewall rule")
+
+// PortRange is an inclusive range of ports.
+type PortRange struct {
+	First, Last uint16
+}
+
+func (r PortRange) String() string {
+	if r.First == r.Last {
+		return strconv.Itoa(int(r.First))
+	}
+	return fmt.Sprintf("%d-%d", r.First, r.Last)
+}
+
+// Rule is a parsed firewall rule.
+type Rule struct {
+	// Name and Index of the rule in the spec.
+	Name  string
+	Index int
+	// Action is ActionAllow or ActionDeny.
+	Action string
+	// Protocol is ProtocolTCP, ProtocolUDP, ProtocolICMP or ProtocolAll.
+	Protocol string
+	// Ports sorted and merged, nil for every port.
+	Ports []PortRange
+	// Sources and Destinations sorted and merged, nil for any address.
+	Sources      []netip.Prefix
+	Destinations []netip.Prefix
+}
+
+// Policy is a compiled firewall.
+type Policy struct {
+	// Name of the Vitistack.
+	Name string
+	// DefaultAction applies to traffic no rule matches.
+	DefaultAction string
+	Rules         []Rule
+}
+
+// Names resolves named sources and destinations to prefixes.
+type Names map[string][]netip.Prefix
+
+// NamesFrom returns the names of the VPCs and subnets of a Vitistack network,
+// and the extra named sets, which win over VPCs and subnets of the same name.
+func NamesFrom(networking *v1alpha1.VitistackNetworking, sets map[string][]string) (Names, error) {
+	names, errs := namesFrom(networking, sets)
+	return names, errors.Join(errs...)
+}
+
+func namesFrom(networking *v1alpha1.VitistackNetworking, sets map[string][]string) (Names, []error) {
+	names := Names{}
+	var errs []error
+	add := func(name string, cidrs ...string) {
+		var prefixes []netip.Prefix
+		for _, s := range cidrs {
+			p, err := parseAddresses(s)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				return
+			}
+			prefixes = append(prefixes, p...)
+		}
+		names[name] = merge(prefixes)
+	}
+	for _, vpc := range networking.VPCs {
+		add(vpc.Name, vpc.CIDR)
+		for _, subnet := range vpc.Subnets {
+			add(subnet.Name, subnet.CIDR)
+		}
+	}
+	for _, name := range sortedKeys(sets) {
+		add(name, sets[name]...)
+	}
+	return names, errs
+}
+
+// Compile parses the firewall of a Vitistack. Named sources and destinations
+// refer to its VPCs and subnets or to sets. Rules that cannot be compiled are
+// left out and reported in the returned error, which joins one error per rule
+// and wraps ErrInvalidRule. Leaving out a rule can open or close traffic, so
+// callers should not enforce a policy compiled with errors.
+func Compile(vs *v1alpha1.Vitistack, sets map[string][]string) (*Policy, error) {
+	p, invalid := compile(vs, sets)
+	errs := make([]error, len(invalid))
+	for i := range invalid {
+		errs[i] = invalid[i]
+	}
+	return p, errors.Join(errs...)
+}
+
+// compile returns the valid rules of a Vitistack and a KindInvalid finding
+// for every problem.
+func compile(vs *v1alpha1.Vitistack, sets map[string][]string) (*Policy, []Finding) {
+	fw := &vs.Spec.Networking.Firewall
+	p := &Policy{Name: vs.Name, DefaultAction: fw.DefaultPolicy}
+	if p.DefaultAction == "" {
+		p.DefaultAction = ActionDeny
+	}
+	var invalid []Finding
+	if p.DefaultAction != ActionAllow && p.DefaultAction != ActionDeny {
+		invalid = append(invalid, Finding{Kind: KindInvalid, Index: -1, Message: fmt.Sprintf("invalid default policy %q", fw.DefaultPolicy)})
+	}
+	names, errs := namesFrom(&vs.Spec.Networking, sets)
+	for _, err := range errs {
+		invalid = append(invalid, Finding{Kind: KindInvalid, Index: -1, Message: err.Error()})
+	}
+	seen := map[string]bool{}
+	for i := range fw.Rules {
+		r, err := parseRule(&fw.Rules[i], i, names)
+		if err == nil && seen[r.Name] {
+			err = errors.New("name is used twice")
+		}
+		if err != nil {
+			invalid = append(invalid, Finding{Kind: KindInvalid, Rule: fw.Rules[i].Name, Index: i, Message: err.Error()})
+			continue
+		}
+		seen[r.Name] = true
+		p.Rules = append(p.Rules, r)
+	}
+	return p, invalid
+}
+
+// ParseRule parses one rule. Sources and destinations are empty or "any",
+// CIDRs, addresses, ranges (10.0.0.10-10.0.0.20) or names, comma separated.
+// Ports are single ports or ranges (8000-8100), comma separated.
+func ParseRule(in *v1alpha1.VitistackFirewallRule, index int, names Names) (Rule, error) {
+	r, err := parseRule(in, index, names)
+	if err != nil {
+		return Rule{}, fmt.Errorf("%w %q: %v", ErrInvalidRule, in.Name, err)
+	}
+	return r, nil
+}
+
+func parseRule(in *v1alpha1.VitistackFirewallRule, index int, names Names) (Rule, error) {
+	r := Rule{Name: in.Name, Index: index, Action: in.Action, Protocol: in.Protocol}
+	fail := func(format string, args ...any) (Rule, error) {
+		return Rule{}, fmt.Errorf(format, args...)
+	}
+	if in.Name == "" {
+		return fail("no name")
+	}
+	if r.Action != ActionAllow && r.Action != ActionDeny {
+		return fail("invalid action %q", in.Action)
+	}
+	if r.Protocol == "" {
+		r.Protocol = ProtocolTCP
+	}
+	switch r.Protocol {
+	case ProtocolTCP, ProtocolUDP, ProtocolAll, ProtocolICMP:
+	default:
+		return fail("invalid protocol %q", in.Protocol)
+	}
+
+	var err error
+	if r.Ports, err = parsePorts(in.Port); err != nil {
+		return fail("%v", err)
+	}
+	if r.Ports != nil && r.Protocol == ProtocolICMP {
+		return fail("icmp has no ports")
+	}
+	if r.Sources, err = resolve(in.Source, names); err != nil {
+		return fail("source: %v", err)
+	}
+	if r.Destinations, err = resolve(in.Destination, names); err != nil {
+		return fail("destination: %v", err)
+	}
+	if r.Sources != nil && r.Destinations != nil && len(r.Families()) == 0 {
+		return fail("source and destination have no address family in common")
+	}
+	return r, nil
+}
+
+// Families returns the address families the rule can match, "ip" and "ip6".
+func (r *Rule) Families() []string {
+	var out []string
+	for _, f := range []string{"ip", "ip6"} {
+		if r.matchesFamily(f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func (r *Rule) matchesFamily(family string) bool {
+	has := func(prefixes []netip.Prefix) bool {
+		return prefixes == nil || slices.ContainsFunc(prefixes, func(p netip.Prefix) bool { return prefixFamily(p) == family })
+	}
+	return has(r.Sources) && has(r.Destinations)
+}
+
+// resolve parses a source or destination. It returns nil for any address.
+func resolve(s string, names Names) ([]netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "any" || s == "*" {
+		return nil, nil
+	}
+	var out []netip.Prefix
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if prefixes, ok := names[item]; ok {
+			out = append(out, prefixes...)
+			continue
+		}
+		prefixes, err := parseAddresses(item)
+		if err != nil {
+			return nil, fmt.Errorf("%q is neither an address nor a known name", item)
+		}
+		out = append(out, prefixes...)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%q matches no address", s)
+	}
+	return merge(out), nil
+}
+
+// parseAddresses parses a CIDR, an address or a range of addresses.
+func parseAddresses(s string) ([]netip.Prefix, error) {
+	if first, last, ok := strings.Cut(s, "-"); ok {
+		a, errA := netip.ParseAddr(strings.TrimSpace(first))
+		b, errB := netip.ParseAddr(strings.TrimSpace(last))
+		if errA != nil || errB != nil || a.Is4() != b.Is4() || b.Less(a) {
+			return nil, fmt.Errorf("invalid range %q", s)
+		}
+		return rangePrefixes(a.Unmap(), b.Unmap()), nil
+	}
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q", s)
+		}
+		return []netip.Prefix{p.Masked()}, nil
+	}
+	a, err := netip.ParseAddr(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q", s)
+	}
+	a = a.Unmap()
+	return []netip.Prefix{netip.PrefixFrom(a, a.BitLen())}, nil
+}
+
+// rangePrefixes returns the fewest prefixes covering first-last.
+func rangePrefixes(first, last netip.Addr) []netip.Prefix {
+	var out []netip.Prefix
+	for {
+		bits := first.BitLen()
+		// Grow the prefix while it starts at first and ends before last.
+		for bits > 0 {
+			p := netip.PrefixFrom(first, bits-1).Masked()
+			if p.Addr() != first || lastAddr(p).Compare(last) > 0 {
+				break
+			}
+			bits--
+		}
+		p := netip.PrefixFrom(first, bits)
+		out = append(out, p)
+		end := lastAddr(p)
+		if end.Compare(last) >= 0 || !end.Next().IsValid() {
+			return out
+		}
+		first = end.Next()
+	}
+}
+
+// lastAddr returns the last address of a prefix.
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Masked().Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	a, _ := netip.AddrFromSlice(b)
+	return a
+}
+
+// merge sorts prefixes and drops those inside another.
+func merge(prefixes []netip.Prefix) []netip.Prefix {
+	sorted := slices.Clone(prefixes)
+	sort.Slice(sorted, func(i, j int) bool {
+		if c := sorted[i].Addr().Compare(sorted[j].Addr()); c != 0 {
+			return c < 0
+		}
+		return sorted[i].Bits() < sorted[j].Bits()
+	})
+	var out []netip.Prefix
+	for _, p := range sorted {
+		if n := len(out); n > 0 && out[n-1].Overlaps(p) && out[n-1].Bits() <= p.Bits() {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// parsePorts parses ports and ranges. It returns nil for every port.
+func parsePorts(s string) ([]PortRange, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "any" || s == "*" {
+		return nil, nil
+	}
+	var out []PortRange
+	for _, item := range strings.Split(s, ",") {
+		first, last, isRange := strings.Cut(strings.TrimSpace(item), "-")
+		if !isRange {
+			last = first
+		}
+		a, errA := strconv.ParseUint(strings.TrimSpace(first), 10, 16)
+		b, errB := strconv.ParseUint(strings.TrimSpace(last), 10, 16)
+		if errA != nil || errB != nil || a == 0 || a > b {
+			return nil, fmt.Errorf("invalid port %q, want 1-65535 or a range", strings.TrimSpace(item))
+		}
+		out = append(out, PortRange{uint16(a), uint16(b)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].First < out[j].First })
+	merged := out[:1]
+	for _, r := range out[1:] {
+		if last := &merged[len(merged)-1]; int(r.First) <= int(last.Last)+1 {
+			last.Last = max(last.Last, r.Last)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged, nil
+}
+
+func prefixFamily(p netip.Prefix) string {
+	if p.Addr().Is4() {
+		return "ip"
+	}
+	return "ip6"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,137 @@
+package firewall
+
+import (
+	"errors"
+	"flag"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// sets are the named sets of the tests, next to the VPC prod and its subnet
+// web of newVitistack.
+var sets = map[string][]string{
+	"office":  {"192.0.2.0/24", "2001:db8:1::/48"},
+	"bastion": {"192.0.2.10"},
+}
+
+func newVitistack(defaultPolicy string, rules ...v1alpha1.VitistackFirewallRule) *v1alpha1.Vitistack {
+	return &v1alpha1.Vitistack{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+		Spec: v1alpha1.VitistackSpec{Networking: v1alpha1.VitistackNetworking{
+			VPCs: []v1alpha1.VitistackVPC{{
+				Name: "prod", CIDR: "10.10.0.0/16",
+				Subnets: []v1alpha1.VitistackSubnet{{Name: "web", CIDR: "10.10.1.0/24"}},
+			}},
+			Firewall: v1alpha1.VitistackFirewall{DefaultPolicy: defaultPolicy, Rules: rules},
+		}},
+	}
+}
+
+// rule returns a firewall rule, empty fields being unset.
+func rule(name, action, protocol, port, source, destination string) v1alpha1.VitistackFirewallRule {
+	return v1alpha1.VitistackFirewallRule{Name: name, Action: action, Protocol: protocol, Port: port, Source: source, Destination: destination}
+}
+
+func mustCompile(t *testing.T, vs *v1alpha1.Vitistack) *Policy {
+	t.Helper()
+	p, err := Compile(vs, sets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func prefixes(cidrs ...string) []netip.Prefix {
+	out := make([]netip.Prefix, len(cidrs))
+	for i, s := range cidrs {
+		out[i] = netip.MustParsePrefix(s)
+	}
+	return out
+}
+
+func TestParseRule(t *testing.T) {
+	names, err := NamesFrom(&newVitistack("").Spec.Networking, sets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		in      v1alpha1.VitistackFirewallRule
+		want    Rule
+		wantErr bool
+	}{
+		{
+			name: "tcp and any address by default",
+			in:   rule("ssh", ActionAllow, "", "22", "", "any"),
+			want: Rule{Name: "ssh", Action: ActionAllow, Protocol: ProtocolTCP, Ports: []PortRange{{22, 22}}},
+		},
+		{
+			name: "ports are sorted and merged",
+			in:   rule("web", ActionAllow, ProtocolAll, "8443, 80-90,85-100,101", "", ""),
+			want: Rule{Name: "web", Action: ActionAllow, Protocol: ProtocolAll, Ports: []PortRange{{80, 101}, {8443, 8443}}},
+		},
+		{
+			name: "names, ranges and addresses are merged",
+			in:   rule("admin", ActionAllow, ProtocolTCP, "", "office, bastion, 10.0.0.1-10.0.0.6, web", "prod"),
+			want: Rule{
+				Name: "admin", Action: ActionAllow, Protocol: ProtocolTCP,
+				Sources:      prefixes("10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/31", "10.0.0.6/32", "10.10.1.0/24", "192.0.2.0/24", "2001:db8:1::/48"),
+				Destinations: prefixes("10.10.0.0/16"),
+			},
+		},
+		{name: "no name", in: rule("", ActionAllow, "", "", "", ""), wantErr: true},
+		{name: "invalid action", in: rule("x", "reject", "", "", "", ""), wantErr: true},
+		{name: "invalid protocol", in: rule("x", ActionAllow, "sctp", "", "", ""), wantErr: true},
+		{name: "icmp with ports", in: rule("x", ActionAllow, ProtocolICMP, "8", "", ""), wantErr: true},
+		{name: "port 0", in: rule("x", ActionAllow, "", "0", "", ""), wantErr: true},
+		{name: "reversed port range", in: rule("x", ActionAllow, "", "90-80", "", ""), wantErr: true},
+		{name: "unknown name", in: rule("x", ActionAllow, "", "", "partners", ""), wantErr: true},
+		{name: "mixed family range", in: rule("x", ActionAllow, "", "", "10.0.0.1-2001:db8::1", ""), wantErr: true},
+		{name: "no common family", in: rule("x", ActionAllow, "", "", "10.0.0.0/8", "2001:db8::/32"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRule(&tt.in, 3, names)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRule) {
+					t.Errorf("error = %v, want ErrInvalidRule", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.want.Index = 3
+			if got.Name != tt.want.Name || got.Index != tt.want.Index || got.Action != tt.want.Action || got.Protocol != tt.want.Protocol ||
+				!slices.Equal(got.Ports, tt.want.Ports) || !slices.Equal(got.Sources, tt.want.Sources) || !slices.Equal(got.Destinations, tt.want.Destinations) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s, run go test -update to accept it:\n%s", path, got)
+	}
+}
@@ -0,0 +1,349 @@
+package firewall
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	metav1unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// NetworkPolicyGVK is the GroupVersionKind of NetworkPolicy.
+	NetworkPolicyGVK = schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicy"}
+	// AdminNetworkPolicyGVK is the GroupVersionKind of AdminNetworkPolicy.
+	AdminNetworkPolicyGVK = schema.GroupVersionKind{Group: "policy.networking.k8s.io", Version: "v1alpha1", Kind: "AdminNetworkPolicy"}
+)
+
+// MaxAdminNetworkPolicyRules is the number of ingress or egress rules an
+// AdminNetworkPolicy may hold.
+const MaxAdminNetworkPolicyRules = 100
+
+// VitistackLabel is set on generated objects to the name of their Vitistack.
+const VitistackLabel = "vitistack.io/vitistack"
+
+// KubernetesOptions tune generated NetworkPolicies and AdminNetworkPolicies.
+type KubernetesOptions struct {
+	// Name of the object, default "vitistack-" followed by the Vitistack name.
+	Name string
+	// Namespace of a NetworkPolicy. Required for NetworkPolicies.
+	Namespace string
+	// PodSelector selects the pods of a NetworkPolicy, default every pod of
+	// the namespace.
+	PodSelector map[string]string
+	// NamespaceSelector selects the namespaces of an AdminNetworkPolicy,
+	// default every namespace.
+	NamespaceSelector map[string]string
+	// Priority of an AdminNetworkPolicy, 1-1000, lower first. Default 50.
+	Priority int64
+}
+
+// Rule directions. In a cluster the selected pods are one end of the traffic,
+// so a rule names the other: a source makes an ingress rule, a destination an
+// egress rule. Rules without either are ingress rules.
+const (
+	directionIngress = "ingress"
+	directionEgress  = "egress"
+)
+
+func ruleDirection(r *Rule) string {
+	switch {
+	case r.Sources != nil && r.Destinations != nil:
+		return ""
+	case r.Destinations != nil:
+		return directionEgress
+	}
+	return directionIngress
+}
+
+// NetworkPolicy generates a NetworkPolicy enforcing a policy on pods.
+// NetworkPolicies only allow, so deny rules become exceptions of the address
+// blocks of later allow rules, and a default allow becomes a final allow-all
+// rule. Ingress is always restricted under a default deny, egress only when
+// egress rules exist. What NetworkPolicies cannot express, such as ICMP or
+// rules with both a source and a destination, is left out and reported in the
+// warnings.
+func NetworkPolicy(p *Policy, opts KubernetesOptions) (*metav1unstructured.Unstructured, []string, error) {
+	if opts.Namespace == "" {
+		return nil, nil, fmt.Errorf("NetworkPolicy of Vitistack %s needs a namespace", p.Name)
+	}
+	var warnings []string
+	byDirection := splitDirections(p, &warnings, "NetworkPolicies")
+
+	var policyTypes []any
+	spec := map[string]any{"podSelector": selector(opts.PodSelector)}
+	for _, direction := range []string{directionIngress, directionEgress} {
+		rules := byDirection[direction]
+		hasDeny := slices.ContainsFunc(rules, func(r *Rule) bool { return r.Action == ActionDeny })
+		if p.DefaultAction == ActionAllow {
+			if !hasDeny {
+				continue
+			}
+			rules = append(rules, &Rule{Name: "default", Action: ActionAllow, Protocol: ProtocolAll})
+		} else if direction == directionEgress && len(rules) == 0 {
+			continue
+		}
+
+		out := []any{}
+		var denies []*Rule
+		for _, r := range rules {
+			if r.Action == ActionDeny {
+				denies = append(denies, r)
+				continue
+			}
+			peers, ok := npPeers(r, denies, &warnings)
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("rule %q is left out: earlier deny rules match all of its addresses", r.Name))
+				continue
+			}
+			npRule := map[string]any{}
+			if peers != nil {
+				key := "from"
+				if direction == directionEgress {
+					key = "to"
+				}
+				npRule[key] = peers
+			}
+			if ports := npPorts(r); ports != nil {
+				npRule["ports"] = ports
+			}
+			out = append(out, npRule)
+		}
+		spec[direction] = out
+		policyTypes = append(policyTypes, strings.ToUpper(direction[:1])+direction[1:])
+	}
+	spec["policyTypes"] = policyTypes
+
+	u := newObject(NetworkPolicyGVK, p, opts)
+	u.SetNamespace(opts.Namespace)
+	u.Object["spec"] = spec
+	return u, warnings, nil
+}
+
+// AdminNetworkPolicy generates a cluster-wide AdminNetworkPolicy enforcing
+// the egress rules of a policy, in order and with deny rules, followed by a
+// deny-all rule under a default deny. AdminNetworkPolicies cannot match
+// ingress by address, so ingress rules are left out and reported in the
+// warnings, as are ICMP rules.
+func AdminNetworkPolicy(p *Policy, opts KubernetesOptions) (*metav1unstructured.Unstructured, []string, error) {
+	if opts.Priority == 0 {
+		opts.Priority = 50
+	}
+	if opts.Priority < 1 || opts.Priority > 1000 {
+		return nil, nil, fmt.Errorf("AdminNetworkPolicy priority %d is not in 1-1000", opts.Priority)
+	}
+	var warnings []string
+	byDirection := splitDirections(p, &warnings, "AdminNetworkPolicies")
+	for _, r := range byDirection[directionIngress] {
+		warnings = append(warnings, fmt.Sprintf("rule %q is left out: AdminNetworkPolicies cannot match ingress by address", r.Name))
+	}
+
+	egress := []any{}
+	for _, r := range byDirection[directionEgress] {
+		action := "Allow"
+		if r.Action == ActionDeny {
+			action = "Deny"
+		}
+		anpRule := map[string]any{
+			"name":   r.Name,
+			"action": action,
+			"to":     []any{map[string]any{"networks": networks(r.Destinations)}},
+		}
+		if ports := anpPorts(r); ports != nil {
+			anpRule["ports"] = ports
+		}
+		egress = append(egress, anpRule)
+	}
+	if len(egress) > 0 && p.DefaultAction == ActionDeny {
+		egress = append(egress, map[string]any{
+			"name":   "default-deny",
+			"action": "Deny",
+			"to":     []any{map[string]any{"networks": networks(nil)}},
+		})
+	}
+	if len(egress) > MaxAdminNetworkPolicyRules {
+		return nil, warnings, fmt.Errorf("Vitistack %s needs %d egress rules, an AdminNetworkPolicy holds %d", p.Name, len(egress), MaxAdminNetworkPolicyRules)
+	}
+
+	u := newObject(AdminNetworkPolicyGVK, p, opts)
+	u.Object["spec"] = map[string]any{
+		"priority": opts.Priority,
+		"subject":  map[string]any{"namespaces": selector(opts.NamespaceSelector)},
+		"egress":   egress,
+	}
+	return u, warnings, nil
+}
+
+// splitDirections sorts the rules Kubernetes policies can express by
+// direction and warns about the others.
+func splitDirections(p *Policy, warnings *[]string, kind string) map[string][]*Rule {
+	out := map[string][]*Rule{}
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		direction := ruleDirection(r)
+		switch {
+		case direction == "":
+			*warnings = append(*warnings, fmt.Sprintf("rule %q is left out: %s cannot match both a source and a destination", r.Name, kind))
+		case r.Protocol == ProtocolICMP:
+			*warnings = append(*warnings, fmt.Sprintf("rule %q is left out: %s cannot match ICMP", r.Name, kind))
+		default:
+			out[direction] = append(out[direction], r)
+		}
+	}
+	return out
+}
+
+// npPeers returns the ipBlock peers of an allow rule without the addresses of
+// earlier deny rules, nil for any address. It returns false when the deny
+// rules leave no address.
+func npPeers(allow *Rule, denies []*Rule, warnings *[]string) ([]any, bool) {
+	allowed := ruleAddresses(allow)
+	var excluded []netip.Prefix
+	for _, deny := range denies {
+		if !trafficOverlaps(deny, allow) || !addressesOverlap(ruleAddresses(deny), allowed) {
+			continue
+		}
+		if !trafficCovers(deny, allow) {
+			*warnings = append(*warnings, fmt.Sprintf("deny rule %q only matches part of the ports of allow rule %q, NetworkPolicies allow all of them", deny.Name, allow.Name))
+			continue
+		}
+		denied := ruleAddresses(deny)
+		if denied == nil {
+			return nil, false
+		}
+		excluded = append(excluded, denied...)
+	}
+	if len(excluded) == 0 && allowed == nil {
+		return nil, true
+	}
+	if allowed == nil {
+		allowed = anyAddress()
+	}
+
+	peers := []any{}
+	for _, a := range allowed {
+		block := map[string]any{"cidr": a.String()}
+		var except []any
+		covered := false
+		for _, e := range merge(excluded) {
+			switch {
+			case e.Bits() <= a.Bits() && e.Overlaps(a):
+				covered = true
+			case e.Overlaps(a):
+				except = append(except, e.String())
+			}
+		}
+		if covered {
+			continue
+		}
+		if except != nil {
+			block["except"] = except
+		}
+		peers = append(peers, map[string]any{"ipBlock": block})
+	}
+	return peers, len(peers) > 0
+}
+
+// npPorts returns the NetworkPolicy ports of a rule, nil for every protocol
+// and port.
+func npPorts(r *Rule) []any {
+	var out []any
+	for _, protocol := range protocols(r) {
+		if r.Ports == nil {
+			out = append(out, map[string]any{"protocol": protocol})
+			continue
+		}
+		for _, pr := range r.Ports {
+			port := map[string]any{"protocol": protocol, "port": int64(pr.First)}
+			if pr.Last != pr.First {
+				port["endPort"] = int64(pr.Last)
+			}
+			out = append(out, port)
+		}
+	}
+	return out
+}
+
+// anpPorts returns the AdminNetworkPolicy ports of a rule, nil for every
+// protocol and port. AdminNetworkPolicies cannot match a protocol without a
+// port, so such rules match every port of the protocol.
+func anpPorts(r *Rule) []any {
+	var out []any
+	for _, protocol := range protocols(r) {
+		ports := r.Ports
+		if ports == nil {
+			ports = []PortRange{{1, 65535}}
+		}
+		for _, pr := range ports {
+			if pr.First == pr.Last {
+				out = append(out, map[string]any{"portNumber": map[string]any{"protocol": protocol, "port": int64(pr.First)}})
+				continue
+			}
+			out = append(out, map[string]any{"portRange": map[string]any{"protocol": protocol, "start": int64(pr.First), "end": int64(pr.Last)}})
+		}
+	}
+	return out
+}
+
+// protocols returns the Kubernetes protocols of a rule, none for every
+// protocol.
+func protocols(r *Rule) []string {
+	switch r.Protocol {
+	case ProtocolTCP, ProtocolUDP:
+		return []string{strings.ToUpper(r.Protocol)}
+	case ProtocolAll:
+		if r.Ports != nil {
+			return []string{"TCP", "UDP"}
+		}
+	}
+	return nil
+}
+
+// ruleAddresses returns the addresses of the other end of a rule in a
+// cluster, nil for any address.
+func ruleAddresses(r *Rule) []netip.Prefix {
+	if ruleDirection(r) == directionEgress {
+		return r.Destinations
+	}
+	return r.Sources
+}
+
+func anyAddress() []netip.Prefix {
+	return []netip.Prefix{netip.MustParsePrefix("0.0.0.0/0"), netip.MustParsePrefix("::/0")}
+}
+
+func networks(prefixes []netip.Prefix) []any {
+	if prefixes == nil {
+		prefixes = anyAddress()
+	}
+	out := make([]any, len(prefixes))
+	for i, p := range prefixes {
+		out[i] = p.String()
+	}
+	return out
+}
+
+func selector(labels map[string]string) map[string]any {
+	if len(labels) == 0 {
+		return map[string]any{}
+	}
+	matchLabels := map[string]any{}
+	for k, v := range labels {
+		matchLabels[k] = v
+	}
+	return map[string]any{"matchLabels": matchLabels}
+}
+
+func newObject(gvk schema.GroupVersionKind, p *Policy, opts KubernetesOptions) *metav1unstructured.Unstructured {
+	name := opts.Name
+	if name == "" {
+		name = "vitistack-" + p.Name
+	}
+	u := &metav1unstructured.Unstructured{Object: map[string]any{}}
+	u.SetGroupVersionKind(gvk)
+	u.SetName(name)
+	u.SetLabels(map[string]string{VitistackLabel: p.Name})
+	return u
+}
@@ -0,0 +1,86 @@
+package firewall
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	metav1unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+func TestKubernetesGolden(t *testing.T) {
+	for name, vs := range goldenFirewalls {
+		t.Run(name, func(t *testing.T) {
+			p := mustCompile(t, vs)
+
+			u, warnings, err := NetworkPolicy(p, KubernetesOptions{Namespace: "tenant-a", PodSelector: map[string]string{"app": "web"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, name+".networkpolicy.yaml", objectYAML(t, u, warnings))
+
+			u, warnings, err = AdminNetworkPolicy(p, KubernetesOptions{NamespaceSelector: map[string]string{"tenant": "a"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, name+".adminnetworkpolicy.yaml", objectYAML(t, u, warnings))
+		})
+	}
+}
+
+// objectYAML returns an object as YAML below its warnings as comments.
+func objectYAML(t *testing.T, u *metav1unstructured.Unstructured, warnings []string) string {
+	t.Helper()
+	out, err := yaml.Marshal(u.Object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	for _, w := range warnings {
+		fmt.Fprintf(&b, "# %s\n", w)
+	}
+	b.Write(out)
+	return b.String()
+}
+
+func TestNetworkPolicyDeniedEverything(t *testing.T) {
+	p := mustCompile(t, newVitistack(ActionDeny,
+		rule("lockdown", ActionDeny, ProtocolTCP, "", "", ""),
+		rule("ssh", ActionAllow, ProtocolTCP, "22", "", ""),
+	))
+	u, warnings, err := NetworkPolicy(p, KubernetesOptions{Namespace: "tenant-a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ingress := u.Object["spec"].(map[string]any)["ingress"].([]any); len(ingress) != 0 {
+		t.Errorf("ingress = %v, want none", ingress)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `"ssh" is left out`) {
+		t.Errorf("warnings = %q, want ssh left out", warnings)
+	}
+}
+
+func TestKubernetesErrors(t *testing.T) {
+	p := mustCompile(t, newVitistack(ActionDeny, rule("dns", ActionAllow, ProtocolUDP, "53", "", "10.10.0.53")))
+	if _, _, err := NetworkPolicy(p, KubernetesOptions{}); err == nil {
+		t.Error("a NetworkPolicy without a namespace must be rejected")
+	}
+	for _, priority := range []int64{-1, 1001} {
+		if _, _, err := AdminNetworkPolicy(p, KubernetesOptions{Priority: priority}); err == nil {
+			t.Errorf("priority %d must be rejected", priority)
+		}
+	}
+
+	vs := newVitistack(ActionDeny)
+	for i := range MaxAdminNetworkPolicyRules {
+		vs.Spec.Networking.Firewall.Rules = append(vs.Spec.Networking.Firewall.Rules,
+			rule(fmt.Sprintf("r%d", i), ActionAllow, ProtocolTCP, fmt.Sprint(i+1), "", "10.10.0.0/16"))
+	}
+	// The default deny rule makes one too many.
+	_, _, err := AdminNetworkPolicy(mustCompile(t, vs), KubernetesOptions{})
+	if err == nil || errors.Is(err, ErrInvalidRule) {
+		t.Errorf("error = %v, want too many rules", err)
+	}
+}
@@ -0,0 +1,128 @@
+package firewall
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// NftablesTable is the inet table of the generated ruleset.
+const NftablesTable = "vitistack"
+
+// RenderNftables renders a policy as an nftables ruleset for `nft -f`. The
+// ruleset replaces the table atomically and leaves other tables alone. The
+// input and forward chains accept established traffic, then jump to the rules
+// in spec order; rules matching both address families are split per family.
+func RenderNftables(p *Policy) string {
+	policy := "drop"
+	if p.DefaultAction == ActionAllow {
+		policy = "accept"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated from Vitistack %s, do not edit.\n", p.Name)
+	// Declaring the table first lets the delete succeed on a fresh host.
+	fmt.Fprintf(&b, "table inet %s\ndelete table inet %s\n\n", NftablesTable, NftablesTable)
+	fmt.Fprintf(&b, "table inet %s {\n", NftablesTable)
+	for _, hook := range []string{"input", "forward"} {
+		fmt.Fprintf(&b, "\tchain %s {\n", hook)
+		fmt.Fprintf(&b, "\t\ttype filter hook %s priority filter; policy %s;\n", hook, policy)
+		b.WriteString("\t\tct state established,related accept\n")
+		b.WriteString("\t\tct state invalid drop\n")
+		if hook == "input" {
+			b.WriteString("\t\tiifname \"lo\" accept\n")
+		}
+		b.WriteString("\t\tjump rules\n")
+		b.WriteString("\t}\n\n")
+	}
+	b.WriteString("\tchain rules {\n")
+	for i := range p.Rules {
+		for _, line := range nftRule(&p.Rules[i]) {
+			fmt.Fprintf(&b, "\t\t%s\n", line)
+		}
+	}
+	b.WriteString("\t}\n}\n")
+	return b.String()
+}
+
+// nftRule returns the statements of a rule, one per address family it
+// restricts.
+func nftRule(r *Rule) []string {
+	verdict := "drop"
+	if r.Action == ActionAllow {
+		verdict = "accept"
+	}
+	tail := fmt.Sprintf("counter %s comment %q", verdict, r.Name)
+
+	families := []string{""}
+	if r.Sources != nil || r.Destinations != nil {
+		families = r.Families()
+	}
+	var out []string
+	for _, family := range families {
+		var parts []string
+		if r.Sources != nil {
+			parts = append(parts, family+" saddr "+nftSet(prefixStrings(r.Sources, family)))
+		}
+		if r.Destinations != nil {
+			parts = append(parts, family+" daddr "+nftSet(prefixStrings(r.Destinations, family)))
+		}
+		if m := nftProtocol(r, family); m != "" {
+			parts = append(parts, m)
+		}
+		parts = append(parts, tail)
+		out = append(out, strings.Join(parts, " "))
+	}
+	return out
+}
+
+// nftProtocol returns the protocol and port match of a rule in a family, ""
+// for the empty family.
+func nftProtocol(r *Rule, family string) string {
+	ports := make([]string, len(r.Ports))
+	for i, p := range r.Ports {
+		ports[i] = p.String()
+	}
+	switch r.Protocol {
+	case ProtocolTCP, ProtocolUDP:
+		if r.Ports == nil {
+			return "meta l4proto " + r.Protocol
+		}
+		return r.Protocol + " dport " + nftSet(ports)
+	case ProtocolICMP:
+		switch family {
+		case "ip":
+			return "meta l4proto icmp"
+		case "ip6":
+			return "meta l4proto ipv6-icmp"
+		}
+		return "meta l4proto { icmp, ipv6-icmp }"
+	}
+	if r.Ports == nil {
+		return ""
+	}
+	return "meta l4proto { tcp, udp } th dport " + nftSet(ports)
+}
+
+// nftSet returns a single element as is and several as an anonymous set.
+func nftSet(elements []string) string {
+	if len(elements) == 1 {
+		return elements[0]
+	}
+	return "{ " + strings.Join(elements, ", ") + " }"
+}
+
+func prefixStrings(prefixes []netip.Prefix, family string) []string {
+	var out []string
+	for _, p := range prefixes {
+		if prefixFamily(p) != family {
+			continue
+		}
+		if p.IsSingleIP() {
+			out = append(out, p.Addr().String())
+			continue
+		}
+		out = append(out, p.String())
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package firewall
+
+import (
+	"slices"
+	"testing"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+// goldenFirewalls are the firewalls rendered into testdata.
+var goldenFirewalls = map[string]*v1alpha1.Vitistack{
+	// Ingress with deny exceptions, egress, dual stack sets, and rules
+	// Kubernetes policies leave out.
+	"default-deny": newVitistack(ActionDeny,
+		rule("guests", ActionDeny, ProtocolTCP, "22", "10.10.1.128/25", ""),
+		rule("ssh", ActionAllow, ProtocolTCP, "22", "prod, bastion", ""),
+		rule("web", ActionAllow, ProtocolTCP, "80,443", "", ""),
+		rule("apps", ActionAllow, ProtocolAll, "8000-8100", "office", ""),
+		rule("dns", ActionAllow, ProtocolUDP, "53", "", "10.10.0.53, 2001:db8:1::53"),
+		rule("no-smtp", ActionDeny, ProtocolTCP, "25", "", "0.0.0.0/0"),
+		rule("mail", ActionAllow, ProtocolTCP, "", "", "prod"),
+		rule("ping", ActionAllow, ProtocolICMP, "", "", ""),
+		rule("db", ActionAllow, ProtocolTCP, "5432", "web", "prod"),
+	),
+	// Deny rules in front of the default allow.
+	"default-allow": newVitistack(ActionAllow,
+		rule("telnet", ActionDeny, ProtocolTCP, "23", "", ""),
+		rule("blocked", ActionDeny, ProtocolAll, "", "198.51.100.0/24, 2001:db8:bad::/48", ""),
+		rule("ping-v6", ActionDeny, ProtocolICMP, "", "2001:db8::/32", ""),
+		rule("exfiltration", ActionDeny, ProtocolAll, "", "", "203.0.113.0/24"),
+	),
+}
+
+func TestRenderNftablesGolden(t *testing.T) {
+	for name, vs := range goldenFirewalls {
+		t.Run(name, func(t *testing.T) {
+			checkGolden(t, name+".nft", RenderNftables(mustCompile(t, vs)))
+		})
+	}
+}
+
+func TestNftRule(t *testing.T) {
+	tests := []struct {
+		in   v1alpha1.VitistackFirewallRule
+		want []string
+	}{
+		{
+			in:   rule("any", ActionAllow, ProtocolAll, "", "", ""),
+			want: []string{`counter accept comment "any"`},
+		},
+		{
+			in:   rule("tcp", ActionDeny, ProtocolTCP, "", "", ""),
+			want: []string{`meta l4proto tcp counter drop comment "tcp"`},
+		},
+		{
+			in:   rule("ports", ActionAllow, ProtocolAll, "53,8000-8100", "", ""),
+			want: []string{`meta l4proto { tcp, udp } th dport { 53, 8000-8100 } counter accept comment "ports"`},
+		},
+		{
+			in:   rule("ping", ActionAllow, ProtocolICMP, "", "", ""),
+			want: []string{`meta l4proto { icmp, ipv6-icmp } counter accept comment "ping"`},
+		},
+		{
+			in: rule("office", ActionAllow, ProtocolICMP, "", "office", ""),
+			want: []string{
+				`ip saddr 192.0.2.0/24 meta l4proto icmp counter accept comment "office"`,
+				`ip6 saddr 2001:db8:1::/48 meta l4proto ipv6-icmp counter accept comment "office"`,
+			},
+		},
+		{
+			// Only the family both ends have is matched.
+			in:   rule("bastion", ActionAllow, ProtocolTCP, "22", "office", "10.10.0.1, 10.10.0.2"),
+			want: []string{`ip saddr 192.0.2.0/24 ip daddr { 10.10.0.1, 10.10.0.2 } tcp dport 22 counter accept comment "bastion"`},
+		},
+	}
+	names, err := NamesFrom(&newVitistack("").Spec.Networking, sets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.in.Name, func(t *testing.T) {
+			r, err := ParseRule(&tt.in, 0, names)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := nftRule(&r); !slices.Equal(got, tt.want) {
+				t.Errorf("got\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+// Package healthcheck evaluates a MachineHealthCheck against a set of
+// Machines and decides which machines to remediate.
+package healthcheck
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vitistack/crds/pkg/machineset"
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// DefaultStartupTimeout is used when spec.startupTimeout is not set.
+const DefaultStartupTimeout = 10 * time.Minute
+
+var defaultMaxUnhealthy = intstr.FromString("100%")
+
+// Action is what a controller should do with an unhealthy machine.
+type Action string
+
+const (
+	// ActionReboot restarts the machine through its provider.
+	ActionReboot Action = "Reboot"
+	// ActionDelete deletes the machine so its MachineSet creates a replacement.
+	ActionDelete Action = "Delete"
+	// ActionAnnotate only sets the unhealthy annotation on the machine.
+	ActionAnnotate Action = "Annotate"
+)
+
+// Reasons reported for unhealthy machines.
+const (
+	ReasonMachineFailed      = "MachineFailed"
+	ReasonStartupTimeout     = "StartupTimeout"
+	ReasonUnhealthyCondition = "UnhealthyCondition"
+	ReasonTooManyUnhealthy   = "TooManyUnhealthy"
+)
+
+// Unhealthy is a machine that failed the health check.
+type Unhealthy struct {
+	Machine *v1alpha1.Machine
+	Reason  string
+	Message string
+}
+
+// Remediation is a decision to act on an unhealthy machine.
+type Remediation struct {
+	Machine *v1alpha1.Machine
+	Action  Action
+	Reason  string
+	Message string
+}
+
+// Result is the outcome of a health check evaluation.
+type Result struct {
+	// Targets are the machines matched by the selector, sorted by name.
+	Targets []*v1alpha1.Machine
+	// Healthy are the targets that passed the check.
+	Healthy []*v1alpha1.Machine
+	// Unhealthy are the targets that failed the check.
+	Unhealthy []Unhealthy
+	// Remediate lists the actions to take. It is empty when short-circuited.
+	Remediate []Remediation
+	// MaxUnhealthy is the absolute limit derived from spec.maxUnhealthy.
+	MaxUnhealthy int
+	// ShortCircuited is true when more machines are unhealthy than allowed,
+	// in which case nothing is remediated.
+	ShortCircuited bool
+	// RecheckAfter is the time until the next machine may cross a timeout, or
+	// zero when no timeout is pending.
+	RecheckAfter time.Duration
+}
+
+// Evaluate checks every machine matched by the health check.
+//
+// A machine is unhealthy when it has failed, when it has not reported a Ready
+// condition within the startup timeout, or when one of the unhealthy condition
+// rules has held for longer than its timeout. A rule with status Unknown also
+// matches a condition that is missing, counted from the machine's creation.
+//
+// A machine rebooted or deleted within the startup timeout, as recorded by
+// MarkRemediated, is not remediated again until the timeout expires.
+func Evaluate(mhc *v1alpha1.MachineHealthCheck, machines []v1alpha1.Machine, now time.Time) (*Result, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&mhc.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector on MachineHealthCheck %s/%s: %w", mhc.Namespace, mhc.Name, err)
+	}
+	if selector.Empty() {
+		return nil, fmt.Errorf("empty selector on MachineHealthCheck %s/%s would match every machine", mhc.Namespace, mhc.Name)
+	}
+
+	result := &Result{}
+	for i := range machines {
+		m := &machines[i]
+		if m.Namespace != mhc.Namespace || machineset.IsMachineDeleting(m) || !selector.Matches(labels.Set(m.Labels)) {
+			continue
+		}
+		result.Targets = append(result.Targets, m)
+	}
+	sort.Slice(result.Targets, func(i, j int) bool { return result.Targets[i].Name < result.Targets[j].Name })
+
+	for _, m := range result.Targets {
+		reason, message, recheck := check(mhc, m, now)
+		if reason == "" {
+			result.Healthy = append(result.Healthy, m)
+		} else {
+			result.Unhealthy = append(result.Unhealthy, Unhealthy{Machine: m, Reason: reason, Message: message})
+		}
+		if recheck > 0 && (result.RecheckAfter == 0 || recheck < result.RecheckAfter) {
+			result.RecheckAfter = recheck
+		}
+	}
+
+	result.MaxUnhealthy, err = intstr.GetScaledValueFromIntOrPercent(
+		intstr.ValueOrDefault(mhc.Spec.MaxUnhealthy, defaultMaxUnhealthy), len(result.Targets), false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maxUnhealthy: %w", err)
+	}
+	if len(result.Unhealthy) > result.MaxUnhealthy {
+		result.ShortCircuited = true
+		return result, nil
+	}
+
+	for _, u := range result.Unhealthy {
+		r, ok := remediation(mhc, u)
+		if !ok {
+			continue
+		}
+		// A rebooted or replaced machine gets the startup timeout to recover
+		// before it is remediated again.
+		if at, ok := RemediatedAt(u.Machine); ok && r.Action != ActionAnnotate {
+			if wait := at.Add(startupTimeout(mhc)).Sub(now); wait > 0 {
+				if result.RecheckAfter == 0 || wait < result.RecheckAfter {
+					result.RecheckAfter = wait
+				}
+				continue
+			}
+		}
+		result.Remediate = append(result.Remediate, r)
+	}
+	return result, nil
+}
+
+// RemediatedAt returns when a machine was last rebooted or deleted by a
+// health check, from its remediated-at annotation.
+func RemediatedAt(m *v1alpha1.Machine) (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339, m.Annotations[v1alpha1.MachineRemediatedAnnotation])
+	return t, err == nil
+}
+
+// MarkRemediated records a remediation on the machine. Controllers call it
+// when they reboot or delete a machine, so Evaluate does not repeat the
+// remediation while the machine recovers.
+func MarkRemediated(m *v1alpha1.Machine, now time.Time) {
+	if m.Annotations == nil {
+		m.Annotations = map[string]string{}
+	}
+	m.Annotations[v1alpha1.MachineRemediatedAnnotation] = now.UTC().Format(time.RFC3339)
+}
+
+// CalculateStatus builds the MachineHealthCheck status from an evaluation result.
+func CalculateStatus(mhc *v1alpha1.MachineHealthCheck, result *Result, now time.Time) v1alpha1.MachineHealthCheckStatus {
+	status := *mhc.Status.DeepCopy()
+	status.ObservedGeneration = mhc.Generation
+	status.ExpectedMachines = int32(len(result.Targets))
+	status.CurrentHealthy = int32(len(result.Healthy))
+	status.RemediationsAllowed = int32(max(result.MaxUnhealthy-len(result.Unhealthy), 0))
+
+	status.Targets = make([]string, 0, len(result.Targets))
+	for _, m := range result.Targets {
+		status.Targets = append(status.Targets, m.Name)
+	}
+	status.UnhealthyMachines = nil
+	for _, u := range result.Unhealthy {
+		status.UnhealthyMachines = append(status.UnhealthyMachines, u.Machine.Name)
+	}
+	if len(result.Remediate) > 0 {
+		t := metav1.NewTime(now)
+		status.LastRemediationTime = &t
+	}
+
+	c := metav1.Condition{
+		Type:               v1alpha1.MachineHealthCheckConditionRemediationAllowed,
+		Status:             metav1.ConditionTrue,
+		Reason:             "RemediationAllowed",
+		Message:            fmt.Sprintf("%d of %d machines unhealthy, limit is %d", len(result.Unhealthy), len(result.Targets), result.MaxUnhealthy),
+		ObservedGeneration: mhc.Generation,
+		LastTransitionTime: metav1.NewTime(now),
+	}
+	if result.ShortCircuited {
+		c.Status = metav1.ConditionFalse
+		c.Reason = ReasonTooManyUnhealthy
+	}
+	meta.SetStatusCondition(&status.Conditions, c)
+	return status
+}
+
+// check returns the reason a machine is unhealthy, or "" with the time until
+// the earliest pending timeout expires.
+func check(mhc *v1alpha1.MachineHealthCheck, m *v1alpha1.Machine, now time.Time) (reason, message string, recheck time.Duration) {
+	if machineset.IsMachineFailed(m) {
+		message := fmt.Sprintf("machine is in phase %s", m.Status.Phase)
+		if m.Status.FailureMessage != nil {
+			message = *m.Status.FailureMessage
+		} else if m.Status.FailureReason != nil {
+			message = *m.Status.FailureReason
+		}
+		return ReasonMachineFailed, message, 0
+	}
+
+	created := m.CreationTimestamp.Time
+	if conditionOf(m, v1alpha1.MachineConditionReady) == nil {
+		timeout := startupTimeout(mhc)
+		recheck = created.Add(timeout).Sub(now)
+		if recheck <= 0 {
+			return ReasonStartupTimeout, fmt.Sprintf("no Ready condition reported within %s", timeout), 0
+		}
+	}
+
+	for _, rule := range mhc.Spec.UnhealthyConditions {
+		since := created
+		if c := conditionOf(m, rule.Type); c != nil {
+			if c.Status != rule.Status {
+				continue
+			}
+			since = c.LastTransitionTime.Time
+		} else if rule.Status != v1alpha1.ConditionUnknown {
+			continue
+		}
+
+		remaining := since.Add(rule.Timeout.Duration).Sub(now)
+		if remaining <= 0 {
+			return ReasonUnhealthyCondition, fmt.Sprintf("condition %s has been %s for more than %s", rule.Type, rule.Status, rule.Timeout.Duration), 0
+		}
+		if recheck == 0 || remaining < recheck {
+			recheck = remaining
+		}
+	}
+	return "", "", recheck
+}
+
+func remediation(mhc *v1alpha1.MachineHealthCheck, u Unhealthy) (Remediation, bool) {
+	r := Remediation{Machine: u.Machine, Reason: u.Reason, Message: u.Message}
+	switch mhc.Spec.Remediation.Type {
+	case v1alpha1.MachineRemediationReboot:
+		r.Action = ActionReboot
+	case v1alpha1.MachineRemediationAnnotateOnly:
+		r.Action = ActionAnnotate
+	default:
+		// Deleting a machine nobody recreates would lose it, so machines
+		// without a controlling MachineSet are only annotated.
+		r.Action = ActionDelete
+		if ref := metav1.GetControllerOf(u.Machine); ref == nil || ref.Kind != "MachineSet" {
+			r.Action = ActionAnnotate
+			r.Message += ", not controlled by a MachineSet"
+		}
+	}
+	if r.Action == ActionAnnotate && u.Machine.Annotations[v1alpha1.MachineUnhealthyAnnotation] != "" {
+		return r, false
+	}
+	return r, true
+}
+
+func startupTimeout(mhc *v1alpha1.MachineHealthCheck) time.Duration {
+	if mhc.Spec.StartupTimeout != nil {
+		return mhc.Spec.StartupTimeout.Duration
+	}
+	return DefaultStartupTimeout
+}
+
+func conditionOf(m *v1alpha1.Machine, conditionType string) *v1alpha1.MachineCondition {
+	for i := range m.Status.Conditions {
+		if m.Status.Conditions[i].Type == conditionType {
+			return &m.Status.Conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package healthcheck
+
+import (
+	"testing"
+	"time"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newHealthCheck(remediation string) *v1alpha1.MachineHealthCheck {
+	return &v1alpha1.MachineHealthCheck{
+		ObjectMeta: metav1.ObjectMeta{Name: "workers", Namespace: "default"},
+		Spec: v1alpha1.MachineHealthCheckSpec{
+			Selector: metav1.LabelSelector{MatchLabels: map[string]string{"role": "worker"}},
+			UnhealthyConditions: []v1alpha1.UnhealthyCondition{
+				{Type: v1alpha1.MachineConditionReady, Status: v1alpha1.ConditionFalse, Timeout: metav1.Duration{Duration: 5 * time.Minute}},
+			},
+			Remediation: v1alpha1.MachineRemediationStrategy{Type: remediation},
+		},
+	}
+}
+
+// newMachine returns a worker whose Ready condition has had the given
+// status since the given time before now.
+func newMachine(name, ready string, since time.Duration) v1alpha1.Machine {
+	return v1alpha1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			Labels:            map[string]string{"role": "worker"},
+			CreationTimestamp: metav1.NewTime(now.Add(-24 * time.Hour)),
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: v1alpha1.GroupVersion.String(), Kind: "MachineSet", Name: "workers", UID: "ms-uid", Controller: ptr(true),
+			}},
+		},
+		Status: v1alpha1.MachineStatus{Conditions: []v1alpha1.MachineCondition{{
+			Type: v1alpha1.MachineConditionReady, Status: ready, LastTransitionTime: metav1.NewTime(now.Add(-since)),
+		}}},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name          string
+		remediation   string
+		maxUnhealthy  *intstr.IntOrString
+		machines      []v1alpha1.Machine
+		wantUnhealthy int
+		wantActions   map[string]Action
+		shortCircuit  bool
+		wantRecheck   time.Duration
+	}{
+		{
+			name:        "healthy",
+			machines:    []v1alpha1.Machine{newMachine("a", v1alpha1.ConditionTrue, time.Hour)},
+			wantActions: map[string]Action{},
+		},
+		{
+			name:        "not ready within the timeout",
+			machines:    []v1alpha1.Machine{newMachine("a", v1alpha1.ConditionFalse, 2*time.Minute)},
+			wantActions: map[string]Action{},
+			wantRecheck: 3 * time.Minute,
+		},
+		{
+			name:          "recreate deletes",
+			remediation:   v1alpha1.MachineRemediationRecreate,
+			machines:      []v1alpha1.Machine{newMachine("a", v1alpha1.ConditionFalse, time.Hour)},
+			wantUnhealthy: 1,
+			wantActions:   map[string]Action{"a": ActionDelete},
+		},
+		{
+			name:          "reboot",
+			remediation:   v1alpha1.MachineRemediationReboot,
+			machines:      []v1alpha1.Machine{newMachine("a", v1alpha1.ConditionFalse, time.Hour)},
+			wantUnhealthy: 1,
+			wantActions:   map[string]Action{"a": ActionReboot},
+		},
+		{
+			name:         "too many unhealthy",
+			remediation:  v1alpha1.MachineRemediationReboot,
+			maxUnhealthy: ptr(intstr.FromInt32(1)),
+			machines: []v1alpha1.Machine{
+				newMachine("a", v1alpha1.ConditionFalse, time.Hour),
+				newMachine("b", v1alpha1.ConditionFalse, time.Hour),
+				newMachine("c", v1alpha1.ConditionTrue, time.Hour),
+			},
+			wantUnhealthy: 2,
+			wantActions:   map[string]Action{},
+			shortCircuit:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mhc := newHealthCheck(tt.remediation)
+			mhc.Spec.MaxUnhealthy = tt.maxUnhealthy
+			result, err := Evaluate(mhc, tt.machines, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Unhealthy) != tt.wantUnhealthy {
+				t.Errorf("unhealthy = %d, want %d", len(result.Unhealthy), tt.wantUnhealthy)
+			}
+			if result.ShortCircuited != tt.shortCircuit {
+				t.Errorf("short-circuited = %v, want %v", result.ShortCircuited, tt.shortCircuit)
+			}
+			if result.RecheckAfter != tt.wantRecheck {
+				t.Errorf("recheck after %s, want %s", result.RecheckAfter, tt.wantRecheck)
+			}
+			got := map[string]Action{}
+			for _, r := range result.Remediate {
+				got[r.Machine.Name] = r.Action
+			}
+			if len(got) != len(tt.wantActions) {
+				t.Fatalf("actions = %v, want %v", got, tt.wantActions)
+			}
+			for name, action := range tt.wantActions {
+				if got[name] != action {
+					t.Errorf("action for %s = %s, want %s", name, got[name], action)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateRemediationInFlight(t *testing.T) {
+	mhc := newHealthCheck(v1alpha1.MachineRemediationReboot)
+	machine := newMachine("a", v1alpha1.ConditionFalse, time.Hour)
+
+	result, err := Evaluate(mhc, []v1alpha1.Machine{machine}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Remediate) != 1 || result.Remediate[0].Action != ActionReboot {
+		t.Fatalf("remediate = %+v, want one reboot", result.Remediate)
+	}
+	MarkRemediated(&machine, now)
+
+	// The rebooting machine is still unhealthy, but is left alone until the
+	// startup timeout has passed.
+	for _, after := range []time.Duration{time.Second, 5 * time.Minute, DefaultStartupTimeout - time.Second} {
+		result, err := Evaluate(mhc, []v1alpha1.Machine{machine}, now.Add(after))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Unhealthy) != 1 || len(result.Remediate) != 0 {
+			t.Fatalf("after %s: %d unhealthy, remediate %+v, want 1 unhealthy and no remediation", after, len(result.Unhealthy), result.Remediate)
+		}
+		if want := DefaultStartupTimeout - after; result.RecheckAfter != want {
+			t.Errorf("after %s: recheck after %s, want %s", after, result.RecheckAfter, want)
+		}
+	}
+
+	result, err = Evaluate(mhc, []v1alpha1.Machine{machine}, now.Add(DefaultStartupTimeout))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Remediate) != 1 || result.Remediate[0].Action != ActionReboot {
+		t.Fatalf("after the startup timeout: remediate = %+v, want another reboot", result.Remediate)
+	}
+}
+
+func TestEvaluateAnnotateOnlyOnce(t *testing.T) {
+	mhc := newHealthCheck(v1alpha1.MachineRemediationAnnotateOnly)
+	machine := newMachine("a", v1alpha1.ConditionFalse, time.Hour)
+	result, err := Evaluate(mhc, []v1alpha1.Machine{machine}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Remediate) != 1 || result.Remediate[0].Action != ActionAnnotate {
+		t.Fatalf("remediate = %+v, want one annotation", result.Remediate)
+	}
+
+	machine.Annotations = map[string]string{v1alpha1.MachineUnhealthyAnnotation: ReasonUnhealthyCondition}
+	if result, _ := Evaluate(mhc, []v1alpha1.Machine{machine}, now); len(result.Remediate) != 0 {
+		t.Errorf("annotated machine remediated again: %+v", result.Remediate)
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
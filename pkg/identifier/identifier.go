@@ -0,0 +1,283 @@
+// Package identifier parses, formats and validates the datacenter, supervisor
+// and cluster identifiers carried by NetworkNamespace, NetworkConfiguration
+// and LoadBalancer.
+//
+// A datacenter identifier is <country>-<region>-<availability zone>, for
+// example no-west-az1, where country is an ISO 3166-1 alpha-2 code. A
+// supervisor identifier is unique within a datacenter and a cluster identifier
+// is unique within an availability zone; both are lower case DNS labels. The
+// CRDs enforce the same formats with the patterns below, except for the
+// country code list, which only Validate checks.
+package identifier
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Patterns of the identifiers. They must match the kubebuilder markers on the
+// identifier fields of the API types.
+const (
+	DatacenterPattern = `^[a-z]{2}-[a-z0-9]+-[a-z0-9]+$`
+	NamePattern       = `^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`
+)
+
+// Length limits of the identifiers, as in the CRDs.
+const (
+	MaxLength           = 32
+	MinSupervisorLength = 2
+	MinClusterLength    = 3
+)
+
+// Labels set on objects to select them by location.
+const (
+	DatacenterLabel = "vitistack.io/datacenter"
+	CountryLabel    = "vitistack.io/country"
+	RegionLabel     = "vitistack.io/region"
+	ZoneLabel       = "vitistack.io/zone"
+	SupervisorLabel = "vitistack.io/supervisor"
+	ClusterLabel    = "vitistack.io/cluster"
+)
+
+var (
+	datacenterRegexp = regexp.MustCompile(DatacenterPattern)
+	nameRegexp       = regexp.MustCompile(NamePattern)
+)
+
+// Datacenter is a parsed datacenter identifier.
+type Datacenter struct {
+	// Country is an ISO 3166-1 alpha-2 code in lower case, e.g. "no".
+	Country string
+	// Region within the country, e.g. "west".
+	Region string
+	// Zone is the availability zone within the region, e.g. "az1".
+	Zone string
+}
+
+// NewDatacenter builds a datacenter identifier from its parts. The parts are
+// lower-cased before they are validated.
+func NewDatacenter(country, region, zone string) (Datacenter, error) {
+	d := Datacenter{
+		Country: strings.ToLower(country),
+		Region:  strings.ToLower(region),
+		Zone:    strings.ToLower(zone),
+	}
+	return d, d.Validate()
+}
+
+// ParseDatacenter parses and validates a datacenter identifier such as "no-west-az1".
+func ParseDatacenter(s string) (Datacenter, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 3 {
+		return Datacenter{}, fmt.Errorf("invalid datacenter identifier %q: expected <country>-<region>-<availability zone>, e.g. no-west-az1", s)
+	}
+	d := Datacenter{Country: parts[0], Region: parts[1], Zone: parts[2]}
+	if err := d.Validate(); err != nil {
+		return Datacenter{}, err
+	}
+	return d, nil
+}
+
+// String formats the identifier as <country>-<region>-<availability zone>.
+func (d Datacenter) String() string {
+	return d.Country + "-" + d.Region + "-" + d.Zone
+}
+
+// Validate checks the format of every part and that the country is an ISO
+// 3166-1 alpha-2 code.
+func (d Datacenter) Validate() error {
+	s := d.String()
+	if len(s) > MaxLength {
+		return fmt.Errorf("invalid datacenter identifier %q: must be at most %d characters", s, MaxLength)
+	}
+	if !datacenterRegexp.MatchString(s) {
+		return fmt.Errorf("invalid datacenter identifier %q: expected lower case <country>-<region>-<availability zone>, e.g. no-west-az1", s)
+	}
+	if !countries[d.Country] {
+		return fmt.Errorf("invalid datacenter identifier %q: %q is not an ISO 3166-1 alpha-2 country code", s, d.Country)
+	}
+	return nil
+}
+
+// Compare orders datacenters by country, region and zone. It returns -1, 0 or +1.
+func (d Datacenter) Compare(other Datacenter) int {
+	if c := strings.Compare(d.Country, other.Country); c != 0 {
+		return c
+	}
+	if c := strings.Compare(d.Region, other.Region); c != 0 {
+		return c
+	}
+	return strings.Compare(d.Zone, other.Zone)
+}
+
+// SameRegion reports whether both datacenters are availability zones of the same region.
+func (d Datacenter) SameRegion(other Datacenter) bool {
+	return d.Country == other.Country && d.Region == other.Region
+}
+
+// ValidateSupervisor checks a supervisor identifier.
+func ValidateSupervisor(s string) error {
+	return validateName("supervisor", s, MinSupervisorLength)
+}
+
+// ValidateCluster checks a cluster identifier.
+func ValidateCluster(s string) error {
+	return validateName("cluster", s, MinClusterLength)
+}
+
+func validateName(kind, s string, minLength int) error {
+	if len(s) < minLength || len(s) > MaxLength {
+		return fmt.Errorf("invalid %s identifier %q: must be %d to %d characters", kind, s, minLength, MaxLength)
+	}
+	if !nameRegexp.MatchString(s) {
+		return fmt.Errorf("invalid %s identifier %q: must consist of lower case letters, digits and '-', and start and end with a letter or digit", kind, s)
+	}
+	return nil
+}
+
+// Location identifies where an object belongs. Supervisor and Cluster may be
+// empty for objects that are not scoped to them.
+type Location struct {
+	Datacenter Datacenter
+	Supervisor string
+	Cluster    string
+}
+
+// Parse parses and validates the three identifiers. Empty supervisor and
+// cluster identifiers are allowed.
+func Parse(datacenter, supervisor, cluster string) (Location, error) {
+	d, err := ParseDatacenter(datacenter)
+	if err != nil {
+		return Location{}, err
+	}
+	l := Location{Datacenter: d, Supervisor: supervisor, Cluster: cluster}
+	if err := l.Validate(); err != nil {
+		return Location{}, err
+	}
+	return l, nil
+}
+
+// Validate checks all identifiers of the location.
+func (l Location) Validate() error {
+	if err := l.Datacenter.Validate(); err != nil {
+		return err
+	}
+	if l.Supervisor != "" {
+		if err := ValidateSupervisor(l.Supervisor); err != nil {
+			return err
+		}
+	}
+	if l.Cluster != "" {
+		if err := ValidateCluster(l.Cluster); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String formats the location as <datacenter>/<supervisor>/<cluster>, leaving
+// out empty trailing parts. The parts are positional, so a cluster without a
+// supervisor keeps an empty supervisor, as in no-west-az1//prod.
+func (l Location) String() string {
+	s := l.Datacenter.String()
+	switch {
+	case l.Cluster != "":
+		return s + "/" + l.Supervisor + "/" + l.Cluster
+	case l.Supervisor != "":
+		return s + "/" + l.Supervisor
+	}
+	return s
+}
+
+// Compare orders locations by datacenter, supervisor and cluster. It returns -1, 0 or +1.
+func (l Location) Compare(other Location) int {
+	if c := l.Datacenter.Compare(other.Datacenter); c != 0 {
+		return c
+	}
+	if c := strings.Compare(l.Supervisor, other.Supervisor); c != 0 {
+		return c
+	}
+	return strings.Compare(l.Cluster, other.Cluster)
+}
+
+// Labels returns the location labels to set on an object.
+func (l Location) Labels() map[string]string {
+	m := map[string]string{
+		DatacenterLabel: l.Datacenter.String(),
+		CountryLabel:    l.Datacenter.Country,
+		RegionLabel:     l.Datacenter.Region,
+		ZoneLabel:       l.Datacenter.Zone,
+	}
+	if l.Supervisor != "" {
+		m[SupervisorLabel] = l.Supervisor
+	}
+	if l.Cluster != "" {
+		m[ClusterLabel] = l.Cluster
+	}
+	return m
+}
+
+// FromLabels reads a location from object labels.
+func FromLabels(m map[string]string) (Location, error) {
+	if m[DatacenterLabel] == "" {
+		return Location{}, fmt.Errorf("label %s is not set", DatacenterLabel)
+	}
+	return Parse(m[DatacenterLabel], m[SupervisorLabel], m[ClusterLabel])
+}
+
+// Query selects objects by location. Empty fields match anything, so a query
+// with only Country set selects a whole country.
+type Query struct {
+	Country    string
+	Region     string
+	Zone       string
+	Supervisor string
+	Cluster    string
+}
+
+// LabelSelector returns a label selector for the query, for use in specs.
+func (q Query) LabelSelector() *metav1.LabelSelector {
+	match := map[string]string{}
+	for label, value := range map[string]string{
+		CountryLabel:    q.Country,
+		RegionLabel:     q.Region,
+		ZoneLabel:       q.Zone,
+		SupervisorLabel: q.Supervisor,
+		ClusterLabel:    q.Cluster,
+	} {
+		if value != "" {
+			match[label] = value
+		}
+	}
+	return &metav1.LabelSelector{MatchLabels: match}
+}
+
+// Selector returns a label selector for the query, for use with list calls.
+func (q Query) Selector() labels.Selector {
+	return labels.SelectorFromSet(q.LabelSelector().MatchLabels)
+}
+
+// Matches reports whether a location is selected by the query.
+func (q Query) Matches(l Location) bool {
+	return q.Selector().Matches(labels.Set(l.Labels()))
+}
+
+// FromNetworkNamespace reads the location from the spec of a NetworkNamespace.
+func FromNetworkNamespace(ns *v1alpha1.NetworkNamespace) (Location, error) {
+	return Parse(ns.Spec.DatacenterIdentifier, ns.Spec.SupervisorIdentifier, ns.Spec.ClusterIdentifier)
+}
+
+// FromNetworkConfiguration reads the location from the spec of a NetworkConfiguration.
+func FromNetworkConfiguration(nc *v1alpha1.NetworkConfiguration) (Location, error) {
+	return Parse(nc.Spec.DatacenterIdentifier, nc.Spec.SupervisorIdentifier, nc.Spec.ClusterIdentifier)
+}
+
+// FromLoadBalancer reads the location from the spec of a LoadBalancer.
+func FromLoadBalancer(lb *v1alpha1.LoadBalancer) (Location, error) {
+	return Parse(lb.Spec.DatacenterIdentifier, lb.Spec.SupervisorIdentifier, lb.Spec.ClusterIdentifier)
+}
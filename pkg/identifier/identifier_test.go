@@ -0,0 +1,178 @@
+package identifier
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseDatacenter(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Datacenter
+		// wantErr is part of the error, "" for none.
+		wantErr string
+	}{
+		{in: "no-west-az1", want: Datacenter{Country: "no", Region: "west", Zone: "az1"}},
+		{in: "gb-london-2", want: Datacenter{Country: "gb", Region: "london", Zone: "2"}},
+		{in: "uk-london-az1", wantErr: `"uk" is not an ISO 3166-1 alpha-2 country code`},
+		{in: "xx-west-az1", wantErr: "not an ISO 3166-1"},
+		{in: "No-west-az1", wantErr: "expected lower case"},
+		{in: "nor-west-az1", wantErr: "expected lower case"},
+		{in: "no-west_1-az1", wantErr: "expected lower case"},
+		{in: "no-west", wantErr: "expected <country>-<region>-<availability zone>"},
+		{in: "no-west-az1-b", wantErr: "expected <country>-<region>-<availability zone>"},
+		{in: "no--az1", wantErr: "expected lower case"},
+		{in: "no-westernnorwayregion-availzone1", wantErr: "at most 32 characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDatacenter(tt.in)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("datacenter = %+v, want %+v", got, tt.want)
+			}
+			if err == nil && got.String() != tt.in {
+				t.Errorf("String() = %q, want %q", got.String(), tt.in)
+			}
+		})
+	}
+
+	if d, err := NewDatacenter("NO", "West", "AZ1"); err != nil || d.String() != "no-west-az1" {
+		t.Errorf("NewDatacenter = %v, %v, want no-west-az1", d, err)
+	}
+}
+
+func TestValidateNames(t *testing.T) {
+	tests := []struct {
+		name       string
+		supervisor bool
+		cluster    bool
+	}{
+		{name: "my-namespace", supervisor: true, cluster: true},
+		{name: "ab", supervisor: true},
+		{name: "a"},
+		{name: "abc1", supervisor: true, cluster: true},
+		{name: "My-namespace"},
+		{name: "my_namespace"},
+		{name: "-abc"},
+		{name: "abc-"},
+		{name: strings.Repeat("a", MaxLength), supervisor: true, cluster: true},
+		{name: strings.Repeat("a", MaxLength+1)},
+	}
+	for _, tt := range tests {
+		if err := ValidateSupervisor(tt.name); (err == nil) != tt.supervisor {
+			t.Errorf("ValidateSupervisor(%q) = %v, want valid %v", tt.name, err, tt.supervisor)
+		}
+		if err := ValidateCluster(tt.name); (err == nil) != tt.cluster {
+			t.Errorf("ValidateCluster(%q) = %v, want valid %v", tt.name, err, tt.cluster)
+		}
+	}
+}
+
+func TestLocationString(t *testing.T) {
+	tests := []struct {
+		supervisor, cluster string
+		want                string
+	}{
+		{want: "no-west-az1"},
+		{supervisor: "sv1", want: "no-west-az1/sv1"},
+		{supervisor: "sv1", cluster: "prod", want: "no-west-az1/sv1/prod"},
+		{cluster: "prod", want: "no-west-az1//prod"},
+	}
+	for _, tt := range tests {
+		l, err := Parse("no-west-az1", tt.supervisor, tt.cluster)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := l.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestLocationCompare(t *testing.T) {
+	var locations []Location
+	for _, s := range [][3]string{
+		{"se-east-az1", "", ""},
+		{"no-west-az2", "", ""},
+		{"no-west-az1", "sv2", ""},
+		{"no-west-az1", "sv1", "prod"},
+		{"no-west-az1", "sv1", "dev"},
+		{"no-east-az1", "", ""},
+		{"no-west-az1", "", ""},
+	} {
+		l, err := Parse(s[0], s[1], s[2])
+		if err != nil {
+			t.Fatal(err)
+		}
+		locations = append(locations, l)
+	}
+	slices.SortFunc(locations, Location.Compare)
+	var got []string
+	for _, l := range locations {
+		got = append(got, l.String())
+	}
+	want := []string{"no-east-az1", "no-west-az1", "no-west-az1/sv1/dev", "no-west-az1/sv1/prod", "no-west-az1/sv2", "no-west-az2", "se-east-az1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("sorted = %q, want %q", got, want)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	for _, l := range []Location{
+		{Datacenter: Datacenter{Country: "no", Region: "west", Zone: "az1"}, Supervisor: "sv1", Cluster: "prod"},
+		{Datacenter: Datacenter{Country: "no", Region: "west", Zone: "az1"}, Cluster: "prod"},
+		{Datacenter: Datacenter{Country: "no", Region: "west", Zone: "az1"}},
+	} {
+		labels := l.Labels()
+		if labels[CountryLabel] != "no" || labels[RegionLabel] != "west" || labels[ZoneLabel] != "az1" {
+			t.Errorf("labels = %v, want country, region and zone of no-west-az1", labels)
+		}
+		if _, ok := labels[SupervisorLabel]; ok != (l.Supervisor != "") {
+			t.Errorf("labels = %v, supervisor label set %v, want %v", labels, ok, l.Supervisor != "")
+		}
+		got, err := FromLabels(labels)
+		if err != nil || got != l {
+			t.Errorf("FromLabels(%v) = %v, %v, want %v", labels, got, err, l)
+		}
+	}
+
+	if _, err := FromLabels(map[string]string{SupervisorLabel: "sv1"}); err == nil {
+		t.Error("FromLabels without a datacenter label succeeded")
+	}
+	if _, err := FromLabels(map[string]string{DatacenterLabel: "no-west-az1", ClusterLabel: "Prod"}); err == nil {
+		t.Error("FromLabels with an invalid cluster succeeded")
+	}
+}
+
+func TestQueryMatches(t *testing.T) {
+	l, err := Parse("no-west-az1", "sv1", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		query Query
+		want  bool
+	}{
+		{query: Query{}, want: true},
+		{query: Query{Country: "no"}, want: true},
+		{query: Query{Country: "no", Region: "west"}, want: true},
+		{query: Query{Country: "no", Region: "east"}},
+		{query: Query{Zone: "az1", Cluster: "prod"}, want: true},
+		{query: Query{Zone: "az2"}},
+		{query: Query{Supervisor: "sv2"}},
+		{query: Query{Country: "se"}},
+	}
+	for _, tt := range tests {
+		if got := tt.query.Matches(l); got != tt.want {
+			t.Errorf("%+v.Matches(%s) = %v, want %v", tt.query, l, got, tt.want)
+		}
+	}
+
+	if sel := (Query{Country: "no", Supervisor: "sv1"}).Selector().String(); sel != "vitistack.io/country=no,vitistack.io/supervisor=sv1" {
+		t.Errorf("selector = %q", sel)
+	}
+}
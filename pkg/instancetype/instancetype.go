@@ -0,0 +1,279 @@
+// Package instancetype resolves the instance type of a machine on a
+// MachineProvider. It expands an instance type name into vCPUs and memory
+// using the provider's InstanceTypeInfo, reports explicit CPU and memory
+// settings that disagree with it, suggests the smallest instance type when
+// only CPU and memory are given, and enforces the per-machine CPU, memory and
+// GPU limits of the provider.
+package instancetype
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+var (
+	// ErrUnknown is wrapped by errors of instance types the provider does not
+	// offer or has currently not available.
+	ErrUnknown = errors.New("unknown instance type")
+	// ErrConflict is wrapped by errors of explicit CPU or memory settings
+	// that disagree with the instance type.
+	ErrConflict = errors.New("instance type conflict")
+	// ErrNoFit is wrapped by errors of requirements no instance type meets.
+	ErrNoFit = errors.New("no instance type fits")
+	// ErrExceedsLimit is wrapped by errors of machines larger than the
+	// provider allows.
+	ErrExceedsLimit = errors.New("exceeds provider limit")
+	// ErrNoGPU is wrapped by errors of GPU requirements the provider or
+	// instance type cannot meet.
+	ErrNoGPU = errors.New("no GPU")
+)
+
+// Source tells where the resolved instance type comes from.
+type Source string
+
+const (
+	// SourceSpec is an instance type set on the machine.
+	SourceSpec Source = "Spec"
+	// SourceDefault is the default instance type of the provider, used when
+	// the machine sets neither an instance type nor CPU and memory.
+	SourceDefault Source = "ProviderDefault"
+	// SourceSuggested is the smallest instance type meeting the CPU and memory
+	// of the machine.
+	SourceSuggested Source = "Suggested"
+)
+
+// GiB is the unit of the memory of instance types and of the memory limit of
+// providers. The API calls it GB, but instance types are sized in binary
+// gigabytes: memoryGB "8" is the 8589934592 bytes a Machine sets.
+const GiB = 1 << 30
+
+// Requirements are what a machine asks for. Zero values are unset.
+type Requirements struct {
+	InstanceType string
+	CPUs         int
+	// Memory in bytes.
+	Memory int64
+	GPU    bool
+}
+
+// MachineRequirements returns the requirements of a machine spec. A machine
+// spec has no GPU field, so GPU is left to the caller.
+func MachineRequirements(spec *v1alpha1.MachineSpec) Requirements {
+	return Requirements{
+		InstanceType: spec.InstanceType,
+		CPUs:         VCPUs(spec.CPU),
+		Memory:       spec.Memory,
+	}
+}
+
+// VCPUs returns the number of vCPUs of a CPU configuration: cores times
+// sockets times threads per core, 0 when no cores are set.
+func VCPUs(cpu v1alpha1.MachineCPU) int {
+	if cpu.Cores == 0 {
+		return 0
+	}
+	return cpu.Cores * max(cpu.Sockets, 1) * max(cpu.ThreadsPerCore, 1)
+}
+
+// Resolution is the instance type of a machine on a provider.
+type Resolution struct {
+	// InstanceType is empty when the machine gives CPU and memory and the
+	// provider lists no instance types.
+	InstanceType string
+	Source       Source
+	// Info is nil when the provider does not list the instance type.
+	Info *v1alpha1.InstanceTypeInfo
+	// CPUs, Memory in bytes and GPU of the machine, from Info when known.
+	CPUs   int
+	Memory int64
+	GPU    bool
+}
+
+// CostPerHour returns the cost of the instance type, false when unknown.
+func (r *Resolution) CostPerHour() (float64, bool) {
+	if r.Info == nil {
+		return 0, false
+	}
+	cost, err := strconv.ParseFloat(r.Info.CostPerHour, 64)
+	return cost, err == nil
+}
+
+// Resolve resolves requirements on a provider:
+//
+//   - An instance type is expanded with the InstanceTypeInfo of the
+//     provider. Explicit CPUs or memory that differ from it are conflicts.
+//   - Without an instance type, the smallest available type with enough
+//     vCPUs and memory is suggested, by vCPUs, then memory, then cost. Types
+//     with a GPU are only suggested when a GPU is required.
+//   - Without an instance type, CPUs or memory, the default instance type of
+//     the provider is used.
+//
+// The result must stay within the maxCPUs and maxMemoryGB of the provider, and
+// a GPU needs GPU support of the provider or a GPU instance type. The
+// resolution is returned together with an error that joins one error per
+// problem.
+func Resolve(req Requirements, mp *v1alpha1.MachineProvider) (*Resolution, error) {
+	types := mp.Spec.Capabilities.InstanceTypes
+	res := &Resolution{InstanceType: req.InstanceType, Source: SourceSpec, CPUs: req.CPUs, Memory: req.Memory, GPU: req.GPU}
+	var errs []error
+	fail := func(kind error, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: %s", kind, fmt.Sprintf(format, args...)))
+	}
+
+	if res.InstanceType == "" && req.CPUs == 0 && req.Memory == 0 {
+		if mp.Spec.Compute.DefaultInstanceType == "" {
+			fail(ErrNoFit, "no instance type, CPUs or memory is set and MachineProvider %s has no default instance type", mp.Name)
+			return res, errors.Join(errs...)
+		}
+		res.InstanceType, res.Source = mp.Spec.Compute.DefaultInstanceType, SourceDefault
+	}
+
+	switch {
+	case res.InstanceType != "":
+		info := Find(types, res.InstanceType)
+		if info == nil {
+			if len(types) > 0 {
+				fail(ErrUnknown, "MachineProvider %s does not offer instance type %s", mp.Name, res.InstanceType)
+			}
+			break
+		}
+		if !available(mp, info.Name) {
+			fail(ErrUnknown, "instance type %s is currently not available on MachineProvider %s", info.Name, mp.Name)
+		}
+		res.Info = info
+		memory, err := MemoryBytes(info)
+		if err != nil {
+			fail(ErrUnknown, "%v", err)
+		}
+		if req.CPUs > 0 && req.CPUs != info.VCPUs {
+			fail(ErrConflict, "instance type %s has %d vCPUs, the machine sets %d", info.Name, info.VCPUs, req.CPUs)
+		}
+		if req.Memory > 0 && err == nil && req.Memory != memory {
+			fail(ErrConflict, "instance type %s has %s memory, the machine sets %s", info.Name, FormatMemory(memory), FormatMemory(req.Memory))
+		}
+		if req.GPU && !info.GPU {
+			fail(ErrNoGPU, "instance type %s has no GPU", info.Name)
+		}
+		res.CPUs, res.Memory, res.GPU = info.VCPUs, memory, req.GPU || info.GPU
+	case len(types) > 0:
+		info := Suggest(req, mp)
+		if info == nil {
+			fail(ErrNoFit, "MachineProvider %s has no instance type with %s", mp.Name, describe(req))
+			break
+		}
+		memory, _ := MemoryBytes(info)
+		res.InstanceType, res.Source, res.Info = info.Name, SourceSuggested, info
+		res.CPUs, res.Memory, res.GPU = info.VCPUs, memory, req.GPU || info.GPU
+	}
+
+	compute := &mp.Spec.Compute
+	if compute.MaxCPUs > 0 && res.CPUs > compute.MaxCPUs {
+		fail(ErrExceedsLimit, "MachineProvider %s allows %d vCPUs per machine, the machine has %d", mp.Name, compute.MaxCPUs, res.CPUs)
+	}
+	if limit := MaxMemory(mp); limit > 0 && res.Memory > limit {
+		fail(ErrExceedsLimit, "MachineProvider %s allows %s memory per machine, the machine has %s", mp.Name, FormatMemory(limit), FormatMemory(res.Memory))
+	}
+	if res.GPU && !compute.GPUSupport && (res.Info == nil || !res.Info.GPU) {
+		fail(ErrNoGPU, "MachineProvider %s has no GPU support", mp.Name)
+	}
+	return res, errors.Join(errs...)
+}
+
+// Suggest returns the smallest available instance type of a provider with at
+// least the vCPUs and memory of the requirements and within the limits of the
+// provider, nil when none fits. Types with a GPU are only suggested when a
+// GPU is required.
+func Suggest(req Requirements, mp *v1alpha1.MachineProvider) *v1alpha1.InstanceTypeInfo {
+	maxCPUs, maxMemory := mp.Spec.Compute.MaxCPUs, MaxMemory(mp)
+	var fits []*v1alpha1.InstanceTypeInfo
+	for i := range mp.Spec.Capabilities.InstanceTypes {
+		info := &mp.Spec.Capabilities.InstanceTypes[i]
+		memory, err := MemoryBytes(info)
+		switch {
+		case err != nil, !available(mp, info.Name), info.GPU != req.GPU,
+			info.VCPUs < req.CPUs, memory < req.Memory,
+			maxCPUs > 0 && info.VCPUs > maxCPUs,
+			maxMemory > 0 && memory > maxMemory:
+			continue
+		}
+		fits = append(fits, info)
+	}
+	if len(fits) == 0 {
+		return nil
+	}
+	return slices.MinFunc(fits, func(a, b *v1alpha1.InstanceTypeInfo) int {
+		ma, _ := MemoryBytes(a)
+		mb, _ := MemoryBytes(b)
+		ca, errA := strconv.ParseFloat(a.CostPerHour, 64)
+		cb, errB := strconv.ParseFloat(b.CostPerHour, 64)
+		if errA != nil {
+			ca = math.Inf(1)
+		}
+		if errB != nil {
+			cb = math.Inf(1)
+		}
+		return cmp.Or(cmp.Compare(a.VCPUs, b.VCPUs), cmp.Compare(ma, mb), cmp.Compare(ca, cb), cmp.Compare(a.Name, b.Name))
+	})
+}
+
+// Find returns the instance type of a name, nil when types has none.
+func Find(types []v1alpha1.InstanceTypeInfo, name string) *v1alpha1.InstanceTypeInfo {
+	for i := range types {
+		if types[i].Name == name {
+			return &types[i]
+		}
+	}
+	return nil
+}
+
+// available tells whether an instance type is available now. Providers that
+// do not report availability have every type available.
+func available(mp *v1alpha1.MachineProvider, name string) bool {
+	types := mp.Status.AvailableResources.InstanceTypes
+	return len(types) == 0 || slices.Contains(types, name)
+}
+
+// MemoryBytes returns the memory of an instance type in bytes.
+func MemoryBytes(info *v1alpha1.InstanceTypeInfo) (int64, error) {
+	gb, err := strconv.ParseFloat(info.MemoryGB, 64)
+	if err != nil || gb < 0 || gb > math.MaxInt64/GiB {
+		return 0, fmt.Errorf("instance type %s has invalid memory %q", info.Name, info.MemoryGB)
+	}
+	return int64(math.Round(gb * GiB)), nil
+}
+
+// MaxMemory returns the memory limit per machine of a provider in bytes, 0
+// for none.
+func MaxMemory(mp *v1alpha1.MachineProvider) int64 {
+	return int64(mp.Spec.Compute.MaxMemoryGB) * GiB
+}
+
+// FormatMemory formats bytes as GiB, or as bytes when not a whole number of
+// GiB.
+func FormatMemory(bytes int64) string {
+	if bytes%GiB == 0 {
+		return strconv.FormatInt(bytes/GiB, 10) + "GiB"
+	}
+	return strconv.FormatInt(bytes, 10) + " bytes"
+}
+
+func describe(req Requirements) string {
+	var parts []string
+	if req.CPUs > 0 {
+		parts = append(parts, fmt.Sprintf("%d vCPUs", req.CPUs))
+	}
+	if req.Memory > 0 {
+		parts = append(parts, FormatMemory(req.Memory)+" memory")
+	}
+	if req.GPU {
+		parts = append(parts, "a GPU")
+	}
+	return strings.Join(parts, " and ")
+}
@@ -0,0 +1,79 @@
+package instancetype
+
+import (
+	"errors"
+	"testing"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newProvider() *v1alpha1.MachineProvider {
+	return &v1alpha1.MachineProvider{
+		ObjectMeta: metav1.ObjectMeta{Name: "onprem"},
+		Spec: v1alpha1.MachineProviderSpec{
+			Compute: v1alpha1.ProviderComputeConfig{MaxCPUs: 32, MaxMemoryGB: 64, DefaultInstanceType: "small"},
+			Capabilities: v1alpha1.ProviderCapabilities{InstanceTypes: []v1alpha1.InstanceTypeInfo{
+				{Name: "small", VCPUs: 2, MemoryGB: "4", CostPerHour: "0.05"},
+				{Name: "medium", VCPUs: 4, MemoryGB: "8", CostPerHour: "0.10"},
+				{Name: "medium-cheap", VCPUs: 4, MemoryGB: "8", CostPerHour: "0.08"},
+				{Name: "half", VCPUs: 1, MemoryGB: "0.5"},
+				{Name: "huge", VCPUs: 64, MemoryGB: "256"},
+				{Name: "gpu", VCPUs: 8, MemoryGB: "32", GPU: true},
+			}},
+		},
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        Requirements
+		wantType   string
+		wantSource Source
+		wantMemory int64
+		wantErr    error
+	}{
+		{name: "instance type", req: Requirements{InstanceType: "medium"}, wantType: "medium", wantSource: SourceSpec, wantMemory: 8 * GiB},
+		{name: "matching CPU and memory", req: Requirements{InstanceType: "medium", CPUs: 4, Memory: 8589934592}, wantType: "medium", wantSource: SourceSpec, wantMemory: 8 * GiB},
+		{name: "fractional GiB", req: Requirements{InstanceType: "half", Memory: 512 << 20}, wantType: "half", wantSource: SourceSpec, wantMemory: 512 << 20},
+		{name: "decimal gigabytes", req: Requirements{InstanceType: "medium", Memory: 8e9}, wantType: "medium", wantSource: SourceSpec, wantMemory: 8 * GiB, wantErr: ErrConflict},
+		{name: "one byte more", req: Requirements{InstanceType: "medium", Memory: 8*GiB + 1}, wantType: "medium", wantSource: SourceSpec, wantMemory: 8 * GiB, wantErr: ErrConflict},
+		{name: "CPU conflict", req: Requirements{InstanceType: "small", CPUs: 4}, wantType: "small", wantSource: SourceSpec, wantMemory: 4 * GiB, wantErr: ErrConflict},
+		{name: "default", wantType: "small", wantSource: SourceDefault, wantMemory: 4 * GiB},
+		{name: "suggested by memory", req: Requirements{Memory: 4*GiB + 1}, wantType: "medium-cheap", wantSource: SourceSuggested, wantMemory: 8 * GiB},
+		{name: "suggested exact fit", req: Requirements{CPUs: 2, Memory: 4 * GiB}, wantType: "small", wantSource: SourceSuggested, wantMemory: 4 * GiB},
+		{name: "suggested GPU", req: Requirements{CPUs: 2, GPU: true}, wantType: "gpu", wantSource: SourceSuggested, wantMemory: 32 * GiB},
+		{name: "no fit", req: Requirements{Memory: 65 * GiB}, wantSource: SourceSpec, wantMemory: 65 * GiB, wantErr: ErrNoFit},
+		{name: "unknown", req: Requirements{InstanceType: "tiny"}, wantType: "tiny", wantSource: SourceSpec, wantErr: ErrUnknown},
+		{name: "above the limits", req: Requirements{InstanceType: "huge"}, wantType: "huge", wantSource: SourceSpec, wantMemory: 256 * GiB, wantErr: ErrExceedsLimit},
+		{name: "GPU without GPU type", req: Requirements{InstanceType: "small", GPU: true}, wantType: "small", wantSource: SourceSpec, wantMemory: 4 * GiB, wantErr: ErrNoGPU},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Resolve(tt.req, newProvider())
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if res.InstanceType != tt.wantType || res.Source != tt.wantSource || res.Memory != tt.wantMemory {
+				t.Errorf("resolved %s from %s with %d bytes, want %s from %s with %d bytes",
+					res.InstanceType, res.Source, res.Memory, tt.wantType, tt.wantSource, tt.wantMemory)
+			}
+		})
+	}
+}
+
+func TestMachineRequirements(t *testing.T) {
+	spec := &v1alpha1.MachineSpec{InstanceType: "medium", CPU: v1alpha1.MachineCPU{Cores: 2, Sockets: 2, ThreadsPerCore: 2}, Memory: 16 * GiB}
+	if req := MachineRequirements(spec); req.CPUs != 8 || req.Memory != 16*GiB || req.InstanceType != "medium" {
+		t.Errorf("requirements = %+v, want medium with 8 vCPUs and 16GiB", req)
+	}
+}
+
+func TestFormatMemory(t *testing.T) {
+	for bytes, want := range map[int64]string{8 * GiB: "8GiB", 8e9: "8000000000 bytes", 0: "0GiB"} {
+		if got := FormatMemory(bytes); got != want {
+			t.Errorf("FormatMemory(%d) = %q, want %q", bytes, got, want)
+		}
+	}
+}
@@ -0,0 +1,404 @@
+// Package ipam allocates IPv4 and IPv6 addresses from IPPools. An Allocator
+// holds the state of one pool, is safe for concurrent use and never hands out
+// an address twice. A controller rebuilds the allocator from the pool and its
+// IPAddresses.
+package ipam
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+var (
+	// ErrExhausted is returned when a pool has no free address of the requested family.
+	ErrExhausted = errors.New("no free address")
+	// ErrConflict is returned when an address is already allocated to another owner.
+	ErrConflict = errors.New("address already allocated")
+	// ErrOutOfRange is returned for addresses outside the pool's CIDRs.
+	ErrOutOfRange = errors.New("address not in pool")
+	// ErrExcluded is returned for excluded, gateway, network or broadcast addresses.
+	ErrExcluded = errors.New("address excluded from allocation")
+	// ErrReserved is returned for addresses reserved for another owner.
+	ErrReserved = errors.New("address reserved")
+)
+
+type ownerKey struct {
+	owner, family string
+}
+
+// Allocator hands out addresses of one pool.
+type Allocator struct {
+	mu sync.Mutex
+
+	prefixes     []netip.Prefix
+	ranges       []addrRange // one per prefix
+	excluded     []addrRange // merged
+	gateways     map[string]netip.Addr
+	reserved     map[netip.Addr]string // address to owner, "" for infrastructure
+	reservedFor  map[ownerKey]netip.Addr
+	allocated    map[netip.Addr]string
+	allocatedFor map[ownerKey]netip.Addr
+}
+
+// Config describes the address space of an allocator.
+type Config struct {
+	// CIDRs to allocate from. They may not overlap.
+	CIDRs []string
+	// ExcludedRanges are CIDRs, ranges (a-b) or single addresses never allocated.
+	ExcludedRanges []string
+	// Gateways are never allocated and must be inside one of the CIDRs.
+	Gateways []string
+	// Reservations hold addresses back for an owner, or for infrastructure when
+	// the owner is empty.
+	Reservations []v1alpha1.IPPoolReservation
+}
+
+// New builds an allocator. The network address of every CIDR, and the
+// broadcast address of IPv4 CIDRs, are excluded except for /31, /32 and /127,
+// /128 networks.
+func New(cfg Config) (*Allocator, error) {
+	a := &Allocator{
+		gateways:     map[string]netip.Addr{},
+		reserved:     map[netip.Addr]string{},
+		reservedFor:  map[ownerKey]netip.Addr{},
+		allocated:    map[netip.Addr]string{},
+		allocatedFor: map[ownerKey]netip.Addr{},
+	}
+	if len(cfg.CIDRs) == 0 {
+		return nil, fmt.Errorf("no CIDRs")
+	}
+
+	var excluded []addrRange
+	for _, s := range cfg.CIDRs {
+		p, err := netip.ParsePrefix(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", s, err)
+		}
+		if p.Addr().Is4In6() || p.Addr().Zone() != "" {
+			return nil, fmt.Errorf("invalid CIDR %q: use plain IPv4 or IPv6 notation", s)
+		}
+		p = p.Masked()
+		r := prefixRange(p)
+		for i, other := range a.ranges {
+			if _, ok := intersect(r, other); ok {
+				return nil, fmt.Errorf("CIDR %s overlaps %s", p, a.prefixes[i])
+			}
+		}
+		a.prefixes = append(a.prefixes, p)
+		a.ranges = append(a.ranges, r)
+
+		hostBits := p.Addr().BitLen() - p.Bits()
+		if hostBits > 1 {
+			excluded = append(excluded, addrRange{r.first, r.first})
+			if p.Addr().Is4() {
+				excluded = append(excluded, addrRange{r.last, r.last})
+			}
+		}
+	}
+
+	for _, s := range cfg.ExcludedRanges {
+		r, err := parseRange(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid excluded range %q: %w", s, err)
+		}
+		excluded = append(excluded, r)
+	}
+
+	for _, s := range cfg.Gateways {
+		if s == "" {
+			continue
+		}
+		gw, err := parseAddr(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gateway %q: %w", s, err)
+		}
+		if a.prefixOf(gw) == nil {
+			return nil, fmt.Errorf("gateway %s is not inside any CIDR of the pool", gw)
+		}
+		if _, dup := a.gateways[familyOf(gw)]; dup {
+			return nil, fmt.Errorf("more than one %s gateway", familyOf(gw))
+		}
+		a.gateways[familyOf(gw)] = gw
+		excluded = append(excluded, addrRange{gw, gw})
+	}
+	a.excluded = mergeRanges(excluded)
+
+	for _, res := range cfg.Reservations {
+		addr, err := parseAddr(res.Address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reserved address %q: %w", res.Address, err)
+		}
+		if err := a.checkAllocatable(addr); err != nil {
+			return nil, fmt.Errorf("reservation %s: %w", addr, err)
+		}
+		if owner, dup := a.reserved[addr]; dup {
+			return nil, fmt.Errorf("address %s is reserved twice (for %q and %q)", addr, owner, res.ClaimName)
+		}
+		a.reserved[addr] = res.ClaimName
+		if res.ClaimName == "" {
+			continue
+		}
+		key := ownerKey{res.ClaimName, familyOf(addr)}
+		if other, dup := a.reservedFor[key]; dup {
+			return nil, fmt.Errorf("%q has two reserved %s addresses (%s and %s)", res.ClaimName, key.family, other, addr)
+		}
+		a.reservedFor[key] = addr
+	}
+	return a, nil
+}
+
+// Prefixes returns the CIDRs of the pool.
+func (a *Allocator) Prefixes() []netip.Prefix {
+	return append([]netip.Prefix(nil), a.prefixes...)
+}
+
+// Families returns the address families the pool serves, IPv4 first.
+func (a *Allocator) Families() []string {
+	var has4, has6 bool
+	for _, p := range a.prefixes {
+		has4 = has4 || p.Addr().Is4()
+		has6 = has6 || !p.Addr().Is4()
+	}
+	var families []string
+	if has4 {
+		families = append(families, v1alpha1.IPFamilyIPv4)
+	}
+	if has6 {
+		families = append(families, v1alpha1.IPFamilyIPv6)
+	}
+	return families
+}
+
+// Gateway returns the gateway of a family, if set.
+func (a *Allocator) Gateway(family string) (netip.Addr, bool) {
+	gw, ok := a.gateways[family]
+	return gw, ok
+}
+
+// PrefixOf returns the CIDR containing addr.
+func (a *Allocator) PrefixOf(addr netip.Addr) (netip.Prefix, bool) {
+	p := a.prefixOf(addr.Unmap())
+	if p == nil {
+		return netip.Prefix{}, false
+	}
+	return *p, true
+}
+
+// Allocate returns an address of the family for owner. An owner has at most
+// one address per family: repeated calls return the same address. An address
+// reserved for the owner is used first, otherwise the lowest free address.
+func (a *Allocator) Allocate(owner, family string) (netip.Addr, error) {
+	if owner == "" {
+		return netip.Addr{}, fmt.Errorf("empty owner")
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := ownerKey{owner, family}
+	if addr, ok := a.allocatedFor[key]; ok {
+		return addr, nil
+	}
+	if addr, ok := a.reservedFor[key]; ok {
+		if err := a.assign(owner, addr); err != nil {
+			return netip.Addr{}, err
+		}
+		return addr, nil
+	}
+
+	for i, p := range a.prefixes {
+		if familyOf(p.Addr()) != family {
+			continue
+		}
+		if addr, ok := a.firstFree(a.ranges[i]); ok {
+			a.allocated[addr] = owner
+			a.allocatedFor[key] = addr
+			return addr, nil
+		}
+	}
+	if len(a.rangesOf(family)) == 0 {
+		return netip.Addr{}, fmt.Errorf("%w: pool has no %s CIDR", ErrExhausted, family)
+	}
+	return netip.Addr{}, fmt.Errorf("%w: all %s addresses are allocated", ErrExhausted, family)
+}
+
+// AllocateAddress allocates a specific address to owner. It succeeds when the
+// owner already holds the address, and fails when the owner holds another
+// address of the same family.
+func (a *Allocator) AllocateAddress(owner string, addr netip.Addr) error {
+	if owner == "" {
+		return fmt.Errorf("empty owner")
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.assign(owner, addr.Unmap())
+}
+
+// Release frees all addresses of owner and returns them.
+func (a *Allocator) Release(owner string) []netip.Addr {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var released []netip.Addr
+	for _, family := range []string{v1alpha1.IPFamilyIPv4, v1alpha1.IPFamilyIPv6} {
+		key := ownerKey{owner, family}
+		if addr, ok := a.allocatedFor[key]; ok {
+			delete(a.allocatedFor, key)
+			delete(a.allocated, addr)
+			released = append(released, addr)
+		}
+	}
+	return released
+}
+
+// Owner returns the owner of an allocated address.
+func (a *Allocator) Owner(addr netip.Addr) (string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	owner, ok := a.allocated[addr.Unmap()]
+	return owner, ok
+}
+
+// Allocations returns all allocated addresses in order.
+func (a *Allocator) Allocations() []netip.Addr {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	addrs := make([]netip.Addr, 0, len(a.allocated))
+	for addr := range a.allocated {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].Less(addrs[j]) })
+	return addrs
+}
+
+// Usage counts the addresses of a family, or returns nil when the pool has no
+// CIDR of that family. Counts saturate at the int64 maximum.
+func (a *Allocator) Usage(family string) *v1alpha1.IPPoolUsage {
+	ranges := a.rangesOf(family)
+	if len(ranges) == 0 {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	total := new(big.Int)
+	for _, r := range ranges {
+		total.Add(total, r.size())
+		for _, ex := range a.excluded {
+			if in, ok := intersect(ex, r); ok {
+				total.Sub(total, in.size())
+			}
+		}
+	}
+	var used, held int64
+	for addr, owner := range a.reserved {
+		if familyOf(addr) != family {
+			continue
+		}
+		if owner == "" {
+			total.Sub(total, big.NewInt(1))
+		} else if a.allocated[addr] != owner {
+			held++
+		}
+	}
+	for addr := range a.allocated {
+		if familyOf(addr) == family {
+			used++
+		}
+	}
+
+	usage := &v1alpha1.IPPoolUsage{Total: math.MaxInt64, Used: used}
+	if total.IsInt64() {
+		usage.Total = total.Int64()
+	}
+	usage.Free = max(usage.Total-used-held, 0)
+	return usage
+}
+
+// assign records addr for owner. The caller holds the lock.
+func (a *Allocator) assign(owner string, addr netip.Addr) error {
+	if err := a.checkAllocatable(addr); err != nil {
+		return err
+	}
+	if reservedFor, ok := a.reserved[addr]; ok && reservedFor != owner {
+		if reservedFor == "" {
+			return fmt.Errorf("%w: %s is reserved for infrastructure", ErrReserved, addr)
+		}
+		return fmt.Errorf("%w: %s is reserved for %q", ErrReserved, addr, reservedFor)
+	}
+	if current, ok := a.allocated[addr]; ok {
+		if current == owner {
+			return nil
+		}
+		return fmt.Errorf("%w: %s is allocated to %q", ErrConflict, addr, current)
+	}
+	key := ownerKey{owner, familyOf(addr)}
+	if other, ok := a.allocatedFor[key]; ok {
+		return fmt.Errorf("%w: %q already holds %s", ErrConflict, owner, other)
+	}
+	a.allocated[addr] = owner
+	a.allocatedFor[key] = addr
+	return nil
+}
+
+func (a *Allocator) checkAllocatable(addr netip.Addr) error {
+	if a.prefixOf(addr) == nil {
+		return fmt.Errorf("%w: %s", ErrOutOfRange, addr)
+	}
+	if a.excludedRange(addr) != nil {
+		return fmt.Errorf("%w: %s", ErrExcluded, addr)
+	}
+	return nil
+}
+
+// firstFree returns the lowest free address in r. The caller holds the lock.
+func (a *Allocator) firstFree(r addrRange) (netip.Addr, bool) {
+	addr := r.first
+	for addr.IsValid() && r.contains(addr) {
+		if ex := a.excludedRange(addr); ex != nil {
+			addr = ex.last.Next()
+			continue
+		}
+		_, isReserved := a.reserved[addr]
+		_, isAllocated := a.allocated[addr]
+		if !isReserved && !isAllocated {
+			return addr, true
+		}
+		addr = addr.Next()
+	}
+	return netip.Addr{}, false
+}
+
+func (a *Allocator) excludedRange(addr netip.Addr) *addrRange {
+	i := sort.Search(len(a.excluded), func(i int) bool { return !a.excluded[i].last.Less(addr) })
+	if i < len(a.excluded) && a.excluded[i].contains(addr) {
+		return &a.excluded[i]
+	}
+	return nil
+}
+
+func (a *Allocator) prefixOf(addr netip.Addr) *netip.Prefix {
+	for i, p := range a.prefixes {
+		if p.Contains(addr) {
+			return &a.prefixes[i]
+		}
+	}
+	return nil
+}
+
+func (a *Allocator) rangesOf(family string) []addrRange {
+	var ranges []addrRange
+	for _, r := range a.ranges {
+		if familyOf(r.first) == family {
+			ranges = append(ranges, r)
+		}
+	}
+	return ranges
+}
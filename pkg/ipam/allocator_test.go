@@ -0,0 +1,222 @@
+package ipam
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"sync"
+	"testing"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+func mustNew(t *testing.T, cfg Config) *Allocator {
+	t.Helper()
+	a, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestAllocateSkipsUnallocatableAddresses(t *testing.T) {
+	// 10.0.0.0/29: .0 is the network, .7 the broadcast, .1 the gateway, .2
+	// and .3 are excluded, .4 is held for infrastructure and .5 for claim b.
+	a := mustNew(t, Config{
+		CIDRs:          []string{"10.0.0.0/29"},
+		ExcludedRanges: []string{"10.0.0.2-10.0.0.3"},
+		Gateways:       []string{"10.0.0.1"},
+		Reservations: []v1alpha1.IPPoolReservation{
+			{Address: "10.0.0.4"},
+			{Address: "10.0.0.5", ClaimName: "b"},
+		},
+	})
+
+	if got, want := *a.Usage(v1alpha1.IPFamilyIPv4), (v1alpha1.IPPoolUsage{Total: 2, Used: 0, Free: 1}); got != want {
+		t.Errorf("usage before allocation = %+v, want %+v", got, want)
+	}
+	for _, step := range []struct {
+		owner string
+		want  string
+	}{
+		{"a", "10.0.0.6"},
+		{"b", "10.0.0.5"},
+	} {
+		addr, err := a.Allocate(step.owner, v1alpha1.IPFamilyIPv4)
+		if err != nil {
+			t.Fatalf("allocate %s: %v", step.owner, err)
+		}
+		if addr.String() != step.want {
+			t.Errorf("allocate %s = %s, want %s", step.owner, addr, step.want)
+		}
+	}
+	if _, err := a.Allocate("c", v1alpha1.IPFamilyIPv4); !errors.Is(err, ErrExhausted) {
+		t.Errorf("allocate from a full pool: %v, want ErrExhausted", err)
+	}
+	if _, err := a.Allocate("c", v1alpha1.IPFamilyIPv6); !errors.Is(err, ErrExhausted) {
+		t.Errorf("allocate a missing family: %v, want ErrExhausted", err)
+	}
+	if got, want := *a.Usage(v1alpha1.IPFamilyIPv4), (v1alpha1.IPPoolUsage{Total: 2, Used: 2, Free: 0}); got != want {
+		t.Errorf("usage after allocation = %+v, want %+v", got, want)
+	}
+}
+
+func TestAllocatePointToPointNetworks(t *testing.T) {
+	a := mustNew(t, Config{CIDRs: []string{"10.0.0.0/31", "192.0.2.9/32", "2001:db8::/127"}})
+	var got []string
+	for i := range 3 {
+		addr, err := a.Allocate(fmt.Sprintf("owner-%d", i), v1alpha1.IPFamilyIPv4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, addr.String())
+	}
+	if want := fmt.Sprint([]string{"10.0.0.0", "10.0.0.1", "192.0.2.9"}); fmt.Sprint(got) != want {
+		t.Errorf("allocated %v, want %s", got, want)
+	}
+	if usage := a.Usage(v1alpha1.IPFamilyIPv6); usage.Total != 2 {
+		t.Errorf("IPv6 /127 total = %d, want 2", usage.Total)
+	}
+}
+
+func TestAllocateAddress(t *testing.T) {
+	a := mustNew(t, Config{
+		CIDRs:          []string{"10.0.0.0/24"},
+		ExcludedRanges: []string{"10.0.0.200/29"},
+		Gateways:       []string{"10.0.0.1"},
+		Reservations:   []v1alpha1.IPPoolReservation{{Address: "10.0.0.50", ClaimName: "db"}},
+	})
+	addr := netip.MustParseAddr("10.0.0.10")
+	if err := a.AllocateAddress("web", addr); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		owner string
+		addr  string
+		want  error
+	}{
+		{"same owner again", "web", "10.0.0.10", nil},
+		{"address held by another owner", "api", "10.0.0.10", ErrConflict},
+		{"second address of the same family", "web", "10.0.0.11", ErrConflict},
+		{"reserved for another claim", "api", "10.0.0.50", ErrReserved},
+		{"network address", "api", "10.0.0.0", ErrExcluded},
+		{"broadcast address", "api", "10.0.0.255", ErrExcluded},
+		{"gateway", "api", "10.0.0.1", ErrExcluded},
+		{"excluded range", "api", "10.0.0.203", ErrExcluded},
+		{"outside the pool", "api", "10.0.1.10", ErrOutOfRange},
+		{"reserved for this claim", "db", "10.0.0.50", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := a.AllocateAddress(tt.owner, netip.MustParseAddr(tt.addr))
+			if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if got, err := a.Allocate("web", v1alpha1.IPFamilyIPv4); err != nil || got != addr {
+		t.Errorf("allocate for an owner holding %s = %s, %v", addr, got, err)
+	}
+	if released := a.Release("web"); len(released) != 1 || released[0] != addr {
+		t.Errorf("released %v, want [%s]", released, addr)
+	}
+	if err := a.AllocateAddress("api", addr); err != nil {
+		t.Errorf("allocate a released address: %v", err)
+	}
+}
+
+func TestAllocateConcurrent(t *testing.T) {
+	// 254 allocatable addresses for 300 owners, each asking twice.
+	a := mustNew(t, Config{CIDRs: []string{"10.0.0.0/24"}})
+	const owners = 300
+
+	var wg sync.WaitGroup
+	results := make([][2]netip.Addr, owners)
+	errs := make([][2]error, owners)
+	for i := range owners {
+		for j := range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i][j], errs[i][j] = a.Allocate(fmt.Sprintf("owner-%d", i), v1alpha1.IPFamilyIPv4)
+			}()
+		}
+	}
+	wg.Wait()
+
+	seen := map[netip.Addr]int{}
+	exhausted := 0
+	for i := range owners {
+		if errs[i][0] != nil || errs[i][1] != nil {
+			if !errors.Is(errs[i][0], ErrExhausted) || !errors.Is(errs[i][1], ErrExhausted) {
+				t.Fatalf("owner-%d: %v, %v", i, errs[i][0], errs[i][1])
+			}
+			exhausted++
+			continue
+		}
+		if results[i][0] != results[i][1] {
+			t.Errorf("owner-%d got two addresses: %s and %s", i, results[i][0], results[i][1])
+		}
+		if other, dup := seen[results[i][0]]; dup {
+			t.Errorf("%s handed to owner-%d and owner-%d", results[i][0], other, i)
+		}
+		seen[results[i][0]] = i
+	}
+	if len(seen) != 254 || exhausted != owners-254 {
+		t.Errorf("%d owners got an address and %d none, want 254 and %d", len(seen), exhausted, owners-254)
+	}
+	if usage := a.Usage(v1alpha1.IPFamilyIPv4); usage.Used != 254 || usage.Free != 0 {
+		t.Errorf("usage = %+v, want 254 used and none free", usage)
+	}
+}
+
+func TestUsageLargeIPv6Pool(t *testing.T) {
+	a := mustNew(t, Config{
+		CIDRs:        []string{"2001:db8::/64", "10.0.0.0/30"},
+		Gateways:     []string{"2001:db8::1"},
+		Reservations: []v1alpha1.IPPoolReservation{{Address: "2001:db8::10", ClaimName: "db"}},
+	})
+	for _, owner := range []string{"a", "b", "c"} {
+		if _, err := a.Allocate(owner, v1alpha1.IPFamilyIPv6); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := *a.Usage(v1alpha1.IPFamilyIPv6)
+	want := v1alpha1.IPPoolUsage{Total: math.MaxInt64, Used: 3, Free: math.MaxInt64 - 3 - 1}
+	if got != want {
+		t.Errorf("IPv6 /64 usage = %+v, want %+v", got, want)
+	}
+
+	a = mustNew(t, Config{CIDRs: []string{"2001:db8::/120"}, Gateways: []string{"2001:db8::1"}})
+	if got, want := *a.Usage(v1alpha1.IPFamilyIPv6), (v1alpha1.IPPoolUsage{Total: 254, Free: 254}); got != want {
+		t.Errorf("IPv6 /120 usage = %+v, want %+v", got, want)
+	}
+	if a.Usage(v1alpha1.IPFamilyIPv4) != nil {
+		t.Error("usage of a missing family must be nil")
+	}
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"no CIDRs", Config{}},
+		{"overlapping CIDRs", Config{CIDRs: []string{"10.0.0.0/24", "10.0.0.128/25"}}},
+		{"gateway outside the pool", Config{CIDRs: []string{"10.0.0.0/24"}, Gateways: []string{"10.0.1.1"}}},
+		{"reserved gateway", Config{CIDRs: []string{"10.0.0.0/24"}, Gateways: []string{"10.0.0.1"}, Reservations: []v1alpha1.IPPoolReservation{{Address: "10.0.0.1"}}}},
+		{"address reserved twice", Config{CIDRs: []string{"10.0.0.0/24"}, Reservations: []v1alpha1.IPPoolReservation{{Address: "10.0.0.5"}, {Address: "10.0.0.5", ClaimName: "a"}}}},
+		{"two addresses for one claim", Config{CIDRs: []string{"10.0.0.0/24"}, Reservations: []v1alpha1.IPPoolReservation{{Address: "10.0.0.5", ClaimName: "a"}, {Address: "10.0.0.6", ClaimName: "a"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.cfg); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
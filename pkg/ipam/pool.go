@@ -0,0 +1,195 @@
+package ipam
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PoolCIDRs returns the CIDRs of a pool. When spec.cidrs is empty they are
+// taken from the IPv4 and IPv6 prefixes in the status of the referenced
+// NetworkNamespace; ns may be nil otherwise.
+func PoolCIDRs(pool *v1alpha1.IPPool, ns *v1alpha1.NetworkNamespace) ([]string, error) {
+	if len(pool.Spec.CIDRs) > 0 {
+		return pool.Spec.CIDRs, nil
+	}
+	ref := pool.Spec.NetworkNamespaceRef
+	if ref == nil {
+		return nil, fmt.Errorf("IPPool %s/%s has neither cidrs nor a networkNamespaceRef", pool.Namespace, pool.Name)
+	}
+	if ns == nil || ns.Name != ref.Name || ns.Namespace != pool.Namespace {
+		return nil, fmt.Errorf("NetworkNamespace %s/%s referenced by IPPool %s was not given", pool.Namespace, ref.Name, pool.Name)
+	}
+	var cidrs []string
+	for _, prefix := range []string{ns.Status.IPv4Prefix, ns.Status.IPv6Prefix} {
+		if prefix != "" {
+			cidrs = append(cidrs, prefix)
+		}
+	}
+	if len(cidrs) == 0 {
+		return nil, fmt.Errorf("NetworkNamespace %s/%s has no prefixes yet", ns.Namespace, ns.Name)
+	}
+	return cidrs, nil
+}
+
+// NewForPool builds the allocator of a pool and records the existing
+// IPAddresses allocated from it. ns is only needed when the pool takes its
+// CIDRs from a NetworkNamespace.
+//
+// Addresses that conflict with each other or with the pool are reported in
+// the returned error, which joins one error per IPAddress. The allocator is
+// still returned in that case, with the first (oldest) allocation of every
+// address recorded.
+func NewForPool(pool *v1alpha1.IPPool, ns *v1alpha1.NetworkNamespace, addresses []v1alpha1.IPAddress) (*Allocator, error) {
+	cidrs, err := PoolCIDRs(pool, ns)
+	if err != nil {
+		return nil, err
+	}
+	a, err := New(Config{
+		CIDRs:          cidrs,
+		ExcludedRanges: pool.Spec.ExcludedRanges,
+		Gateways:       []string{pool.Spec.IPv4Gateway, pool.Spec.IPv6Gateway},
+		Reservations:   pool.Spec.Reservations,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("IPPool %s/%s: %w", pool.Namespace, pool.Name, err)
+	}
+
+	var owned []*v1alpha1.IPAddress
+	for i := range addresses {
+		ip := &addresses[i]
+		if ip.Namespace == pool.Namespace && ip.Spec.PoolRef.Name == pool.Name && ip.DeletionTimestamp == nil {
+			owned = append(owned, ip)
+		}
+	}
+	sort.SliceStable(owned, func(i, j int) bool {
+		ti, tj := owned[i].CreationTimestamp, owned[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return owned[i].Name < owned[j].Name
+	})
+
+	var errs []error
+	for _, ip := range owned {
+		addr, err := parseAddr(ip.Spec.Address)
+		if err == nil {
+			err = a.AllocateAddress(ip.Spec.ClaimRef.Name, addr)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("IPAddress %s: %w", ip.Name, err))
+		}
+	}
+	return a, errors.Join(errs...)
+}
+
+// ClaimFamily returns the family requested by a claim, applying the API default.
+func ClaimFamily(claim *v1alpha1.IPAddressClaim) string {
+	if claim.Spec.RequestedAddress != "" {
+		if addr, err := parseAddr(claim.Spec.RequestedAddress); err == nil {
+			return familyOf(addr)
+		}
+	}
+	if claim.Spec.Family == "" {
+		return v1alpha1.IPFamilyIPv4
+	}
+	return claim.Spec.Family
+}
+
+// Claim allocates the address requested by a claim: the requested address
+// when set, otherwise the next free address of the claim's family.
+func (a *Allocator) Claim(claim *v1alpha1.IPAddressClaim) (netip.Addr, error) {
+	if claim.Spec.RequestedAddress == "" {
+		return a.Allocate(claim.Name, ClaimFamily(claim))
+	}
+	addr, err := parseAddr(claim.Spec.RequestedAddress)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("invalid requested address: %w", err)
+	}
+	if claim.Spec.Family != "" && familyOf(addr) != claim.Spec.Family {
+		return netip.Addr{}, fmt.Errorf("requested address %s is not %s", addr, claim.Spec.Family)
+	}
+	if err := a.AllocateAddress(claim.Name, addr); err != nil {
+		return netip.Addr{}, err
+	}
+	return addr, nil
+}
+
+// AddressName returns the name of the IPAddress object for an address of a
+// pool. The name is unique per address, so creating a second IPAddress for
+// the same address fails on the API server even when two allocators race.
+func AddressName(poolName string, addr netip.Addr) string {
+	addr = addr.Unmap()
+	s := addr.String()
+	if addr.Is6() {
+		s = addr.StringExpanded()
+	}
+	return poolName + "-" + strings.NewReplacer(".", "-", ":", "-").Replace(s)
+}
+
+// NewIPAddress builds the IPAddress object recording that addr was allocated
+// to claim. It is owned by the claim, so deleting the claim releases the address.
+func NewIPAddress(pool *v1alpha1.IPPool, claim *v1alpha1.IPAddressClaim, a *Allocator, addr netip.Addr) (*v1alpha1.IPAddress, error) {
+	prefix, ok := a.PrefixOf(addr)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrOutOfRange, addr)
+	}
+	if owner, ok := a.Owner(addr); !ok || owner != claim.Name {
+		return nil, fmt.Errorf("address %s is not allocated to %q", addr, claim.Name)
+	}
+
+	ip := &v1alpha1.IPAddress{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "IPAddress",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            AddressName(pool.Name, addr),
+			Namespace:       pool.Namespace,
+			Labels:          map[string]string{v1alpha1.IPPoolNameLabel: pool.Name},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(claim, v1alpha1.GroupVersion.WithKind("IPAddressClaim"))},
+		},
+		Spec: v1alpha1.IPAddressSpec{
+			Address:  addr.Unmap().String(),
+			Prefix:   int32(prefix.Bits()),
+			PoolRef:  v1alpha1.IPPoolReference{Name: pool.Name},
+			ClaimRef: v1alpha1.IPAddressClaimReference{Name: claim.Name},
+		},
+	}
+	if gw, ok := a.Gateway(familyOf(addr.Unmap())); ok && prefix.Contains(gw) {
+		ip.Spec.Gateway = gw.String()
+	}
+	return ip, nil
+}
+
+// PoolStatus fills the CIDRs and usage of a pool status from its allocator.
+func PoolStatus(pool *v1alpha1.IPPool, a *Allocator) v1alpha1.IPPoolStatus {
+	status := *pool.Status.DeepCopy()
+	status.ObservedGeneration = pool.Generation
+	status.CIDRs = nil
+	for _, p := range a.Prefixes() {
+		status.CIDRs = append(status.CIDRs, p.String())
+	}
+	status.IPv4 = a.Usage(v1alpha1.IPFamilyIPv4)
+	status.IPv6 = a.Usage(v1alpha1.IPFamilyIPv6)
+
+	status.Phase = v1alpha1.IPPoolPhaseReady
+	status.Message = ""
+	var exhausted []string
+	for _, family := range a.Families() {
+		usage := a.Usage(family)
+		if usage.Free == 0 {
+			exhausted = append(exhausted, family)
+		}
+	}
+	if len(exhausted) > 0 {
+		status.Phase = v1alpha1.IPPoolPhaseExhausted
+		status.Message = fmt.Sprintf("no free %s addresses", strings.Join(exhausted, " or "))
+	}
+	return status
+}
@@ -0,0 +1,90 @@
+package ipam
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newIPAddress(name, claim, addr string, age time.Duration) v1alpha1.IPAddress {
+	return v1alpha1.IPAddress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Add(-age)),
+		},
+		Spec: v1alpha1.IPAddressSpec{
+			Address:  addr,
+			PoolRef:  v1alpha1.IPPoolReference{Name: "pool"},
+			ClaimRef: v1alpha1.IPAddressClaimReference{Name: claim},
+		},
+	}
+}
+
+func TestNewForPoolReportsDoubleAllocation(t *testing.T) {
+	pool := &v1alpha1.IPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"},
+		Spec:       v1alpha1.IPPoolSpec{CIDRs: []string{"10.0.0.0/29"}, IPv4Gateway: "10.0.0.1"},
+	}
+	addresses := []v1alpha1.IPAddress{
+		newIPAddress("newer", "b", "10.0.0.2", time.Hour),
+		newIPAddress("older", "a", "10.0.0.2", 2*time.Hour),
+		newIPAddress("gateway", "c", "10.0.0.1", time.Hour),
+		newIPAddress("fine", "d", "10.0.0.3", time.Hour),
+	}
+	other := newIPAddress("other-pool", "e", "10.0.0.2", 3*time.Hour)
+	other.Spec.PoolRef.Name = "other"
+	addresses = append(addresses, other)
+
+	a, err := NewForPool(pool, nil, addresses)
+	if a == nil {
+		t.Fatalf("no allocator: %v", err)
+	}
+	if !errors.Is(err, ErrConflict) || !errors.Is(err, ErrExcluded) {
+		t.Errorf("error = %v, want the conflict and the gateway reported", err)
+	}
+	if owner, _ := a.Owner(netip.MustParseAddr("10.0.0.2")); owner != "a" {
+		t.Errorf("10.0.0.2 owned by %q, want the oldest claim a", owner)
+	}
+	if owner, _ := a.Owner(netip.MustParseAddr("10.0.0.3")); owner != "d" {
+		t.Errorf("10.0.0.3 owned by %q, want d", owner)
+	}
+
+	status := PoolStatus(pool, a)
+	if status.IPv4 == nil || status.IPv4.Total != 5 || status.IPv4.Used != 2 || status.IPv4.Free != 3 {
+		t.Errorf("usage = %+v, want 5 total, 2 used, 3 free", status.IPv4)
+	}
+	if status.Phase != v1alpha1.IPPoolPhaseReady {
+		t.Errorf("phase = %s, want %s", status.Phase, v1alpha1.IPPoolPhaseReady)
+	}
+}
+
+func TestClaim(t *testing.T) {
+	a := mustNew(t, Config{CIDRs: []string{"10.0.0.0/29", "2001:db8::/64"}})
+	claim := func(name, family, requested string) *v1alpha1.IPAddressClaim {
+		return &v1alpha1.IPAddressClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1alpha1.IPAddressClaimSpec{Family: family, RequestedAddress: requested},
+		}
+	}
+
+	if addr, err := a.Claim(claim("v4", "", "")); err != nil || addr.String() != "10.0.0.1" {
+		t.Errorf("default family claim = %s, %v, want 10.0.0.1", addr, err)
+	}
+	if addr, err := a.Claim(claim("v6", v1alpha1.IPFamilyIPv6, "")); err != nil || addr.String() != "2001:db8::1" {
+		t.Errorf("IPv6 claim = %s, %v, want 2001:db8::1", addr, err)
+	}
+	if addr, err := a.Claim(claim("static", "", "10.0.0.5")); err != nil || addr.String() != "10.0.0.5" {
+		t.Errorf("requested address claim = %s, %v, want 10.0.0.5", addr, err)
+	}
+	if _, err := a.Claim(claim("mismatch", v1alpha1.IPFamilyIPv6, "10.0.0.6")); err == nil {
+		t.Error("a requested address of the wrong family must be rejected")
+	}
+	if _, err := a.Claim(claim("taken", "", "10.0.0.5")); !errors.Is(err, ErrConflict) {
+		t.Errorf("claim of a taken address: %v, want ErrConflict", err)
+	}
+}
@@ -0,0 +1,129 @@
+package ipam
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"sort"
+	"strings"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+// addrRange is an inclusive range of addresses of one family.
+type addrRange struct {
+	first, last netip.Addr
+}
+
+func (r addrRange) contains(a netip.Addr) bool {
+	return r.first.Compare(a) <= 0 && a.Compare(r.last) <= 0
+}
+
+func (r addrRange) size() *big.Int {
+	n := new(big.Int).Sub(addrInt(r.last), addrInt(r.first))
+	return n.Add(n, big.NewInt(1))
+}
+
+// parseRange parses a CIDR (10.0.0.0/28), a range (10.0.0.1-10.0.0.9) or a
+// single address into an inclusive range.
+func parseRange(s string) (addrRange, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return addrRange{}, err
+		}
+		return prefixRange(p), nil
+	}
+	if from, to, ok := strings.Cut(s, "-"); ok {
+		first, err := parseAddr(from)
+		if err != nil {
+			return addrRange{}, err
+		}
+		last, err := parseAddr(to)
+		if err != nil {
+			return addrRange{}, err
+		}
+		if first.Is4() != last.Is4() {
+			return addrRange{}, fmt.Errorf("range %q mixes IPv4 and IPv6", s)
+		}
+		if last.Less(first) {
+			return addrRange{}, fmt.Errorf("range %q ends before it starts", s)
+		}
+		return addrRange{first, last}, nil
+	}
+	a, err := parseAddr(s)
+	if err != nil {
+		return addrRange{}, err
+	}
+	return addrRange{a, a}, nil
+}
+
+func parseAddr(s string) (netip.Addr, error) {
+	a, err := netip.ParseAddr(strings.TrimSpace(s))
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	if a.Zone() != "" {
+		return netip.Addr{}, fmt.Errorf("address %q has a zone", s)
+	}
+	return a.Unmap(), nil
+}
+
+func prefixRange(p netip.Prefix) addrRange {
+	p = p.Masked()
+	first := p.Addr()
+	b := first.AsSlice()
+	for i := p.Bits(); i < first.BitLen(); i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	last, _ := netip.AddrFromSlice(b)
+	return addrRange{first, last}
+}
+
+// mergeRanges sorts ranges and joins the ones that overlap or touch.
+func mergeRanges(ranges []addrRange) []addrRange {
+	if len(ranges) == 0 {
+		return nil
+	}
+	sorted := append([]addrRange(nil), ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].first.Less(sorted[j].first) })
+	merged := []addrRange{sorted[0]}
+	for _, r := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if last.first.Is4() == r.first.Is4() && (r.first.Compare(last.last) <= 0 || last.last.Next() == r.first) {
+			if last.last.Less(r.last) {
+				last.last = r.last
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// intersect returns the part of r inside p, if any.
+func intersect(r, p addrRange) (addrRange, bool) {
+	if r.first.Is4() != p.first.Is4() || r.last.Less(p.first) || p.last.Less(r.first) {
+		return addrRange{}, false
+	}
+	out := r
+	if out.first.Less(p.first) {
+		out.first = p.first
+	}
+	if p.last.Less(out.last) {
+		out.last = p.last
+	}
+	return out, true
+}
+
+func addrInt(a netip.Addr) *big.Int {
+	return new(big.Int).SetBytes(a.AsSlice())
+}
+
+func familyOf(a netip.Addr) string {
+	if a.Is4() {
+		return v1alpha1.IPFamilyIPv4
+	}
+	return v1alpha1.IPFamilyIPv6
+}
@@ -0,0 +1,390 @@
+// Package lbconfig renders a LoadBalancer into the configuration of an
+// HAProxy and keepalived appliance: haproxy.cfg for TCP and HTTP listeners,
+// and keepalived.conf for the VRRP VIPs and UDP listeners. The output only
+// depends on its input, so a changed hash means a changed configuration.
+package lbconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vitistack/crds/pkg/loadbalancer"
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+// DefaultCertDir is where the appliance keeps certificates and crt-lists.
+const DefaultCertDir = "/etc/haproxy/certs"
+
+// HAProxyOptions tune the rendered HAProxy configuration.
+type HAProxyOptions struct {
+	// CertDir holds one <secret name>.pem file per TLS Secret, the
+	// certificate chain followed by the key. Defaults to DefaultCertDir.
+	CertDir string
+	// MaxConn is the global connection limit, 0 leaves the HAProxy default.
+	MaxConn int
+}
+
+// HAProxyConfig is a rendered HAProxy configuration.
+type HAProxyConfig struct {
+	// Config is the content of haproxy.cfg.
+	Config string
+	// CrtLists maps the path of each crt-list file to its content.
+	CrtLists map[string]string
+	// Certificates maps the path of each PEM file the configuration loads to
+	// the Secret it is built from.
+	Certificates map[string]string
+}
+
+// Hash returns a SHA-256 over the configuration and crt-lists.
+func (c *HAProxyConfig) Hash() string {
+	parts := []string{c.Config}
+	for _, p := range sortedKeys(c.CrtLists) {
+		parts = append(parts, p, c.CrtLists[p])
+	}
+	return Hash(parts...)
+}
+
+// Hash returns the hex SHA-256 of the parts, each prefixed by its length.
+func Hash(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		fmt.Fprintf(h, "%d:%s", len(p), p)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// RenderHAProxy renders the TCP, HTTP and HTTPS listeners of a load balancer.
+// The frontends bind to the VIPs in status.loadBalancerIps and the backends
+// forward to the resolved pool members, see loadbalancer.ResolveMembers. UDP
+// listeners are left to keepalived.
+//
+// The methods map to HAProxy as follows:
+//
+//   - round-robin: balance roundrobin, all members weigh the same
+//   - weighted-round-robin: balance roundrobin with member weights
+//   - least-session: balance leastconn with member weights
+//   - source-hash: balance source with consistent hashing
+//   - first-alive: the first member takes all traffic, the others are
+//     backups used one at a time in order
+//
+// For the other methods, backup members share the traffic when every
+// regular member is down.
+func RenderHAProxy(lb *v1alpha1.LoadBalancer, backends []loadbalancer.Backend, opts HAProxyOptions) (*HAProxyConfig, error) {
+	if opts.CertDir == "" {
+		opts.CertDir = DefaultCertDir
+	}
+	vips, err := vips(lb)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadbalancer.Validate(lb); err != nil {
+		return nil, err
+	}
+	if len(lb.Spec.Listeners) == 0 {
+		return nil, errors.New("load balancer has no listeners")
+	}
+
+	out := &HAProxyConfig{CrtLists: map[string]string{}, Certificates: map[string]string{}}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated from LoadBalancer %s/%s, do not edit.\n", lb.Namespace, lb.Name)
+	b.WriteString("global\n")
+	b.WriteString("    log stdout format raw local0\n")
+	if opts.MaxConn > 0 {
+		fmt.Fprintf(&b, "    maxconn %d\n", opts.MaxConn)
+	}
+	b.WriteString("\ndefaults\n")
+	b.WriteString("    log global\n")
+	b.WriteString("    option dontlognull\n")
+	fmt.Fprintf(&b, "    timeout connect %s\n", duration(loadbalancer.DefaultConnectTimeout))
+	fmt.Fprintf(&b, "    timeout client %s\n", duration(loadbalancer.DefaultClientTimeout))
+	fmt.Fprintf(&b, "    timeout server %s\n", duration(loadbalancer.DefaultServerTimeout))
+
+	for _, l := range sortedListeners(lb) {
+		if loadbalancer.Protocol(l) == v1alpha1.LoadBalancerProtocolUDP {
+			continue
+		}
+		name := sanitize(l.Name)
+		mode := "tcp"
+		if p := loadbalancer.Protocol(l); p == v1alpha1.LoadBalancerProtocolHTTP ||
+			p == v1alpha1.LoadBalancerProtocolHTTPS && l.TLS != nil {
+			mode = "http"
+		}
+		t := loadbalancer.Timeouts(lb, l)
+
+		fmt.Fprintf(&b, "\nfrontend fe_%s\n", name)
+		fmt.Fprintf(&b, "    mode %s\n", mode)
+		bindTLS := bindTLSOptions(l, opts.CertDir, out)
+		for _, vip := range vips {
+			fmt.Fprintf(&b, "    bind %s%s\n", net.JoinHostPort(vip, strconv.Itoa(int(l.Port))), bindTLS)
+		}
+		fmt.Fprintf(&b, "    timeout client %s\n", duration(t.Client.Duration))
+		if mode == "http" {
+			b.WriteString("    option forwardfor\n")
+		}
+		fmt.Fprintf(&b, "    default_backend be_%s\n", name)
+
+		fmt.Fprintf(&b, "\nbackend be_%s\n", name)
+		fmt.Fprintf(&b, "    mode %s\n", mode)
+		writeBalance(&b, lb, backends)
+		fmt.Fprintf(&b, "    timeout connect %s\n", duration(t.Connect.Duration))
+		fmt.Fprintf(&b, "    timeout server %s\n", duration(t.Server.Duration))
+		fmt.Fprintf(&b, "    timeout tunnel %s\n", duration(t.Tunnel.Duration))
+		hc := loadbalancer.HealthCheck(lb, l)
+		check := writeHealthCheck(&b, &hc)
+		for _, s := range servers(lb, backends, l) {
+			b.WriteString("    server " + s.name + " " + net.JoinHostPort(s.address, strconv.Itoa(int(s.port))))
+			if check != "" {
+				b.WriteString(check)
+				if p := checkPort(&hc, l, s); p != s.port {
+					fmt.Fprintf(&b, " port %d", p)
+				}
+			}
+			if s.weight >= 0 {
+				fmt.Fprintf(&b, " weight %d", s.weight)
+			}
+			if s.backup {
+				b.WriteString(" backup")
+			}
+			if s.disabled {
+				b.WriteString(" disabled")
+			}
+			b.WriteString("\n")
+		}
+	}
+	out.Config = b.String()
+	return out, nil
+}
+
+// bindTLSOptions returns the TLS options of a bind line and records the
+// crt-list and certificates of the listener.
+func bindTLSOptions(l *v1alpha1.LoadBalancerListener, certDir string, out *HAProxyConfig) string {
+	if l.TLS == nil {
+		return ""
+	}
+	var opts strings.Builder
+	var crtList strings.Builder
+	for _, cert := range loadbalancer.TLSCertificates(l) {
+		pem := path.Join(certDir, cert.SecretName+".pem")
+		out.Certificates[pem] = cert.SecretName
+		if cert.Default {
+			fmt.Fprintf(&opts, " ssl crt %s", pem)
+			continue
+		}
+		hosts := make([]string, len(cert.Hostnames))
+		for i, h := range cert.Hostnames {
+			hosts[i] = strings.ToLower(h)
+		}
+		sort.Strings(hosts)
+		fmt.Fprintf(&crtList, "%s %s\n", pem, strings.Join(hosts, " "))
+	}
+	if crtList.Len() > 0 {
+		list := path.Join(certDir, sanitize(l.Name)+".crtlist")
+		out.CrtLists[list] = crtList.String()
+		fmt.Fprintf(&opts, " crt-list %s", list)
+	}
+	fmt.Fprintf(&opts, " ssl-min-ver TLSv%s", loadbalancer.MinTLSVersion(l.TLS))
+	if ciphers := loadbalancer.CipherSuites(loadbalancer.CipherPolicy(l.TLS)); len(ciphers) > 0 {
+		fmt.Fprintf(&opts, " ciphers %s", strings.Join(ciphers, ":"))
+	}
+	if loadbalancer.Protocol(l) == v1alpha1.LoadBalancerProtocolHTTPS {
+		opts.WriteString(" alpn h2,http/1.1")
+	}
+	return opts.String()
+}
+
+func writeBalance(b *strings.Builder, lb *v1alpha1.LoadBalancer, backends []loadbalancer.Backend) {
+	switch method(lb) {
+	case v1alpha1.LoadBalancerMethodLeastSession:
+		b.WriteString("    balance leastconn\n")
+	case v1alpha1.LoadBalancerMethodSourceHash:
+		b.WriteString("    balance source\n")
+		b.WriteString("    hash-type consistent\n")
+	case v1alpha1.LoadBalancerMethodFirstAlive:
+		// The first operational backup takes all traffic; no allbackups.
+		b.WriteString("    balance first\n")
+		return
+	default:
+		b.WriteString("    balance roundrobin\n")
+	}
+	backups := 0
+	for i := range backends {
+		if backends[i].Backup {
+			backups++
+		}
+	}
+	if backups > 1 {
+		b.WriteString("    option allbackups\n")
+	}
+}
+
+// writeHealthCheck writes the backend health check options and returns the
+// options for each server line, empty when members are not checked.
+func writeHealthCheck(b *strings.Builder, hc *v1alpha1.LoadBalancerHealthCheck) string {
+	if hc.Type == v1alpha1.LoadBalancerHealthCheckNone {
+		return ""
+	}
+	fmt.Fprintf(b, "    timeout check %s\n", duration(hc.Timeout.Duration))
+	check := fmt.Sprintf(" check inter %s rise %d fall %d", duration(hc.Interval.Duration), hc.HealthyThreshold, hc.UnhealthyThreshold)
+	switch hc.Type {
+	case v1alpha1.LoadBalancerHealthCheckHTTP, v1alpha1.LoadBalancerHealthCheckHTTPS:
+		b.WriteString("    option httpchk\n")
+		fmt.Fprintf(b, "    http-check send meth GET uri %s\n", hc.Path)
+		fmt.Fprintf(b, "    http-check expect status %s\n", hc.ExpectedStatus)
+		if hc.Type == v1alpha1.LoadBalancerHealthCheckHTTPS {
+			check += " check-ssl verify none"
+		}
+	}
+	return check
+}
+
+type server struct {
+	name     string
+	address  string
+	port     int32
+	member   *v1alpha1.LoadBalancerPoolMember
+	weight   int32 // -1 leaves the HAProxy default
+	backup   bool
+	disabled bool
+}
+
+// servers returns the server lines of a listener backend in order. For
+// first-alive the first regular member is the primary and every other member
+// a backup, so HAProxy fails over in member order. For the other methods the
+// order of the members does not matter, so servers are sorted by name and
+// reordering members changes neither the configuration nor the source hash.
+func servers(lb *v1alpha1.LoadBalancer, backends []loadbalancer.Backend, l *v1alpha1.LoadBalancerListener) []server {
+	var regular, backups []server
+	for i := range backends {
+		m := &backends[i].LoadBalancerPoolMember
+		s := server{
+			address: backends[i].Address,
+			port:    loadbalancer.MemberPort(m, l),
+			member:  m,
+			weight:  loadbalancer.Weight(m),
+			backup:  m.Backup,
+		}
+		s.name = backends[i].Machine
+		if s.name == "" {
+			s.name = backends[i].Address
+		}
+		if m.Port != 0 {
+			s.name += "_" + strconv.Itoa(int(m.Port))
+		}
+		s.name = sanitize(s.name)
+		switch loadbalancer.AdminState(m) {
+		case v1alpha1.LoadBalancerMemberDrain:
+			s.weight = 0
+		case v1alpha1.LoadBalancerMemberDisabled:
+			s.disabled = true
+		}
+		if method(lb) == v1alpha1.LoadBalancerMethodRoundRobin && s.weight != 0 {
+			s.weight = -1
+		}
+		if s.backup {
+			backups = append(backups, s)
+		} else {
+			regular = append(regular, s)
+		}
+	}
+	if method(lb) != v1alpha1.LoadBalancerMethodFirstAlive {
+		for _, list := range [][]server{regular, backups} {
+			sort.SliceStable(list, func(i, j int) bool { return list[i].name < list[j].name })
+		}
+		return append(regular, backups...)
+	}
+	for i := range regular {
+		regular[i].backup = i > 0
+		if regular[i].weight != 0 {
+			regular[i].weight = -1
+		}
+	}
+	for i := range backups {
+		if backups[i].weight != 0 {
+			backups[i].weight = -1
+		}
+	}
+	return append(regular, backups...)
+}
+
+// checkPort returns the port probed on a server. A member port replaces the
+// backend port, unless the health check sets its own.
+func checkPort(hc *v1alpha1.LoadBalancerHealthCheck, l *v1alpha1.LoadBalancerListener, s server) int32 {
+	if s.member.Port != 0 && hc.Port == loadbalancer.BackendPort(l) {
+		return s.port
+	}
+	return hc.Port
+}
+
+func method(lb *v1alpha1.LoadBalancer) string {
+	if lb.Spec.Method == "" {
+		return v1alpha1.LoadBalancerMethodFirstAlive
+	}
+	return lb.Spec.Method
+}
+
+// vips returns the VIPs of the load balancer, sorted.
+func vips(lb *v1alpha1.LoadBalancer) ([]string, error) {
+	if len(lb.Status.LoadBalancerIps) == 0 {
+		return nil, errors.New("load balancer has no VIPs in status.loadBalancerIps")
+	}
+	vips := make([]string, 0, len(lb.Status.LoadBalancerIps))
+	for _, vip := range lb.Status.LoadBalancerIps {
+		ip := net.ParseIP(vip)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid VIP %q", vip)
+		}
+		vips = append(vips, ip.String())
+	}
+	sort.Strings(vips)
+	return vips, nil
+}
+
+func sortedListeners(lb *v1alpha1.LoadBalancer) []*v1alpha1.LoadBalancerListener {
+	listeners := make([]*v1alpha1.LoadBalancerListener, len(lb.Spec.Listeners))
+	for i := range lb.Spec.Listeners {
+		listeners[i] = &lb.Spec.Listeners[i]
+	}
+	sort.SliceStable(listeners, func(i, j int) bool { return listeners[i].Name < listeners[j].Name })
+	return listeners
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sanitize keeps the characters HAProxy allows in names.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == ':':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// duration formats a duration in the largest HAProxy unit that keeps it whole.
+func duration(d time.Duration) string {
+	for _, u := range []struct {
+		unit   time.Duration
+		suffix string
+	}{{time.Hour, "h"}, {time.Minute, "m"}, {time.Second, "s"}} {
+		if d%u.unit == 0 {
+			return strconv.FormatInt(int64(d/u.unit), 10) + u.suffix
+		}
+	}
+	return strconv.FormatInt(d.Milliseconds(), 10) + "ms"
+}
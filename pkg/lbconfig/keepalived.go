@@ -0,0 +1,245 @@
+package lbconfig
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/vitistack/crds/pkg/loadbalancer"
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+// DefaultCheckScript is the VRRP track script. A node whose HAProxy is not
+// running gives up the VIPs.
+const DefaultCheckScript = "/usr/bin/pidof haproxy"
+
+// KeepalivedOptions are the node specific settings of keepalived. Every node
+// of an appliance pair renders the same load balancer with its own options.
+type KeepalivedOptions struct {
+	// Interface the VIPs are added to, e.g. "eth0". Required.
+	Interface string
+	// VirtualRouterID is the VRRP router id, 1-255, the same on every node and
+	// unique on the network segment. Defaults to a hash of the load balancer
+	// namespace and name; set it where load balancers share a segment.
+	VirtualRouterID int
+	// Priority of this node, 1-254. The highest priority node holds the VIPs.
+	// Defaults to 100.
+	Priority int
+	// UnicastSourceIP and UnicastPeers switch VRRP from multicast to unicast.
+	UnicastSourceIP string
+	UnicastPeers    []string
+	// AdvertInterval is the VRRP advertisement interval, default 1s.
+	AdvertInterval time.Duration
+	// CheckScript is run by the track script, default DefaultCheckScript.
+	CheckScript string
+	// LVSKind is the IPVS forwarding method of UDP listeners: NAT (default),
+	// DR or TUN.
+	LVSKind string
+}
+
+// RenderKeepalived renders keepalived.conf: one VRRP instance holding the VIPs
+// in status.loadBalancerIps, and an IPVS virtual server per UDP listener and
+// VIP. VRRP advertises one address family per instance, so when there are
+// IPv4 VIPs the IPv6 VIPs go into virtual_ipaddress_excluded.
+//
+// UDP listeners map the methods to IPVS schedulers: round-robin to rr,
+// weighted-round-robin to wrr, least-session to wlc, source-hash to sh and
+// first-alive to fo, with weights falling in member order. The first backup
+// member becomes the sorry server. Only members of the VIP's address family
+// are real servers, and members with host names are left out.
+//
+// Real servers are probed with TCP_CHECK on the health check port. IPVS has
+// no HTTP probe for UDP services, so an HTTP or HTTPS health check on a UDP
+// listener, its own or inherited from spec.healthCheck, is an error.
+func RenderKeepalived(lb *v1alpha1.LoadBalancer, backends []loadbalancer.Backend, opts KeepalivedOptions) (string, error) {
+	if opts.Interface == "" {
+		return "", errors.New("keepalived interface is not set")
+	}
+	vips, err := vips(lb)
+	if err != nil {
+		return "", err
+	}
+	if err := loadbalancer.Validate(lb); err != nil {
+		return "", err
+	}
+	if opts.VirtualRouterID == 0 {
+		opts.VirtualRouterID = VirtualRouterID(lb)
+	}
+	if opts.VirtualRouterID < 1 || opts.VirtualRouterID > 255 {
+		return "", fmt.Errorf("virtual router id %d is not in 1-255", opts.VirtualRouterID)
+	}
+	if opts.Priority == 0 {
+		opts.Priority = 100
+	}
+	if opts.Priority < 1 || opts.Priority > 254 {
+		return "", fmt.Errorf("priority %d is not in 1-254", opts.Priority)
+	}
+	if opts.AdvertInterval == 0 {
+		opts.AdvertInterval = time.Second
+	}
+	if opts.CheckScript == "" {
+		opts.CheckScript = DefaultCheckScript
+	}
+	if opts.LVSKind == "" {
+		opts.LVSKind = "NAT"
+	}
+
+	instance := sanitize(lb.Namespace + "_" + lb.Name)
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated from LoadBalancer %s/%s, do not edit.\n", lb.Namespace, lb.Name)
+	b.WriteString("global_defs {\n")
+	fmt.Fprintf(&b, "    router_id %s\n", instance)
+	b.WriteString("    enable_script_security\n")
+	b.WriteString("}\n\n")
+
+	b.WriteString("vrrp_script chk_haproxy {\n")
+	fmt.Fprintf(&b, "    script \"%s\"\n", opts.CheckScript)
+	b.WriteString("    interval 2\n")
+	b.WriteString("    fall 2\n")
+	b.WriteString("    rise 2\n")
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "vrrp_instance %s {\n", instance)
+	b.WriteString("    state BACKUP\n")
+	fmt.Fprintf(&b, "    interface %s\n", opts.Interface)
+	fmt.Fprintf(&b, "    virtual_router_id %d\n", opts.VirtualRouterID)
+	fmt.Fprintf(&b, "    priority %d\n", opts.Priority)
+	fmt.Fprintf(&b, "    advert_int %s\n", seconds(opts.AdvertInterval))
+	if opts.UnicastSourceIP != "" {
+		fmt.Fprintf(&b, "    unicast_src_ip %s\n", opts.UnicastSourceIP)
+	}
+	if len(opts.UnicastPeers) > 0 {
+		b.WriteString("    unicast_peer {\n")
+		for _, p := range slices.Sorted(slices.Values(opts.UnicastPeers)) {
+			fmt.Fprintf(&b, "        %s\n", p)
+		}
+		b.WriteString("    }\n")
+	}
+	// IPv4 VIPs are advertised when there are any, IPv6 VIPs otherwise.
+	v4 := slices.ContainsFunc(vips, func(vip string) bool { return net.ParseIP(vip).To4() != nil })
+	var primary, excluded []string
+	for _, vip := range vips {
+		if (net.ParseIP(vip).To4() != nil) == v4 {
+			primary = append(primary, vip)
+		} else {
+			excluded = append(excluded, vip)
+		}
+	}
+	writeVIPs(&b, "virtual_ipaddress", primary, opts.Interface)
+	writeVIPs(&b, "virtual_ipaddress_excluded", excluded, opts.Interface)
+	b.WriteString("    track_script {\n")
+	b.WriteString("        chk_haproxy\n")
+	b.WriteString("    }\n")
+	b.WriteString("}\n")
+
+	for _, l := range sortedListeners(lb) {
+		if loadbalancer.Protocol(l) != v1alpha1.LoadBalancerProtocolUDP {
+			continue
+		}
+		for _, vip := range vips {
+			writeVirtualServer(&b, lb, backends, l, vip, opts.LVSKind)
+		}
+	}
+	return b.String(), nil
+}
+
+// VirtualRouterID derives a VRRP router id in 1-255 from the namespace and
+// name of a load balancer.
+func VirtualRouterID(lb *v1alpha1.LoadBalancer) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(lb.Namespace + "/" + lb.Name))
+	return int(h.Sum32()%255) + 1
+}
+
+func writeVIPs(b *strings.Builder, block string, vips []string, iface string) {
+	if len(vips) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "    %s {\n", block)
+	for _, vip := range vips {
+		bits := 128
+		if net.ParseIP(vip).To4() != nil {
+			bits = 32
+		}
+		fmt.Fprintf(b, "        %s/%d dev %s\n", vip, bits, iface)
+	}
+	b.WriteString("    }\n")
+}
+
+func writeVirtualServer(b *strings.Builder, lb *v1alpha1.LoadBalancer, backends []loadbalancer.Backend, l *v1alpha1.LoadBalancerListener, vip, kind string) {
+	hc := loadbalancer.HealthCheck(lb, l)
+	all := servers(lb, backends, l)
+
+	scheduler := map[string]string{
+		v1alpha1.LoadBalancerMethodRoundRobin:         "rr",
+		v1alpha1.LoadBalancerMethodWeightedRoundRobin: "wrr",
+		v1alpha1.LoadBalancerMethodLeastSession:       "wlc",
+		v1alpha1.LoadBalancerMethodSourceHash:         "sh",
+		v1alpha1.LoadBalancerMethodFirstAlive:         "fo",
+	}[method(lb)]
+
+	fmt.Fprintf(b, "\nvirtual_server %s %d {\n", vip, l.Port)
+	fmt.Fprintf(b, "    delay_loop %s\n", seconds(hc.Interval.Duration))
+	fmt.Fprintf(b, "    lb_algo %s\n", scheduler)
+	fmt.Fprintf(b, "    lb_kind %s\n", kind)
+	b.WriteString("    protocol UDP\n")
+
+	// IPVS forwards within one address family.
+	v4 := net.ParseIP(vip).To4() != nil
+	var sorry *server
+	var real []server
+	for i := range all {
+		s := all[i]
+		ip := net.ParseIP(s.address)
+		switch {
+		case s.disabled, ip == nil, (ip.To4() != nil) != v4:
+		case s.member.Backup && sorry == nil:
+			sorry = &s
+		case s.member.Backup:
+		default:
+			real = append(real, s)
+		}
+	}
+	if sorry != nil {
+		fmt.Fprintf(b, "    sorry_server %s %d\n", sorry.address, sorry.port)
+	}
+	for i, s := range real {
+		weight := loadbalancer.Weight(s.member)
+		switch {
+		case s.weight == 0:
+			weight = 0
+		case method(lb) == v1alpha1.LoadBalancerMethodRoundRobin:
+			weight = 1
+		case method(lb) == v1alpha1.LoadBalancerMethodFirstAlive:
+			// fo sends everything to the highest weight available server.
+			weight = int32(len(real) - i)
+		}
+		fmt.Fprintf(b, "\n    real_server %s %d {\n", s.address, s.port)
+		fmt.Fprintf(b, "        weight %d\n", weight)
+		// Validate rejects HTTP and HTTPS checks on UDP listeners.
+		if hc.Type == v1alpha1.LoadBalancerHealthCheckTCP {
+			b.WriteString("        TCP_CHECK {\n")
+			fmt.Fprintf(b, "            connect_port %d\n", checkPort(&hc, l, s))
+			fmt.Fprintf(b, "            connect_timeout %s\n", seconds(hc.Timeout.Duration))
+			fmt.Fprintf(b, "            retry %d\n", hc.UnhealthyThreshold)
+			b.WriteString("        }\n")
+		}
+		b.WriteString("    }\n")
+	}
+	b.WriteString("}\n")
+}
+
+// seconds formats a duration in seconds for keepalived, at least 1.
+func seconds(d time.Duration) string {
+	if d < time.Second {
+		return "1"
+	}
+	if d%time.Second == 0 {
+		return fmt.Sprintf("%d", d/time.Second)
+	}
+	return fmt.Sprintf("%g", d.Seconds())
+}
@@ -0,0 +1,184 @@
+package lbconfig
+
+import (
+	"flag"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vitistack/crds/pkg/loadbalancer"
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+var methods = []string{
+	v1alpha1.LoadBalancerMethodRoundRobin,
+	v1alpha1.LoadBalancerMethodWeightedRoundRobin,
+	v1alpha1.LoadBalancerMethodLeastSession,
+	v1alpha1.LoadBalancerMethodSourceHash,
+	v1alpha1.LoadBalancerMethodFirstAlive,
+}
+
+// newLoadBalancer returns a dual stack load balancer with an HTTP, a TCP and
+// a UDP listener, and members covering weights, member ports, backups and
+// admin states.
+func newLoadBalancer(method string) *v1alpha1.LoadBalancer {
+	return &v1alpha1.LoadBalancer{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: v1alpha1.LoadBalancerSpec{
+			Method: method,
+			Members: []v1alpha1.LoadBalancerPoolMember{
+				{Address: "10.0.1.1", Weight: ptr[int32](3)},
+				{Address: "10.0.1.2"},
+				{Address: "10.0.1.3", AdminState: v1alpha1.LoadBalancerMemberDrain},
+				{Address: "10.0.1.4", Port: 9090},
+				{Address: "10.0.1.5", AdminState: v1alpha1.LoadBalancerMemberDisabled},
+				{Address: "2001:db8::1:1", Weight: ptr[int32](2)},
+				{Address: "10.0.1.9", Backup: true},
+				{Address: "10.0.1.8", Backup: true},
+			},
+			Listeners: []v1alpha1.LoadBalancerListener{
+				{Name: "http", Protocol: v1alpha1.LoadBalancerProtocolHTTP, Port: 80, BackendPort: 8080,
+					HealthCheck: &v1alpha1.LoadBalancerHealthCheck{Path: "/healthz", ExpectedStatus: "200-399"}},
+				{Name: "postgres", Protocol: v1alpha1.LoadBalancerProtocolTCP, Port: 5432,
+					HealthCheck: &v1alpha1.LoadBalancerHealthCheck{Type: v1alpha1.LoadBalancerHealthCheckTCP, Interval: &metav1.Duration{Duration: 2 * time.Second}},
+					Timeouts:    &v1alpha1.LoadBalancerTimeouts{Client: &metav1.Duration{Duration: 30 * time.Minute}}},
+				{Name: "dns", Protocol: v1alpha1.LoadBalancerProtocolUDP, Port: 53,
+					HealthCheck: &v1alpha1.LoadBalancerHealthCheck{Type: v1alpha1.LoadBalancerHealthCheckTCP}},
+			},
+		},
+		Status: v1alpha1.LoadBalancerStatus{LoadBalancerIps: []string{"2001:db8::10", "10.0.0.10"}},
+	}
+}
+
+func resolve(t *testing.T, lb *v1alpha1.LoadBalancer) []loadbalancer.Backend {
+	t.Helper()
+	backends, err := loadbalancer.ResolveMembers(lb, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return backends
+}
+
+var keepalivedOptions = KeepalivedOptions{
+	Interface:       "eth0",
+	Priority:        150,
+	UnicastSourceIP: "10.0.0.2",
+	UnicastPeers:    []string{"10.0.0.3"},
+}
+
+func TestRenderGolden(t *testing.T) {
+	for _, method := range methods {
+		t.Run(method, func(t *testing.T) {
+			lb := newLoadBalancer(method)
+			backends := resolve(t, lb)
+
+			haproxy, err := RenderHAProxy(lb, backends, HAProxyOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, method+".haproxy.cfg", haproxy.Config)
+
+			keepalived, err := RenderKeepalived(lb, backends, keepalivedOptions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, method+".keepalived.conf", keepalived)
+		})
+	}
+}
+
+func TestRenderIgnoresMemberOrder(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for _, method := range methods {
+		// With first-alive the member order is the failover order.
+		if method == v1alpha1.LoadBalancerMethodFirstAlive {
+			continue
+		}
+		t.Run(method, func(t *testing.T) {
+			lb := newLoadBalancer(method)
+			want, err := RenderHAProxy(lb, resolve(t, lb), HAProxyOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantKeepalived, err := RenderKeepalived(lb, resolve(t, lb), keepalivedOptions)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for range 10 {
+				members := lb.Spec.Members
+				r.Shuffle(len(members), func(i, j int) { members[i], members[j] = members[j], members[i] })
+				got, err := RenderHAProxy(lb, resolve(t, lb), HAProxyOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Config != want.Config || got.Hash() != want.Hash() {
+					t.Fatalf("haproxy.cfg changed after shuffling the members:\n%s", got.Config)
+				}
+				gotKeepalived, err := RenderKeepalived(lb, resolve(t, lb), keepalivedOptions)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if gotKeepalived != wantKeepalived {
+					t.Fatalf("keepalived.conf changed after shuffling the members:\n%s", gotKeepalived)
+				}
+			}
+		})
+	}
+}
+
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s, run go test -update to accept it:\n%s", path, got)
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestRenderKeepalivedHTTPCheck(t *testing.T) {
+	httpCheck := &v1alpha1.LoadBalancerHealthCheck{Type: v1alpha1.LoadBalancerHealthCheckHTTP, Path: "/healthz"}
+	for name, set := range map[string]func(lb *v1alpha1.LoadBalancer){
+		"listener": func(lb *v1alpha1.LoadBalancer) { lb.Spec.Listeners[2].HealthCheck = httpCheck },
+		"inherited": func(lb *v1alpha1.LoadBalancer) {
+			lb.Spec.Listeners[2].HealthCheck = nil
+			lb.Spec.HealthCheck = httpCheck
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			lb := newLoadBalancer(v1alpha1.LoadBalancerMethodRoundRobin)
+			set(lb)
+			if _, err := RenderKeepalived(lb, resolve(t, lb), keepalivedOptions); err == nil || !strings.Contains(err.Error(), `listener "dns": HTTP health check is not possible on a UDP listener`) {
+				t.Errorf("error = %v, want the HTTP check on the UDP listener rejected", err)
+			}
+		})
+	}
+
+	// Without a probe the real servers have no check block.
+	lb := newLoadBalancer(v1alpha1.LoadBalancerMethodRoundRobin)
+	lb.Spec.Listeners[2].HealthCheck = &v1alpha1.LoadBalancerHealthCheck{Type: v1alpha1.LoadBalancerHealthCheckNone}
+	got, err := RenderKeepalived(lb, resolve(t, lb), keepalivedOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "_CHECK") || !strings.Contains(got, "real_server 10.0.1.1 53") {
+		t.Errorf("keepalived.conf without health check:\n%s", got)
+	}
+}
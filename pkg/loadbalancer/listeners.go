@@ -0,0 +1,233 @@
+// Package loadbalancer resolves and validates the listeners and pool members
+// of a LoadBalancer. It applies the API defaults for health checks, timeouts
+// and members so providers render the same effective configuration.
+package loadbalancer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Defaults applied to health checks and timeouts.
+const (
+	DefaultHealthCheckInterval = 5 * time.Second
+	DefaultHealthCheckTimeout  = 3 * time.Second
+	DefaultHealthyThreshold    = 2
+	DefaultUnhealthyThreshold  = 3
+	DefaultHealthCheckPath     = "/"
+	DefaultExpectedStatus      = "200-399"
+
+	DefaultConnectTimeout = 5 * time.Second
+	DefaultClientTimeout  = 50 * time.Second
+	DefaultServerTimeout  = 50 * time.Second
+	DefaultTunnelTimeout  = time.Hour
+)
+
+// Protocol returns the listener protocol, applying the API default.
+func Protocol(l *v1alpha1.LoadBalancerListener) string {
+	if l.Protocol == "" {
+		return v1alpha1.LoadBalancerProtocolTCP
+	}
+	return l.Protocol
+}
+
+// BackendPort returns the port on the pool members.
+func BackendPort(l *v1alpha1.LoadBalancerListener) int32 {
+	if l.BackendPort == 0 {
+		return l.Port
+	}
+	return l.BackendPort
+}
+
+// HealthCheck returns the effective health check of a listener. Fields set on
+// the listener win over spec.healthCheck, which wins over the defaults. Every
+// field of the result is set, except Path and ExpectedStatus for non-HTTP probes.
+func HealthCheck(lb *v1alpha1.LoadBalancer, l *v1alpha1.LoadBalancerListener) v1alpha1.LoadBalancerHealthCheck {
+	var hc v1alpha1.LoadBalancerHealthCheck
+	for _, layer := range []*v1alpha1.LoadBalancerHealthCheck{l.HealthCheck, lb.Spec.HealthCheck} {
+		if layer == nil {
+			continue
+		}
+		hc.Type = firstString(hc.Type, layer.Type)
+		hc.Path = firstString(hc.Path, layer.Path)
+		hc.ExpectedStatus = firstString(hc.ExpectedStatus, layer.ExpectedStatus)
+		hc.Port = firstInt(hc.Port, layer.Port)
+		hc.HealthyThreshold = firstInt(hc.HealthyThreshold, layer.HealthyThreshold)
+		hc.UnhealthyThreshold = firstInt(hc.UnhealthyThreshold, layer.UnhealthyThreshold)
+		hc.Interval = firstDuration(hc.Interval, layer.Interval)
+		hc.Timeout = firstDuration(hc.Timeout, layer.Timeout)
+	}
+
+	if hc.Type == "" {
+		switch Protocol(l) {
+		case v1alpha1.LoadBalancerProtocolHTTP:
+			hc.Type = v1alpha1.LoadBalancerHealthCheckHTTP
+		case v1alpha1.LoadBalancerProtocolUDP:
+			hc.Type = v1alpha1.LoadBalancerHealthCheckNone
+		default:
+			hc.Type = v1alpha1.LoadBalancerHealthCheckTCP
+		}
+	}
+	if isHTTPCheck(hc.Type) {
+		hc.Path = firstString(hc.Path, DefaultHealthCheckPath)
+		hc.ExpectedStatus = firstString(hc.ExpectedStatus, DefaultExpectedStatus)
+	} else {
+		hc.Path, hc.ExpectedStatus = "", ""
+	}
+	hc.Port = firstInt(hc.Port, BackendPort(l))
+	hc.HealthyThreshold = firstInt(hc.HealthyThreshold, DefaultHealthyThreshold)
+	hc.UnhealthyThreshold = firstInt(hc.UnhealthyThreshold, DefaultUnhealthyThreshold)
+	hc.Interval = firstDuration(hc.Interval, &metav1.Duration{Duration: DefaultHealthCheckInterval})
+	// The default timeout never exceeds a shorter interval.
+	hc.Timeout = firstDuration(hc.Timeout, &metav1.Duration{Duration: min(DefaultHealthCheckTimeout, hc.Interval.Duration)})
+	return *hc.DeepCopy()
+}
+
+// Timeouts returns the effective timeouts of a listener. Fields set on the
+// listener win over spec.timeouts, which wins over the defaults.
+func Timeouts(lb *v1alpha1.LoadBalancer, l *v1alpha1.LoadBalancerListener) v1alpha1.LoadBalancerTimeouts {
+	var t v1alpha1.LoadBalancerTimeouts
+	for _, layer := range []*v1alpha1.LoadBalancerTimeouts{l.Timeouts, lb.Spec.Timeouts} {
+		if layer == nil {
+			continue
+		}
+		t.Connect = firstDuration(t.Connect, layer.Connect)
+		t.Client = firstDuration(t.Client, layer.Client)
+		t.Server = firstDuration(t.Server, layer.Server)
+		t.Tunnel = firstDuration(t.Tunnel, layer.Tunnel)
+	}
+	t.Connect = firstDuration(t.Connect, &metav1.Duration{Duration: DefaultConnectTimeout})
+	t.Client = firstDuration(t.Client, &metav1.Duration{Duration: DefaultClientTimeout})
+	t.Server = firstDuration(t.Server, &metav1.Duration{Duration: DefaultServerTimeout})
+	t.Tunnel = firstDuration(t.Tunnel, &metav1.Duration{Duration: DefaultTunnelTimeout})
+	return *t.DeepCopy()
+}
+
+// Validate checks the listeners and pool members of a load balancer beyond
+// what the CRD schema can express. It returns one joined error listing every
+// problem.
+func Validate(lb *v1alpha1.LoadBalancer) error {
+	errs := validateMembers(lb)
+	names := map[string]bool{}
+	ports := map[string]string{}
+	for i := range lb.Spec.Listeners {
+		l := &lb.Spec.Listeners[i]
+		if names[l.Name] {
+			errs = append(errs, fmt.Errorf("listener %q: duplicate name", l.Name))
+		}
+		names[l.Name] = true
+
+		// TCP, HTTP and HTTPS listeners share the TCP port space of the VIP.
+		transport := "TCP"
+		if Protocol(l) == v1alpha1.LoadBalancerProtocolUDP {
+			transport = "UDP"
+		}
+		key := transport + "/" + strconv.Itoa(int(l.Port))
+		if other, ok := ports[key]; ok {
+			errs = append(errs, fmt.Errorf("listener %q: %s port %d is already used by listener %q", l.Name, transport, l.Port, other))
+		} else {
+			ports[key] = l.Name
+		}
+
+		hc := HealthCheck(lb, l)
+		if Protocol(l) == v1alpha1.LoadBalancerProtocolUDP && isHTTPCheck(hc.Type) {
+			errs = append(errs, fmt.Errorf("listener %q: %s health check is not possible on a UDP listener", l.Name, hc.Type))
+		}
+		if hc.Type != v1alpha1.LoadBalancerHealthCheckNone && hc.Timeout.Duration > hc.Interval.Duration {
+			errs = append(errs, fmt.Errorf("listener %q: health check timeout %s exceeds its interval %s", l.Name, hc.Timeout.Duration, hc.Interval.Duration))
+		}
+		if err := validateExpectedStatus(hc.ExpectedStatus); err != nil {
+			errs = append(errs, fmt.Errorf("listener %q: %w", l.Name, err))
+		}
+		errs = append(errs, validateTLS(l)...)
+	}
+	return errors.Join(errs...)
+}
+
+// ListenerStatuses returns one status entry per listener and VIP, sorted by
+// listener name and VIP. Ready and Message are kept from the current status
+// for entries that did not change.
+func ListenerStatuses(lb *v1alpha1.LoadBalancer, vips []string) []v1alpha1.LoadBalancerListenerStatus {
+	current := map[string]v1alpha1.LoadBalancerListenerStatus{}
+	for _, s := range lb.Status.Listeners {
+		current[statusKey(s)] = s
+	}
+
+	var statuses []v1alpha1.LoadBalancerListenerStatus
+	for i := range lb.Spec.Listeners {
+		l := &lb.Spec.Listeners[i]
+		for _, vip := range vips {
+			s := v1alpha1.LoadBalancerListenerStatus{Name: l.Name, Protocol: Protocol(l), VIP: vip, Port: l.Port}
+			if prev, ok := current[statusKey(s)]; ok && prev.Protocol == s.Protocol {
+				s.Ready, s.Message = prev.Ready, prev.Message
+			}
+			statuses = append(statuses, s)
+		}
+	}
+	sort.SliceStable(statuses, func(i, j int) bool {
+		if statuses[i].Name != statuses[j].Name {
+			return statuses[i].Name < statuses[j].Name
+		}
+		return statuses[i].VIP < statuses[j].VIP
+	})
+	return statuses
+}
+
+func statusKey(s v1alpha1.LoadBalancerListenerStatus) string {
+	return s.Name + "/" + s.VIP + "/" + strconv.Itoa(int(s.Port))
+}
+
+func validateExpectedStatus(s string) error {
+	if s == "" {
+		return nil
+	}
+	from, to, isRange := strings.Cut(s, "-")
+	lo, err1 := strconv.Atoi(from)
+	hi := lo
+	var err2 error
+	if isRange {
+		hi, err2 = strconv.Atoi(to)
+	}
+	if err1 != nil || err2 != nil || lo < 100 || hi > 599 || hi < lo {
+		return fmt.Errorf("invalid expected status %q", s)
+	}
+	return nil
+}
+
+func isHTTPCheck(t string) bool {
+	return t == v1alpha1.LoadBalancerHealthCheckHTTP || t == v1alpha1.LoadBalancerHealthCheckHTTPS
+}
+
+func firstString(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func firstInt(values ...int32) int32 {
+	for _, v := range values {
+		if v != 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+func firstDuration(values ...*metav1.Duration) *metav1.Duration {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package loadbalancer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func duration(d time.Duration) *metav1.Duration {
+	return &metav1.Duration{Duration: d}
+}
+
+func newLoadBalancer(listeners ...v1alpha1.LoadBalancerListener) *v1alpha1.LoadBalancer {
+	return &v1alpha1.LoadBalancer{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+		Spec: v1alpha1.LoadBalancerSpec{
+			Members:   []v1alpha1.LoadBalancerPoolMember{{Address: "10.0.0.11"}},
+			Listeners: listeners,
+		},
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     *v1alpha1.LoadBalancerHealthCheck
+		listener v1alpha1.LoadBalancerListener
+		want     v1alpha1.LoadBalancerHealthCheck
+	}{
+		{
+			name:     "TCP defaults",
+			listener: v1alpha1.LoadBalancerListener{Name: "api", Port: 6443},
+			want: v1alpha1.LoadBalancerHealthCheck{
+				Type: v1alpha1.LoadBalancerHealthCheckTCP, Port: 6443, HealthyThreshold: 2, UnhealthyThreshold: 3,
+				Interval: duration(5 * time.Second), Timeout: duration(3 * time.Second),
+			},
+		},
+		{
+			name:     "HTTP defaults",
+			listener: v1alpha1.LoadBalancerListener{Name: "web", Protocol: v1alpha1.LoadBalancerProtocolHTTP, Port: 80, BackendPort: 8080},
+			want: v1alpha1.LoadBalancerHealthCheck{
+				Type: v1alpha1.LoadBalancerHealthCheckHTTP, Path: "/", ExpectedStatus: "200-399", Port: 8080, HealthyThreshold: 2, UnhealthyThreshold: 3,
+				Interval: duration(5 * time.Second), Timeout: duration(3 * time.Second),
+			},
+		},
+		{
+			name:     "UDP defaults to none",
+			listener: v1alpha1.LoadBalancerListener{Name: "dns", Protocol: v1alpha1.LoadBalancerProtocolUDP, Port: 53},
+			want: v1alpha1.LoadBalancerHealthCheck{
+				Type: v1alpha1.LoadBalancerHealthCheckNone, Port: 53, HealthyThreshold: 2, UnhealthyThreshold: 3,
+				Interval: duration(5 * time.Second), Timeout: duration(3 * time.Second),
+			},
+		},
+		{
+			name: "listener wins over the spec",
+			spec: &v1alpha1.LoadBalancerHealthCheck{Type: v1alpha1.LoadBalancerHealthCheckHTTPS, Path: "/healthz", HealthyThreshold: 5, Interval: duration(10 * time.Second)},
+			listener: v1alpha1.LoadBalancerListener{Name: "api", Port: 6443, HealthCheck: &v1alpha1.LoadBalancerHealthCheck{
+				Path: "/readyz", Port: 9000, Interval: duration(2 * time.Second),
+			}},
+			want: v1alpha1.LoadBalancerHealthCheck{
+				Type: v1alpha1.LoadBalancerHealthCheckHTTPS, Path: "/readyz", ExpectedStatus: "200-399", Port: 9000, HealthyThreshold: 5, UnhealthyThreshold: 3,
+				Interval: duration(2 * time.Second), Timeout: duration(2 * time.Second),
+			},
+		},
+		{
+			name:     "path dropped for a TCP probe",
+			spec:     &v1alpha1.LoadBalancerHealthCheck{Path: "/healthz", ExpectedStatus: "200"},
+			listener: v1alpha1.LoadBalancerListener{Name: "api", Port: 6443},
+			want: v1alpha1.LoadBalancerHealthCheck{
+				Type: v1alpha1.LoadBalancerHealthCheckTCP, Port: 6443, HealthyThreshold: 2, UnhealthyThreshold: 3,
+				Interval: duration(5 * time.Second), Timeout: duration(3 * time.Second),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lb := newLoadBalancer(tt.listener)
+			lb.Spec.HealthCheck = tt.spec
+			got := HealthCheck(lb, &lb.Spec.Listeners[0])
+			if got.Type != tt.want.Type || got.Path != tt.want.Path || got.ExpectedStatus != tt.want.ExpectedStatus || got.Port != tt.want.Port ||
+				got.HealthyThreshold != tt.want.HealthyThreshold || got.UnhealthyThreshold != tt.want.UnhealthyThreshold ||
+				got.Interval.Duration != tt.want.Interval.Duration || got.Timeout.Duration != tt.want.Timeout.Duration {
+				t.Errorf("health check = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// The result does not share the layers it was built from.
+	lb := newLoadBalancer(v1alpha1.LoadBalancerListener{Name: "api", Port: 6443})
+	lb.Spec.HealthCheck = &v1alpha1.LoadBalancerHealthCheck{Interval: duration(time.Second)}
+	HealthCheck(lb, &lb.Spec.Listeners[0]).Interval.Duration = time.Hour
+	if lb.Spec.HealthCheck.Interval.Duration != time.Second {
+		t.Error("changing the result changed spec.healthCheck")
+	}
+}
+
+func TestTimeouts(t *testing.T) {
+	lb := newLoadBalancer(
+		v1alpha1.LoadBalancerListener{Name: "api", Port: 6443, Timeouts: &v1alpha1.LoadBalancerTimeouts{Client: duration(time.Minute)}},
+		v1alpha1.LoadBalancerListener{Name: "web", Port: 80},
+	)
+	lb.Spec.Timeouts = &v1alpha1.LoadBalancerTimeouts{Client: duration(10 * time.Second), Tunnel: duration(2 * time.Hour)}
+
+	tests := []struct {
+		listener                        int
+		connect, client, server, tunnel time.Duration
+	}{
+		{listener: 0, connect: 5 * time.Second, client: time.Minute, server: 50 * time.Second, tunnel: 2 * time.Hour},
+		{listener: 1, connect: 5 * time.Second, client: 10 * time.Second, server: 50 * time.Second, tunnel: 2 * time.Hour},
+	}
+	for _, tt := range tests {
+		got := Timeouts(lb, &lb.Spec.Listeners[tt.listener])
+		if got.Connect.Duration != tt.connect || got.Client.Duration != tt.client || got.Server.Duration != tt.server || got.Tunnel.Duration != tt.tunnel {
+			t.Errorf("listener %d: timeouts = %v %v %v %v, want %v %v %v %v", tt.listener,
+				got.Connect.Duration, got.Client.Duration, got.Server.Duration, got.Tunnel.Duration, tt.connect, tt.client, tt.server, tt.tunnel)
+		}
+	}
+
+	defaults := Timeouts(newLoadBalancer(), &v1alpha1.LoadBalancerListener{})
+	if defaults.Connect.Duration != DefaultConnectTimeout || defaults.Client.Duration != DefaultClientTimeout ||
+		defaults.Server.Duration != DefaultServerTimeout || defaults.Tunnel.Duration != DefaultTunnelTimeout {
+		t.Errorf("defaults = %+v", defaults)
+	}
+}
+
+func TestValidateListeners(t *testing.T) {
+	udp := func(name string, port int32, hc *v1alpha1.LoadBalancerHealthCheck) v1alpha1.LoadBalancerListener {
+		return v1alpha1.LoadBalancerListener{Name: name, Protocol: v1alpha1.LoadBalancerProtocolUDP, Port: port, HealthCheck: hc}
+	}
+	tests := []struct {
+		name      string
+		spec      *v1alpha1.LoadBalancerHealthCheck
+		listeners []v1alpha1.LoadBalancerListener
+		// want is part of the error, "" for none.
+		want string
+	}{
+		{
+			name: "valid",
+			listeners: []v1alpha1.LoadBalancerListener{
+				{Name: "api", Port: 6443},
+				{Name: "web", Protocol: v1alpha1.LoadBalancerProtocolHTTP, Port: 80},
+				udp("dns", 53, nil),
+			},
+		},
+		{
+			name:      "TCP and UDP share a port number",
+			listeners: []v1alpha1.LoadBalancerListener{{Name: "dns-tcp", Port: 53}, udp("dns-udp", 53, nil)},
+		},
+		{
+			name:      "duplicate name",
+			listeners: []v1alpha1.LoadBalancerListener{{Name: "api", Port: 6443}, {Name: "api", Port: 443}},
+			want:      `listener "api": duplicate name`,
+		},
+		{
+			name:      "HTTP and TCP on one port",
+			listeners: []v1alpha1.LoadBalancerListener{{Name: "api", Port: 443}, {Name: "web", Protocol: v1alpha1.LoadBalancerProtocolHTTPS, Port: 443}},
+			want:      `listener "web": TCP port 443 is already used by listener "api"`,
+		},
+		{
+			name:      "UDP port used twice",
+			listeners: []v1alpha1.LoadBalancerListener{udp("a", 53, nil), udp("b", 53, nil)},
+			want:      `listener "b": UDP port 53 is already used by listener "a"`,
+		},
+		{
+			name:      "HTTP check on a UDP listener",
+			listeners: []v1alpha1.LoadBalancerListener{udp("dns", 53, &v1alpha1.LoadBalancerHealthCheck{Type: v1alpha1.LoadBalancerHealthCheckHTTP})},
+			want:      `listener "dns": HTTP health check is not possible on a UDP listener`,
+		},
+		{
+			name:      "HTTP check inherited by a UDP listener",
+			spec:      &v1alpha1.LoadBalancerHealthCheck{Type: v1alpha1.LoadBalancerHealthCheckHTTPS},
+			listeners: []v1alpha1.LoadBalancerListener{udp("dns", 53, nil)},
+			want:      `listener "dns": HTTPS health check is not possible on a UDP listener`,
+		},
+		{
+			name: "timeout exceeds interval",
+			listeners: []v1alpha1.LoadBalancerListener{{Name: "api", Port: 6443, HealthCheck: &v1alpha1.LoadBalancerHealthCheck{
+				Interval: duration(2 * time.Second), Timeout: duration(5 * time.Second),
+			}}},
+			want: `listener "api": health check timeout 5s exceeds its interval 2s`,
+		},
+		{
+			name: "timeout ignored without a probe",
+			listeners: []v1alpha1.LoadBalancerListener{udp("dns", 53, &v1alpha1.LoadBalancerHealthCheck{
+				Interval: duration(2 * time.Second), Timeout: duration(5 * time.Second),
+			})},
+		},
+		{
+			name: "expected status range reversed",
+			listeners: []v1alpha1.LoadBalancerListener{{Name: "web", Protocol: v1alpha1.LoadBalancerProtocolHTTP, Port: 80, HealthCheck: &v1alpha1.LoadBalancerHealthCheck{
+				ExpectedStatus: "399-200",
+			}}},
+			want: `listener "web": invalid expected status "399-200"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lb := newLoadBalancer(tt.listeners...)
+			lb.Spec.HealthCheck = tt.spec
+			err := Validate(lb)
+			if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateExpectedStatus(t *testing.T) {
+	for s, ok := range map[string]bool{
+		"":        true,
+		"200":     true,
+		"200-399": true,
+		"204-204": true,
+		"99":      false,
+		"600":     false,
+		"200-600": false,
+		"abc":     false,
+		"200-":    false,
+	} {
+		if err := validateExpectedStatus(s); (err == nil) != ok {
+			t.Errorf("validateExpectedStatus(%q) = %v, want ok %v", s, err, ok)
+		}
+	}
+}
+
+func TestListenerStatuses(t *testing.T) {
+	lb := newLoadBalancer(
+		v1alpha1.LoadBalancerListener{Name: "web", Protocol: v1alpha1.LoadBalancerProtocolHTTP, Port: 80},
+		v1alpha1.LoadBalancerListener{Name: "api", Port: 6443},
+	)
+	lb.Status.Listeners = []v1alpha1.LoadBalancerListenerStatus{
+		{Name: "api", Protocol: v1alpha1.LoadBalancerProtocolTCP, VIP: "10.0.0.100", Port: 6443, Ready: true, Message: "serving"},
+		// The protocol changed, so the entry starts over.
+		{Name: "web", Protocol: v1alpha1.LoadBalancerProtocolTCP, VIP: "10.0.0.100", Port: 80, Ready: true},
+		// The listener is gone.
+		{Name: "old", Protocol: v1alpha1.LoadBalancerProtocolTCP, VIP: "10.0.0.100", Port: 22, Ready: true},
+	}
+
+	got := ListenerStatuses(lb, []string{"2001:db8::100", "10.0.0.100"})
+	want := []v1alpha1.LoadBalancerListenerStatus{
+		{Name: "api", Protocol: v1alpha1.LoadBalancerProtocolTCP, VIP: "10.0.0.100", Port: 6443, Ready: true, Message: "serving"},
+		{Name: "api", Protocol: v1alpha1.LoadBalancerProtocolTCP, VIP: "2001:db8::100", Port: 6443},
+		{Name: "web", Protocol: v1alpha1.LoadBalancerProtocolHTTP, VIP: "10.0.0.100", Port: 80},
+		{Name: "web", Protocol: v1alpha1.LoadBalancerProtocolHTTP, VIP: "2001:db8::100", Port: 80},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("statuses = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("status %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
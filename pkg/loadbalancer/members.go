@@ -0,0 +1,337 @@
+package loadbalancer
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultMemberWeight is the weight of a pool member without one.
+const DefaultMemberWeight = 1
+
+var hostnameRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*$`)
+
+// ParsePoolMember converts an entry of the deprecated spec.poolMembers list to
+// a pool member. The entry is an address with an optional port: "10.0.0.1",
+// "10.0.0.1:6443", "2001:db8::1" or "[2001:db8::1]:6443".
+func ParsePoolMember(s string) (v1alpha1.LoadBalancerPoolMember, error) {
+	m := v1alpha1.LoadBalancerPoolMember{Address: s}
+	if _, err := netip.ParseAddr(s); err == nil {
+		return m, nil
+	}
+	if strings.Contains(s, ":") {
+		host, port, err := net.SplitHostPort(s)
+		if err != nil {
+			return m, fmt.Errorf("invalid pool member %q: %w", s, err)
+		}
+		p, err := strconv.Atoi(port)
+		if err != nil || p < 1 || p > 65535 {
+			return m, fmt.Errorf("invalid pool member %q: invalid port %q", s, port)
+		}
+		m.Address, m.Port = host, int32(p)
+	}
+	if err := validateAddress(m.Address); err != nil {
+		return m, fmt.Errorf("invalid pool member %q: %w", s, err)
+	}
+	return m, nil
+}
+
+// ConvertPoolMembers moves the deprecated spec.poolMembers strings into
+// spec.members and clears spec.poolMembers. Entries already listed in
+// spec.members are dropped. The load balancer is only changed when every
+// entry parses.
+func ConvertPoolMembers(lb *v1alpha1.LoadBalancer) error {
+	members, err := parsePoolMembers(lb.Spec.PoolMembers)
+	if err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for i := range lb.Spec.Members {
+		seen[memberKey(&lb.Spec.Members[i])] = true
+	}
+	for i := range members {
+		if key := memberKey(&members[i]); !seen[key] {
+			seen[key] = true
+			lb.Spec.Members = append(lb.Spec.Members, members[i])
+		}
+	}
+	lb.Spec.PoolMembers = nil
+	return nil
+}
+
+// Members returns the effective pool members: spec.members followed by the
+// converted spec.poolMembers, without duplicates and with Weight and
+// AdminState set.
+func Members(lb *v1alpha1.LoadBalancer) ([]v1alpha1.LoadBalancerPoolMember, error) {
+	converted, err := parsePoolMembers(lb.Spec.PoolMembers)
+	if err != nil {
+		return nil, err
+	}
+	var members []v1alpha1.LoadBalancerPoolMember
+	seen := map[string]bool{}
+	for _, m := range append(append([]v1alpha1.LoadBalancerPoolMember{}, lb.Spec.Members...), converted...) {
+		if key := memberKey(&m); !seen[key] {
+			seen[key] = true
+			m := *m.DeepCopy()
+			weight := Weight(&m)
+			m.Weight = &weight
+			m.AdminState = AdminState(&m)
+			members = append(members, m)
+		}
+	}
+	return members, nil
+}
+
+// Weight returns the weight of a pool member, applying the default.
+func Weight(m *v1alpha1.LoadBalancerPoolMember) int32 {
+	if m.Weight == nil {
+		return DefaultMemberWeight
+	}
+	return *m.Weight
+}
+
+// AdminState returns the admin state of a pool member, applying the API default.
+func AdminState(m *v1alpha1.LoadBalancerPoolMember) string {
+	if m.AdminState == "" {
+		return v1alpha1.LoadBalancerMemberEnabled
+	}
+	return m.AdminState
+}
+
+// MemberPort returns the port of a pool member for a listener.
+func MemberPort(m *v1alpha1.LoadBalancerPoolMember, l *v1alpha1.LoadBalancerListener) int32 {
+	if m.Port != 0 {
+		return m.Port
+	}
+	return BackendPort(l)
+}
+
+// Backend is a pool member with its address resolved.
+type Backend struct {
+	v1alpha1.LoadBalancerPoolMember
+	// Address is the IP address or host name traffic is sent to.
+	Address string
+	// Machine is the name of the referenced Machine, if any.
+	Machine string
+}
+
+// ResolveMembers returns the effective pool members with machine references
+// resolved to the first private, IPv4 or IPv6 address in the Machine status.
+// Members that cannot be resolved are left out and reported in the joined
+// error, so a caller can still serve the others. Only machines in the
+// namespace of the load balancer are considered.
+func ResolveMembers(lb *v1alpha1.LoadBalancer, machines []v1alpha1.Machine) ([]Backend, error) {
+	members, err := Members(lb)
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]*v1alpha1.Machine{}
+	for i := range machines {
+		if machines[i].Namespace == lb.Namespace {
+			byName[machines[i].Name] = &machines[i]
+		}
+	}
+
+	var backends []Backend
+	var errs []error
+	for _, m := range members {
+		if m.MachineRef == nil {
+			backends = append(backends, Backend{LoadBalancerPoolMember: m, Address: m.Address})
+			continue
+		}
+		machine, ok := byName[m.MachineRef.Name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("pool member: machine %q not found", m.MachineRef.Name))
+			continue
+		}
+		addr := machineAddress(machine)
+		if addr == "" {
+			errs = append(errs, fmt.Errorf("pool member: machine %q has no IP address yet", m.MachineRef.Name))
+			continue
+		}
+		backends = append(backends, Backend{LoadBalancerPoolMember: m, Address: addr, Machine: machine.Name})
+	}
+	return backends, errors.Join(errs...)
+}
+
+// Addresses returns the distinct backend addresses, sorted. It fills the
+// deprecated status.poolMembers.
+func Addresses(backends []Backend) []string {
+	seen := map[string]bool{}
+	var addrs []string
+	for _, b := range backends {
+		if !seen[b.Address] {
+			seen[b.Address] = true
+			addrs = append(addrs, b.Address)
+		}
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+// MemberStatuses returns one status entry per backend and listener, or per
+// backend when the load balancer has no listeners, sorted by listener,
+// address and port. Health, Message and LastTransitionTime are kept from the
+// current status; new entries are Unknown.
+func MemberStatuses(lb *v1alpha1.LoadBalancer, backends []Backend) []v1alpha1.LoadBalancerMemberStatus {
+	current := map[string]v1alpha1.LoadBalancerMemberStatus{}
+	for _, s := range lb.Status.Members {
+		current[memberStatusKey(s.Listener, s.Address, s.Port)] = s
+	}
+
+	var statuses []v1alpha1.LoadBalancerMemberStatus
+	add := func(b *Backend, listener string, port int32) {
+		s := v1alpha1.LoadBalancerMemberStatus{
+			Address:    b.Address,
+			Machine:    b.Machine,
+			Listener:   listener,
+			Port:       port,
+			Health:     v1alpha1.LoadBalancerMemberUnknown,
+			AdminState: AdminState(&b.LoadBalancerPoolMember),
+		}
+		if prev, ok := current[memberStatusKey(listener, b.Address, port)]; ok {
+			s.Health, s.Message, s.LastTransitionTime = prev.Health, prev.Message, prev.LastTransitionTime
+		}
+		statuses = append(statuses, s)
+	}
+	for i := range backends {
+		b := &backends[i]
+		if len(lb.Spec.Listeners) == 0 {
+			add(b, "", b.Port)
+			continue
+		}
+		for j := range lb.Spec.Listeners {
+			l := &lb.Spec.Listeners[j]
+			add(b, l.Name, MemberPort(&b.LoadBalancerPoolMember, l))
+		}
+	}
+	sort.SliceStable(statuses, func(i, j int) bool {
+		a, b := statuses[i], statuses[j]
+		if a.Listener != b.Listener {
+			return a.Listener < b.Listener
+		}
+		if a.Address != b.Address {
+			return a.Address < b.Address
+		}
+		return a.Port < b.Port
+	})
+	return statuses
+}
+
+// SetMemberHealth records a probe result in status.members. LastTransitionTime
+// is only changed when the health changes. It reports whether a matching entry
+// was found.
+func SetMemberHealth(lb *v1alpha1.LoadBalancer, listener, address string, port int32, health, message string, now time.Time) bool {
+	for i := range lb.Status.Members {
+		s := &lb.Status.Members[i]
+		if s.Listener != listener || s.Address != address || s.Port != port {
+			continue
+		}
+		if s.Health != health || s.LastTransitionTime == nil {
+			t := metav1.NewTime(now)
+			s.LastTransitionTime = &t
+		}
+		s.Health, s.Message = health, message
+		return true
+	}
+	return false
+}
+
+func validateMembers(lb *v1alpha1.LoadBalancer) []error {
+	var errs []error
+	if len(lb.Spec.Members) == 0 && len(lb.Spec.PoolMembers) == 0 {
+		errs = append(errs, errors.New("at least one of members and poolMembers must be set"))
+	}
+	seen := map[string]bool{}
+	for i := range lb.Spec.Members {
+		m := &lb.Spec.Members[i]
+		switch {
+		case m.Address == "" && m.MachineRef == nil:
+			errs = append(errs, fmt.Errorf("member %d: one of address and machineRef must be set", i))
+			continue
+		case m.Address != "" && m.MachineRef != nil:
+			errs = append(errs, fmt.Errorf("member %d: address and machineRef are mutually exclusive", i))
+			continue
+		case m.Address != "":
+			if err := validateAddress(m.Address); err != nil {
+				errs = append(errs, fmt.Errorf("member %d: %w", i, err))
+			}
+		}
+		key := memberKey(m)
+		if seen[key] {
+			errs = append(errs, fmt.Errorf("member %d: duplicate member %s", i, key))
+		}
+		seen[key] = true
+	}
+	if _, err := parsePoolMembers(lb.Spec.PoolMembers); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func parsePoolMembers(entries []string) ([]v1alpha1.LoadBalancerPoolMember, error) {
+	var members []v1alpha1.LoadBalancerPoolMember
+	var errs []error
+	for _, s := range entries {
+		m, err := ParsePoolMember(s)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		members = append(members, m)
+	}
+	return members, errors.Join(errs...)
+}
+
+func validateAddress(s string) error {
+	if _, err := netip.ParseAddr(s); err == nil {
+		return nil
+	}
+	// A name ending in a numeric label is a mistyped IP address, e.g. 10.0.0.300.
+	if len(s) > 253 || !hostnameRegexp.MatchString(s) || isNumeric(s[strings.LastIndex(s, ".")+1:]) {
+		return fmt.Errorf("invalid address %q: must be an IP address or a lower case host name", s)
+	}
+	return nil
+}
+
+func isNumeric(s string) bool {
+	return strings.Trim(s, "0123456789") == ""
+}
+
+// memberKey identifies a member by its address or machine and its port.
+func memberKey(m *v1alpha1.LoadBalancerPoolMember) string {
+	target := m.Address
+	if m.MachineRef != nil {
+		target = "machine/" + m.MachineRef.Name
+	} else if addr, err := netip.ParseAddr(m.Address); err == nil {
+		target = addr.String()
+	}
+	if m.Port == 0 {
+		return target
+	}
+	return net.JoinHostPort(target, strconv.Itoa(int(m.Port)))
+}
+
+func memberStatusKey(listener, address string, port int32) string {
+	return listener + "/" + address + "/" + strconv.Itoa(int(port))
+}
+
+func machineAddress(m *v1alpha1.Machine) string {
+	for _, addrs := range [][]string{m.Status.PrivateIPAddresses, m.Status.IPAddresses, m.Status.IPv6Addresses} {
+		for _, a := range addrs {
+			if a != "" {
+				return a
+			}
+		}
+	}
+	return ""
+}
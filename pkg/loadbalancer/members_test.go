@@ -0,0 +1,240 @@
+package loadbalancer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func weight(w int32) *int32 {
+	return &w
+}
+
+func machineMember(name string) v1alpha1.LoadBalancerPoolMember {
+	return v1alpha1.LoadBalancerPoolMember{MachineRef: &v1alpha1.MachineReference{Name: name}}
+}
+
+func TestParsePoolMember(t *testing.T) {
+	tests := []struct {
+		in      string
+		address string
+		port    int32
+		// wantErr is part of the error, "" for none.
+		wantErr string
+	}{
+		{in: "10.0.0.1", address: "10.0.0.1"},
+		{in: "10.0.0.1:6443", address: "10.0.0.1", port: 6443},
+		{in: "2001:db8::1", address: "2001:db8::1"},
+		{in: "[2001:db8::1]:6443", address: "2001:db8::1", port: 6443},
+		{in: "cp-0.example.com", address: "cp-0.example.com"},
+		{in: "cp-0.example.com:6443", address: "cp-0.example.com", port: 6443},
+		{in: "10.0.0.1:0", wantErr: `invalid port "0"`},
+		{in: "10.0.0.1:65536", wantErr: `invalid port "65536"`},
+		{in: "10.0.0.1:https", wantErr: `invalid port "https"`},
+		{in: "[2001:db8::1]", wantErr: `invalid pool member "[2001:db8::1]"`},
+		{in: "CP-0.example.com", wantErr: "must be an IP address or a lower case host name"},
+		{in: "10.0.0.300", wantErr: "must be an IP address or a lower case host name"},
+		{in: "", wantErr: "must be an IP address or a lower case host name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			m, err := ParsePoolMember(tt.in)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if err == nil && (m.Address != tt.address || m.Port != tt.port || m.MachineRef != nil) {
+				t.Errorf("member = %+v, want address %q port %d", m, tt.address, tt.port)
+			}
+		})
+	}
+}
+
+func TestConvertPoolMembers(t *testing.T) {
+	lb := newLoadBalancer()
+	lb.Spec.Members = []v1alpha1.LoadBalancerPoolMember{{Address: "10.0.0.11", Weight: weight(5)}, machineMember("cp-0")}
+	lb.Spec.PoolMembers = []string{"10.0.0.11", "10.0.0.12:6443", "10.0.0.12:6443", "2001:db8:0::1"}
+	if err := ConvertPoolMembers(lb); err != nil {
+		t.Fatal(err)
+	}
+	want := []v1alpha1.LoadBalancerPoolMember{
+		{Address: "10.0.0.11", Weight: weight(5)},
+		machineMember("cp-0"),
+		{Address: "10.0.0.12", Port: 6443},
+		{Address: "2001:db8:0::1"},
+	}
+	if !reflect.DeepEqual(lb.Spec.Members, want) {
+		t.Errorf("members = %+v, want %+v", lb.Spec.Members, want)
+	}
+	if lb.Spec.PoolMembers != nil {
+		t.Errorf("poolMembers = %q, want none", lb.Spec.PoolMembers)
+	}
+
+	// Nothing changes when an entry does not parse.
+	lb = newLoadBalancer()
+	lb.Spec.PoolMembers = []string{"10.0.0.12", "10.0.0.13:http"}
+	if err := ConvertPoolMembers(lb); err == nil {
+		t.Error("an invalid entry was converted")
+	}
+	if len(lb.Spec.Members) != 1 || len(lb.Spec.PoolMembers) != 2 {
+		t.Errorf("members %+v and poolMembers %q changed", lb.Spec.Members, lb.Spec.PoolMembers)
+	}
+}
+
+func TestMembers(t *testing.T) {
+	lb := newLoadBalancer()
+	lb.Spec.Members = []v1alpha1.LoadBalancerPoolMember{
+		{Address: "10.0.0.11", Weight: weight(0), AdminState: v1alpha1.LoadBalancerMemberDrain},
+		{Address: "2001:db8::1"},
+	}
+	lb.Spec.PoolMembers = []string{"10.0.0.11", "[2001:db8:0::1]:443", "2001:db8:0::1"}
+	got, err := Members(lb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []v1alpha1.LoadBalancerPoolMember{
+		{Address: "10.0.0.11", Weight: weight(0), AdminState: v1alpha1.LoadBalancerMemberDrain},
+		{Address: "2001:db8::1", Weight: weight(1), AdminState: v1alpha1.LoadBalancerMemberEnabled},
+		{Address: "2001:db8:0::1", Port: 443, Weight: weight(1), AdminState: v1alpha1.LoadBalancerMemberEnabled},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("members = %+v, want %+v", got, want)
+	}
+	if lb.Spec.Members[1].Weight != nil || lb.Spec.Members[1].AdminState != "" {
+		t.Error("applying the defaults changed spec.members")
+	}
+}
+
+func TestValidateMembers(t *testing.T) {
+	tests := []struct {
+		name        string
+		members     []v1alpha1.LoadBalancerPoolMember
+		poolMembers []string
+		// want is part of the error, "" for none.
+		want string
+	}{
+		{name: "members", members: []v1alpha1.LoadBalancerPoolMember{{Address: "10.0.0.11"}, {Address: "10.0.0.11", Port: 8443}, machineMember("cp-0")}},
+		{name: "pool members", poolMembers: []string{"10.0.0.11"}},
+		{name: "none", want: "at least one of members and poolMembers must be set"},
+		{name: "neither address nor machine", members: []v1alpha1.LoadBalancerPoolMember{{Port: 80}}, want: "member 0: one of address and machineRef must be set"},
+		{
+			name:    "address and machine",
+			members: []v1alpha1.LoadBalancerPoolMember{{Address: "10.0.0.11", MachineRef: &v1alpha1.MachineReference{Name: "cp-0"}}},
+			want:    "member 0: address and machineRef are mutually exclusive",
+		},
+		{name: "invalid address", members: []v1alpha1.LoadBalancerPoolMember{{Address: "10.0.0.300"}}, want: `member 0: invalid address "10.0.0.300"`},
+		{name: "duplicate address", members: []v1alpha1.LoadBalancerPoolMember{{Address: "2001:db8::1"}, {Address: "2001:db8:0::1"}}, want: "member 1: duplicate member 2001:db8::1"},
+		{name: "duplicate machine", members: []v1alpha1.LoadBalancerPoolMember{machineMember("cp-0"), machineMember("cp-0")}, want: "member 1: duplicate member machine/cp-0"},
+		{name: "invalid pool member", poolMembers: []string{"10.0.0.11:0"}, want: `invalid pool member "10.0.0.11:0"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lb := newLoadBalancer()
+			lb.Spec.Members, lb.Spec.PoolMembers = tt.members, tt.poolMembers
+			err := Validate(lb)
+			if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveMembers(t *testing.T) {
+	machine := func(namespace, name string, status v1alpha1.MachineStatus) v1alpha1.Machine {
+		return v1alpha1.Machine{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}, Status: status}
+	}
+	machines := []v1alpha1.Machine{
+		machine("default", "private", v1alpha1.MachineStatus{
+			PrivateIPAddresses: []string{"", "10.0.0.21"}, IPAddresses: []string{"192.0.2.21"}, IPv6Addresses: []string{"2001:db8::21"},
+		}),
+		machine("default", "public", v1alpha1.MachineStatus{IPAddresses: []string{"192.0.2.22"}, IPv6Addresses: []string{"2001:db8::22"}}),
+		machine("default", "ipv6", v1alpha1.MachineStatus{IPv6Addresses: []string{"2001:db8::23"}}),
+		machine("default", "pending", v1alpha1.MachineStatus{}),
+		machine("tenant", "other", v1alpha1.MachineStatus{IPAddresses: []string{"192.0.2.24"}}),
+	}
+	lb := newLoadBalancer()
+	lb.Spec.Members = []v1alpha1.LoadBalancerPoolMember{
+		{Address: "10.0.0.11"},
+		machineMember("private"),
+		machineMember("public"),
+		machineMember("ipv6"),
+		machineMember("pending"),
+		machineMember("other"),
+	}
+
+	backends, err := ResolveMembers(lb, machines)
+	if err == nil || !strings.Contains(err.Error(), `machine "pending" has no IP address yet`) || !strings.Contains(err.Error(), `machine "other" not found`) {
+		t.Errorf("error = %v, want pending without an address and other not found", err)
+	}
+	var got []string
+	for _, b := range backends {
+		got = append(got, b.Machine+"="+b.Address)
+	}
+	want := []string{"=10.0.0.11", "private=10.0.0.21", "public=192.0.2.22", "ipv6=2001:db8::23"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("backends = %q, want %q", got, want)
+	}
+	if addrs := Addresses(append(backends, backends[0])); !reflect.DeepEqual(addrs, []string{"10.0.0.11", "10.0.0.21", "192.0.2.22", "2001:db8::23"}) {
+		t.Errorf("addresses = %q", addrs)
+	}
+}
+
+func TestMemberStatuses(t *testing.T) {
+	since := metav1.NewTime(time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC))
+	backends := []Backend{
+		{LoadBalancerPoolMember: v1alpha1.LoadBalancerPoolMember{Address: "10.0.0.12", AdminState: v1alpha1.LoadBalancerMemberDrain}, Address: "10.0.0.12"},
+		{LoadBalancerPoolMember: v1alpha1.LoadBalancerPoolMember{MachineRef: &v1alpha1.MachineReference{Name: "cp-0"}, Port: 8443}, Address: "10.0.0.11", Machine: "cp-0"},
+	}
+
+	lb := newLoadBalancer(
+		v1alpha1.LoadBalancerListener{Name: "web", Port: 443},
+		v1alpha1.LoadBalancerListener{Name: "api", Port: 6443, BackendPort: 16443},
+	)
+	lb.Status.Members = []v1alpha1.LoadBalancerMemberStatus{
+		{Address: "10.0.0.12", Listener: "api", Port: 16443, Health: v1alpha1.LoadBalancerMemberUnhealthy, Message: "connection refused", LastTransitionTime: &since},
+		// The port changed, so the entry starts over.
+		{Address: "10.0.0.12", Listener: "web", Port: 8443, Health: v1alpha1.LoadBalancerMemberHealthy},
+	}
+	want := []v1alpha1.LoadBalancerMemberStatus{
+		{Address: "10.0.0.11", Machine: "cp-0", Listener: "api", Port: 8443, Health: v1alpha1.LoadBalancerMemberUnknown, AdminState: v1alpha1.LoadBalancerMemberEnabled},
+		{Address: "10.0.0.12", Listener: "api", Port: 16443, Health: v1alpha1.LoadBalancerMemberUnhealthy, AdminState: v1alpha1.LoadBalancerMemberDrain, Message: "connection refused", LastTransitionTime: &since},
+		{Address: "10.0.0.11", Machine: "cp-0", Listener: "web", Port: 8443, Health: v1alpha1.LoadBalancerMemberUnknown, AdminState: v1alpha1.LoadBalancerMemberEnabled},
+		{Address: "10.0.0.12", Listener: "web", Port: 443, Health: v1alpha1.LoadBalancerMemberUnknown, AdminState: v1alpha1.LoadBalancerMemberDrain},
+	}
+	if got := MemberStatuses(lb, backends); !reflect.DeepEqual(got, want) {
+		t.Errorf("statuses = %+v\nwant %+v", got, want)
+	}
+
+	// Without listeners there is one entry per backend, on the member port.
+	lb.Spec.Listeners = nil
+	lb.Status.Members = nil
+	got := MemberStatuses(lb, backends)
+	if len(got) != 2 || got[0].Address != "10.0.0.11" || got[0].Port != 8443 || got[1].Address != "10.0.0.12" || got[1].Port != 0 || got[1].Listener != "" {
+		t.Errorf("statuses without listeners = %+v", got)
+	}
+}
+
+func TestSetMemberHealth(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	lb := newLoadBalancer(v1alpha1.LoadBalancerListener{Name: "api", Port: 6443})
+	lb.Status.Members = MemberStatuses(lb, []Backend{{Address: "10.0.0.11"}})
+
+	if !SetMemberHealth(lb, "api", "10.0.0.11", 6443, v1alpha1.LoadBalancerMemberHealthy, "", now) {
+		t.Fatal("entry not found")
+	}
+	SetMemberHealth(lb, "api", "10.0.0.11", 6443, v1alpha1.LoadBalancerMemberHealthy, "still up", now.Add(time.Minute))
+	s := lb.Status.Members[0]
+	if s.Health != v1alpha1.LoadBalancerMemberHealthy || s.Message != "still up" || !s.LastTransitionTime.Time.Equal(now) {
+		t.Errorf("status = %+v, want healthy since %v", s, now)
+	}
+	SetMemberHealth(lb, "api", "10.0.0.11", 6443, v1alpha1.LoadBalancerMemberUnhealthy, "timeout", now.Add(2*time.Minute))
+	if s := lb.Status.Members[0]; !s.LastTransitionTime.Time.Equal(now.Add(2 * time.Minute)) {
+		t.Errorf("last transition = %v, want the time of the change", s.LastTransitionTime)
+	}
+	if SetMemberHealth(lb, "api", "10.0.0.11", 443, v1alpha1.LoadBalancerMemberHealthy, "", now) {
+		t.Error("found an entry for another port")
+	}
+}
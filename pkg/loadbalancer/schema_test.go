@@ -0,0 +1,148 @@
+package loadbalancer
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+// The CEL rules of the LoadBalancer CRD mirror checks made by Validate. These
+// tests keep the generated schema, its chart copy and the markers in the API
+// types in step.
+
+const (
+	crdFile   = "../../crds/vitistack.io_loadbalancers.yaml"
+	chartFile = "../../charts/vitistack-crds/templates/vitistack.io_loadbalancers.yaml"
+	typesFile = "../v1alpha1/load_balancer.go"
+)
+
+var markerRegexp = regexp.MustCompile(`\+kubebuilder:validation:XValidation:rule="(.*)",message="(.*)"$`)
+
+type validationRule struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type schema struct {
+	Properties  map[string]*schema `json:"properties"`
+	Items       *schema            `json:"items"`
+	MinItems    *int               `json:"minItems"`
+	MinLength   *int               `json:"minLength"`
+	Validations []validationRule   `json:"x-kubernetes-validations"`
+}
+
+func loadSchema(t *testing.T) *schema {
+	t.Helper()
+	data, err := os.ReadFile(crdFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chart, err := os.ReadFile(chartFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, chart) {
+		t.Errorf("%s differs from %s", chartFile, crdFile)
+	}
+	var crd struct {
+		Spec struct {
+			Versions []struct {
+				Schema struct {
+					OpenAPIV3Schema *schema `json:"openAPIV3Schema"`
+				} `json:"schema"`
+			} `json:"versions"`
+		} `json:"spec"`
+	}
+	if err := yaml.Unmarshal(data, &crd); err != nil {
+		t.Fatal(err)
+	}
+	if len(crd.Spec.Versions) != 1 {
+		t.Fatalf("%d versions, want 1", len(crd.Spec.Versions))
+	}
+	return crd.Spec.Versions[0].Schema.OpenAPIV3Schema
+}
+
+// lookup returns the schema at a dotted path, "[]" stands for the items of a list.
+func lookup(t *testing.T, s *schema, path string) *schema {
+	t.Helper()
+	for _, name := range strings.Split(path, ".") {
+		if s != nil && name == "[]" {
+			s = s.Items
+		} else if s != nil {
+			s = s.Properties[name]
+		}
+		if s == nil {
+			t.Fatalf("no schema at %s", path)
+		}
+	}
+	return s
+}
+
+func TestSchemaValidations(t *testing.T) {
+	root := loadSchema(t)
+	tests := []struct {
+		path    string
+		message string
+	}{
+		{path: "spec", message: "at least one of members and poolMembers must be set"},
+		{path: "spec.members.[]", message: "exactly one of address and machineRef must be set"},
+		{path: "spec.listeners.[]", message: "a UDP listener cannot have an HTTP or HTTPS health check"},
+	}
+	for _, tt := range tests {
+		var found bool
+		for _, v := range lookup(t, root, tt.path).Validations {
+			found = found || v.Message == tt.message
+		}
+		if !found {
+			t.Errorf("%s: no rule with message %q", tt.path, tt.message)
+		}
+	}
+
+	// An empty list or address would pass the has() checks above.
+	for _, path := range []string{"spec.members", "spec.poolMembers"} {
+		if s := lookup(t, root, path); s.MinItems == nil || *s.MinItems != 1 {
+			t.Errorf("%s: minItems = %v, want 1", path, s.MinItems)
+		}
+	}
+	if s := lookup(t, root, "spec.members.[].address"); s.MinLength == nil || *s.MinLength != 1 {
+		t.Errorf("spec.members.[].address: minLength = %v, want 1", s.MinLength)
+	}
+
+	// Every marker on the API types is rendered into the schema.
+	types, err := os.ReadFile(typesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered := map[validationRule]bool{}
+	var walk func(s *schema)
+	walk = func(s *schema) {
+		for _, v := range s.Validations {
+			rendered[v] = true
+		}
+		for _, p := range s.Properties {
+			walk(p)
+		}
+		if s.Items != nil {
+			walk(s.Items)
+		}
+	}
+	walk(root)
+	var markers int
+	for _, line := range strings.Split(string(types), "\n") {
+		m := markerRegexp.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		markers++
+		if v := (validationRule{Rule: m[1], Message: m[2]}); !rendered[v] {
+			t.Errorf("rule %q with message %q is not in %s", v.Rule, v.Message, crdFile)
+		}
+	}
+	if markers != len(rendered) {
+		t.Errorf("%d rules in %s, %d markers in %s", len(rendered), crdFile, markers, typesFile)
+	}
+}
@@ -0,0 +1,279 @@
+package loadbalancer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Secret type and keys of a TLS Secret, as in k8s.io/api/core/v1.
+const (
+	SecretTypeTLS    = "kubernetes.io/tls"
+	TLSCertKey       = "tls.crt"
+	TLSPrivateKeyKey = "tls.key"
+)
+
+// Cipher suites of the TLS 1.2 cipher policies in OpenSSL notation, after the
+// Mozilla server side TLS profiles. TLS 1.3 suites are not configurable.
+var cipherSuites = map[string][]string{
+	v1alpha1.LoadBalancerCipherPolicyModern: nil,
+	v1alpha1.LoadBalancerCipherPolicyIntermediate: {
+		"ECDHE-ECDSA-AES128-GCM-SHA256",
+		"ECDHE-RSA-AES128-GCM-SHA256",
+		"ECDHE-ECDSA-AES256-GCM-SHA384",
+		"ECDHE-RSA-AES256-GCM-SHA384",
+		"ECDHE-ECDSA-CHACHA20-POLY1305",
+		"ECDHE-RSA-CHACHA20-POLY1305",
+	},
+	v1alpha1.LoadBalancerCipherPolicyLegacy: {
+		"ECDHE-ECDSA-AES128-GCM-SHA256",
+		"ECDHE-RSA-AES128-GCM-SHA256",
+		"ECDHE-ECDSA-AES256-GCM-SHA384",
+		"ECDHE-RSA-AES256-GCM-SHA384",
+		"ECDHE-ECDSA-CHACHA20-POLY1305",
+		"ECDHE-RSA-CHACHA20-POLY1305",
+		"ECDHE-ECDSA-AES128-SHA256",
+		"ECDHE-RSA-AES128-SHA256",
+		"ECDHE-ECDSA-AES128-SHA",
+		"ECDHE-RSA-AES128-SHA",
+		"ECDHE-ECDSA-AES256-SHA",
+		"ECDHE-RSA-AES256-SHA",
+		"AES128-GCM-SHA256",
+		"AES256-GCM-SHA384",
+		"AES128-SHA256",
+		"AES128-SHA",
+		"AES256-SHA",
+	},
+}
+
+var tlsVersions = []string{"1.0", "1.1", "1.2", "1.3"}
+
+// CipherPolicy returns the cipher policy of a TLS configuration, applying the API default.
+func CipherPolicy(t *v1alpha1.LoadBalancerTLS) string {
+	if t.CipherPolicy == "" {
+		return v1alpha1.LoadBalancerCipherPolicyIntermediate
+	}
+	return t.CipherPolicy
+}
+
+// MinTLSVersion returns the minimum TLS version of a TLS configuration: the
+// configured one, 1.3 for the Modern cipher policy and 1.2 otherwise.
+func MinTLSVersion(t *v1alpha1.LoadBalancerTLS) string {
+	switch {
+	case t.MinVersion != "":
+		return t.MinVersion
+	case CipherPolicy(t) == v1alpha1.LoadBalancerCipherPolicyModern:
+		return "1.3"
+	}
+	return "1.2"
+}
+
+// CipherSuites returns the TLS 1.2 and older cipher suites of a cipher policy
+// in OpenSSL notation, strongest first. Modern allows TLS 1.3 only and
+// returns none.
+func CipherSuites(policy string) []string {
+	return slices.Clone(cipherSuites[policy])
+}
+
+// TLSCertificate is a certificate served by a listener and the host names it
+// must cover.
+type TLSCertificate struct {
+	SecretName string
+	// Hostnames the certificate is served for. Empty for a default
+	// certificate without host names.
+	Hostnames []string
+	// Default is set for the certificate served when SNI matches no host name.
+	Default bool
+}
+
+// TLSCertificates returns the certificates of a listener, the default one
+// first. It returns nil for listeners without TLS.
+func TLSCertificates(l *v1alpha1.LoadBalancerListener) []TLSCertificate {
+	if l.TLS == nil {
+		return nil
+	}
+	certs := []TLSCertificate{{SecretName: l.TLS.SecretRef.Name, Hostnames: l.TLS.Hostnames, Default: true}}
+	for _, sni := range l.TLS.SNI {
+		certs = append(certs, TLSCertificate{SecretName: sni.SecretRef.Name, Hostnames: sni.Hostnames})
+	}
+	return certs
+}
+
+// Certificate is a parsed certificate chain with a matching private key.
+type Certificate struct {
+	// Chain starts with the leaf certificate, followed by its issuers.
+	Chain []*x509.Certificate
+}
+
+// Leaf returns the first certificate of the chain.
+func (c *Certificate) Leaf() *x509.Certificate {
+	return c.Chain[0]
+}
+
+// ParseCertificate parses the data of a kubernetes.io/tls Secret. It checks
+// that the certificate chain parses, that the private key matches the leaf
+// certificate and that every certificate is signed by the next one.
+func ParseCertificate(secretType string, data map[string][]byte) (*Certificate, error) {
+	if secretType != SecretTypeTLS {
+		return nil, fmt.Errorf("secret type is %q, expected %q", secretType, SecretTypeTLS)
+	}
+	certPEM, keyPEM := data[TLSCertKey], data[TLSPrivateKeyKey]
+	if len(certPEM) == 0 || len(keyPEM) == 0 {
+		return nil, fmt.Errorf("secret must contain %s and %s", TLSCertKey, TLSPrivateKeyKey)
+	}
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		return nil, fmt.Errorf("invalid certificate or key: %w", err)
+	}
+
+	var chain []*x509.Certificate
+	for rest := certPEM; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("certificate %d: %w", len(chain), err)
+		}
+		chain = append(chain, cert)
+	}
+	for i := 0; i+1 < len(chain); i++ {
+		if err := chain[i].CheckSignatureFrom(chain[i+1]); err != nil {
+			return nil, fmt.Errorf("certificate %d (%s) is not signed by the next certificate in the chain (%s): %w",
+				i, chain[i].Subject, chain[i+1].Subject, err)
+		}
+	}
+	return &Certificate{Chain: chain}, nil
+}
+
+// Verify checks that every certificate in the chain is valid at now and that
+// the leaf certificate covers the host names. A wildcard host name such as
+// *.apps.example.com must be in the certificate as is. Host names are
+// compared case-insensitively.
+func (c *Certificate) Verify(hostnames []string, now time.Time) error {
+	var errs []error
+	for i, cert := range c.Chain {
+		switch {
+		case now.Before(cert.NotBefore):
+			errs = append(errs, fmt.Errorf("certificate %d (%s) is not valid before %s", i, cert.Subject, cert.NotBefore.UTC().Format(time.RFC3339)))
+		case now.After(cert.NotAfter):
+			errs = append(errs, fmt.Errorf("certificate %d (%s) expired at %s", i, cert.Subject, cert.NotAfter.UTC().Format(time.RFC3339)))
+		}
+	}
+	leaf := c.Leaf()
+	for _, h := range hostnames {
+		h = strings.ToLower(h)
+		if strings.HasPrefix(h, "*.") {
+			if !slices.ContainsFunc(leaf.DNSNames, func(name string) bool { return strings.ToLower(name) == h }) {
+				errs = append(errs, fmt.Errorf("certificate does not cover host name %q", h))
+			}
+		} else if err := leaf.VerifyHostname(h); err != nil {
+			errs = append(errs, fmt.Errorf("certificate does not cover host name %q", h))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CertificateStatus parses and verifies the Secret of a listener certificate
+// and returns its status entry. The Secret data may be nil when the Secret
+// does not exist.
+func CertificateStatus(listener string, cert TLSCertificate, secretType string, data map[string][]byte, now time.Time) v1alpha1.LoadBalancerCertificateStatus {
+	s := v1alpha1.LoadBalancerCertificateStatus{Listener: listener, SecretName: cert.SecretName}
+	if data == nil {
+		s.Message = fmt.Sprintf("secret %q not found", cert.SecretName)
+		return s
+	}
+	c, err := ParseCertificate(secretType, data)
+	if err != nil {
+		s.Message = fmt.Sprintf("secret %q: %v", cert.SecretName, err)
+		return s
+	}
+	leaf := c.Leaf()
+	notBefore, notAfter := metav1.NewTime(leaf.NotBefore), metav1.NewTime(leaf.NotAfter)
+	s.DNSNames, s.NotBefore, s.NotAfter = slices.Clone(leaf.DNSNames), &notBefore, &notAfter
+	if err := c.Verify(cert.Hostnames, now); err != nil {
+		s.Message = fmt.Sprintf("secret %q: %v", cert.SecretName, err)
+		return s
+	}
+	s.Valid = true
+	return s
+}
+
+// SortCertificateStatuses sorts status entries by listener and secret name.
+func SortCertificateStatuses(statuses []v1alpha1.LoadBalancerCertificateStatus) {
+	sort.SliceStable(statuses, func(i, j int) bool {
+		if statuses[i].Listener != statuses[j].Listener {
+			return statuses[i].Listener < statuses[j].Listener
+		}
+		return statuses[i].SecretName < statuses[j].SecretName
+	})
+}
+
+// NextExpiry returns the earliest expiry of the certificates in the status,
+// so a controller can requeue before it. It returns the zero time when no
+// expiry is known.
+func NextExpiry(statuses []v1alpha1.LoadBalancerCertificateStatus) time.Time {
+	var next time.Time
+	for _, s := range statuses {
+		if s.NotAfter != nil && (next.IsZero() || s.NotAfter.Time.Before(next)) {
+			next = s.NotAfter.Time
+		}
+	}
+	return next
+}
+
+func validateTLS(l *v1alpha1.LoadBalancerListener) []error {
+	if l.TLS == nil {
+		return nil
+	}
+	var errs []error
+	switch Protocol(l) {
+	case v1alpha1.LoadBalancerProtocolHTTPS, v1alpha1.LoadBalancerProtocolTCP:
+	default:
+		errs = append(errs, fmt.Errorf("listener %q: TLS is only possible on HTTPS and TCP listeners", l.Name))
+	}
+	if CipherPolicy(l.TLS) == v1alpha1.LoadBalancerCipherPolicyModern &&
+		slices.Index(tlsVersions, MinTLSVersion(l.TLS)) < slices.Index(tlsVersions, "1.3") {
+		errs = append(errs, fmt.Errorf("listener %q: the Modern cipher policy requires TLS 1.3, minVersion is %s", l.Name, l.TLS.MinVersion))
+	}
+	seen := map[string]bool{}
+	for _, cert := range TLSCertificates(l) {
+		for _, h := range cert.Hostnames {
+			if err := validateTLSHostname(h); err != nil {
+				errs = append(errs, fmt.Errorf("listener %q: %w", l.Name, err))
+			}
+			// The default certificate may repeat an SNI host name, SNI entries may not.
+			if !cert.Default {
+				if seen[strings.ToLower(h)] {
+					errs = append(errs, fmt.Errorf("listener %q: SNI host name %q is mapped more than once", l.Name, h))
+				}
+				seen[strings.ToLower(h)] = true
+			}
+		}
+	}
+	return errs
+}
+
+func validateTLSHostname(h string) error {
+	name, wildcard := strings.CutPrefix(strings.ToLower(h), "*.")
+	switch {
+	case len(h) > 253 || strings.Contains(name, "*") || !hostnameRegexp.MatchString(name):
+		return fmt.Errorf("invalid TLS host name %q: must be a DNS name, optionally with a leading *. wildcard", h)
+	case wildcard && !strings.Contains(name, "."):
+		return fmt.Errorf("invalid TLS host name %q: a wildcard must not cover a top level domain", h)
+	}
+	return nil
+}
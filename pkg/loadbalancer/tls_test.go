@@ -0,0 +1,192 @@
+package loadbalancer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// issued is a generated certificate with its key.
+type issued struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// issue creates a certificate valid from the hour before now to the hour
+// after it, signed by parent or self-signed when parent is nil.
+func issue(t *testing.T, name string, parent *issued, dnsNames ...string) *issued {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		DNSNames:              dnsNames,
+		IsCA:                  len(dnsNames) == 0,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &issued{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func keyPEM(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+func secretData(t *testing.T, key *ecdsa.PrivateKey, chain ...*issued) map[string][]byte {
+	t.Helper()
+	var certs []byte
+	for _, c := range chain {
+		certs = append(certs, c.pem...)
+	}
+	return map[string][]byte{TLSCertKey: certs, TLSPrivateKeyKey: keyPEM(t, key)}
+}
+
+func TestParseCertificate(t *testing.T) {
+	root := issue(t, "root", nil)
+	intermediate := issue(t, "intermediate", root)
+	leaf := issue(t, "web", intermediate, "web.example.com")
+	other := issue(t, "other", nil, "other.example.com")
+
+	tests := []struct {
+		name       string
+		secretType string
+		data       map[string][]byte
+		// want is part of the error, "" for none.
+		want string
+	}{
+		{name: "chain", data: secretData(t, leaf.key, leaf, intermediate, root)},
+		{name: "leaf only", data: secretData(t, leaf.key, leaf)},
+		{name: "wrong secret type", secretType: "Opaque", data: secretData(t, leaf.key, leaf), want: `secret type is "Opaque", expected "kubernetes.io/tls"`},
+		{name: "no certificate", data: map[string][]byte{TLSPrivateKeyKey: keyPEM(t, leaf.key)}, want: "secret must contain tls.crt and tls.key"},
+		{name: "no key", data: map[string][]byte{TLSCertKey: leaf.pem}, want: "secret must contain tls.crt and tls.key"},
+		{name: "key of another certificate", data: secretData(t, other.key, leaf, intermediate), want: "invalid certificate or key"},
+		{name: "chain out of order", data: secretData(t, leaf.key, leaf, root, intermediate), want: "certificate 0 (CN=web) is not signed by the next certificate in the chain (CN=root)"},
+		{name: "unrelated issuer", data: secretData(t, leaf.key, leaf, other), want: "is not signed by the next certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secretType := tt.secretType
+			if secretType == "" {
+				secretType = SecretTypeTLS
+			}
+			c, err := ParseCertificate(secretType, tt.data)
+			if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Fatalf("error = %v, want %q", err, tt.want)
+			}
+			if err == nil && !c.Leaf().Equal(leaf.cert) {
+				t.Errorf("leaf = %s, want %s", c.Leaf().Subject, leaf.cert.Subject)
+			}
+		})
+	}
+}
+
+func TestCertificateStatus(t *testing.T) {
+	now := time.Now()
+	ca := issue(t, "ca", nil)
+	leaf := issue(t, "web", ca, "web.example.com")
+	data := secretData(t, leaf.key, leaf, ca)
+	cert := TLSCertificate{SecretName: "web-tls", Hostnames: []string{"web.example.com"}, Default: true}
+
+	s := CertificateStatus("https", cert, SecretTypeTLS, data, now)
+	if !s.Valid || s.Message != "" || s.Listener != "https" || s.SecretName != "web-tls" {
+		t.Errorf("status = %+v, want a valid certificate", s)
+	}
+	if len(s.DNSNames) != 1 || s.DNSNames[0] != "web.example.com" || s.NotAfter == nil || !s.NotAfter.Time.Equal(leaf.cert.NotAfter) {
+		t.Errorf("status = %+v, want the DNS names and validity of the leaf", s)
+	}
+
+	tests := []struct {
+		name       string
+		cert       TLSCertificate
+		secretType string
+		data       map[string][]byte
+		now        time.Time
+		message    string
+		// dates is set when the certificate parsed.
+		dates bool
+	}{
+		{name: "missing secret", cert: cert, secretType: SecretTypeTLS, now: now, message: `secret "web-tls" not found`},
+		{name: "wrong secret type", cert: cert, secretType: "Opaque", data: data, now: now, message: `secret "web-tls": secret type is "Opaque"`},
+		{
+			name: "host name not covered", secretType: SecretTypeTLS, data: data, now: now, dates: true,
+			cert:    TLSCertificate{SecretName: "web-tls", Hostnames: []string{"api.example.com"}},
+			message: `secret "web-tls": certificate does not cover host name "api.example.com"`,
+		},
+		{name: "expired", cert: cert, secretType: SecretTypeTLS, data: data, now: now.Add(2 * time.Hour), dates: true, message: "expired at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := CertificateStatus("https", tt.cert, tt.secretType, tt.data, tt.now)
+			if s.Valid || !strings.Contains(s.Message, tt.message) {
+				t.Errorf("status = %+v, want invalid with message %q", s, tt.message)
+			}
+			if (s.NotAfter != nil) != tt.dates {
+				t.Errorf("not after = %v, want set %v", s.NotAfter, tt.dates)
+			}
+		})
+	}
+}
+
+func TestVerifyHostnames(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	c := &Certificate{Chain: []*x509.Certificate{{
+		NotBefore: now.Add(-time.Hour),
+		NotAfter:  now.Add(time.Hour),
+		DNSNames:  []string{"Web.Example.com", "*.Apps.Example.COM"},
+	}}}
+
+	tests := []struct {
+		hostname string
+		ok       bool
+	}{
+		{"web.example.com", true},
+		{"WEB.EXAMPLE.COM", true},
+		{"*.apps.example.com", true},
+		{"*.APPS.example.com", true},
+		{"shop.apps.example.com", true},
+		{"Shop.Apps.Example.Com", true},
+		{"api.example.com", false},
+		{"*.example.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.hostname, func(t *testing.T) {
+			if err := c.Verify([]string{tt.hostname}, now); (err == nil) != tt.ok {
+				t.Errorf("Verify(%q) = %v, want ok %v", tt.hostname, err, tt.ok)
+			}
+		})
+	}
+
+	if err := c.Verify(nil, now.Add(2*time.Hour)); err == nil {
+		t.Error("an expired certificate must be reported")
+	}
+}
@@ -0,0 +1,432 @@
+// Package machinedeployment plans MachineDeployment rollouts. Given a
+// MachineDeployment and the MachineSets it owns, it decides which MachineSet
+// to create, how to scale each set and which old sets to prune, and it
+// computes the rollout progress conditions.
+package machinedeployment
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/vitistack/crds/pkg/machineset"
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// DefaultRevisionHistoryLimit is used when spec.revisionHistoryLimit is not set.
+const DefaultRevisionHistoryLimit int32 = 1
+
+// DefaultProgressDeadlineSeconds is used when spec.progressDeadlineSeconds is not set.
+const DefaultProgressDeadlineSeconds int32 = 600
+
+var (
+	defaultMaxSurge       = intstr.FromInt32(1)
+	defaultMaxUnavailable = intstr.FromInt32(0)
+)
+
+// ScaleAction sets the replica count of an existing MachineSet.
+type ScaleAction struct {
+	// Name of the MachineSet.
+	Name string
+	// From is the current spec.replicas.
+	From int32
+	// To is the new spec.replicas.
+	To int32
+}
+
+// RolloutPlan is the set of changes that moves a deployment one step closer to
+// its desired state. Controllers apply it and call Plan again on the next
+// reconcile.
+type RolloutPlan struct {
+	// NewMachineSet is the set to create for the current template, if it does not exist yet.
+	NewMachineSet *v1alpha1.MachineSet
+	// Scale lists replica changes for existing sets, sorted by name.
+	Scale []ScaleAction
+	// Delete lists old sets to remove because they exceed the revision history limit.
+	Delete []string
+	// Complete is true when every machine runs the current template and is available.
+	Complete bool
+}
+
+// Empty reports whether the plan requires no changes.
+func (p *RolloutPlan) Empty() bool {
+	return p.NewMachineSet == nil && len(p.Scale) == 0 && len(p.Delete) == 0
+}
+
+// ResolveFenceposts returns the absolute maxSurge and maxUnavailable for the
+// deployment. Percentages are rounded up for surge and down for unavailable,
+// and both being zero falls back to maxUnavailable of one so a rollout can
+// always make progress.
+func ResolveFenceposts(md *v1alpha1.MachineDeployment) (maxSurge, maxUnavailable int32, err error) {
+	desired := int(machineset.Replicas(md.Spec.Replicas))
+	surge, unavailable := &defaultMaxSurge, &defaultMaxUnavailable
+	if ru := md.Spec.Strategy.RollingUpdate; ru != nil {
+		surge = intstr.ValueOrDefault(ru.MaxSurge, defaultMaxSurge)
+		unavailable = intstr.ValueOrDefault(ru.MaxUnavailable, defaultMaxUnavailable)
+	}
+
+	s, err := intstr.GetScaledValueFromIntOrPercent(surge, desired, true)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid maxSurge: %w", err)
+	}
+	u, err := intstr.GetScaledValueFromIntOrPercent(unavailable, desired, false)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid maxUnavailable: %w", err)
+	}
+	if s < 0 || u < 0 {
+		return 0, 0, fmt.Errorf("maxSurge and maxUnavailable must not be negative")
+	}
+	if s == 0 && u == 0 {
+		u = 1
+	}
+	if u > desired {
+		u = desired
+	}
+	return int32(s), int32(u), nil
+}
+
+// OwnedMachineSets returns the sets controlled by the deployment, falling back
+// to the deployment name label for sets without an owner reference.
+func OwnedMachineSets(md *v1alpha1.MachineDeployment, sets []v1alpha1.MachineSet) []*v1alpha1.MachineSet {
+	var owned []*v1alpha1.MachineSet
+	for i := range sets {
+		ms := &sets[i]
+		if ms.Namespace != md.Namespace || ms.DeletionTimestamp != nil {
+			continue
+		}
+		if ref := metav1.GetControllerOf(ms); ref != nil {
+			if ref.UID == md.UID && ref.Kind == "MachineDeployment" {
+				owned = append(owned, ms)
+			}
+			continue
+		}
+		if ms.Labels[v1alpha1.MachineDeploymentNameLabel] == md.Name {
+			owned = append(owned, ms)
+		}
+	}
+	return owned
+}
+
+// SplitMachineSets finds the set created from the deployment's current
+// template and returns it along with all other (old) sets, oldest revision first.
+func SplitMachineSets(md *v1alpha1.MachineDeployment, owned []*v1alpha1.MachineSet) (newMS *v1alpha1.MachineSet, oldSets []*v1alpha1.MachineSet, err error) {
+	hash, err := machineset.TemplateHash(&md.Spec.Template)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, ms := range owned {
+		if ms.Labels[v1alpha1.MachineTemplateHashLabel] == hash && (newMS == nil || Revision(ms) > Revision(newMS)) {
+			if newMS != nil {
+				oldSets = append(oldSets, newMS)
+			}
+			newMS = ms
+			continue
+		}
+		oldSets = append(oldSets, ms)
+	}
+	sortByRevision(oldSets)
+	return newMS, oldSets, nil
+}
+
+// Revision returns the rollout revision recorded on a MachineSet, or 0.
+func Revision(ms *v1alpha1.MachineSet) int64 {
+	v, err := strconv.ParseInt(ms.Annotations[v1alpha1.MachineDeploymentRevisionAnnotation], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// NewMachineSet builds the MachineSet for the deployment's current template.
+func NewMachineSet(md *v1alpha1.MachineDeployment, replicas int32, revision int64) (*v1alpha1.MachineSet, error) {
+	hash, err := machineset.TemplateHash(&md.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	template := md.Spec.Template.DeepCopy()
+	if template.Metadata.Labels == nil {
+		template.Metadata.Labels = map[string]string{}
+	}
+	template.Metadata.Labels[v1alpha1.MachineTemplateHashLabel] = hash
+	template.Metadata.Labels[v1alpha1.MachineDeploymentNameLabel] = md.Name
+
+	selector := md.Spec.Selector.DeepCopy()
+	if selector.MatchLabels == nil {
+		selector.MatchLabels = map[string]string{}
+	}
+	selector.MatchLabels[v1alpha1.MachineTemplateHashLabel] = hash
+
+	setLabels := map[string]string{}
+	for k, v := range md.Spec.Template.Metadata.Labels {
+		setLabels[k] = v
+	}
+	setLabels[v1alpha1.MachineTemplateHashLabel] = hash
+	setLabels[v1alpha1.MachineDeploymentNameLabel] = md.Name
+
+	return &v1alpha1.MachineSet{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "MachineSet",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      md.Name + "-" + hash,
+			Namespace: md.Namespace,
+			Labels:    setLabels,
+			Annotations: map[string]string{
+				v1alpha1.MachineDeploymentRevisionAnnotation: strconv.FormatInt(revision, 10),
+			},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(md, v1alpha1.GroupVersion.WithKind("MachineDeployment"))},
+		},
+		Spec: v1alpha1.MachineSetSpec{
+			Replicas:        &replicas,
+			MinReadySeconds: md.Spec.MinReadySeconds,
+			DeletePolicy:    deletePolicy(md),
+			Selector:        *selector,
+			Template:        *template,
+		},
+	}, nil
+}
+
+// Plan computes the next rollout step for the deployment. owned must contain
+// the MachineSets controlled by the deployment (see OwnedMachineSets).
+func Plan(md *v1alpha1.MachineDeployment, owned []*v1alpha1.MachineSet) (*RolloutPlan, error) {
+	newMS, oldSets, err := SplitMachineSets(md, owned)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &RolloutPlan{}
+	plan.Complete = isComplete(md, newMS, oldSets)
+	plan.Delete = setsToPrune(md, oldSets)
+	if md.Spec.Paused || plan.Complete {
+		return plan, nil
+	}
+
+	switch md.Spec.Strategy.Type {
+	case v1alpha1.MachineDeploymentStrategyRecreate:
+		err = planRecreate(md, newMS, oldSets, plan)
+	case "", v1alpha1.MachineDeploymentStrategyRollingUpdate:
+		err = planRollingUpdate(md, newMS, oldSets, plan)
+	default:
+		err = fmt.Errorf("unknown strategy type %q", md.Spec.Strategy.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(plan.Scale, func(i, j int) bool { return plan.Scale[i].Name < plan.Scale[j].Name })
+	return plan, nil
+}
+
+func planRecreate(md *v1alpha1.MachineDeployment, newMS *v1alpha1.MachineSet, oldSets []*v1alpha1.MachineSet, plan *RolloutPlan) error {
+	desired := machineset.Replicas(md.Spec.Replicas)
+
+	// Scale every old set to zero and wait for its machines to be gone
+	// before any new machine is created.
+	oldActive := false
+	for _, ms := range oldSets {
+		if replicas := machineset.Replicas(ms.Spec.Replicas); replicas > 0 {
+			plan.Scale = append(plan.Scale, ScaleAction{Name: ms.Name, From: replicas, To: 0})
+		}
+		if machineset.Replicas(ms.Spec.Replicas) > 0 || ms.Status.Replicas > 0 {
+			oldActive = true
+		}
+	}
+	if oldActive {
+		return nil
+	}
+
+	if newMS == nil {
+		created, err := NewMachineSet(md, desired, nextRevision(oldSets))
+		if err != nil {
+			return err
+		}
+		plan.NewMachineSet = created
+		return nil
+	}
+	if current := machineset.Replicas(newMS.Spec.Replicas); current != desired {
+		plan.Scale = append(plan.Scale, ScaleAction{Name: newMS.Name, From: current, To: desired})
+	}
+	return nil
+}
+
+func planRollingUpdate(md *v1alpha1.MachineDeployment, newMS *v1alpha1.MachineSet, oldSets []*v1alpha1.MachineSet, plan *RolloutPlan) error {
+	desired := machineset.Replicas(md.Spec.Replicas)
+	maxSurge, maxUnavailable, err := ResolveFenceposts(md)
+	if err != nil {
+		return err
+	}
+
+	var newReplicas, newAvailable int32
+	if newMS != nil {
+		newReplicas = machineset.Replicas(newMS.Spec.Replicas)
+		newAvailable = newMS.Status.AvailableReplicas
+	}
+	total := newReplicas + specReplicas(oldSets)
+
+	// Step 1: scale the new set up within the surge budget. A scale up is
+	// returned on its own so the next step sees the resulting machines.
+	target := newReplicas
+	switch {
+	case newReplicas > desired:
+		target = desired
+	case newReplicas < desired:
+		if room := desired + maxSurge - total; room > 0 {
+			target = newReplicas + min(room, desired-newReplicas)
+		}
+	}
+	if newMS == nil {
+		if len(oldSets) == 0 {
+			target = desired
+		}
+		created, err := NewMachineSet(md, target, nextRevision(oldSets))
+		if err != nil {
+			return err
+		}
+		plan.NewMachineSet = created
+		return nil
+	}
+	if target != newReplicas {
+		plan.Scale = append(plan.Scale, ScaleAction{Name: newMS.Name, From: newReplicas, To: target})
+		if target > newReplicas {
+			return nil
+		}
+	}
+
+	// Step 2: scale old sets down without dropping below the minimum
+	// number of available machines.
+	minAvailable := desired - maxUnavailable
+	newUnavailable := max(newReplicas-newAvailable, 0)
+	budget := total - minAvailable - newUnavailable
+	if budget <= 0 {
+		return nil
+	}
+
+	scaled := map[string]int32{}
+	// Machines in old sets that are not available do not count towards
+	// availability, so they can always be removed first.
+	for _, ms := range oldSets {
+		if budget <= 0 {
+			break
+		}
+		replicas := machineset.Replicas(ms.Spec.Replicas)
+		unhealthy := max(replicas-ms.Status.AvailableReplicas, 0)
+		if n := min(unhealthy, budget); n > 0 {
+			scaled[ms.Name] = replicas - n
+			budget -= n
+		}
+	}
+
+	available := newAvailable + availableReplicas(oldSets)
+	budget = min(budget, available-minAvailable)
+	for _, ms := range oldSets {
+		if budget <= 0 {
+			break
+		}
+		replicas := machineset.Replicas(ms.Spec.Replicas)
+		if v, ok := scaled[ms.Name]; ok {
+			replicas = v
+		}
+		if n := min(replicas, budget); n > 0 {
+			scaled[ms.Name] = replicas - n
+			budget -= n
+		}
+	}
+
+	for _, ms := range oldSets {
+		if to, ok := scaled[ms.Name]; ok {
+			plan.Scale = append(plan.Scale, ScaleAction{Name: ms.Name, From: machineset.Replicas(ms.Spec.Replicas), To: to})
+		}
+	}
+	return nil
+}
+
+func isComplete(md *v1alpha1.MachineDeployment, newMS *v1alpha1.MachineSet, oldSets []*v1alpha1.MachineSet) bool {
+	if newMS == nil {
+		return false
+	}
+	desired := machineset.Replicas(md.Spec.Replicas)
+	if machineset.Replicas(newMS.Spec.Replicas) != desired ||
+		newMS.Status.Replicas != desired ||
+		newMS.Status.AvailableReplicas != desired {
+		return false
+	}
+	for _, ms := range oldSets {
+		if machineset.Replicas(ms.Spec.Replicas) != 0 || ms.Status.Replicas != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// setsToPrune returns the oldest scaled-down sets beyond the revision history limit.
+func setsToPrune(md *v1alpha1.MachineDeployment, oldSets []*v1alpha1.MachineSet) []string {
+	limit := DefaultRevisionHistoryLimit
+	if md.Spec.RevisionHistoryLimit != nil {
+		limit = *md.Spec.RevisionHistoryLimit
+	}
+
+	var idle []*v1alpha1.MachineSet
+	for _, ms := range oldSets {
+		if machineset.Replicas(ms.Spec.Replicas) == 0 && ms.Status.Replicas == 0 {
+			idle = append(idle, ms)
+		}
+	}
+	surplus := len(idle) - int(limit)
+	if surplus <= 0 {
+		return nil
+	}
+	names := make([]string, 0, surplus)
+	for _, ms := range idle[:surplus] {
+		names = append(names, ms.Name)
+	}
+	return names
+}
+
+func nextRevision(oldSets []*v1alpha1.MachineSet) int64 {
+	var highest int64
+	for _, ms := range oldSets {
+		highest = max(highest, Revision(ms))
+	}
+	return highest + 1
+}
+
+func sortByRevision(sets []*v1alpha1.MachineSet) {
+	sort.SliceStable(sets, func(i, j int) bool {
+		ri, rj := Revision(sets[i]), Revision(sets[j])
+		if ri != rj {
+			return ri < rj
+		}
+		ti, tj := sets[i].CreationTimestamp, sets[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return sets[i].Name < sets[j].Name
+	})
+}
+
+func specReplicas(sets []*v1alpha1.MachineSet) int32 {
+	var n int32
+	for _, ms := range sets {
+		n += machineset.Replicas(ms.Spec.Replicas)
+	}
+	return n
+}
+
+func availableReplicas(sets []*v1alpha1.MachineSet) int32 {
+	var n int32
+	for _, ms := range sets {
+		n += ms.Status.AvailableReplicas
+	}
+	return n
+}
+
+func deletePolicy(md *v1alpha1.MachineDeployment) string {
+	if ru := md.Spec.Strategy.RollingUpdate; ru != nil && ru.DeletePolicy != "" {
+		return ru.DeletePolicy
+	}
+	return v1alpha1.MachineSetDeletePolicyRandom
+}
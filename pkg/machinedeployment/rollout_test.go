@@ -0,0 +1,225 @@
+package machinedeployment
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/vitistack/crds/pkg/machineset"
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func newDeployment(replicas int32) *v1alpha1.MachineDeployment {
+	return &v1alpha1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "md-uid", Generation: 2},
+		Spec: v1alpha1.MachineDeploymentSpec{
+			Replicas: &replicas,
+			Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: v1alpha1.MachineTemplateSpec{
+				Metadata: v1alpha1.MachineTemplateMetadata{Labels: map[string]string{"app": "web"}},
+				Spec:     v1alpha1.MachineSpec{Name: "web", InstanceType: "small"},
+			},
+		},
+	}
+}
+
+// newSet returns a set owned by md. A current set is built from the
+// deployment's template, any other set from an older one.
+func newSet(t *testing.T, md *v1alpha1.MachineDeployment, revision int64, current bool, spec, replicas, available int32) *v1alpha1.MachineSet {
+	t.Helper()
+	hash := "old" + strconv.FormatInt(revision, 10)
+	if current {
+		var err error
+		if hash, err = machineset.TemplateHash(&md.Spec.Template); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &v1alpha1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        md.Name + "-" + hash,
+			Namespace:   md.Namespace,
+			Labels:      map[string]string{v1alpha1.MachineTemplateHashLabel: hash, v1alpha1.MachineDeploymentNameLabel: md.Name},
+			Annotations: map[string]string{v1alpha1.MachineDeploymentRevisionAnnotation: strconv.FormatInt(revision, 10)},
+		},
+		Spec:   v1alpha1.MachineSetSpec{Replicas: &spec},
+		Status: v1alpha1.MachineSetStatus{Replicas: replicas, ReadyReplicas: available, AvailableReplicas: available},
+	}
+}
+
+func TestResolveFenceposts(t *testing.T) {
+	percent := func(s string) *intstr.IntOrString { v := intstr.FromString(s); return &v }
+	number := func(n int32) *intstr.IntOrString { v := intstr.FromInt32(n); return &v }
+	tests := []struct {
+		name                       string
+		replicas                   int32
+		surge, unavailable         *intstr.IntOrString
+		wantSurge, wantUnavailable int32
+	}{
+		{name: "defaults", replicas: 3, wantSurge: 1, wantUnavailable: 0},
+		{name: "percentages round surge up and unavailable down", replicas: 5, surge: percent("25%"), unavailable: percent("25%"), wantSurge: 2, wantUnavailable: 1},
+		{name: "both zero allow one unavailable", replicas: 3, surge: number(0), unavailable: number(0), wantSurge: 0, wantUnavailable: 1},
+		{name: "unavailable capped at replicas", replicas: 2, surge: number(0), unavailable: number(5), wantSurge: 0, wantUnavailable: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := newDeployment(tt.replicas)
+			md.Spec.Strategy.RollingUpdate = &v1alpha1.MachineRollingUpdateDeployment{MaxSurge: tt.surge, MaxUnavailable: tt.unavailable}
+			surge, unavailable, err := ResolveFenceposts(md)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if surge != tt.wantSurge || unavailable != tt.wantUnavailable {
+				t.Errorf("got surge %d, unavailable %d, want %d, %d", surge, unavailable, tt.wantSurge, tt.wantUnavailable)
+			}
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name       string
+		strategy   string
+		paused     bool
+		sets       func(md *v1alpha1.MachineDeployment) []*v1alpha1.MachineSet
+		wantNew    int32 // replicas of the created set, -1 for none
+		wantScale  []ScaleAction
+		wantDelete []string
+		complete   bool
+	}{
+		{
+			name:    "first rollout creates the full set",
+			sets:    func(*v1alpha1.MachineDeployment) []*v1alpha1.MachineSet { return nil },
+			wantNew: 3,
+		},
+		{
+			name: "rolling update creates the new set within the surge",
+			sets: func(md *v1alpha1.MachineDeployment) []*v1alpha1.MachineSet {
+				return []*v1alpha1.MachineSet{newSet(t, md, 1, false, 3, 3, 3)}
+			},
+			wantNew: 1,
+		},
+		{
+			name: "rolling update waits for new machines to become available",
+			sets: func(md *v1alpha1.MachineDeployment) []*v1alpha1.MachineSet {
+				return []*v1alpha1.MachineSet{newSet(t, md, 1, false, 3, 3, 3), newSet(t, md, 2, true, 1, 1, 0)}
+			},
+			wantNew: -1,
+		},
+		{
+			name: "rolling update scales the old set down once new machines are available",
+			sets: func(md *v1alpha1.MachineDeployment) []*v1alpha1.MachineSet {
+				return []*v1alpha1.MachineSet{newSet(t, md, 1, false, 3, 3, 3), newSet(t, md, 2, true, 1, 1, 1)}
+			},
+			wantNew:   -1,
+			wantScale: []ScaleAction{{Name: "web-old1", From: 3, To: 2}},
+		},
+		{
+			name: "rolling update removes unavailable old machines first",
+			sets: func(md *v1alpha1.MachineDeployment) []*v1alpha1.MachineSet {
+				return []*v1alpha1.MachineSet{newSet(t, md, 1, false, 3, 3, 1), newSet(t, md, 2, true, 2, 2, 2)}
+			},
+			wantNew:   -1,
+			wantScale: []ScaleAction{{Name: "web-old1", From: 3, To: 1}},
+		},
+		{
+			name:     "recreate scales old sets to zero first",
+			strategy: v1alpha1.MachineDeploymentStrategyRecreate,
+			sets: func(md *v1alpha1.MachineDeployment) []*v1alpha1.MachineSet {
+				return []*v1alpha1.MachineSet{newSet(t, md, 1, false, 3, 3, 3)}
+			},
+			wantNew:   -1,
+			wantScale: []ScaleAction{{Name: "web-old1", From: 3, To: 0}},
+		},
+		{
+			name:     "recreate waits for old machines to be gone",
+			strategy: v1alpha1.MachineDeploymentStrategyRecreate,
+			sets: func(md *v1alpha1.MachineDeployment) []*v1alpha1.MachineSet {
+				return []*v1alpha1.MachineSet{newSet(t, md, 1, false, 0, 2, 0)}
+			},
+			wantNew: -1,
+		},
+		{
+			name:     "recreate creates the new set after old machines are gone",
+			strategy: v1alpha1.MachineDeploymentStrategyRecreate,
+			sets: func(md *v1alpha1.MachineDeployment) []*v1alpha1.MachineSet {
+				return []*v1alpha1.MachineSet{newSet(t, md, 1, false, 0, 0, 0)}
+			},
+			wantNew: 3,
+		},
+		{
+			name:   "paused deployment is left alone",
+			paused: true,
+			sets: func(md *v1alpha1.MachineDeployment) []*v1alpha1.MachineSet {
+				return []*v1alpha1.MachineSet{newSet(t, md, 1, false, 3, 3, 3)}
+			},
+			wantNew: -1,
+		},
+		{
+			name: "complete rollout prunes sets beyond the history limit",
+			sets: func(md *v1alpha1.MachineDeployment) []*v1alpha1.MachineSet {
+				return []*v1alpha1.MachineSet{
+					newSet(t, md, 2, false, 0, 0, 0),
+					newSet(t, md, 1, false, 0, 0, 0),
+					newSet(t, md, 3, true, 3, 3, 3),
+				}
+			},
+			wantNew:    -1,
+			wantDelete: []string{"web-old1"},
+			complete:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := newDeployment(3)
+			md.Spec.Strategy.Type = tt.strategy
+			md.Spec.Paused = tt.paused
+			plan, err := Plan(md, tt.sets(md))
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case tt.wantNew < 0 && plan.NewMachineSet != nil:
+				t.Errorf("created set %s, want none", plan.NewMachineSet.Name)
+			case tt.wantNew >= 0 && plan.NewMachineSet == nil:
+				t.Errorf("created no set, want one with %d replicas", tt.wantNew)
+			case tt.wantNew >= 0 && *plan.NewMachineSet.Spec.Replicas != tt.wantNew:
+				t.Errorf("created set with %d replicas, want %d", *plan.NewMachineSet.Spec.Replicas, tt.wantNew)
+			}
+			if !reflect.DeepEqual(plan.Scale, tt.wantScale) {
+				t.Errorf("scale = %+v, want %+v", plan.Scale, tt.wantScale)
+			}
+			if !reflect.DeepEqual(plan.Delete, tt.wantDelete) {
+				t.Errorf("delete = %v, want %v", plan.Delete, tt.wantDelete)
+			}
+			if plan.Complete != tt.complete {
+				t.Errorf("complete = %v, want %v", plan.Complete, tt.complete)
+			}
+		})
+	}
+}
+
+func TestNewMachineSet(t *testing.T) {
+	md := newDeployment(3)
+	ms, err := NewMachineSet(md, 2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, _ := machineset.TemplateHash(&md.Spec.Template)
+	if ms.Name != "web-"+hash {
+		t.Errorf("name = %s, want web-%s", ms.Name, hash)
+	}
+	if Revision(ms) != 4 || *ms.Spec.Replicas != 2 {
+		t.Errorf("revision %d with %d replicas, want 4 with 2", Revision(ms), *ms.Spec.Replicas)
+	}
+	if ms.Spec.Selector.MatchLabels[v1alpha1.MachineTemplateHashLabel] != hash || ms.Spec.Template.Metadata.Labels[v1alpha1.MachineTemplateHashLabel] != hash {
+		t.Error("selector and template must carry the template hash")
+	}
+	if ref := metav1.GetControllerOf(ms); ref == nil || ref.UID != md.UID {
+		t.Error("set must be controlled by the deployment")
+	}
+	if md.Spec.Template.Metadata.Labels[v1alpha1.MachineTemplateHashLabel] != "" {
+		t.Error("deployment template must not be modified")
+	}
+}
@@ -0,0 +1,150 @@
+package machinedeployment
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vitistack/crds/pkg/machineset"
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CalculateStatus derives the deployment status and its Available and
+// Progressing conditions from the owned MachineSets.
+//
+// The Progressing condition's transition time is reset whenever the rollout
+// makes progress (its message changes), so a rollout that stalls for longer
+// than progressDeadlineSeconds is reported with ProgressDeadlineExceeded. That
+// reason is kept until the replica counts or the new MachineSet change.
+func CalculateStatus(md *v1alpha1.MachineDeployment, owned []*v1alpha1.MachineSet, now time.Time) (v1alpha1.MachineDeploymentStatus, error) {
+	status := *md.Status.DeepCopy()
+	newMS, oldSets, err := SplitMachineSets(md, owned)
+	if err != nil {
+		return status, err
+	}
+	_, maxUnavailable, err := ResolveFenceposts(md)
+	if err != nil {
+		return status, err
+	}
+	desired := machineset.Replicas(md.Spec.Replicas)
+
+	status.Selector = metav1.FormatLabelSelector(&md.Spec.Selector)
+	status.ObservedGeneration = md.Generation
+	status.Replicas, status.ReadyReplicas, status.AvailableReplicas, status.UpdatedReplicas = 0, 0, 0, 0
+	for _, ms := range owned {
+		status.Replicas += ms.Status.Replicas
+		status.ReadyReplicas += ms.Status.ReadyReplicas
+		status.AvailableReplicas += ms.Status.AvailableReplicas
+	}
+	status.Revision = ""
+	if newMS != nil {
+		status.UpdatedReplicas = newMS.Status.Replicas
+		status.Revision = newMS.Annotations[v1alpha1.MachineDeploymentRevisionAnnotation]
+	}
+	status.UnavailableReplicas = max(desired-status.AvailableReplicas, 0)
+
+	available := metav1.Condition{
+		Type:               v1alpha1.MachineDeploymentConditionAvailable,
+		Status:             metav1.ConditionTrue,
+		Reason:             v1alpha1.MachineDeploymentReasonMinimumReplicasAvailable,
+		Message:            "deployment has minimum availability",
+		ObservedGeneration: md.Generation,
+		LastTransitionTime: metav1.NewTime(now),
+	}
+	if status.AvailableReplicas < desired-maxUnavailable {
+		available.Status = metav1.ConditionFalse
+		available.Reason = v1alpha1.MachineDeploymentReasonMinimumReplicasUnavailable
+		available.Message = fmt.Sprintf("%d of %d machines available, %d required", status.AvailableReplicas, desired, desired-maxUnavailable)
+	}
+	meta.SetStatusCondition(&status.Conditions, available)
+
+	progressing := progressingCondition(md, newMS, oldSets, &status, now)
+	setProgressingCondition(&status.Conditions, progressing)
+
+	switch {
+	case progressing.Reason == v1alpha1.MachineDeploymentReasonProgressDeadlineExceeded:
+		status.Phase = v1alpha1.MachineDeploymentPhaseFailed
+	case progressing.Reason == v1alpha1.MachineDeploymentReasonNewMachineSetAvailable:
+		status.Phase = v1alpha1.MachineDeploymentPhaseRunning
+	case status.UpdatedReplicas < status.Replicas:
+		status.Phase = v1alpha1.MachineDeploymentPhaseRollingOut
+	case status.Replicas < desired:
+		status.Phase = v1alpha1.MachineDeploymentPhaseScalingUp
+	case status.Replicas > desired:
+		status.Phase = v1alpha1.MachineDeploymentPhaseScalingDown
+	default:
+		status.Phase = v1alpha1.MachineDeploymentPhaseRollingOut
+	}
+	status.Message = progressing.Message
+
+	return status, nil
+}
+
+func progressingCondition(md *v1alpha1.MachineDeployment, newMS *v1alpha1.MachineSet, oldSets []*v1alpha1.MachineSet, status *v1alpha1.MachineDeploymentStatus, now time.Time) metav1.Condition {
+	c := metav1.Condition{
+		Type:               v1alpha1.MachineDeploymentConditionProgressing,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: md.Generation,
+		LastTransitionTime: metav1.NewTime(now),
+	}
+	desired := machineset.Replicas(md.Spec.Replicas)
+
+	switch {
+	case md.Spec.Paused:
+		c.Status = metav1.ConditionUnknown
+		c.Reason = v1alpha1.MachineDeploymentReasonPaused
+		c.Message = "deployment is paused"
+		return c
+	case isComplete(md, newMS, oldSets):
+		c.Reason = v1alpha1.MachineDeploymentReasonNewMachineSetAvailable
+		c.Message = fmt.Sprintf("machine set %s has successfully progressed", newMS.Name)
+		return c
+	case newMS == nil:
+		c.Reason = v1alpha1.MachineDeploymentReasonNewMachineSetCreated
+		c.Message = "waiting for the machine set of the current template to be created"
+	default:
+		c.Reason = v1alpha1.MachineDeploymentReasonMachineSetUpdated
+		c.Message = fmt.Sprintf("machine set %s is progressing: %d of %d updated machines available, %d old machines remaining",
+			newMS.Name, newMS.Status.AvailableReplicas, desired, status.Replicas-status.UpdatedReplicas)
+	}
+
+	// The rollout has not finished, check whether it stalled.
+	deadline := DefaultProgressDeadlineSeconds
+	if md.Spec.ProgressDeadlineSeconds != nil {
+		deadline = *md.Spec.ProgressDeadlineSeconds
+	}
+	exceeded := c
+	exceeded.Status = metav1.ConditionFalse
+	exceeded.Reason = v1alpha1.MachineDeploymentReasonProgressDeadlineExceeded
+	exceeded.Message = fmt.Sprintf("no progress for more than %ds: %s", deadline, c.Message)
+
+	prev := meta.FindStatusCondition(status.Conditions, v1alpha1.MachineDeploymentConditionProgressing)
+	switch {
+	case prev == nil:
+		return c
+	case prev.Reason == v1alpha1.MachineDeploymentReasonProgressDeadlineExceeded:
+		// A stalled rollout stays failed until its progress changes.
+		if prev.Message == exceeded.Message {
+			return exceeded
+		}
+		return c
+	case prev.Status != metav1.ConditionTrue || prev.Message != c.Message:
+		return c
+	}
+	if prev.LastTransitionTime.Add(time.Duration(deadline) * time.Second).Before(now) {
+		return exceeded
+	}
+	return c
+}
+
+// setProgressingCondition replaces the Progressing condition, keeping the
+// previous transition time only while neither status, reason nor message change.
+func setProgressingCondition(conditions *[]metav1.Condition, c metav1.Condition) {
+	if prev := meta.FindStatusCondition(*conditions, c.Type); prev != nil &&
+		prev.Status == c.Status && prev.Reason == c.Reason && prev.Message == c.Message {
+		c.LastTransitionTime = prev.LastTransitionTime
+	}
+	meta.RemoveStatusCondition(conditions, c.Type)
+	*conditions = append(*conditions, c)
+}
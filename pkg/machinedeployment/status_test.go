@@ -0,0 +1,129 @@
+package machinedeployment
+
+import (
+	"testing"
+	"time"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+func TestCalculateStatus(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		sets          func(md *v1alpha1.MachineDeployment) []*v1alpha1.MachineSet
+		wantPhase     string
+		wantAvailable bool
+		wantReason    string
+	}{
+		{
+			name: "complete",
+			sets: func(md *v1alpha1.MachineDeployment) []*v1alpha1.MachineSet {
+				return []*v1alpha1.MachineSet{newSet(t, md, 1, false, 0, 0, 0), newSet(t, md, 2, true, 3, 3, 3)}
+			},
+			wantPhase:     v1alpha1.MachineDeploymentPhaseRunning,
+			wantAvailable: true,
+			wantReason:    v1alpha1.MachineDeploymentReasonNewMachineSetAvailable,
+		},
+		{
+			name: "rolling out",
+			sets: func(md *v1alpha1.MachineDeployment) []*v1alpha1.MachineSet {
+				return []*v1alpha1.MachineSet{newSet(t, md, 1, false, 3, 3, 3), newSet(t, md, 2, true, 1, 1, 0)}
+			},
+			wantPhase:     v1alpha1.MachineDeploymentPhaseRollingOut,
+			wantAvailable: true,
+			wantReason:    v1alpha1.MachineDeploymentReasonMachineSetUpdated,
+		},
+		{
+			name: "waiting for the new set",
+			sets: func(md *v1alpha1.MachineDeployment) []*v1alpha1.MachineSet {
+				return []*v1alpha1.MachineSet{newSet(t, md, 1, false, 3, 3, 2)}
+			},
+			wantPhase:     v1alpha1.MachineDeploymentPhaseRollingOut,
+			wantAvailable: false,
+			wantReason:    v1alpha1.MachineDeploymentReasonNewMachineSetCreated,
+		},
+		{
+			name: "scaling up",
+			sets: func(md *v1alpha1.MachineDeployment) []*v1alpha1.MachineSet {
+				return []*v1alpha1.MachineSet{newSet(t, md, 1, true, 3, 1, 1)}
+			},
+			wantPhase:     v1alpha1.MachineDeploymentPhaseScalingUp,
+			wantAvailable: false,
+			wantReason:    v1alpha1.MachineDeploymentReasonMachineSetUpdated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := newDeployment(3)
+			status, err := CalculateStatus(md, tt.sets(md), now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.Phase != tt.wantPhase {
+				t.Errorf("phase = %s, want %s", status.Phase, tt.wantPhase)
+			}
+			if got := meta.IsStatusConditionTrue(status.Conditions, v1alpha1.MachineDeploymentConditionAvailable); got != tt.wantAvailable {
+				t.Errorf("available = %v, want %v", got, tt.wantAvailable)
+			}
+			if c := meta.FindStatusCondition(status.Conditions, v1alpha1.MachineDeploymentConditionProgressing); c == nil || c.Reason != tt.wantReason {
+				t.Errorf("progressing = %+v, want reason %s", c, tt.wantReason)
+			}
+			if status.ObservedGeneration != md.Generation {
+				t.Errorf("observed generation = %d, want %d", status.ObservedGeneration, md.Generation)
+			}
+		})
+	}
+}
+
+func TestCalculateStatusProgressDeadline(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	md := newDeployment(3)
+	deadline := int32(600)
+	md.Spec.ProgressDeadlineSeconds = &deadline
+	stalled := func() []*v1alpha1.MachineSet {
+		return []*v1alpha1.MachineSet{newSet(t, md, 1, false, 3, 3, 3), newSet(t, md, 2, true, 1, 1, 0)}
+	}
+
+	step := func(sets []*v1alpha1.MachineSet, now time.Time) {
+		t.Helper()
+		status, err := CalculateStatus(md, sets, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		md.Status = status
+	}
+	progressing := func() (reason string, since time.Time) {
+		c := meta.FindStatusCondition(md.Status.Conditions, v1alpha1.MachineDeploymentConditionProgressing)
+		return c.Reason, c.LastTransitionTime.Time
+	}
+
+	step(stalled(), start)
+	step(stalled(), start.Add(5*time.Minute))
+	if reason, since := progressing(); reason != v1alpha1.MachineDeploymentReasonMachineSetUpdated || !since.Equal(start) {
+		t.Fatalf("before the deadline: %s since %s, want MachineSetUpdated since %s", reason, since, start)
+	}
+
+	exceededAt := start.Add(11 * time.Minute)
+	step(stalled(), exceededAt)
+	if reason, _ := progressing(); reason != v1alpha1.MachineDeploymentReasonProgressDeadlineExceeded || md.Status.Phase != v1alpha1.MachineDeploymentPhaseFailed {
+		t.Fatalf("after the deadline: %s in phase %s, want ProgressDeadlineExceeded in Failed", reason, md.Status.Phase)
+	}
+
+	// Without progress the rollout stays failed and the deadline does not restart.
+	for _, after := range []time.Duration{12 * time.Minute, 30 * time.Minute, 2 * time.Hour} {
+		step(stalled(), start.Add(after))
+		reason, since := progressing()
+		if reason != v1alpha1.MachineDeploymentReasonProgressDeadlineExceeded || !since.Equal(exceededAt) || md.Status.Phase != v1alpha1.MachineDeploymentPhaseFailed {
+			t.Fatalf("at %s: %s since %s in phase %s, want ProgressDeadlineExceeded since %s in Failed", after, reason, since, md.Status.Phase, exceededAt)
+		}
+	}
+
+	// Progress clears the failure and restarts the deadline.
+	progressedAt := start.Add(3 * time.Hour)
+	step([]*v1alpha1.MachineSet{newSet(t, md, 1, false, 3, 3, 3), newSet(t, md, 2, true, 1, 1, 1)}, progressedAt)
+	if reason, since := progressing(); reason != v1alpha1.MachineDeploymentReasonMachineSetUpdated || !since.Equal(progressedAt) || md.Status.Phase != v1alpha1.MachineDeploymentPhaseRollingOut {
+		t.Fatalf("after progress: %s since %s in phase %s, want MachineSetUpdated since %s in RollingOut", reason, since, md.Status.Phase, progressedAt)
+	}
+}
@@ -0,0 +1,314 @@
+// Package machineset computes the scaling decisions and the observed status of
+// a MachineSet from the Machines it owns, as a controller lists them from its
+// cache.
+package machineset
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"time"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// DefaultReplicas is used when spec.replicas is not set.
+const DefaultReplicas int32 = 1
+
+// ScalePlan describes what is needed to bring a MachineSet to its desired size.
+type ScalePlan struct {
+	// Create is the number of machines to create.
+	Create int
+	// Delete lists the machines to delete, in the order they should be removed.
+	Delete []*v1alpha1.Machine
+}
+
+// Replicas returns the desired replica count, applying the API default.
+func Replicas(replicas *int32) int32 {
+	if replicas == nil {
+		return DefaultReplicas
+	}
+	return *replicas
+}
+
+// TemplateHash returns a short stable hash of a machine template. Two templates
+// hash equally when they would produce the same machines.
+func TemplateHash(template *v1alpha1.MachineTemplateSpec) (string, error) {
+	if template == nil {
+		return "", fmt.Errorf("nil template")
+	}
+	// encoding/json sorts map keys, so the encoding is deterministic.
+	data, err := json.Marshal(template)
+	if err != nil {
+		return "", err
+	}
+	h := fnv.New32a()
+	_, _ = h.Write(data)
+	return strconv.FormatUint(uint64(h.Sum32()), 36), nil
+}
+
+// IsMachineReady reports whether the machine has its Ready condition set to True.
+func IsMachineReady(m *v1alpha1.Machine) bool {
+	c := readyCondition(m)
+	return c != nil && c.Status == v1alpha1.ConditionTrue
+}
+
+// IsMachineAvailable reports whether the machine has been ready for at least minReadySeconds.
+func IsMachineAvailable(m *v1alpha1.Machine, minReadySeconds int32, now time.Time) bool {
+	c := readyCondition(m)
+	if c == nil || c.Status != v1alpha1.ConditionTrue {
+		return false
+	}
+	if minReadySeconds == 0 {
+		return true
+	}
+	minReady := time.Duration(minReadySeconds) * time.Second
+	return !c.LastTransitionTime.IsZero() && !c.LastTransitionTime.Add(minReady).After(now)
+}
+
+// IsMachineFailed reports whether the machine reached a terminal failure.
+func IsMachineFailed(m *v1alpha1.Machine) bool {
+	return m.Status.Phase == v1alpha1.MachinePhaseFailed || m.Status.FailureReason != nil || m.Status.FailureMessage != nil
+}
+
+// IsMachineDeleting reports whether the machine is already being deleted.
+func IsMachineDeleting(m *v1alpha1.Machine) bool {
+	return m.DeletionTimestamp != nil
+}
+
+// OwnedMachines returns the machines in the set's namespace that match its
+// selector and are not controlled by another object.
+func OwnedMachines(ms *v1alpha1.MachineSet, machines []v1alpha1.Machine) ([]*v1alpha1.Machine, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&ms.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector on MachineSet %s/%s: %w", ms.Namespace, ms.Name, err)
+	}
+	if selector.Empty() {
+		return nil, fmt.Errorf("empty selector on MachineSet %s/%s would match every machine", ms.Namespace, ms.Name)
+	}
+
+	var owned []*v1alpha1.Machine
+	for i := range machines {
+		m := &machines[i]
+		if m.Namespace != ms.Namespace || !selector.Matches(labels.Set(m.Labels)) {
+			continue
+		}
+		if ref := metav1.GetControllerOf(m); ref != nil && ref.UID != ms.UID {
+			continue
+		}
+		owned = append(owned, m)
+	}
+	return owned, nil
+}
+
+// PlanScale compares the owned machines with the desired replica count and
+// decides how many machines to create or which ones to delete. Machines that
+// are already being deleted are neither counted nor selected.
+func PlanScale(ms *v1alpha1.MachineSet, owned []*v1alpha1.Machine) *ScalePlan {
+	active := activeMachines(owned)
+	diff := int(Replicas(ms.Spec.Replicas)) - len(active)
+
+	plan := &ScalePlan{}
+	switch {
+	case diff > 0:
+		plan.Create = diff
+	case diff < 0:
+		plan.Delete = MachinesToDelete(active, -diff, ms.Spec.DeletePolicy)
+	}
+	return plan
+}
+
+// MachinesToDelete picks count machines to remove. Machines annotated for
+// deletion go first, then failed and unready machines, then the remaining
+// machines ordered by the delete policy.
+func MachinesToDelete(machines []*v1alpha1.Machine, count int, policy string) []*v1alpha1.Machine {
+	if count <= 0 {
+		return nil
+	}
+	candidates := make([]*v1alpha1.Machine, len(machines))
+	copy(candidates, machines)
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		pi, pj := deletePriority(candidates[i]), deletePriority(candidates[j])
+		if pi != pj {
+			return pi > pj
+		}
+		return policyLess(candidates[i], candidates[j], policy)
+	})
+
+	if count > len(candidates) {
+		count = len(candidates)
+	}
+	return candidates[:count]
+}
+
+// NewMachine builds a Machine from the set's template. The machine is named
+// with GenerateName and controlled by the set.
+func NewMachine(ms *v1alpha1.MachineSet) *v1alpha1.Machine {
+	template := ms.Spec.Template.DeepCopy()
+
+	machineLabels := template.Metadata.Labels
+	if machineLabels == nil {
+		machineLabels = map[string]string{}
+	}
+	machineLabels[v1alpha1.MachineSetNameLabel] = ms.Name
+	if name, ok := ms.Labels[v1alpha1.MachineDeploymentNameLabel]; ok {
+		machineLabels[v1alpha1.MachineDeploymentNameLabel] = name
+	}
+
+	return &v1alpha1.Machine{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "Machine",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName:    ms.Name + "-",
+			Namespace:       ms.Namespace,
+			Labels:          machineLabels,
+			Annotations:     template.Metadata.Annotations,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(ms, v1alpha1.GroupVersion.WithKind("MachineSet"))},
+		},
+		Spec: template.Spec,
+	}
+}
+
+// CalculateStatus derives the MachineSet status from the machines it owns.
+// Existing conditions keep their transition time when their status is unchanged.
+func CalculateStatus(ms *v1alpha1.MachineSet, owned []*v1alpha1.Machine, now time.Time) v1alpha1.MachineSetStatus {
+	status := *ms.Status.DeepCopy()
+	desired := Replicas(ms.Spec.Replicas)
+	templateLabels := labels.SelectorFromSet(ms.Spec.Template.Metadata.Labels)
+
+	status.Selector = metav1.FormatLabelSelector(&ms.Spec.Selector)
+	status.ObservedGeneration = ms.Generation
+	status.Replicas, status.FullyLabeledReplicas, status.ReadyReplicas, status.AvailableReplicas = 0, 0, 0, 0
+
+	failed := 0
+	for _, m := range activeMachines(owned) {
+		status.Replicas++
+		if templateLabels.Matches(labels.Set(m.Labels)) {
+			status.FullyLabeledReplicas++
+		}
+		if IsMachineReady(m) {
+			status.ReadyReplicas++
+		}
+		if IsMachineAvailable(m, ms.Spec.MinReadySeconds, now) {
+			status.AvailableReplicas++
+		}
+		if IsMachineFailed(m) {
+			failed++
+		}
+	}
+
+	switch {
+	case failed > 0:
+		status.Phase = v1alpha1.MachineSetPhaseFailed
+		status.Message = fmt.Sprintf("%d of %d machines failed", failed, status.Replicas)
+	case status.Replicas < desired:
+		status.Phase = v1alpha1.MachineSetPhaseScalingUp
+		status.Message = fmt.Sprintf("scaling up from %d to %d machines", status.Replicas, desired)
+	case status.Replicas > desired:
+		status.Phase = v1alpha1.MachineSetPhaseScalingDown
+		status.Message = fmt.Sprintf("scaling down from %d to %d machines", status.Replicas, desired)
+	case status.ReadyReplicas < desired:
+		status.Phase = v1alpha1.MachineSetPhaseProvisioning
+		status.Message = fmt.Sprintf("%d of %d machines ready", status.ReadyReplicas, desired)
+	default:
+		status.Phase = v1alpha1.MachineSetPhaseRunning
+		status.Message = ""
+	}
+
+	setCondition(&status.Conditions, ms.Generation, now, v1alpha1.MachineSetConditionResized,
+		status.Replicas == desired, "ReplicasMatch", "ReplicasMismatch",
+		fmt.Sprintf("%d of %d machines exist", status.Replicas, desired))
+	setCondition(&status.Conditions, ms.Generation, now, v1alpha1.MachineSetConditionMachinesReady,
+		status.ReadyReplicas >= desired, "MachinesReady", "MachinesNotReady",
+		fmt.Sprintf("%d of %d machines ready", status.ReadyReplicas, desired))
+	setCondition(&status.Conditions, ms.Generation, now, v1alpha1.MachineSetConditionMachinesCreated,
+		failed == 0, "MachinesCreated", "MachineCreationFailed",
+		fmt.Sprintf("%d machines failed", failed))
+
+	return status
+}
+
+func setCondition(conditions *[]metav1.Condition, generation int64, now time.Time, conditionType string, ok bool, trueReason, falseReason, message string) {
+	c := metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionFalse,
+		Reason:             falseReason,
+		Message:            message,
+		ObservedGeneration: generation,
+		LastTransitionTime: metav1.NewTime(now),
+	}
+	if ok {
+		c.Status = metav1.ConditionTrue
+		c.Reason = trueReason
+	}
+	meta.SetStatusCondition(conditions, c)
+}
+
+func readyCondition(m *v1alpha1.Machine) *v1alpha1.MachineCondition {
+	for i := range m.Status.Conditions {
+		if m.Status.Conditions[i].Type == v1alpha1.MachineConditionReady {
+			return &m.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func activeMachines(machines []*v1alpha1.Machine) []*v1alpha1.Machine {
+	var active []*v1alpha1.Machine
+	for _, m := range machines {
+		if !IsMachineDeleting(m) {
+			active = append(active, m)
+		}
+	}
+	return active
+}
+
+// deletePriority ranks machines for deletion, higher values are deleted first.
+func deletePriority(m *v1alpha1.Machine) int {
+	switch {
+	case m.Annotations[v1alpha1.MachineDeleteAnnotation] != "":
+		return 3
+	case IsMachineFailed(m):
+		return 2
+	case !IsMachineReady(m):
+		return 1
+	default:
+		return 0
+	}
+}
+
+func policyLess(a, b *v1alpha1.Machine, policy string) bool {
+	ta, tb := a.CreationTimestamp.Time, b.CreationTimestamp.Time
+	switch policy {
+	case v1alpha1.MachineSetDeletePolicyOldest:
+		if !ta.Equal(tb) {
+			return ta.Before(tb)
+		}
+	case v1alpha1.MachineSetDeletePolicyNewest:
+		if !ta.Equal(tb) {
+			return ta.After(tb)
+		}
+	default:
+		// Random: spread deletions by a stable hash of the name so the
+		// result does not depend on list order.
+		ha, hb := nameHash(a.Name), nameHash(b.Name)
+		if ha != hb {
+			return ha < hb
+		}
+	}
+	return a.Name < b.Name
+}
+
+func nameHash(name string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	return h.Sum32()
+}
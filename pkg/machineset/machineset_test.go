@@ -0,0 +1,214 @@
+package machineset
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newMachineSet(replicas int32) *v1alpha1.MachineSet {
+	return &v1alpha1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "ms-uid", Generation: 3},
+		Spec: v1alpha1.MachineSetSpec{
+			Replicas: &replicas,
+			Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: v1alpha1.MachineTemplateSpec{
+				Metadata: v1alpha1.MachineTemplateMetadata{Labels: map[string]string{"app": "web"}},
+			},
+		},
+	}
+}
+
+type machineOption func(*v1alpha1.Machine)
+
+func newMachine(name string, created time.Time, opts ...machineOption) *v1alpha1.Machine {
+	m := &v1alpha1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			Labels:            map[string]string{"app": "web"},
+			CreationTimestamp: metav1.NewTime(created),
+		},
+	}
+	ready(time.Hour)(m)
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// ready marks a machine ready since the given time before now.
+func ready(ago time.Duration) machineOption {
+	return func(m *v1alpha1.Machine) {
+		m.Status.Conditions = []v1alpha1.MachineCondition{{
+			Type:               v1alpha1.MachineConditionReady,
+			Status:             v1alpha1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(now.Add(-ago)),
+		}}
+	}
+}
+
+func notReady(m *v1alpha1.Machine) { m.Status.Conditions = nil }
+
+func failed(m *v1alpha1.Machine) { m.Status.Phase = v1alpha1.MachinePhaseFailed }
+
+func deleting(m *v1alpha1.Machine) {
+	ts := metav1.NewTime(now)
+	m.DeletionTimestamp = &ts
+}
+
+func markedForDeletion(m *v1alpha1.Machine) {
+	m.Annotations = map[string]string{v1alpha1.MachineDeleteAnnotation: "true"}
+}
+
+func names(machines []*v1alpha1.Machine) []string {
+	out := make([]string, len(machines))
+	for i, m := range machines {
+		out[i] = m.Name
+	}
+	return out
+}
+
+func TestPlanScale(t *testing.T) {
+	day := func(d int) time.Time { return now.AddDate(0, 0, -d) }
+	tests := []struct {
+		name       string
+		replicas   int32
+		policy     string
+		machines   []*v1alpha1.Machine
+		wantCreate int
+		wantDelete []string
+	}{
+		{
+			name:       "scale up",
+			replicas:   3,
+			machines:   []*v1alpha1.Machine{newMachine("a", day(1))},
+			wantCreate: 2,
+		},
+		{
+			name:       "deleting machines are not counted",
+			replicas:   2,
+			machines:   []*v1alpha1.Machine{newMachine("a", day(1)), newMachine("b", day(1), deleting)},
+			wantCreate: 1,
+		},
+		{
+			name:     "in sync",
+			replicas: 2,
+			machines: []*v1alpha1.Machine{newMachine("a", day(1)), newMachine("b", day(2))},
+		},
+		{
+			name:       "oldest policy",
+			replicas:   1,
+			policy:     v1alpha1.MachineSetDeletePolicyOldest,
+			machines:   []*v1alpha1.Machine{newMachine("a", day(1)), newMachine("b", day(3)), newMachine("c", day(2))},
+			wantDelete: []string{"b", "c"},
+		},
+		{
+			name:       "newest policy",
+			replicas:   1,
+			policy:     v1alpha1.MachineSetDeletePolicyNewest,
+			machines:   []*v1alpha1.Machine{newMachine("a", day(1)), newMachine("b", day(3)), newMachine("c", day(2))},
+			wantDelete: []string{"a", "c"},
+		},
+		{
+			name:     "annotated, failed and unready machines go first",
+			replicas: 1,
+			policy:   v1alpha1.MachineSetDeletePolicyNewest,
+			machines: []*v1alpha1.Machine{
+				newMachine("new", day(0)),
+				newMachine("unready", day(5), notReady),
+				newMachine("failed", day(4), failed),
+				newMachine("marked", day(3), markedForDeletion),
+			},
+			wantDelete: []string{"marked", "failed", "unready"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := newMachineSet(tt.replicas)
+			ms.Spec.DeletePolicy = tt.policy
+			plan := PlanScale(ms, tt.machines)
+			if plan.Create != tt.wantCreate {
+				t.Errorf("create = %d, want %d", plan.Create, tt.wantCreate)
+			}
+			if got := names(plan.Delete); !slices.Equal(got, tt.wantDelete) {
+				t.Errorf("delete = %v, want %v", got, tt.wantDelete)
+			}
+		})
+	}
+}
+
+func TestMachinesToDeleteRandomIsStable(t *testing.T) {
+	machines := []*v1alpha1.Machine{
+		newMachine("a", now), newMachine("b", now), newMachine("c", now), newMachine("d", now),
+	}
+	reversed := []*v1alpha1.Machine{machines[3], machines[2], machines[1], machines[0]}
+	first := names(MachinesToDelete(machines, 2, v1alpha1.MachineSetDeletePolicyRandom))
+	second := names(MachinesToDelete(reversed, 2, v1alpha1.MachineSetDeletePolicyRandom))
+	if !slices.Equal(first, second) {
+		t.Errorf("random policy depends on list order: %v and %v", first, second)
+	}
+}
+
+func TestCalculateStatus(t *testing.T) {
+	ms := newMachineSet(3)
+	ms.Spec.MinReadySeconds = 600
+	machines := []*v1alpha1.Machine{
+		newMachine("available", now),
+		newMachine("recent", now, ready(time.Minute)),
+		newMachine("unready", now, notReady),
+		newMachine("gone", now, deleting),
+	}
+	status := CalculateStatus(ms, machines, now)
+
+	if status.Replicas != 3 || status.ReadyReplicas != 2 || status.AvailableReplicas != 1 || status.FullyLabeledReplicas != 3 {
+		t.Errorf("replicas %d, ready %d, available %d, labeled %d, want 3, 2, 1, 3",
+			status.Replicas, status.ReadyReplicas, status.AvailableReplicas, status.FullyLabeledReplicas)
+	}
+	if status.Phase != v1alpha1.MachineSetPhaseProvisioning {
+		t.Errorf("phase = %s, want %s", status.Phase, v1alpha1.MachineSetPhaseProvisioning)
+	}
+	if !meta.IsStatusConditionTrue(status.Conditions, v1alpha1.MachineSetConditionResized) ||
+		meta.IsStatusConditionTrue(status.Conditions, v1alpha1.MachineSetConditionMachinesReady) {
+		t.Errorf("conditions = %+v, want resized and not ready", status.Conditions)
+	}
+	if status.ObservedGeneration != ms.Generation {
+		t.Errorf("observed generation = %d, want %d", status.ObservedGeneration, ms.Generation)
+	}
+
+	machines = append(machines, newMachine("broken", now, failed))
+	if status := CalculateStatus(ms, machines, now); status.Phase != v1alpha1.MachineSetPhaseFailed {
+		t.Errorf("phase with a failed machine = %s, want %s", status.Phase, v1alpha1.MachineSetPhaseFailed)
+	}
+}
+
+func TestOwnedMachines(t *testing.T) {
+	ms := newMachineSet(1)
+	other := newMachine("other", now)
+	other.OwnerReferences = []metav1.OwnerReference{{UID: "other-uid", Controller: ptr(true)}}
+	foreign := newMachine("foreign", now)
+	foreign.Namespace = "elsewhere"
+	unlabeled := newMachine("unlabeled", now)
+	unlabeled.Labels = nil
+
+	owned, err := OwnedMachines(ms, []v1alpha1.Machine{*newMachine("mine", now), *other, *foreign, *unlabeled})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(owned); !slices.Equal(got, []string{"mine"}) {
+		t.Errorf("owned = %v, want [mine]", got)
+	}
+
+	ms.Spec.Selector = metav1.LabelSelector{}
+	if _, err := OwnedMachines(ms, nil); err == nil {
+		t.Error("empty selector must be rejected")
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,241 @@
+// Package machinespec computes the effective MachineSpec of a Machine that
+// references a MachineClass and/or a MachineTemplate.
+//
+// Layers are applied in this order, later layers win:
+//
+//  1. MachineClass: instanceType, machineType, cpu, memory and disks
+//  2. MachineTemplate: spec.template.spec
+//  3. The Machine's own spec (inline overrides)
+//
+// Within a layer only fields that are set are applied. Maps are merged key by
+// key, disks are merged by name (or by position when unnamed), and any other
+// list replaces the list from earlier layers. Inlined structs are merged as
+// part of their parent. Because unset and false cannot be told apart, a
+// boolean can be switched on by a later layer but not off.
+package machinespec
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+// Source identifies the layer that set a field.
+type Source string
+
+const (
+	SourceClass    Source = "MachineClass"
+	SourceTemplate Source = "MachineTemplate"
+	SourceMachine  Source = "Machine"
+)
+
+// Result is the effective spec together with the origin of every set field.
+type Result struct {
+	// Spec is the merged machine spec.
+	Spec v1alpha1.MachineSpec
+	// Sources maps JSON field paths (e.g. "cpu.cores", "tags.team",
+	// "disks[root].sizeGB") to the layer that set them.
+	Sources map[string]Source
+}
+
+// SourceOf returns the layer that set the field at path, or "" if it is unset.
+func (r *Result) SourceOf(path string) Source {
+	return r.Sources[path]
+}
+
+// Fields returns the paths of all set fields in sorted order.
+func (r *Result) Fields() []string {
+	fields := make([]string, 0, len(r.Sources))
+	for f := range r.Sources {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// TemplateName returns the name of the MachineTemplate the machine references, or "".
+func TemplateName(machine *v1alpha1.Machine) string {
+	if machine.Spec.TemplateRef == nil {
+		return ""
+	}
+	return machine.Spec.TemplateRef.Name
+}
+
+// ClassName returns the name of the MachineClass that applies to the machine.
+// A class referenced by the machine takes precedence over one referenced by
+// its template. template may be nil.
+func ClassName(machine *v1alpha1.Machine, template *v1alpha1.MachineTemplate) string {
+	if machine.Spec.ClassRef != nil {
+		return machine.Spec.ClassRef.Name
+	}
+	if template != nil && template.Spec.Template.Spec.ClassRef != nil {
+		return template.Spec.Template.Spec.ClassRef.Name
+	}
+	return ""
+}
+
+// Resolve merges class, template and machine into the effective spec. class
+// and template must be the objects named by ClassName and TemplateName, and
+// may only be nil when nothing is referenced.
+func Resolve(machine *v1alpha1.Machine, class *v1alpha1.MachineClass, template *v1alpha1.MachineTemplate) (*Result, error) {
+	if machine == nil {
+		return nil, fmt.Errorf("nil machine")
+	}
+
+	var templateName, className string
+	if template != nil {
+		templateName = template.Name
+		if template.Spec.Template.Spec.TemplateRef != nil {
+			return nil, fmt.Errorf("MachineTemplate %q references another template, nested templates are not supported", template.Name)
+		}
+	}
+	if class != nil {
+		className = class.Name
+	}
+	if err := checkRef("MachineTemplate", TemplateName(machine), templateName); err != nil {
+		return nil, err
+	}
+	if err := checkRef("MachineClass", ClassName(machine, template), className); err != nil {
+		return nil, err
+	}
+
+	result := &Result{Sources: map[string]Source{}}
+	dst := reflect.ValueOf(&result.Spec).Elem()
+
+	if class != nil {
+		layer := v1alpha1.MachineSpec{
+			InstanceType: class.Spec.InstanceType,
+			MachineType:  class.Spec.MachineType,
+			CPU:          class.Spec.CPU,
+			Memory:       class.Spec.Memory,
+			Disks:        class.Spec.Disks,
+		}
+		merge(dst, reflect.ValueOf(layer), "", SourceClass, result.Sources)
+	}
+	if template != nil {
+		merge(dst, reflect.ValueOf(template.Spec.Template.Spec), "", SourceTemplate, result.Sources)
+	}
+	merge(dst, reflect.ValueOf(machine.Spec), "", SourceMachine, result.Sources)
+
+	// Return an independent copy, merge shares pointers and slices with its inputs.
+	result.Spec = *result.Spec.DeepCopy()
+	return result, nil
+}
+
+func checkRef(kind, referenced, given string) error {
+	switch {
+	case referenced == "" && given != "":
+		return fmt.Errorf("%s %q was given but the machine does not reference one", kind, given)
+	case referenced != "" && given == "":
+		return fmt.Errorf("%s %q is referenced but was not given", kind, referenced)
+	case referenced != given:
+		return fmt.Errorf("%s %q was given but %q is referenced", kind, given, referenced)
+	}
+	return nil
+}
+
+var diskType = reflect.TypeOf(v1alpha1.MachineSpecDisk{})
+
+func merge(dst, src reflect.Value, path string, source Source, sources map[string]Source) {
+	switch src.Kind() {
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			name, inline := jsonName(src.Type().Field(i))
+			switch {
+			case inline:
+				// Inlined fields are serialized as part of the parent.
+				merge(dst.Field(i), src.Field(i), path, source, sources)
+			case name != "":
+				merge(dst.Field(i), src.Field(i), join(path, name), source, sources)
+			}
+		}
+	case reflect.Map:
+		if src.Len() == 0 {
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(src.Type()))
+		}
+		iter := src.MapRange()
+		for iter.Next() {
+			dst.SetMapIndex(iter.Key(), iter.Value())
+			sources[join(path, fmt.Sprint(iter.Key().Interface()))] = source
+		}
+	case reflect.Slice:
+		if src.Len() == 0 {
+			return
+		}
+		if src.Type().Elem() == diskType {
+			mergeDisks(dst, src, path, source, sources)
+			return
+		}
+		dst.Set(src)
+		sources[path] = source
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(src)
+		sources[path] = source
+	default:
+		if src.IsZero() {
+			return
+		}
+		dst.Set(src)
+		sources[path] = source
+	}
+}
+
+func mergeDisks(dst, src reflect.Value, path string, source Source, sources map[string]Source) {
+	disks := append([]v1alpha1.MachineSpecDisk(nil), dst.Interface().([]v1alpha1.MachineSpecDisk)...)
+	for i, disk := range src.Interface().([]v1alpha1.MachineSpecDisk) {
+		idx := -1
+		if disk.Name != "" {
+			for j := range disks {
+				if disks[j].Name == disk.Name {
+					idx = j
+					break
+				}
+			}
+		} else if i < len(disks) && disks[i].Name == "" {
+			idx = i
+		}
+		if idx < 0 {
+			disks = append(disks, v1alpha1.MachineSpecDisk{})
+			idx = len(disks) - 1
+		}
+
+		key := disk.Name
+		if key == "" {
+			key = strconv.Itoa(idx)
+		}
+		merge(reflect.ValueOf(&disks[idx]).Elem(), reflect.ValueOf(disk), fmt.Sprintf("%s[%s]", path, key), source, sources)
+	}
+	dst.Set(reflect.ValueOf(disks))
+}
+
+// jsonName returns the JSON name of a struct field, and whether the field is
+// inlined into its parent: embedded without a name, or tagged ",inline".
+func jsonName(f reflect.StructField) (name string, inline bool) {
+	name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch {
+	case name == "-":
+		return "", false
+	case name != "":
+		return name, false
+	}
+	inline = f.Anonymous || slices.Contains(strings.Split(opts, ","), "inline")
+	return "", inline && f.Type.Kind() == reflect.Struct
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,212 @@
+package machinespec
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newClass() *v1alpha1.MachineClass {
+	return &v1alpha1.MachineClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "medium"},
+		Spec: v1alpha1.MachineClassSpec{
+			InstanceType: "m.medium",
+			CPU:          v1alpha1.MachineCPU{Cores: 2, Sockets: 1},
+			Memory:       8 << 30,
+			Disks: []v1alpha1.MachineSpecDisk{
+				{Name: "root", SizeGB: 20, Type: "ssd", Boot: true},
+				{Name: "data", SizeGB: 100},
+			},
+		},
+	}
+}
+
+func newTemplate() *v1alpha1.MachineTemplate {
+	return &v1alpha1.MachineTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: v1alpha1.MachineTemplateResourceSpec{
+			Template: v1alpha1.MachineTemplateSpec{Spec: v1alpha1.MachineSpec{
+				ClassRef: &v1alpha1.MachineClassReference{Name: "medium"},
+				CPU:      v1alpha1.MachineCPU{Cores: 4},
+				Disks:    []v1alpha1.MachineSpecDisk{{Name: "data", SizeGB: 200}, {Name: "logs", SizeGB: 10}},
+				OS:       v1alpha1.MachineOS{Distribution: "ubuntu", Version: "24.04"},
+				SSHKeys:  []string{"ssh-ed25519 template"},
+				Tags:     map[string]string{"team": "web", "tier": "frontend"},
+				ProviderConfig: v1alpha1.CloudProviderConfig{
+					Zone:   "az1",
+					Config: map[string]string{"hotplug": "true"},
+				},
+			}},
+		},
+	}
+}
+
+func newMachine() *v1alpha1.Machine {
+	return &v1alpha1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+		Spec: v1alpha1.MachineSpec{
+			TemplateRef: &v1alpha1.MachineTemplateReference{Name: "web"},
+			Memory:      16 << 30,
+			Disks:       []v1alpha1.MachineSpecDisk{{Name: "root", SizeGB: 40}},
+			SSHKeys:     []string{"ssh-ed25519 machine"},
+			Tags:        map[string]string{"tier": "backend"},
+		},
+	}
+}
+
+func TestResolve(t *testing.T) {
+	class, template, machine := newClass(), newTemplate(), newMachine()
+	res, err := Resolve(machine, class, template)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := v1alpha1.MachineSpec{
+		InstanceType: "m.medium",
+		ClassRef:     &v1alpha1.MachineClassReference{Name: "medium"},
+		TemplateRef:  &v1alpha1.MachineTemplateReference{Name: "web"},
+		CPU:          v1alpha1.MachineCPU{Cores: 4, Sockets: 1},
+		Memory:       16 << 30,
+		Disks: []v1alpha1.MachineSpecDisk{
+			{Name: "root", SizeGB: 40, Type: "ssd", Boot: true},
+			{Name: "data", SizeGB: 200},
+			{Name: "logs", SizeGB: 10},
+		},
+		OS:      v1alpha1.MachineOS{Distribution: "ubuntu", Version: "24.04"},
+		SSHKeys: []string{"ssh-ed25519 machine"},
+		Tags:    map[string]string{"team": "web", "tier": "backend"},
+		ProviderConfig: v1alpha1.CloudProviderConfig{
+			Zone:   "az1",
+			Config: map[string]string{"hotplug": "true"},
+		},
+	}
+	if !reflect.DeepEqual(res.Spec, want) {
+		t.Errorf("spec = %+v\nwant %+v", res.Spec, want)
+	}
+
+	sources := map[string]Source{
+		"instanceType":                  SourceClass,
+		"cpu.cores":                     SourceTemplate,
+		"cpu.sockets":                   SourceClass,
+		"memory":                        SourceMachine,
+		"disks[root].sizeGB":            SourceMachine,
+		"disks[root].type":              SourceClass,
+		"disks[root].boot":              SourceClass,
+		"disks[data].sizeGB":            SourceTemplate,
+		"disks[logs].sizeGB":            SourceTemplate,
+		"classRef":                      SourceTemplate,
+		"templateRef":                   SourceMachine,
+		"os.distribution":               SourceTemplate,
+		"sshKeys":                       SourceMachine,
+		"tags.team":                     SourceTemplate,
+		"tags.tier":                     SourceMachine,
+		"providerConfig.zone":           SourceTemplate,
+		"providerConfig.config.hotplug": SourceTemplate,
+		"userData":                      "",
+	}
+	for path, want := range sources {
+		if got := res.SourceOf(path); got != want {
+			t.Errorf("SourceOf(%q) = %q, want %q", path, got, want)
+		}
+	}
+
+	// The result shares nothing with its inputs.
+	res.Spec.Tags["team"] = "changed"
+	res.Spec.Disks[1].SizeGB = 1
+	if template.Spec.Template.Spec.Tags["team"] != "web" || template.Spec.Template.Spec.Disks[0].SizeGB != 200 {
+		t.Error("changing the result changed the template")
+	}
+}
+
+func TestResolveUnnamedDisks(t *testing.T) {
+	machine := &v1alpha1.Machine{Spec: v1alpha1.MachineSpec{
+		ClassRef: &v1alpha1.MachineClassReference{Name: "medium"},
+		Disks:    []v1alpha1.MachineSpecDisk{{SizeGB: 50}, {SizeGB: 500}},
+	}}
+	class := newClass()
+	class.Spec.Disks = []v1alpha1.MachineSpecDisk{{SizeGB: 20, Type: "ssd"}}
+	res, err := Resolve(machine, class, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []v1alpha1.MachineSpecDisk{{SizeGB: 50, Type: "ssd"}, {SizeGB: 500}}
+	if !reflect.DeepEqual(res.Spec.Disks, want) {
+		t.Errorf("disks = %+v, want %+v", res.Spec.Disks, want)
+	}
+	if got := res.SourceOf("disks[0].type"); got != SourceClass {
+		t.Errorf("SourceOf(disks[0].type) = %q, want %q", got, SourceClass)
+	}
+}
+
+func TestResolveClassOverride(t *testing.T) {
+	machine := newMachine()
+	machine.Spec.ClassRef = &v1alpha1.MachineClassReference{Name: "large"}
+	class := newClass()
+	class.Name = "large"
+	res, err := Resolve(machine, class, newTemplate())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Spec.ClassRef.Name != "large" || res.SourceOf("classRef") != SourceMachine {
+		t.Errorf("classRef = %q from %q, want large from the machine", res.Spec.ClassRef.Name, res.SourceOf("classRef"))
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	nested := newTemplate()
+	nested.Spec.Template.Spec.TemplateRef = &v1alpha1.MachineTemplateReference{Name: "base"}
+	other := newClass()
+	other.Name = "large"
+	tests := []struct {
+		name     string
+		machine  *v1alpha1.Machine
+		class    *v1alpha1.MachineClass
+		template *v1alpha1.MachineTemplate
+		want     string
+	}{
+		{name: "nil machine", class: newClass(), template: newTemplate(), want: "nil machine"},
+		{name: "template not given", machine: newMachine(), class: newClass(), want: `MachineTemplate "web" is referenced but was not given`},
+		{name: "class not given", machine: newMachine(), template: newTemplate(), want: `MachineClass "medium" is referenced but was not given`},
+		{name: "wrong class", machine: newMachine(), class: other, template: newTemplate(), want: `MachineClass "large" was given but "medium" is referenced`},
+		{name: "class not referenced", machine: &v1alpha1.Machine{}, class: newClass(), want: `MachineClass "medium" was given but the machine does not reference one`},
+		{name: "nested template", machine: newMachine(), class: newClass(), template: nested, want: "nested templates are not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Resolve(tt.machine, tt.class, tt.template)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeInline(t *testing.T) {
+	type Inner struct {
+		Zone string `json:"zone,omitempty"`
+	}
+	type Tagged struct {
+		Region string `json:"region,omitempty"`
+	}
+	type spec struct {
+		Inner
+		Extra   Tagged `json:",inline"`
+		Name    string `json:"name,omitempty"`
+		Ignored string `json:"-"`
+	}
+	var dst spec
+	sources := map[string]Source{}
+	merge(reflect.ValueOf(&dst).Elem(), reflect.ValueOf(spec{Inner: Inner{Zone: "az1"}, Extra: Tagged{Region: "west"}, Name: "web", Ignored: "x"}), "", SourceMachine, sources)
+
+	want := spec{Inner: Inner{Zone: "az1"}, Extra: Tagged{Region: "west"}, Name: "web"}
+	if dst != want {
+		t.Errorf("merged %+v, want %+v", dst, want)
+	}
+	wantSources := map[string]Source{"zone": SourceMachine, "region": SourceMachine, "name": SourceMachine}
+	if !reflect.DeepEqual(sources, wantSources) {
+		t.Errorf("sources = %v, want %v", sources, wantSources)
+	}
+}
@@ -0,0 +1,302 @@
+// Package migration plans MachineMigrations. Before any data is moved it
+// checks that the target MachineProvider can run the machine: instance type,
+// image, storage types and CPU architecture are matched against the target's
+// capabilities.
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/vitistack/crds/pkg/instancetype"
+	"github.com/vitistack/crds/pkg/osimage"
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+// Plan is the resolved migration of one machine.
+type Plan struct {
+	// Mode is Live or Cold.
+	Mode string
+	// CrossProvider is true when the machine moves to another MachineProvider.
+	CrossProvider bool
+	// Source is where the machine runs now.
+	Source v1alpha1.MachineMigrationLocation
+	// Target is where the machine will run, with the image and instance type
+	// resolved on the target provider.
+	Target v1alpha1.MachineMigrationLocation
+	// Architecture is the normalized CPU architecture of the machine, or "" if unknown.
+	Architecture string
+	// DiskStorageTypes maps each disk (by name, or index when unnamed) to its
+	// storage type on the target.
+	DiskStorageTypes map[string]string
+	// Incompatibilities lists why the target cannot run the machine.
+	Incompatibilities []string
+}
+
+// Compatible reports whether the migration can start.
+func (p *Plan) Compatible() bool {
+	return len(p.Incompatibilities) == 0
+}
+
+// Err returns the incompatibilities as one error, or nil when compatible.
+func (p *Plan) Err() error {
+	if p.Compatible() {
+		return nil
+	}
+	return errors.New(strings.Join(p.Incompatibilities, "; "))
+}
+
+// Mode returns the migration mode, applying the API default.
+func Mode(migration *v1alpha1.MachineMigration) string {
+	if migration.Spec.Mode == "" {
+		return v1alpha1.MachineMigrationModeCold
+	}
+	return migration.Spec.Mode
+}
+
+// NewPlan resolves the migration of machine from the source provider it runs
+// on to the target provider. target must be the provider named by
+// spec.target.providerRef, or source itself when no provider is referenced.
+//
+// Errors are returned for inconsistent input. Anything that keeps the target
+// from running the machine is reported in Plan.Incompatibilities instead, so a
+// controller can surface all of it at once.
+func NewPlan(migration *v1alpha1.MachineMigration, machine *v1alpha1.Machine, source, target *v1alpha1.MachineProvider) (*Plan, error) {
+	if machine == nil || source == nil || target == nil {
+		return nil, fmt.Errorf("machine, source and target provider are required")
+	}
+	if machine.Name != migration.Spec.MachineRef.Name || machine.Namespace != migration.Namespace {
+		return nil, fmt.Errorf("machine %s/%s was given but %s/%s is referenced",
+			machine.Namespace, machine.Name, migration.Namespace, migration.Spec.MachineRef.Name)
+	}
+	targetName := source.Name
+	if ref := migration.Spec.Target.ProviderRef; ref != nil {
+		targetName = ref.Name
+	}
+	if target.Name != targetName {
+		return nil, fmt.Errorf("MachineProvider %q was given as target but %q is referenced", target.Name, targetName)
+	}
+
+	p := &Plan{
+		Mode:             Mode(migration),
+		CrossProvider:    source.Name != target.Name,
+		Architecture:     osimage.NormalizeArchitecture(firstNonEmpty(machine.Status.Architecture, machine.Spec.OS.Architecture)),
+		DiskStorageTypes: map[string]string{},
+	}
+	p.Source = v1alpha1.MachineMigrationLocation{
+		Provider:     source.Name,
+		Zone:         firstNonEmpty(machine.Status.Zone, machine.Spec.ProviderConfig.Zone),
+		InstanceType: machine.Spec.InstanceType,
+		ImageID:      machine.Spec.OS.ImageID,
+	}
+	p.Target = v1alpha1.MachineMigrationLocation{
+		Provider:     target.Name,
+		Host:         migration.Spec.Target.Host,
+		InstanceType: firstNonEmpty(migration.Spec.Target.InstanceType, machine.Spec.InstanceType),
+	}
+
+	p.checkMachine(machine)
+	p.checkProvider(target)
+	p.resolveZone(migration, target)
+	p.checkInstanceType(machine, source, target)
+	p.resolveImage(machine, target)
+	p.checkStorage(migration, machine, source, target)
+	return p, nil
+}
+
+func (p *Plan) addf(format string, args ...any) {
+	p.Incompatibilities = append(p.Incompatibilities, fmt.Sprintf(format, args...))
+}
+
+func (p *Plan) checkMachine(machine *v1alpha1.Machine) {
+	if machine.DeletionTimestamp != nil {
+		p.addf("machine is being deleted")
+		return
+	}
+	switch machine.Status.Phase {
+	case v1alpha1.MachinePhaseRunning:
+	case v1alpha1.MachinePhaseStopped, v1alpha1.MachinePhaseStopping:
+		if p.Mode == v1alpha1.MachineMigrationModeLive {
+			p.addf("live migration requires a running machine, machine is %s", machine.Status.Phase)
+		}
+	default:
+		p.addf("machine in phase %q cannot be migrated", machine.Status.Phase)
+	}
+
+	if p.Mode != v1alpha1.MachineMigrationModeLive {
+		return
+	}
+	if p.CrossProvider {
+		p.addf("live migration between providers is not supported, use mode %s", v1alpha1.MachineMigrationModeCold)
+	}
+	if p.Target.InstanceType != p.Source.InstanceType {
+		p.addf("live migration cannot change the instance type from %q to %q", p.Source.InstanceType, p.Target.InstanceType)
+	}
+}
+
+func (p *Plan) checkProvider(target *v1alpha1.MachineProvider) {
+	switch target.Status.Phase {
+	case v1alpha1.MachineProviderPhaseFailed, v1alpha1.MachineProviderPhaseOffline:
+		p.addf("target provider %q is %s", target.Name, target.Status.Phase)
+	}
+	if limit := target.Spec.Capabilities.MaxMachines; p.CrossProvider && limit > 0 && target.Status.ActiveMachines >= limit {
+		p.addf("target provider %q is at its limit of %d machines", target.Name, limit)
+	}
+}
+
+func (p *Plan) resolveZone(migration *v1alpha1.MachineMigration, target *v1alpha1.MachineProvider) {
+	zones := target.Spec.Zones
+	if zone := migration.Spec.Target.Zone; zone != "" {
+		p.Target.Zone = zone
+		if len(zones) > 0 && !contains(zones, zone) {
+			p.addf("zone %q is not offered by target provider %q (offers %s)", zone, target.Name, strings.Join(zones, ", "))
+		}
+		return
+	}
+	// Stay in the current zone when the target has it, otherwise let the
+	// target provider choose.
+	if p.Source.Zone != "" && (len(zones) == 0 && !p.CrossProvider || contains(zones, p.Source.Zone)) {
+		p.Target.Zone = p.Source.Zone
+	}
+}
+
+// checkInstanceType resolves the target instance type with
+// instancetype.Resolve: the type must be offered and available, fit the
+// limits of the target, and have a GPU when the current type does. Unlike on
+// creation, the type may be larger than the CPU and memory of the machine.
+// Without a type the target's default or smallest fitting type is used.
+func (p *Plan) checkInstanceType(machine *v1alpha1.Machine, source, target *v1alpha1.MachineProvider) {
+	req := instancetype.MachineRequirements(&machine.Spec)
+	req.InstanceType = p.Target.InstanceType
+	if src := instancetype.Find(source.Spec.Capabilities.InstanceTypes, p.Source.InstanceType); src != nil {
+		req.GPU = src.GPU
+	}
+	res, err := instancetype.Resolve(req, target)
+	for _, err := range unjoin(err) {
+		if !errors.Is(err, instancetype.ErrConflict) {
+			p.addf("%v", err)
+		}
+	}
+	if res.Info != nil {
+		if res.CPUs < req.CPUs {
+			p.addf("instance type %q has %d vCPUs, the machine needs %d", res.InstanceType, res.CPUs, req.CPUs)
+		}
+		if res.Memory < req.Memory {
+			p.addf("instance type %q has %s memory, the machine needs %s",
+				res.InstanceType, instancetype.FormatMemory(res.Memory), instancetype.FormatMemory(req.Memory))
+		}
+	}
+	p.Target.InstanceType = res.InstanceType
+}
+
+// resolveImage finds the image the machine boots from on the target with
+// osimage.Resolve. A machine that stays on its provider keeps its image, and a
+// machine that moves keeps it when the target lists it. Otherwise the image is
+// resolved from the image family or operating system of the machine.
+func (p *Plan) resolveImage(machine *v1alpha1.Machine, target *v1alpha1.MachineProvider) {
+	os := machine.Spec.OS
+	os.Architecture = p.Architecture
+	if !p.CrossProvider {
+		p.Target.ImageID = os.ImageID
+		if os.ImageID == "" {
+			return
+		}
+	} else if !slices.ContainsFunc(target.Status.AvailableResources.Images, func(img v1alpha1.ImageInfo) bool { return img.ID == os.ImageID }) {
+		os.ImageID = ""
+	}
+	res, err := osimage.Resolve(os, target)
+	switch {
+	case err == nil:
+		p.Target.ImageID = res.ImageID
+	case !p.CrossProvider && errors.Is(err, osimage.ErrNoImage):
+		// The provider already runs the image, even when its status does not
+		// list it.
+	default:
+		p.addf("%v", err)
+	}
+}
+
+func (p *Plan) checkStorage(migration *v1alpha1.MachineMigration, machine *v1alpha1.Machine, source, target *v1alpha1.MachineProvider) {
+	offered := map[string]*v1alpha1.StorageTypeInfo{}
+	var names []string
+	for i := range target.Spec.Capabilities.StorageTypes {
+		st := &target.Spec.Capabilities.StorageTypes[i]
+		offered[st.Name] = st
+		names = append(names, st.Name)
+	}
+
+	var totalGB int64
+	for i, disk := range machine.Spec.Disks {
+		key := disk.Name
+		if key == "" {
+			key = strconv.Itoa(i)
+		}
+		storageType := firstNonEmpty(migration.Spec.Target.StorageType, disk.Type)
+		if storageType == "" {
+			if p.CrossProvider {
+				storageType = target.Spec.Storage.DefaultType
+			} else {
+				storageType = source.Spec.Storage.DefaultType
+			}
+		}
+		p.DiskStorageTypes[key] = storageType
+		totalGB += disk.SizeGB
+
+		if limit := target.Spec.Storage.MaxStorageGB; limit > 0 && disk.SizeGB > int64(limit) {
+			p.addf("disk %s: %dGB exceeds the %dGB limit of target provider %q", key, disk.SizeGB, limit, target.Name)
+		}
+		if len(offered) == 0 || storageType == "" {
+			continue
+		}
+		st := offered[storageType]
+		if st == nil {
+			p.addf("disk %s: storage type %q is not offered by target provider %q (offers %s), set spec.target.storageType",
+				key, storageType, target.Name, strings.Join(names, ", "))
+			continue
+		}
+		if disk.Encrypted && !st.EncryptionSupported {
+			p.addf("disk %s: storage type %q does not support encryption", key, storageType)
+		}
+		if r := st.IOPSRange; r != nil && disk.IOPS > 0 && (disk.IOPS < r.Min || disk.IOPS > r.Max) {
+			p.addf("disk %s: %d IOPS is outside the %d-%d range of storage type %q", key, disk.IOPS, r.Min, r.Max, storageType)
+		}
+		if r := st.ThroughputRange; r != nil && disk.Throughput > 0 && (disk.Throughput < r.Min || disk.Throughput > r.Max) {
+			p.addf("disk %s: %dMB/s throughput is outside the %d-%d range of storage type %q", key, disk.Throughput, r.Min, r.Max, storageType)
+		}
+	}
+	if limit := target.Status.AvailableResources.Limits.MaxStoragePerMachineGB; limit > 0 && totalGB > int64(limit) {
+		p.addf("disks total %dGB, target provider %q allows %dGB per machine", totalGB, target.Name, limit)
+	}
+}
+
+// unjoin returns the errors joined in err.
+func unjoin(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	if err != nil {
+		return []error{err}
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,204 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vitistack/crds/pkg/instancetype"
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newMachine() *v1alpha1.Machine {
+	return &v1alpha1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: v1alpha1.MachineSpec{
+			InstanceType: "medium",
+			CPU:          v1alpha1.MachineCPU{Cores: 2, Sockets: 2},
+			Memory:       8 * instancetype.GiB,
+			OS:           v1alpha1.MachineOS{Distribution: "ubuntu", Version: "24.04", Architecture: "x86_64", ImageID: "ubuntu-24.04-a"},
+		},
+		Status: v1alpha1.MachineStatus{Phase: v1alpha1.MachinePhaseRunning},
+	}
+}
+
+func newProvider(name string) *v1alpha1.MachineProvider {
+	return &v1alpha1.MachineProvider{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1alpha1.MachineProviderSpec{
+			Compute: v1alpha1.ProviderComputeConfig{MaxCPUs: 16, MaxMemoryGB: 32},
+			Capabilities: v1alpha1.ProviderCapabilities{InstanceTypes: []v1alpha1.InstanceTypeInfo{
+				{Name: "small", VCPUs: 2, MemoryGB: "4"},
+				{Name: "medium", VCPUs: 4, MemoryGB: "8"},
+				{Name: "large", VCPUs: 8, MemoryGB: "16"},
+				{Name: "huge", VCPUs: 32, MemoryGB: "128"},
+				{Name: "gpu", VCPUs: 8, MemoryGB: "32", GPU: true},
+			}},
+		},
+	}
+}
+
+func newMigration(target, instanceType string) *v1alpha1.MachineMigration {
+	m := &v1alpha1.MachineMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "move-web", Namespace: "default"},
+		Spec: v1alpha1.MachineMigrationSpec{
+			MachineRef: v1alpha1.MachineReference{Name: "web"},
+			Mode:       v1alpha1.MachineMigrationModeCold,
+			Target:     v1alpha1.MachineMigrationTarget{InstanceType: instanceType},
+		},
+	}
+	if target != "" {
+		m.Spec.Target.ProviderRef = &v1alpha1.MachineProviderReference{Name: target}
+	}
+	return m
+}
+
+func TestInstanceType(t *testing.T) {
+	tests := []struct {
+		name         string
+		instanceType string
+		machine      func(*v1alpha1.Machine)
+		target       func(*v1alpha1.MachineProvider)
+		wantType     string
+		// want are parts of the incompatibilities, in order.
+		want []string
+	}{
+		{name: "same type", wantType: "medium"},
+		{name: "larger type", instanceType: "large", wantType: "large"},
+		{
+			// The machine has 2 cores on 2 sockets, so 4 vCPUs.
+			name: "smaller type", instanceType: "small", wantType: "small",
+			want: []string{`"small" has 2 vCPUs, the machine needs 4`, `"small" has 4GiB memory, the machine needs 8GiB`},
+		},
+		{
+			name: "memory in decimal gigabytes", instanceType: "medium", wantType: "medium",
+			machine: func(m *v1alpha1.Machine) { m.Spec.Memory = 8e9 },
+		},
+		{
+			name: "unknown type", instanceType: "xlarge", wantType: "xlarge",
+			want: []string{"does not offer instance type xlarge"},
+		},
+		{
+			name: "not available", wantType: "medium",
+			target: func(mp *v1alpha1.MachineProvider) {
+				mp.Status.AvailableResources.InstanceTypes = []string{"small", "large"}
+			},
+			want: []string{"medium is currently not available"},
+		},
+		{
+			name: "above the limits", instanceType: "huge", wantType: "huge",
+			want: []string{"allows 16 vCPUs", "allows 32GiB memory"},
+		},
+		{
+			name: "GPU type kept", instanceType: "large", wantType: "large",
+			machine: func(m *v1alpha1.Machine) { m.Spec.InstanceType = "gpu" },
+			want:    []string{"instance type large has no GPU", "target has no GPU support"},
+		},
+		{
+			name: "suggested without a type", wantType: "medium",
+			machine: func(m *v1alpha1.Machine) { m.Spec.InstanceType = "" },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			machine, source, target := newMachine(), newProvider("source"), newProvider("target")
+			if tt.machine != nil {
+				tt.machine(machine)
+			}
+			if tt.target != nil {
+				tt.target(target)
+			}
+			target.Status.AvailableResources.Images = []v1alpha1.ImageInfo{{ID: "ubuntu-24.04-a", Architecture: "amd64"}}
+			plan, err := NewPlan(newMigration("target", tt.instanceType), machine, source, target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plan.Target.InstanceType != tt.wantType {
+				t.Errorf("instance type = %q, want %q", plan.Target.InstanceType, tt.wantType)
+			}
+			checkIncompatibilities(t, plan, tt.want)
+		})
+	}
+}
+
+// checkIncompatibilities checks that each incompatibility contains the
+// matching part of want.
+func checkIncompatibilities(t *testing.T, plan *Plan, want []string) {
+	t.Helper()
+	ok := len(plan.Incompatibilities) == len(want)
+	for i := 0; ok && i < len(want); i++ {
+		ok = strings.Contains(plan.Incompatibilities[i], want[i])
+	}
+	if !ok {
+		t.Errorf("incompatibilities = %q, want %q", plan.Incompatibilities, want)
+	}
+}
+
+func TestImage(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		machine func(*v1alpha1.Machine)
+		images  []v1alpha1.ImageInfo
+		wantID  string
+		want    []string
+	}{
+		{name: "same provider keeps the image", wantID: "ubuntu-24.04-a"},
+		{
+			name: "same provider, image not listed", wantID: "ubuntu-24.04-a",
+			images: []v1alpha1.ImageInfo{{ID: "ubuntu-24.04-b", OSDistribution: "ubuntu", OSVersion: "24.04"}},
+		},
+		{
+			name: "same provider, other architecture", wantID: "ubuntu-24.04-a",
+			images: []v1alpha1.ImageInfo{{ID: "ubuntu-24.04-a", Architecture: "aarch64"}},
+			want:   []string{`image "ubuntu-24.04-a" is built for arm64, the machine runs amd64`},
+		},
+		{
+			name: "target lists the image", target: "target", wantID: "ubuntu-24.04-a",
+			images: []v1alpha1.ImageInfo{{ID: "ubuntu-24.04-a", Architecture: "amd64"}},
+		},
+		{
+			name: "newest image of the distribution", target: "target", wantID: "ubuntu-24.04-c",
+			images: []v1alpha1.ImageInfo{
+				{ID: "ubuntu-24.04-b", OSDistribution: "ubuntu", OSVersion: "24.04", Architecture: "x86_64", CreationDate: &metav1.Time{Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}},
+				{ID: "ubuntu-24.04-c", OSDistribution: "ubuntu", OSVersion: "24.04", Architecture: "x86_64", CreationDate: &metav1.Time{Time: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}},
+				{ID: "ubuntu-24.04-arm", OSDistribution: "ubuntu", OSVersion: "24.04", Architecture: "arm64", CreationDate: &metav1.Time{Time: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)}},
+			},
+		},
+		{
+			name: "image family", target: "target", wantID: "ubuntu-2404-minimal-1",
+			machine: func(m *v1alpha1.Machine) { m.Spec.OS.ImageFamily = "ubuntu-2404-minimal" },
+			images: []v1alpha1.ImageInfo{
+				{ID: "ubuntu-2404-1", Name: "ubuntu-2404-20250101", OSDistribution: "ubuntu", OSVersion: "24.04", Architecture: "amd64"},
+				{ID: "ubuntu-2404-minimal-1", Name: "ubuntu-2404-minimal-20250101", OSDistribution: "ubuntu", OSVersion: "24.04", Architecture: "amd64"},
+			},
+		},
+		{
+			name: "no image", target: "target",
+			images: []v1alpha1.ImageInfo{{ID: "debian-12", OSDistribution: "debian", OSVersion: "12"}},
+			want:   []string{"MachineProvider target has no image for ubuntu 24.04 amd64"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			machine, source := newMachine(), newProvider("source")
+			if tt.machine != nil {
+				tt.machine(machine)
+			}
+			target := source
+			if tt.target != "" {
+				target = newProvider(tt.target)
+			}
+			target.Status.AvailableResources.Images = tt.images
+			plan, err := NewPlan(newMigration(tt.target, ""), machine, source, target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plan.Target.ImageID != tt.wantID {
+				t.Errorf("image = %q, want %q", plan.Target.ImageID, tt.wantID)
+			}
+			checkIncompatibilities(t, plan, tt.want)
+		})
+	}
+}
@@ -0,0 +1,240 @@
+// Package multus generates Multus NetworkAttachmentDefinitions that attach
+// pods and KubeVirt VMs to the VLAN of a NetworkNamespace, as bridge, macvlan
+// or OVN secondary networks. The IPAM configuration is derived from the
+// namespace prefixes. The objects are unstructured, so the Multus API types
+// are not needed.
+package multus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GroupVersionKind of NetworkAttachmentDefinition.
+var GroupVersionKind = schema.GroupVersionKind{Group: "k8s.cni.cncf.io", Version: "v1", Kind: "NetworkAttachmentDefinition"}
+
+// NetworkNamespaceLabel is set on generated NetworkAttachmentDefinitions to
+// the name of their NetworkNamespace.
+const NetworkNamespaceLabel = "vitistack.io/network-namespace"
+
+// CNIVersion is the CNI specification version of the generated configuration.
+const CNIVersion = "0.3.1"
+
+// Types of secondary networks.
+const (
+	TypeBridge  = "bridge"
+	TypeMacvlan = "macvlan"
+	TypeOVN     = "ovn"
+)
+
+// IPAM plugins.
+const (
+	// IPAMWhereabouts allocates addresses cluster wide from the prefixes.
+	IPAMWhereabouts = "whereabouts"
+	// IPAMNone leaves addressing to the workload, e.g. DHCP inside a VM.
+	IPAMNone = "none"
+)
+
+// Options tune a generated NetworkAttachmentDefinition.
+type Options struct {
+	// Type of the secondary network: TypeBridge, TypeMacvlan or TypeOVN.
+	Type string
+	// Name of the NetworkAttachmentDefinition, default the NetworkNamespace
+	// name followed by the type, e.g. "my-cluster-bridge".
+	Name string
+	// Namespace of the NetworkAttachmentDefinition, default the namespace of
+	// the NetworkNamespace.
+	Namespace string
+	// Bridge is the Linux bridge of TypeBridge networks, tagging the VLAN on
+	// its ports. Default "br0".
+	Bridge string
+	// Master is the host interface carrying the VLANs of TypeMacvlan
+	// networks, e.g. "bond0". The macvlan sits on its VLAN sub-interface,
+	// "bond0.<vlan id>", which the host network must provide.
+	Master string
+	// PhysicalNetwork is the OVN localnet of TypeOVN networks, default
+	// "physnet".
+	PhysicalNetwork string
+	// MTU of the interface in the pod, 0 leaves the plugin default.
+	MTU int
+	// IPAM plugin of TypeBridge and TypeMacvlan networks, default
+	// IPAMWhereabouts. OVN networks always allocate from the prefixes.
+	IPAM string
+	// IPv4Gateway and IPv6Gateway are excluded from allocation. They default
+	// to the first address of each prefix.
+	IPv4Gateway string
+	IPv6Gateway string
+}
+
+// NetworkAttachmentDefinition generates the NetworkAttachmentDefinition of a
+// NetworkNamespace from the VLAN ID and prefixes in its status. Addresses are
+// allocated from the prefixes without the gateways and egress IPs. No routes
+// are added, so the default route of a pod stays on the cluster network.
+func NetworkAttachmentDefinition(nn *v1alpha1.NetworkNamespace, opts Options) (*metav1unstructured.Unstructured, error) {
+	if nn.Status.VlanID == 0 {
+		return nil, fmt.Errorf("NetworkNamespace %s/%s has no VLAN ID yet", nn.Namespace, nn.Name)
+	}
+	ranges, err := ipRanges(nn, &opts)
+	if err != nil {
+		return nil, fmt.Errorf("NetworkNamespace %s/%s: %w", nn.Namespace, nn.Name, err)
+	}
+	if opts.Name == "" {
+		opts.Name = nn.Name + "-" + opts.Type
+	}
+	if opts.Namespace == "" {
+		opts.Namespace = nn.Namespace
+	}
+	if opts.IPAM == "" {
+		opts.IPAM = IPAMWhereabouts
+	}
+
+	var config any
+	switch opts.Type {
+	case TypeBridge, TypeMacvlan:
+		c := cniConfig{CNIVersion: CNIVersion, Name: opts.Name, Type: opts.Type, MTU: opts.MTU}
+		if opts.Type == TypeBridge {
+			c.Bridge = opts.Bridge
+			if c.Bridge == "" {
+				c.Bridge = "br0"
+			}
+			c.VLAN = nn.Status.VlanID
+		} else {
+			if opts.Master == "" {
+				return nil, errors.New("macvlan networks need a master interface")
+			}
+			c.Master = fmt.Sprintf("%s.%d", opts.Master, nn.Status.VlanID)
+			c.Mode = "bridge"
+		}
+		switch opts.IPAM {
+		case IPAMWhereabouts:
+			c.IPAM = &ipamConfig{Type: IPAMWhereabouts, IPRanges: ranges}
+		case IPAMNone:
+			c.IPAM = &ipamConfig{}
+		default:
+			return nil, fmt.Errorf("unknown IPAM %q", opts.IPAM)
+		}
+		config = c
+	case TypeOVN:
+		c := ovnConfig{
+			CNIVersion:       CNIVersion,
+			Name:             opts.PhysicalNetwork,
+			Type:             "ovn-k8s-cni-overlay",
+			Topology:         "localnet",
+			NetAttachDefName: opts.Namespace + "/" + opts.Name,
+			VLANID:           nn.Status.VlanID,
+			MTU:              opts.MTU,
+		}
+		if c.Name == "" {
+			c.Name = "physnet"
+		}
+		for _, r := range ranges {
+			c.Subnets = join(c.Subnets, r.Range)
+			for _, ex := range r.Exclude {
+				c.ExcludeSubnets = join(c.ExcludeSubnets, ex)
+			}
+		}
+		config = c
+	default:
+		return nil, fmt.Errorf("unknown secondary network type %q", opts.Type)
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	u := &metav1unstructured.Unstructured{}
+	u.SetGroupVersionKind(GroupVersionKind)
+	u.SetName(opts.Name)
+	u.SetNamespace(opts.Namespace)
+	u.SetLabels(map[string]string{NetworkNamespaceLabel: nn.Name})
+	if err := metav1unstructured.SetNestedField(u.Object, string(data), "spec", "config"); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+type cniConfig struct {
+	CNIVersion string      `json:"cniVersion"`
+	Name       string      `json:"name"`
+	Type       string      `json:"type"`
+	Bridge     string      `json:"bridge,omitempty"`
+	VLAN       int         `json:"vlan,omitempty"`
+	Master     string      `json:"master,omitempty"`
+	Mode       string      `json:"mode,omitempty"`
+	MTU        int         `json:"mtu,omitempty"`
+	IPAM       *ipamConfig `json:"ipam"`
+}
+
+type ipamConfig struct {
+	Type     string    `json:"type,omitempty"`
+	IPRanges []ipRange `json:"ipRanges,omitempty"`
+}
+
+type ipRange struct {
+	Range   string   `json:"range"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+type ovnConfig struct {
+	CNIVersion       string `json:"cniVersion"`
+	Name             string `json:"name"`
+	Type             string `json:"type"`
+	Topology         string `json:"topology"`
+	NetAttachDefName string `json:"netAttachDefName"`
+	VLANID           int    `json:"vlanID"`
+	Subnets          string `json:"subnets,omitempty"`
+	ExcludeSubnets   string `json:"excludeSubnets,omitempty"`
+	MTU              int    `json:"mtu,omitempty"`
+}
+
+// ipRanges returns the prefixes of a NetworkNamespace, IPv4 first, each
+// without its gateway and the egress IP of its family.
+func ipRanges(nn *v1alpha1.NetworkNamespace, opts *Options) ([]ipRange, error) {
+	var out []ipRange
+	for _, f := range []struct {
+		prefix, gateway, egress string
+		v4                      bool
+	}{
+		{nn.Status.IPv4Prefix, opts.IPv4Gateway, nn.Status.IPv4EgressIP, true},
+		{nn.Status.IPv6Prefix, opts.IPv6Gateway, nn.Status.IPv6EgressIP, false},
+	} {
+		if f.prefix == "" {
+			continue
+		}
+		p, err := netip.ParsePrefix(f.prefix)
+		if err != nil || p.Addr().Is4() != f.v4 {
+			return nil, fmt.Errorf("invalid prefix %q", f.prefix)
+		}
+		p = p.Masked()
+		r := ipRange{Range: p.String()}
+
+		gw := p.Addr().Next()
+		if f.gateway != "" {
+			if gw, err = netip.ParseAddr(f.gateway); err != nil || !p.Contains(gw) {
+				return nil, fmt.Errorf("gateway %q is not in prefix %s", f.gateway, p)
+			}
+		}
+		r.Exclude = append(r.Exclude, netip.PrefixFrom(gw, gw.BitLen()).String())
+		if egress, err := netip.ParseAddr(f.egress); err == nil && p.Contains(egress) && egress != gw {
+			r.Exclude = append(r.Exclude, netip.PrefixFrom(egress, egress.BitLen()).String())
+		}
+		out = append(out, r)
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no prefixes yet")
+	}
+	return out, nil
+}
+
+func join(list, item string) string {
+	if list == "" {
+		return item
+	}
+	return list + "," + item
+}
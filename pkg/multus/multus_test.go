@@ -0,0 +1,106 @@
+package multus
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func newNetworkNamespace() *v1alpha1.NetworkNamespace {
+	return &v1alpha1.NetworkNamespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "tenant-a"},
+		Status: v1alpha1.NetworkNamespaceStatus{
+			VlanID:       120,
+			IPv4Prefix:   "10.12.0.0/24",
+			IPv6Prefix:   "2001:db8:12::/64",
+			IPv4EgressIP: "10.12.0.10",
+			IPv6EgressIP: "2001:db8:12::10",
+		},
+	}
+}
+
+func TestNetworkAttachmentDefinitionGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   Options
+		status func(*v1alpha1.NetworkNamespaceStatus)
+	}{
+		{name: "bridge", opts: Options{Type: TypeBridge}},
+		{name: "bridge-ipv4-gateway", opts: Options{Type: TypeBridge, Bridge: "br-tenant", IPv4Gateway: "10.12.0.254", MTU: 9000},
+			status: func(s *v1alpha1.NetworkNamespaceStatus) { s.IPv6Prefix, s.IPv6EgressIP = "", "" }},
+		{name: "macvlan", opts: Options{Type: TypeMacvlan, Master: "bond0", Name: "tenant-net", Namespace: "vms"}},
+		{name: "macvlan-no-ipam", opts: Options{Type: TypeMacvlan, Master: "bond0", IPAM: IPAMNone, MTU: 1450}},
+		{name: "ovn", opts: Options{Type: TypeOVN, PhysicalNetwork: "datacenter"}},
+		{name: "ovn-ipv6", opts: Options{Type: TypeOVN},
+			status: func(s *v1alpha1.NetworkNamespaceStatus) { s.IPv4Prefix, s.IPv4EgressIP = "", "" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nn := newNetworkNamespace()
+			if tt.status != nil {
+				tt.status(&nn.Status)
+			}
+			u, err := NetworkAttachmentDefinition(nn, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := yaml.Marshal(u.Object)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, tt.name+".yaml", string(got))
+		})
+	}
+}
+
+func TestNetworkAttachmentDefinitionErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   Options
+		status func(*v1alpha1.NetworkNamespaceStatus)
+	}{
+		{name: "no VLAN ID", opts: Options{Type: TypeBridge}, status: func(s *v1alpha1.NetworkNamespaceStatus) { s.VlanID = 0 }},
+		{name: "no prefixes", opts: Options{Type: TypeBridge}, status: func(s *v1alpha1.NetworkNamespaceStatus) { s.IPv4Prefix, s.IPv6Prefix = "", "" }},
+		{name: "IPv6 prefix as IPv4", opts: Options{Type: TypeBridge}, status: func(s *v1alpha1.NetworkNamespaceStatus) { s.IPv4Prefix = "2001:db8::/64" }},
+		{name: "gateway outside the prefix", opts: Options{Type: TypeBridge, IPv4Gateway: "10.13.0.1"}},
+		{name: "macvlan without master", opts: Options{Type: TypeMacvlan}},
+		{name: "unknown IPAM", opts: Options{Type: TypeBridge, IPAM: "host-local"}},
+		{name: "unknown type", opts: Options{Type: "ipvlan"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nn := newNetworkNamespace()
+			if tt.status != nil {
+				tt.status(&nn.Status)
+			}
+			if _, err := NetworkAttachmentDefinition(nn, tt.opts); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s, run go test -update to accept it:\n%s", path, got)
+	}
+}
@@ -0,0 +1,341 @@
+// Package netcheck validates the networks of several objects against each
+// other: cluster pod and service CIDRs against VPCs, subnets and
+// NetworkNamespace prefixes, overlapping siblings, subnets outside their VPC,
+// DNS service IPs outside the service CIDR and gateways outside their subnet.
+// Such mistakes otherwise only show once routing breaks.
+package netcheck
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/vitistack/crds/pkg/clusternet"
+	"github.com/vitistack/crds/pkg/netconfig"
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+var (
+	// ErrInvalid is wrapped by errors of CIDRs and addresses that cannot be
+	// parsed.
+	ErrInvalid = errors.New("invalid network")
+	// ErrOverlap is wrapped by errors of overlapping networks.
+	ErrOverlap = errors.New("networks overlap")
+	// ErrOutside is wrapped by errors of subnets, gateways and DNS service IPs
+	// outside the network they belong to.
+	ErrOutside = errors.New("outside its network")
+)
+
+// Objects are the objects validated together. Any of them may be empty.
+type Objects struct {
+	Vitistacks            []v1alpha1.Vitistack
+	MachineProviders      []v1alpha1.MachineProvider
+	KubernetesProviders   []v1alpha1.KubernetesProvider
+	NetworkNamespaces     []v1alpha1.NetworkNamespace
+	NetworkConfigurations []v1alpha1.NetworkConfiguration
+}
+
+// network is a CIDR of an object.
+type network struct {
+	prefix netip.Prefix
+	// what names the object and field, e.g. "Vitistack prod VPC main".
+	what string
+}
+
+func (n network) String() string {
+	return fmt.Sprintf("%s %s", n.what, n.prefix)
+}
+
+// problem is an error that reads as its message and wraps its kind.
+type problem struct {
+	kind    error
+	message string
+}
+
+func (p *problem) Error() string { return p.message }
+func (p *problem) Unwrap() error { return p.kind }
+
+type checker struct {
+	errs []error
+}
+
+func (c *checker) fail(kind error, format string, args ...any) {
+	c.errs = append(c.errs, &problem{kind, fmt.Sprintf(format, args...)})
+}
+
+// parse parses a CIDR. It returns false for an empty or invalid one, and
+// reports the invalid one.
+func (c *checker) parse(s, what string) (network, bool) {
+	if s == "" {
+		return network{}, false
+	}
+	p, err := netip.ParsePrefix(strings.TrimSpace(s))
+	if err != nil {
+		c.fail(ErrInvalid, "%s %q is not a CIDR", what, s)
+		return network{}, false
+	}
+	return network{p.Masked(), what}, true
+}
+
+// disjoint reports every pair of overlapping networks.
+func (c *checker) disjoint(networks []network) {
+	for i, a := range networks {
+		for _, b := range networks[i+1:] {
+			if a.prefix.Overlaps(b.prefix) {
+				c.fail(ErrOverlap, "%s overlaps %s", b, a)
+			}
+		}
+	}
+}
+
+// inside reports a network not contained in its parent.
+func (c *checker) inside(child, parent network) {
+	if parent.prefix.Bits() > child.prefix.Bits() || !parent.prefix.Contains(child.prefix.Addr()) {
+		c.fail(ErrOutside, "%s is not inside %s", child, parent)
+	}
+}
+
+// Validate checks the networks of the objects:
+//
+//   - The VPCs of a Vitistack or MachineProvider, the subnets of a VPC, and
+//     the prefixes of the NetworkNamespaces of a datacenter do not overlap.
+//   - Every subnet is inside its VPC.
+//   - The pod and service CIDRs of a KubernetesProvider do not overlap each
+//     other, nor any VPC, subnet or NetworkNamespace prefix.
+//   - The DNS service IP of a KubernetesProvider is inside its service CIDR.
+//   - The gateways of a NetworkConfiguration are inside a subnet of their
+//     device, set as ipv4Subnet/ipv6Subnet or given by an address with a
+//     prefix length.
+//
+// Networks of different Vitistacks and MachineProviders may describe the
+// same network and are not compared with each other, nor are the cluster
+// networks of different KubernetesProviders, which are private to each
+// cluster. The returned error joins one error per problem, each wrapping
+// ErrInvalid, ErrOverlap or ErrOutside.
+func Validate(objs *Objects) error {
+	c := &checker{}
+	var infrastructure []network
+
+	for i := range objs.Vitistacks {
+		vs := &objs.Vitistacks[i]
+		var vpcs []vpc
+		for _, v := range vs.Spec.Networking.VPCs {
+			p := vpc{name: v.Name, cidr: v.CIDR}
+			for _, s := range v.Subnets {
+				p.subnets = append(p.subnets, subnet{s.Name, s.CIDR})
+			}
+			vpcs = append(vpcs, p)
+		}
+		infrastructure = append(infrastructure, c.vpcs("Vitistack "+vs.Name, vpcs)...)
+	}
+	for i := range objs.MachineProviders {
+		mp := &objs.MachineProviders[i]
+		var vpcs []vpc
+		for _, v := range mp.Spec.Network.AvailableVPCs {
+			p := vpc{name: v.Name, cidr: v.CIDR}
+			for _, s := range v.Subnets {
+				p.subnets = append(p.subnets, subnet{s.Name, s.CIDR})
+			}
+			vpcs = append(vpcs, p)
+		}
+		infrastructure = append(infrastructure, c.vpcs("MachineProvider "+mp.Name, vpcs)...)
+	}
+
+	byDatacenter := map[string][]network{}
+	var datacenters []string
+	for i := range objs.NetworkNamespaces {
+		nn := &objs.NetworkNamespaces[i]
+		for _, f := range []struct{ field, cidr string }{
+			{"ipv4Prefix", nn.Status.IPv4Prefix},
+			{"ipv6Prefix", nn.Status.IPv6Prefix},
+		} {
+			n, ok := c.parse(f.cidr, fmt.Sprintf("NetworkNamespace %s/%s %s", nn.Namespace, nn.Name, f.field))
+			if !ok {
+				continue
+			}
+			dc := nn.Spec.DatacenterIdentifier
+			if _, seen := byDatacenter[dc]; !seen {
+				datacenters = append(datacenters, dc)
+			}
+			byDatacenter[dc] = append(byDatacenter[dc], n)
+			infrastructure = append(infrastructure, n)
+		}
+	}
+	for _, dc := range datacenters {
+		c.disjoint(byDatacenter[dc])
+	}
+
+	for i := range objs.KubernetesProviders {
+		c.cluster(&objs.KubernetesProviders[i], infrastructure)
+	}
+	for i := range objs.NetworkConfigurations {
+		c.gateways(&objs.NetworkConfigurations[i])
+	}
+	return errors.Join(c.errs...)
+}
+
+// vpc is a VPC of a Vitistack or MachineProvider.
+type vpc struct {
+	name, cidr string
+	subnets    []subnet
+}
+
+type subnet struct {
+	name, cidr string
+}
+
+// vpcs checks the VPCs of an object and returns their networks and those of
+// their subnets.
+func (c *checker) vpcs(owner string, vpcs []vpc) []network {
+	var out, parents []network
+	for _, v := range vpcs {
+		parent, hasParent := c.parse(v.cidr, fmt.Sprintf("%s VPC %s", owner, v.name))
+		if hasParent {
+			parents = append(parents, parent)
+		}
+		var subnets []network
+		for _, s := range v.subnets {
+			n, ok := c.parse(s.cidr, fmt.Sprintf("%s subnet %s/%s", owner, v.name, s.name))
+			if !ok {
+				continue
+			}
+			if hasParent {
+				c.inside(n, parent)
+			}
+			subnets = append(subnets, n)
+		}
+		c.disjoint(subnets)
+		out = append(out, subnets...)
+	}
+	c.disjoint(parents)
+	return append(parents, out...)
+}
+
+// cluster checks the pod and service CIDRs and the DNS service IP of a
+// KubernetesProvider.
+func (c *checker) cluster(kp *v1alpha1.KubernetesProvider, infrastructure []network) {
+	cfg := &kp.Spec.Network
+	var own, services []network
+	for _, f := range []struct {
+		field   string
+		cidrs   []string
+		service bool
+	}{
+		{"podCIDRs", cfg.PodCIDRs, false},
+		{"podCIDR", legacy(cfg.PodCIDRs, cfg.PodCIDR), false},
+		{"serviceCIDRs", cfg.ServiceCIDRs, true},
+		{"serviceCIDR", legacy(cfg.ServiceCIDRs, cfg.ServiceCIDR), true},
+	} {
+		for _, cidr := range f.cidrs {
+			n, ok := c.parse(cidr, fmt.Sprintf("KubernetesProvider %s %s", kp.Name, f.field))
+			if !ok {
+				continue
+			}
+			own = append(own, n)
+			if f.service {
+				services = append(services, n)
+			}
+		}
+	}
+	c.disjoint(own)
+	for _, n := range own {
+		var overlapping []network
+		for _, infra := range infrastructure {
+			if n.prefix.Overlaps(infra.prefix) {
+				overlapping = append(overlapping, infra)
+			}
+		}
+		// A VPC overlapping says enough, leave out its subnets.
+		for _, infra := range overlapping {
+			if !slices.ContainsFunc(overlapping, func(o network) bool {
+				return o.prefix.Bits() < infra.prefix.Bits() && o.prefix.Contains(infra.prefix.Addr())
+			}) {
+				c.fail(ErrOverlap, "%s overlaps %s", n, infra)
+			}
+		}
+	}
+
+	if cfg.DNSServiceIP == "" {
+		return
+	}
+	dns, err := netip.ParseAddr(strings.TrimSpace(cfg.DNSServiceIP))
+	if err != nil {
+		c.fail(ErrInvalid, "KubernetesProvider %s dnsServiceIP %q is not an address", kp.Name, cfg.DNSServiceIP)
+		return
+	}
+	if len(clusternet.ServiceCIDRs(cfg)) == 0 {
+		c.fail(ErrOutside, "KubernetesProvider %s has a dnsServiceIP but no service CIDR", kp.Name)
+		return
+	}
+	i := slices.IndexFunc(services, func(n network) bool { return n.prefix.Contains(dns) })
+	switch {
+	case i < 0:
+		if len(services) > 0 {
+			c.fail(ErrOutside, "KubernetesProvider %s dnsServiceIP %s is not inside a service CIDR", kp.Name, dns)
+		}
+	case dns == services[i].prefix.Addr():
+		c.fail(ErrOutside, "KubernetesProvider %s dnsServiceIP %s is the network address of %s", kp.Name, dns, services[i])
+	}
+}
+
+// legacy returns a deprecated single CIDR field unless it is listed.
+func legacy(list []string, cidr string) []string {
+	if cidr == "" || slices.Contains(list, cidr) {
+		return nil
+	}
+	return []string{cidr}
+}
+
+// gateways checks that the gateways of the devices of a NetworkConfiguration
+// are inside a subnet of their device. The subnets are ipv4Subnet and
+// ipv6Subnet and those of the addresses, as netconfig derives them.
+func (c *checker) gateways(nc *v1alpha1.NetworkConfiguration) {
+	for _, d := range netconfig.Devices(nc) {
+		a := &d.Addressing
+		if a.IPv4Gateway == "" && a.IPv6Gateway == "" {
+			continue
+		}
+		what := fmt.Sprintf("NetworkConfiguration %s/%s %s %s", nc.Namespace, nc.Name, d.Kind, d.Name)
+		prefixes, err := netconfig.Addresses(a)
+		if err != nil {
+			c.fail(ErrInvalid, "%s: %v", what, err)
+			continue
+		}
+		for _, f := range []struct {
+			family, name, subnet, gateway string
+			v4                            bool
+		}{
+			{"ipv4", "IPv4", a.IPv4Subnet, a.IPv4Gateway, true},
+			{"ipv6", "IPv6", a.IPv6Subnet, a.IPv6Gateway, false},
+		} {
+			if f.gateway == "" {
+				continue
+			}
+			gw, err := netip.ParseAddr(strings.TrimSpace(f.gateway))
+			if err != nil || gw.Is4() != f.v4 {
+				c.fail(ErrInvalid, "%s %sGateway %q is not an %s address", what, f.family, f.gateway, f.name)
+				continue
+			}
+			var subnets []netip.Prefix
+			if f.subnet != "" {
+				subnets = append(subnets, netip.MustParsePrefix(f.subnet).Masked())
+			}
+			for _, p := range prefixes {
+				if p.Addr().Is4() == f.v4 && !slices.Contains(subnets, p.Masked()) {
+					subnets = append(subnets, p.Masked())
+				}
+			}
+			// A device without static addressing gets its subnet from DHCP.
+			if len(subnets) == 0 || slices.ContainsFunc(subnets, func(p netip.Prefix) bool { return p.Contains(gw) }) {
+				continue
+			}
+			var list []string
+			for _, p := range subnets {
+				list = append(list, p.String())
+			}
+			c.fail(ErrOutside, "%s %sGateway %s is not inside %s", what, f.family, gw, strings.Join(list, " or "))
+		}
+	}
+}
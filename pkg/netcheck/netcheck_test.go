@@ -0,0 +1,199 @@
+package netcheck
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func vitistack(name, cidr string, subnets ...string) v1alpha1.Vitistack {
+	vpc := v1alpha1.VitistackVPC{Name: "main", CIDR: cidr}
+	for i, s := range subnets {
+		vpc.Subnets = append(vpc.Subnets, v1alpha1.VitistackSubnet{Name: string(rune('a' + i)), CIDR: s})
+	}
+	vs := v1alpha1.Vitistack{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	vs.Spec.Networking.VPCs = []v1alpha1.VitistackVPC{vpc}
+	return vs
+}
+
+func kubernetesProvider(name string, network v1alpha1.KubernetesNetworkConfig) v1alpha1.KubernetesProvider {
+	kp := v1alpha1.KubernetesProvider{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	kp.Spec.Network = network
+	return kp
+}
+
+func networkNamespace(name, datacenter, ipv4Prefix string) v1alpha1.NetworkNamespace {
+	nn := v1alpha1.NetworkNamespace{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	nn.Spec.DatacenterIdentifier = datacenter
+	nn.Status.IPv4Prefix = ipv4Prefix
+	return nn
+}
+
+func networkConfiguration(nics ...v1alpha1.NetworkConfigurationInterface) v1alpha1.NetworkConfiguration {
+	nc := v1alpha1.NetworkConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Namespace: "default"}}
+	nc.Spec.NetworkInterfaces = nics
+	return nc
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		objs Objects
+		// is is a sentinel the error wraps, if any.
+		is error
+		// want lists parts of the error, one per problem.
+		want []string
+	}{
+		{
+			name: "valid",
+			objs: Objects{
+				Vitistacks: []v1alpha1.Vitistack{vitistack("prod", "10.0.0.0/16", "10.0.0.0/24", "10.0.1.0/24")},
+				KubernetesProviders: []v1alpha1.KubernetesProvider{kubernetesProvider("k8s", v1alpha1.KubernetesNetworkConfig{
+					PodCIDRs: []string{"10.244.0.0/16"}, ServiceCIDRs: []string{"10.96.0.0/12"}, DNSServiceIP: "10.96.0.10",
+				})},
+				NetworkNamespaces: []v1alpha1.NetworkNamespace{
+					networkNamespace("a", "no-west-az1", "10.200.0.0/24"),
+					networkNamespace("b", "no-west-az2", "10.200.0.0/24"),
+				},
+				NetworkConfigurations: []v1alpha1.NetworkConfiguration{networkConfiguration(
+					v1alpha1.NetworkConfigurationInterface{Name: "eth0", IPv4Addresses: []string{"10.0.0.5/24"}, IPv4Gateway: "10.0.0.1"},
+				)},
+			},
+		},
+		{
+			name: "vitistack and machine provider describe the same VPC",
+			objs: Objects{
+				Vitistacks: []v1alpha1.Vitistack{vitistack("prod", "10.0.0.0/16")},
+				MachineProviders: []v1alpha1.MachineProvider{{
+					ObjectMeta: metav1.ObjectMeta{Name: "proxmox"},
+					Spec: v1alpha1.MachineProviderSpec{Network: v1alpha1.ProviderNetworkConfig{
+						AvailableVPCs: []v1alpha1.VPCInfo{{Name: "main", CIDR: "10.0.0.0/16"}},
+					}},
+				}},
+			},
+		},
+		{
+			name: "subnets overlap and leave their VPC",
+			objs: Objects{Vitistacks: []v1alpha1.Vitistack{vitistack("prod", "10.0.0.0/16", "10.0.0.0/24", "10.0.0.128/25", "10.1.0.0/24")}},
+			want: []string{
+				"Vitistack prod subnet main/b 10.0.0.128/25 overlaps Vitistack prod subnet main/a 10.0.0.0/24",
+				"Vitistack prod subnet main/c 10.1.0.0/24 is not inside Vitistack prod VPC main 10.0.0.0/16",
+			},
+		},
+		{
+			name: "invalid CIDR",
+			objs: Objects{Vitistacks: []v1alpha1.Vitistack{vitistack("prod", "10.0.0.0/33")}},
+			is:   ErrInvalid,
+			want: []string{`Vitistack prod VPC main "10.0.0.0/33" is not a CIDR`},
+		},
+		{
+			name: "network namespaces of a datacenter overlap",
+			objs: Objects{NetworkNamespaces: []v1alpha1.NetworkNamespace{
+				networkNamespace("a", "no-west-az1", "10.100.0.0/24"),
+				networkNamespace("b", "no-west-az1", "10.100.0.0/23"),
+			}},
+			is:   ErrOverlap,
+			want: []string{"NetworkNamespace default/b ipv4Prefix 10.100.0.0/23 overlaps NetworkNamespace default/a ipv4Prefix 10.100.0.0/24"},
+		},
+		{
+			name: "cluster networks overlap the infrastructure",
+			objs: Objects{
+				Vitistacks: []v1alpha1.Vitistack{vitistack("prod", "10.0.0.0/16", "10.0.0.0/24")},
+				KubernetesProviders: []v1alpha1.KubernetesProvider{kubernetesProvider("k8s", v1alpha1.KubernetesNetworkConfig{
+					PodCIDRs: []string{"10.0.0.0/15"}, ServiceCIDR: "10.1.0.0/16",
+				})},
+			},
+			is: ErrOverlap,
+			want: []string{
+				"KubernetesProvider k8s serviceCIDR 10.1.0.0/16 overlaps KubernetesProvider k8s podCIDRs 10.0.0.0/15",
+				"KubernetesProvider k8s podCIDRs 10.0.0.0/15 overlaps Vitistack prod VPC main 10.0.0.0/16",
+			},
+		},
+		{
+			name: "DNS service IP outside the service CIDR",
+			objs: Objects{KubernetesProviders: []v1alpha1.KubernetesProvider{kubernetesProvider("k8s", v1alpha1.KubernetesNetworkConfig{
+				ServiceCIDRs: []string{"10.96.0.0/12"}, DNSServiceIP: "10.200.0.10",
+			})}},
+			is:   ErrOutside,
+			want: []string{"KubernetesProvider k8s dnsServiceIP 10.200.0.10 is not inside a service CIDR"},
+		},
+		{
+			name: "DNS service IP is the network address",
+			objs: Objects{KubernetesProviders: []v1alpha1.KubernetesProvider{kubernetesProvider("k8s", v1alpha1.KubernetesNetworkConfig{
+				ServiceCIDR: "10.96.0.0/12", DNSServiceIP: "10.96.0.0",
+			})}},
+			is:   ErrOutside,
+			want: []string{"KubernetesProvider k8s dnsServiceIP 10.96.0.0 is the network address of KubernetesProvider k8s serviceCIDR 10.96.0.0/12"},
+		},
+		{
+			name: "gateway outside the subnet",
+			objs: Objects{NetworkConfigurations: []v1alpha1.NetworkConfiguration{networkConfiguration(
+				v1alpha1.NetworkConfigurationInterface{Name: "eth0", IPv4Subnet: "10.0.0.0/24", IPv4Gateway: "10.0.1.1"},
+			)}},
+			is:   ErrOutside,
+			want: []string{"NetworkConfiguration default/node-1 interface eth0 ipv4Gateway 10.0.1.1 is not inside 10.0.0.0/24"},
+		},
+		{
+			name: "gateway outside the subnet of the addresses",
+			objs: Objects{NetworkConfigurations: []v1alpha1.NetworkConfiguration{networkConfiguration(
+				v1alpha1.NetworkConfigurationInterface{Name: "eth0", IPv4Addresses: []string{"10.0.0.5/24", "10.0.2.5/24"}, IPv4Gateway: "10.0.1.1"},
+				v1alpha1.NetworkConfigurationInterface{Name: "eth1", IPv6Addresses: []string{"2001:db8::5/64"}, IPv6Gateway: "2001:db8:1::1"},
+			)}},
+			is: ErrOutside,
+			want: []string{
+				"NetworkConfiguration default/node-1 interface eth0 ipv4Gateway 10.0.1.1 is not inside 10.0.0.0/24 or 10.0.2.0/24",
+				"NetworkConfiguration default/node-1 interface eth1 ipv6Gateway 2001:db8:1::1 is not inside 2001:db8::/64",
+			},
+		},
+		{
+			name: "gateway of a DHCP device",
+			objs: Objects{NetworkConfigurations: []v1alpha1.NetworkConfiguration{networkConfiguration(
+				v1alpha1.NetworkConfigurationInterface{Name: "eth0", DHCPReserved: true, IPv4Gateway: "10.0.1.1"},
+			)}},
+		},
+		{
+			name: "gateway of the wrong family",
+			objs: Objects{NetworkConfigurations: []v1alpha1.NetworkConfiguration{networkConfiguration(
+				v1alpha1.NetworkConfigurationInterface{Name: "eth0", IPv6Addresses: []string{"2001:db8::5/64"}, IPv6Gateway: "10.0.0.1"},
+			)}},
+			is:   ErrInvalid,
+			want: []string{`NetworkConfiguration default/node-1 interface eth0 ipv6Gateway "10.0.0.1" is not an IPv6 address`},
+		},
+		{
+			name: "address without a prefix length",
+			objs: Objects{NetworkConfigurations: []v1alpha1.NetworkConfiguration{networkConfiguration(
+				v1alpha1.NetworkConfigurationInterface{Name: "eth0", IPv4Addresses: []string{"10.0.0.5"}, IPv4Gateway: "10.0.0.1"},
+			)}},
+			is:   ErrInvalid,
+			want: []string{"NetworkConfiguration default/node-1 interface eth0: address 10.0.0.5 has no prefix length and no subnet is set"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&tt.objs)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("error = %v, want none", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("no error, want %q", tt.want)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error = %v, want %q", err, want)
+				}
+			}
+			if n := len(strings.Split(err.Error(), "\n")); n != len(tt.want) {
+				t.Errorf("%d errors, want %d:\n%v", n, len(tt.want), err)
+			}
+			if tt.is != nil && !errors.Is(err, tt.is) {
+				t.Errorf("error = %v, want it to wrap %v", err, tt.is)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+// Package netconfig validates the host network of a NetworkConfiguration:
+// network interfaces, bonds, bridges, VLAN sub-interfaces and static routes.
+// It renders the network as netplan YAML, NetworkManager keyfiles or
+// systemd-networkd units.
+package netconfig
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// DefaultMTU is the MTU of devices without one.
+const DefaultMTU = 1500
+
+// DefaultMIIMonitorInterval is the bond link monitoring interval in
+// milliseconds when none is set.
+const DefaultMIIMonitorInterval = 100
+
+// Kinds of devices.
+const (
+	KindInterface = "interface"
+	KindBond      = "bond"
+	KindBridge    = "bridge"
+	KindVLAN      = "vlan"
+)
+
+// Device is a network device of a NetworkConfiguration with its relations.
+type Device struct {
+	Name string
+	Kind string
+	// Addressing of the device. For network interfaces it is built from the
+	// interface fields.
+	Addressing v1alpha1.NetworkConfigurationAddressing
+	// Lower devices: the members of a bond or bridge, the link of a VLAN.
+	Lower []string
+	// Master is the bond or bridge the device is a member of, if any.
+	Master string
+
+	Interface *v1alpha1.NetworkConfigurationInterface
+	Bond      *v1alpha1.NetworkConfigurationBond
+	Bridge    *v1alpha1.NetworkConfigurationBridge
+	VLAN      *v1alpha1.NetworkConfigurationVLAN
+}
+
+// MTU returns the MTU of the device, applying the default.
+func (d *Device) MTU() int32 {
+	if d.Addressing.MTU == 0 {
+		return DefaultMTU
+	}
+	return d.Addressing.MTU
+}
+
+// Devices returns the devices of a NetworkConfiguration in spec order:
+// network interfaces, bonds, bridges, then VLANs. Network interfaces without
+// a name are left out, as nothing can refer to them. When a device is a
+// member of several masters, Master is the first one.
+func Devices(nc *v1alpha1.NetworkConfiguration) []*Device {
+	var devices []*Device
+	byName := map[string]*Device{}
+	add := func(d *Device) {
+		devices = append(devices, d)
+		if _, ok := byName[d.Name]; !ok {
+			byName[d.Name] = d
+		}
+	}
+	for i := range nc.Spec.NetworkInterfaces {
+		nic := &nc.Spec.NetworkInterfaces[i]
+		if nic.Name != "" {
+			add(&Device{Name: nic.Name, Kind: KindInterface, Addressing: InterfaceAddressing(nic), Interface: nic})
+		}
+	}
+	for i := range nc.Spec.Bonds {
+		b := &nc.Spec.Bonds[i]
+		add(&Device{Name: b.Name, Kind: KindBond, Addressing: b.NetworkConfigurationAddressing, Lower: b.Interfaces, Bond: b})
+	}
+	for i := range nc.Spec.Bridges {
+		b := &nc.Spec.Bridges[i]
+		add(&Device{Name: b.Name, Kind: KindBridge, Addressing: b.NetworkConfigurationAddressing, Lower: b.Interfaces, Bridge: b})
+	}
+	for i := range nc.Spec.VLANs {
+		v := &nc.Spec.VLANs[i]
+		add(&Device{Name: v.Name, Kind: KindVLAN, Addressing: v.NetworkConfigurationAddressing, Lower: []string{v.Link}, VLAN: v})
+	}
+	for _, d := range devices {
+		if d.Kind != KindBond && d.Kind != KindBridge {
+			continue
+		}
+		for _, member := range d.Lower {
+			if m, ok := byName[member]; ok && m.Master == "" {
+				m.Master = d.Name
+			}
+		}
+	}
+	return devices
+}
+
+// InterfaceAddressing returns the layer 3 configuration of a network interface.
+func InterfaceAddressing(nic *v1alpha1.NetworkConfigurationInterface) v1alpha1.NetworkConfigurationAddressing {
+	return v1alpha1.NetworkConfigurationAddressing{
+		IPv4Addresses: nic.IPv4Addresses,
+		IPv6Addresses: nic.IPv6Addresses,
+		IPv4Subnet:    nic.IPv4Subnet,
+		IPv6Subnet:    nic.IPv6Subnet,
+		IPv4Gateway:   nic.IPv4Gateway,
+		IPv6Gateway:   nic.IPv6Gateway,
+		DNS:           nic.DNS,
+		DHCPReserved:  nic.DHCPReserved,
+		MTU:           nic.MTU,
+	}
+}
+
+// Addresses returns the static addresses of a device with their prefix
+// length, IPv4 first. An address without a prefix length takes it from the
+// subnet of its family.
+func Addresses(a *v1alpha1.NetworkConfigurationAddressing) ([]netip.Prefix, error) {
+	var out []netip.Prefix
+	for _, family := range []struct {
+		addresses []string
+		subnet    string
+		v4        bool
+	}{{a.IPv4Addresses, a.IPv4Subnet, true}, {a.IPv6Addresses, a.IPv6Subnet, false}} {
+		var subnet netip.Prefix
+		if family.subnet != "" {
+			var err error
+			if subnet, err = netip.ParsePrefix(family.subnet); err != nil {
+				return nil, fmt.Errorf("invalid subnet %q", family.subnet)
+			}
+			subnet = subnet.Masked()
+		}
+		for _, s := range family.addresses {
+			p, err := parseAddress(s, subnet)
+			if err != nil {
+				return nil, err
+			}
+			if p.Addr().Is4() != family.v4 {
+				return nil, fmt.Errorf("address %s is in the wrong family list", s)
+			}
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func parseAddress(s string, subnet netip.Prefix) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid address %q", s)
+		}
+		if subnet.IsValid() && (p.Bits() != subnet.Bits() || !subnet.Contains(p.Addr())) {
+			return netip.Prefix{}, fmt.Errorf("address %s is not in subnet %s", s, subnet)
+		}
+		return p, nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid address %q", s)
+	}
+	if !subnet.IsValid() {
+		return netip.Prefix{}, fmt.Errorf("address %s has no prefix length and no subnet is set", s)
+	}
+	if !subnet.Contains(addr) {
+		return netip.Prefix{}, fmt.Errorf("address %s is not in subnet %s", s, subnet)
+	}
+	return netip.PrefixFrom(addr, subnet.Bits()), nil
+}
+
+// RouteDestination returns the destination of a route. "default" is
+// 0.0.0.0/0, or ::/0 when the gateway is IPv6.
+func RouteDestination(r *v1alpha1.NetworkConfigurationRoute) (netip.Prefix, error) {
+	if r.To == "default" {
+		if via, err := netip.ParseAddr(r.Via); err == nil && via.Is6() && !via.Is4In6() {
+			return netip.MustParsePrefix("::/0"), nil
+		}
+		return netip.MustParsePrefix("0.0.0.0/0"), nil
+	}
+	p, err := netip.ParsePrefix(r.To)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid route destination %q", r.To)
+	}
+	return p.Masked(), nil
+}
+
+// SetApplied records in the status that the spec has been applied to the host.
+func SetApplied(nc *v1alpha1.NetworkConfiguration) {
+	spec := nc.Spec.DeepCopy()
+	nc.Status.NetworkInterfaces = spec.NetworkInterfaces
+	nc.Status.Bonds = spec.Bonds
+	nc.Status.Bridges = spec.Bridges
+	nc.Status.VLANs = spec.VLANs
+	nc.Status.Routes = spec.Routes
+	nc.Status.ObservedGeneration = nc.Generation
+}
+
+// InSync reports whether the status mirrors the current spec, i.e. the
+// host runs the configuration as specified.
+func InSync(nc *v1alpha1.NetworkConfiguration) bool {
+	return nc.Status.ObservedGeneration == nc.Generation &&
+		equality.Semantic.DeepEqual(nc.Spec.NetworkInterfaces, nc.Status.NetworkInterfaces) &&
+		equality.Semantic.DeepEqual(nc.Spec.Bonds, nc.Status.Bonds) &&
+		equality.Semantic.DeepEqual(nc.Spec.Bridges, nc.Status.Bridges) &&
+		equality.Semantic.DeepEqual(nc.Spec.VLANs, nc.Status.VLANs) &&
+		equality.Semantic.DeepEqual(nc.Spec.Routes, nc.Status.Routes)
+}
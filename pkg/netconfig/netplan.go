@@ -0,0 +1,181 @@
+package netconfig
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+// DefaultNetplanFile is where the netplan configuration is written.
+const DefaultNetplanFile = "/etc/netplan/60-vitistack.yaml"
+
+// RenderNetplan renders a NetworkConfiguration as a netplan version 2 file.
+// Network interfaces with a MAC address are matched on it and renamed to
+// their name. DHCP reserved devices use DHCPv4, and DHCPv6 when they have
+// IPv6 addresses or an IPv6 subnet; other devices get their addresses,
+// gateways and routes statically.
+func RenderNetplan(nc *v1alpha1.NetworkConfiguration) (string, error) {
+	devices, err := plan(nc)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated from NetworkConfiguration %s/%s, do not edit.\n", nc.Namespace, nc.Name)
+	b.WriteString("network:\n")
+	b.WriteString("  version: 2\n")
+	b.WriteString("  renderer: networkd\n")
+	for _, section := range []struct {
+		name string
+		kind string
+	}{{"ethernets", KindInterface}, {"bonds", KindBond}, {"bridges", KindBridge}, {"vlans", KindVLAN}} {
+		wrote := false
+		for _, d := range devices {
+			if d.Kind != section.kind {
+				continue
+			}
+			if !wrote {
+				fmt.Fprintf(&b, "  %s:\n", section.name)
+				wrote = true
+			}
+			writeNetplanDevice(&b, d)
+		}
+	}
+	return b.String(), nil
+}
+
+func writeNetplanDevice(b *strings.Builder, d *hostDevice) {
+	var lines []string
+	add := func(indent int, format string, args ...any) {
+		lines = append(lines, strings.Repeat("  ", indent)+fmt.Sprintf(format, args...))
+	}
+
+	switch d.Kind {
+	case KindInterface:
+		if mac := d.Interface.MacAddress; mac != "" {
+			add(0, "match:")
+			add(1, "macaddress: %s", yamlString(strings.ToLower(mac)))
+			add(0, "set-name: %s", yamlString(d.Name))
+		}
+	case KindBond, KindBridge:
+		add(0, "interfaces: [%s]", yamlList(d.Lower))
+		add(0, "parameters:")
+		if bond := d.Bond; bond != nil {
+			add(1, "mode: %s", yamlString(bondMode(bond)))
+			if bond.LACPRate != "" {
+				add(1, "lacp-rate: %s", bond.LACPRate)
+			}
+			if bond.TransmitHashPolicy != "" {
+				add(1, "transmit-hash-policy: %s", yamlString(bond.TransmitHashPolicy))
+			}
+			add(1, "mii-monitor-interval: %d", miiMonitorInterval(bond))
+			if bond.Primary != "" {
+				add(1, "primary: %s", yamlString(bond.Primary))
+			}
+		}
+		if br := d.Bridge; br != nil {
+			// netplan enables STP by default, the kernel and the API do not.
+			add(1, "stp: %t", br.STP)
+			if br.ForwardDelay != 0 {
+				add(1, "forward-delay: %d", br.ForwardDelay)
+			}
+		}
+	case KindVLAN:
+		add(0, "id: %d", d.VLAN.ID)
+		add(0, "link: %s", yamlString(d.VLAN.Link))
+	}
+	if d.Addressing.MTU != 0 {
+		add(0, "mtu: %d", d.Addressing.MTU)
+	}
+
+	if d.Master == "" {
+		add(0, "dhcp4: %t", d.DHCP4)
+		add(0, "dhcp6: %t", d.DHCP6)
+		if len(d.Addresses) > 0 {
+			add(0, "addresses:")
+			for _, p := range d.Addresses {
+				add(1, "- %s", yamlString(p.String()))
+			}
+		}
+		if len(d.DNS4)+len(d.DNS6) > 0 {
+			add(0, "nameservers:")
+			var dns []string
+			for _, a := range append(d.DNS4, d.DNS6...) {
+				dns = append(dns, a.String())
+			}
+			add(1, "addresses: [%s]", yamlList(dns))
+		}
+		routes := append(d.routes(true), d.routes(false)...)
+		if len(routes) > 0 {
+			add(0, "routes:")
+			for _, r := range routes {
+				add(1, "- to: %s", yamlString(netplanDestination(r.To)))
+				if r.Via.IsValid() {
+					add(2, "via: %s", yamlString(r.Via.String()))
+				} else {
+					add(2, "scope: link")
+				}
+				if r.Metric != 0 {
+					add(2, "metric: %d", r.Metric)
+				}
+				if r.Table != 0 {
+					add(2, "table: %d", r.Table)
+				}
+				if r.OnLink {
+					add(2, "on-link: true")
+				}
+			}
+		}
+	}
+
+	if len(lines) == 0 {
+		fmt.Fprintf(b, "    %s: {}\n", yamlString(d.Name))
+		return
+	}
+	fmt.Fprintf(b, "    %s:\n", yamlString(d.Name))
+	for _, l := range lines {
+		fmt.Fprintf(b, "      %s\n", l)
+	}
+}
+
+func netplanDestination(p netip.Prefix) string {
+	if p.Bits() == 0 {
+		return "default"
+	}
+	return p.String()
+}
+
+// yamlString quotes a string unless it is plain and cannot be read as
+// another type, e.g. a MAC address as a base 60 number.
+func yamlString(s string) string {
+	plain := s != ""
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		if _, err := strconv.ParseFloat(s, 64); err == nil {
+			plain = false
+		}
+		switch strings.ToLower(s) {
+		case "true", "false", "yes", "no", "on", "off", "y", "n", "null", "~":
+			plain = false
+		}
+	}
+	if plain {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+func yamlList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, s := range items {
+		quoted[i] = yamlString(s)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,146 @@
+package netconfig
+
+import (
+	"fmt"
+	"strings"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+// DefaultNetworkdDir is where systemd-networkd reads its units from.
+const DefaultNetworkdDir = "/etc/systemd/network"
+
+// RenderNetworkd renders a NetworkConfiguration as systemd-networkd units and
+// returns their content by file name:
+//
+//   - 10-<device>.link renames a network interface with a MAC address
+//   - 20-<device>.netdev creates a bond, bridge or VLAN
+//   - 30-<device>.network configures every device
+//
+// DHCP reserved devices use DHCP=ipv4, or DHCP=yes when they have IPv6
+// addresses or an IPv6 subnet; other devices get their addresses, gateways
+// and routes statically.
+func RenderNetworkd(nc *v1alpha1.NetworkConfiguration) (map[string]string, error) {
+	devices, err := plan(nc)
+	if err != nil {
+		return nil, err
+	}
+	header := fmt.Sprintf("# Generated from NetworkConfiguration %s/%s, do not edit.\n", nc.Namespace, nc.Name)
+	files := map[string]string{}
+	for _, d := range devices {
+		if mac := macAddress(d); mac != "" {
+			files["10-"+d.Name+".link"] = header + linkUnit(d.Name, mac)
+		}
+		if d.Kind != KindInterface {
+			files["20-"+d.Name+".netdev"] = header + netdevUnit(d)
+		}
+		files["30-"+d.Name+".network"] = header + networkUnit(d)
+	}
+	return files, nil
+}
+
+func linkUnit(name, mac string) string {
+	var b strings.Builder
+	b.WriteString("[Match]\n")
+	fmt.Fprintf(&b, "PermanentMACAddress=%s\n", strings.ToLower(mac))
+	b.WriteString("\n[Link]\n")
+	fmt.Fprintf(&b, "Name=%s\n", name)
+	return b.String()
+}
+
+func netdevUnit(d *hostDevice) string {
+	var b strings.Builder
+	b.WriteString("[NetDev]\n")
+	fmt.Fprintf(&b, "Name=%s\n", d.Name)
+	fmt.Fprintf(&b, "Kind=%s\n", d.Kind)
+	if d.Addressing.MTU != 0 {
+		fmt.Fprintf(&b, "MTUBytes=%d\n", d.Addressing.MTU)
+	}
+	switch {
+	case d.Bond != nil:
+		b.WriteString("\n[Bond]\n")
+		fmt.Fprintf(&b, "Mode=%s\n", bondMode(d.Bond))
+		fmt.Fprintf(&b, "MIIMonitorSec=%dms\n", miiMonitorInterval(d.Bond))
+		if d.Bond.LACPRate != "" {
+			fmt.Fprintf(&b, "LACPTransmitRate=%s\n", d.Bond.LACPRate)
+		}
+		if d.Bond.TransmitHashPolicy != "" {
+			fmt.Fprintf(&b, "TransmitHashPolicy=%s\n", d.Bond.TransmitHashPolicy)
+		}
+	case d.Bridge != nil:
+		b.WriteString("\n[Bridge]\n")
+		fmt.Fprintf(&b, "STP=%s\n", yesNo(d.Bridge.STP))
+		if d.Bridge.ForwardDelay != 0 {
+			fmt.Fprintf(&b, "ForwardDelaySec=%d\n", d.Bridge.ForwardDelay)
+		}
+	case d.VLAN != nil:
+		b.WriteString("\n[VLAN]\n")
+		fmt.Fprintf(&b, "Id=%d\n", d.VLAN.ID)
+	}
+	return b.String()
+}
+
+func networkUnit(d *hostDevice) string {
+	var b strings.Builder
+	b.WriteString("[Match]\n")
+	fmt.Fprintf(&b, "Name=%s\n", d.Name)
+	if d.Addressing.MTU != 0 {
+		b.WriteString("\n[Link]\n")
+		fmt.Fprintf(&b, "MTUBytes=%d\n", d.Addressing.MTU)
+	}
+
+	b.WriteString("\n[Network]\n")
+	for _, v := range d.VLANs {
+		fmt.Fprintf(&b, "VLAN=%s\n", v)
+	}
+	if d.Master != "" {
+		fmt.Fprintf(&b, "%s=%s\n", map[string]string{KindBond: "Bond", KindBridge: "Bridge"}[d.MasterKind], d.Master)
+		// Members carry no addresses, not even link local ones.
+		b.WriteString("LinkLocalAddressing=no\n")
+		return b.String()
+	}
+	switch {
+	case d.DHCP4 && d.DHCP6:
+		b.WriteString("DHCP=yes\n")
+	case d.DHCP4:
+		b.WriteString("DHCP=ipv4\n")
+	default:
+		b.WriteString("DHCP=no\n")
+	}
+	if !d.has6() {
+		b.WriteString("LinkLocalAddressing=no\n")
+		b.WriteString("IPv6AcceptRA=no\n")
+	}
+	for _, p := range d.Addresses {
+		fmt.Fprintf(&b, "Address=%s\n", p)
+	}
+	for _, a := range append(d.DNS4, d.DNS6...) {
+		fmt.Fprintf(&b, "DNS=%s\n", a)
+	}
+	for _, r := range append(d.routes(true), d.routes(false)...) {
+		b.WriteString("\n[Route]\n")
+		fmt.Fprintf(&b, "Destination=%s\n", r.To)
+		if r.Via.IsValid() {
+			fmt.Fprintf(&b, "Gateway=%s\n", r.Via)
+		} else {
+			b.WriteString("Scope=link\n")
+		}
+		if r.Metric != 0 {
+			fmt.Fprintf(&b, "Metric=%d\n", r.Metric)
+		}
+		if r.Table != 0 {
+			fmt.Fprintf(&b, "Table=%d\n", r.Table)
+		}
+		if r.OnLink {
+			b.WriteString("GatewayOnLink=yes\n")
+		}
+	}
+	return b.String()
+}
+
+func yesNo(v bool) string {
+	if v {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,171 @@
+package netconfig
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+// DefaultKeyfileDir is where NetworkManager reads keyfiles from. Keyfiles
+// must be owned by root with mode 0600, or NetworkManager ignores them.
+const DefaultKeyfileDir = "/etc/NetworkManager/system-connections"
+
+// RenderNetworkManager renders a NetworkConfiguration as NetworkManager
+// keyfiles, one connection per device. It returns the content by file name,
+// <device>.nmconnection. Connection UUIDs are derived from the
+// NetworkConfiguration and device names, so they stay the same across
+// renders. DHCP reserved devices use the auto method; devices without
+// addresses of a family have it disabled.
+func RenderNetworkManager(nc *v1alpha1.NetworkConfiguration) (map[string]string, error) {
+	devices, err := plan(nc)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]string{}
+	for _, d := range devices {
+		files[d.Name+".nmconnection"] = keyfile(nc, d)
+	}
+	return files, nil
+}
+
+func keyfile(nc *v1alpha1.NetworkConfiguration, d *hostDevice) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated from NetworkConfiguration %s/%s, do not edit.\n", nc.Namespace, nc.Name)
+	b.WriteString("[connection]\n")
+	fmt.Fprintf(&b, "id=%s\n", d.Name)
+	fmt.Fprintf(&b, "uuid=%s\n", connectionUUID(nc, d.Name))
+	fmt.Fprintf(&b, "type=%s\n", map[string]string{
+		KindInterface: "ethernet",
+		KindBond:      "bond",
+		KindBridge:    "bridge",
+		KindVLAN:      "vlan",
+	}[d.Kind])
+	fmt.Fprintf(&b, "interface-name=%s\n", d.Name)
+	if d.Master != "" {
+		fmt.Fprintf(&b, "master=%s\n", d.Master)
+		fmt.Fprintf(&b, "slave-type=%s\n", d.MasterKind)
+	}
+
+	if mac := macAddress(d); mac != "" || d.Addressing.MTU != 0 {
+		b.WriteString("\n[ethernet]\n")
+		if mac != "" {
+			fmt.Fprintf(&b, "mac-address=%s\n", strings.ToUpper(mac))
+		}
+		if d.Addressing.MTU != 0 {
+			fmt.Fprintf(&b, "mtu=%d\n", d.Addressing.MTU)
+		}
+	}
+
+	switch {
+	case d.Bond != nil:
+		b.WriteString("\n[bond]\n")
+		fmt.Fprintf(&b, "mode=%s\n", bondMode(d.Bond))
+		fmt.Fprintf(&b, "miimon=%d\n", miiMonitorInterval(d.Bond))
+		if d.Bond.LACPRate != "" {
+			fmt.Fprintf(&b, "lacp_rate=%s\n", d.Bond.LACPRate)
+		}
+		if d.Bond.TransmitHashPolicy != "" {
+			fmt.Fprintf(&b, "xmit_hash_policy=%s\n", d.Bond.TransmitHashPolicy)
+		}
+		if d.Bond.Primary != "" {
+			fmt.Fprintf(&b, "primary=%s\n", d.Bond.Primary)
+		}
+	case d.Bridge != nil:
+		b.WriteString("\n[bridge]\n")
+		// NetworkManager enables STP by default, the kernel and the API do not.
+		fmt.Fprintf(&b, "stp=%t\n", d.Bridge.STP)
+		if d.Bridge.ForwardDelay != 0 {
+			fmt.Fprintf(&b, "forward-delay=%d\n", d.Bridge.ForwardDelay)
+		}
+	case d.VLAN != nil:
+		b.WriteString("\n[vlan]\n")
+		fmt.Fprintf(&b, "id=%d\n", d.VLAN.ID)
+		fmt.Fprintf(&b, "parent=%s\n", d.VLAN.Link)
+	}
+
+	if d.Master == "" {
+		writeKeyfileIP(&b, "ipv4", d.DHCP4, d.has4(), d.Addresses, d.Gateway4, d.DNS4, d.routes(true), true)
+		writeKeyfileIP(&b, "ipv6", d.DHCP6, d.has6(), d.Addresses, d.Gateway6, d.DNS6, d.routes(false), false)
+	}
+	return b.String()
+}
+
+func writeKeyfileIP(b *strings.Builder, section string, dhcp, used bool, addresses []netip.Prefix, gateway netip.Addr, dns []netip.Addr, routes []hostRoute, v4 bool) {
+	fmt.Fprintf(b, "\n[%s]\n", section)
+	switch {
+	case dhcp:
+		b.WriteString("method=auto\n")
+	case used:
+		b.WriteString("method=manual\n")
+	default:
+		b.WriteString("method=disabled\n")
+		return
+	}
+	n := 0
+	for _, p := range addresses {
+		if p.Addr().Is4() == v4 {
+			n++
+			fmt.Fprintf(b, "address%d=%s\n", n, p)
+		}
+	}
+	if gateway.IsValid() {
+		fmt.Fprintf(b, "gateway=%s\n", gateway)
+	}
+	if len(dns) > 0 {
+		b.WriteString("dns=")
+		for _, a := range dns {
+			fmt.Fprintf(b, "%s;", a)
+		}
+		b.WriteString("\n")
+	}
+	n = 0
+	for _, r := range routes {
+		if r.To.Bits() == 0 && r.Via == gateway && r.Metric == 0 && r.Table == 0 && !r.OnLink {
+			// The gateway key above.
+			continue
+		}
+		n++
+		via := r.Via
+		if !via.IsValid() {
+			via = netip.IPv4Unspecified()
+			if !v4 {
+				via = netip.IPv6Unspecified()
+			}
+		}
+		fmt.Fprintf(b, "route%d=%s,%s", n, r.To, via)
+		if r.Metric != 0 {
+			fmt.Fprintf(b, ",%d", r.Metric)
+		}
+		b.WriteString("\n")
+		var opts []string
+		if r.OnLink {
+			opts = append(opts, "onlink=true")
+		}
+		if r.Table != 0 {
+			opts = append(opts, fmt.Sprintf("table=%d", r.Table))
+		}
+		if len(opts) > 0 {
+			fmt.Fprintf(b, "route%d_options=%s\n", n, strings.Join(opts, ","))
+		}
+	}
+}
+
+// connectionUUID derives a name based UUID (version 5 layout) from the
+// NetworkConfiguration and device names.
+func connectionUUID(nc *v1alpha1.NetworkConfiguration, device string) string {
+	sum := sha1.Sum([]byte("vitistack.io/networkconfiguration/" + nc.Namespace + "/" + nc.Name + "/" + device))
+	u := sum[:16]
+	u[6] = u[6]&0x0f | 0x50
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+func macAddress(d *hostDevice) string {
+	if d.Interface != nil {
+		return d.Interface.MacAddress
+	}
+	return ""
+}
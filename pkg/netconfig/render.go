@@ -0,0 +1,172 @@
+package netconfig
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+// hostDevice is a device with the layer 3 configuration the renderers write.
+type hostDevice struct {
+	*Device
+	// DHCP4 and DHCP6 are set for DHCP reserved devices. The addresses of such
+	// a device are its reservations and are not configured statically.
+	DHCP4, DHCP6 bool
+	// Addresses, Gateway4 and Gateway6 are the static configuration.
+	Addresses          []netip.Prefix
+	Gateway4, Gateway6 netip.Addr
+	DNS4, DNS6         []netip.Addr
+	Routes             []hostRoute
+	// MasterKind is the kind of the bond or bridge the device is a member of.
+	MasterKind string
+	// VLANs on this device, for systemd-networkd.
+	VLANs []string
+}
+
+type hostRoute struct {
+	To     netip.Prefix
+	Via    netip.Addr
+	Metric int32
+	Table  int64
+	OnLink bool
+}
+
+// plan validates a NetworkConfiguration and works out the configuration of
+// each device. Routes go to their interface, or to the device with a subnet
+// holding the gateway.
+func plan(nc *v1alpha1.NetworkConfiguration) ([]*hostDevice, error) {
+	if err := Validate(nc); err != nil {
+		return nil, err
+	}
+	devices := Devices(nc)
+	var out []*hostDevice
+	byName := map[string]*hostDevice{}
+	for _, d := range devices {
+		h := &hostDevice{Device: d}
+		out = append(out, h)
+		byName[d.Name] = h
+		if d.Master != "" {
+			continue
+		}
+		a := &d.Addressing
+		// Validate has parsed every address.
+		prefixes, _ := Addresses(a)
+		if a.DHCPReserved {
+			h.DHCP4 = true
+			h.DHCP6 = len(a.IPv6Addresses) > 0 || a.IPv6Subnet != ""
+		} else {
+			h.Addresses = prefixes
+			if a.IPv4Gateway != "" {
+				h.Gateway4 = netip.MustParseAddr(a.IPv4Gateway)
+			}
+			if a.IPv6Gateway != "" {
+				h.Gateway6 = netip.MustParseAddr(a.IPv6Gateway)
+			}
+		}
+		for _, s := range a.DNS {
+			addr, err := netip.ParseAddr(s)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: invalid DNS server %q", d.Kind, d.Name, s)
+			}
+			if addr.Is4() {
+				h.DNS4 = append(h.DNS4, addr)
+			} else {
+				h.DNS6 = append(h.DNS6, addr)
+			}
+		}
+	}
+	for _, h := range out {
+		if h.Master != "" {
+			h.MasterKind = byName[h.Master].Kind
+		}
+		if h.Kind == KindVLAN {
+			byName[h.VLAN.Link].VLANs = append(byName[h.VLAN.Link].VLANs, h.Name)
+		}
+	}
+
+	for i := range nc.Spec.Routes {
+		r := &nc.Spec.Routes[i]
+		d, err := RouteDevice(r, devices)
+		if err != nil {
+			return nil, err
+		}
+		to, _ := RouteDestination(r)
+		var via netip.Addr
+		if r.Via != "" {
+			via = netip.MustParseAddr(r.Via)
+		}
+		h := byName[d.Name]
+		h.Routes = append(h.Routes, hostRoute{To: to, Via: via, Metric: r.Metric, Table: r.Table, OnLink: r.OnLink})
+	}
+	return out, nil
+}
+
+// RouteDevice returns the device a route is configured on: its interface, or
+// else the first device with a subnet holding the gateway.
+func RouteDevice(r *v1alpha1.NetworkConfigurationRoute, devices []*Device) (*Device, error) {
+	if r.Interface != "" {
+		i := slices.IndexFunc(devices, func(d *Device) bool { return d.Name == r.Interface })
+		if i < 0 {
+			return nil, fmt.Errorf("route to %s: %w %s", r.To, ErrMissingParent, r.Interface)
+		}
+		return devices[i], nil
+	}
+	via, err := netip.ParseAddr(r.Via)
+	if err != nil {
+		return nil, fmt.Errorf("route to %s: invalid gateway %q", r.To, r.Via)
+	}
+	for _, d := range devices {
+		if d.Master != "" {
+			continue
+		}
+		if prefixes, err := Addresses(&d.Addressing); err == nil && reachable(via, prefixes) {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("route to %s: no device has a subnet holding gateway %s, set the interface", r.To, via)
+}
+
+// has4 and has6 report whether a device uses IPv4 or IPv6.
+func (h *hostDevice) has4() bool {
+	return h.DHCP4 || h.Gateway4.IsValid() || slices.ContainsFunc(h.Addresses, func(p netip.Prefix) bool { return p.Addr().Is4() }) ||
+		slices.ContainsFunc(h.Routes, func(r hostRoute) bool { return r.To.Addr().Is4() })
+}
+
+func (h *hostDevice) has6() bool {
+	return h.DHCP6 || h.Gateway6.IsValid() || slices.ContainsFunc(h.Addresses, func(p netip.Prefix) bool { return p.Addr().Is6() }) ||
+		slices.ContainsFunc(h.Routes, func(r hostRoute) bool { return r.To.Addr().Is6() })
+}
+
+// routes returns the routes of one family, the gateway first as a default
+// route.
+func (h *hostDevice) routes(v4 bool) []hostRoute {
+	var out []hostRoute
+	if gw := h.Gateway4; v4 && gw.IsValid() {
+		out = append(out, hostRoute{To: netip.MustParsePrefix("0.0.0.0/0"), Via: gw})
+	}
+	if gw := h.Gateway6; !v4 && gw.IsValid() {
+		out = append(out, hostRoute{To: netip.MustParsePrefix("::/0"), Via: gw})
+	}
+	for _, r := range h.Routes {
+		if r.To.Addr().Is4() == v4 {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func bondMode(b *v1alpha1.NetworkConfigurationBond) string {
+	if b.Mode == "" {
+		return v1alpha1.BondModeLACP
+	}
+	return b.Mode
+}
+
+func miiMonitorInterval(b *v1alpha1.NetworkConfigurationBond) int32 {
+	if b.MIIMonitorInterval == 0 {
+		return DefaultMIIMonitorInterval
+	}
+	return b.MIIMonitorInterval
+}
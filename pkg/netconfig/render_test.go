@@ -0,0 +1,157 @@
+package netconfig
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+var goldenConfigurations = map[string]v1alpha1.NetworkConfigurationSpec{
+	// Two interfaces in an LACP bond carrying a bridged VLAN and a routed
+	// jumbo frame VLAN, statically addressed, with routes in other tables, on
+	// the link and to gateways outside the subnets.
+	"stacked": {
+		NetworkInterfaces: []v1alpha1.NetworkConfigurationInterface{
+			{Name: "eth0", MacAddress: "52:54:00:AA:00:01", MTU: 9000},
+			{Name: "eth1", MacAddress: "52:54:00:aa:00:02", MTU: 9000},
+		},
+		Bonds: []v1alpha1.NetworkConfigurationBond{{
+			Name:               "bond0",
+			Interfaces:         []string{"eth0", "eth1"},
+			Mode:               v1alpha1.BondModeLACP,
+			LACPRate:           "fast",
+			TransmitHashPolicy: "layer3+4",
+			NetworkConfigurationAddressing: v1alpha1.NetworkConfigurationAddressing{
+				MTU: 9000,
+			},
+		}},
+		Bridges: []v1alpha1.NetworkConfigurationBridge{{
+			Name:         "br100",
+			Interfaces:   []string{"bond0.100"},
+			STP:          true,
+			ForwardDelay: 4,
+			NetworkConfigurationAddressing: v1alpha1.NetworkConfigurationAddressing{
+				IPv4Addresses: []string{"10.0.100.5", "10.0.100.6"},
+				IPv4Subnet:    "10.0.100.0/24",
+				IPv4Gateway:   "10.0.100.1",
+				IPv6Addresses: []string{"2001:db8:100::5/64"},
+				IPv6Gateway:   "2001:db8:100::1",
+				DNS:           []string{"10.0.100.53", "2001:db8:100::53"},
+			},
+		}},
+		VLANs: []v1alpha1.NetworkConfigurationVLAN{
+			{Name: "bond0.100", ID: 100, Link: "bond0"},
+			{Name: "bond0.200", ID: 200, Link: "bond0", NetworkConfigurationAddressing: v1alpha1.NetworkConfigurationAddressing{
+				IPv4Addresses: []string{"172.16.200.5/24"},
+				MTU:           9000,
+			}},
+		},
+		Routes: []v1alpha1.NetworkConfigurationRoute{
+			{To: "10.20.0.0/16", Via: "10.0.100.254", Metric: 100},
+			{To: "10.30.0.0/16", Via: "172.16.200.1", Table: 200},
+			{To: "default", Via: "172.16.200.1", Table: 200, Metric: 50},
+			{To: "192.168.50.0/24", Interface: "br100"},
+			{To: "198.51.100.0/24", Via: "192.0.2.1", Interface: "bond0.200", OnLink: true, Metric: 10},
+			{To: "2001:db8:ff::/48", Via: "2001:db8:100::fe", Table: 100},
+			{To: "default", Via: "fe80::1", Interface: "bond0.200", OnLink: true, Metric: 1024},
+		},
+	},
+	// A DHCP reserved dual stack interface next to a DHCP reserved IPv4
+	// only one and a static IPv6 only one.
+	"dhcp": {
+		NetworkInterfaces: []v1alpha1.NetworkConfigurationInterface{
+			{Name: "eth0", MacAddress: "52:54:00:bb:00:01", DHCPReserved: true, IPv4Addresses: []string{"10.0.0.5"}, IPv4Subnet: "10.0.0.0/24", IPv6Subnet: "2001:db8::/64"},
+			{Name: "eth1", MacAddress: "52:54:00:bb:00:02", DHCPReserved: true, IPv4Addresses: []string{"10.1.0.5/24"}, DNS: []string{"10.1.0.53"}},
+			{Name: "eth2", IPv6Addresses: []string{"2001:db8:2::5/64"}, IPv6Gateway: "2001:db8:2::1", MTU: 1280},
+			{MacAddress: "52:54:00:bb:00:04"},
+		},
+	},
+}
+
+func newNetworkConfiguration(name string) *v1alpha1.NetworkConfiguration {
+	return &v1alpha1.NetworkConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1", Namespace: "default"},
+		Spec:       goldenConfigurations[name],
+	}
+}
+
+func TestRenderGolden(t *testing.T) {
+	for name := range goldenConfigurations {
+		t.Run(name, func(t *testing.T) {
+			nc := newNetworkConfiguration(name)
+			if err := Validate(nc); err != nil {
+				t.Fatal(err)
+			}
+			dir := filepath.Join("testdata", name)
+
+			netplan, err := RenderNetplan(nc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, filepath.Join(dir, "netplan"), map[string]string{"60-vitistack.yaml": netplan})
+
+			networkd, err := RenderNetworkd(nc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, filepath.Join(dir, "networkd"), networkd)
+
+			keyfiles, err := RenderNetworkManager(nc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, filepath.Join(dir, "networkmanager"), keyfiles)
+		})
+	}
+}
+
+// checkGolden compares rendered files by name with the files in dir.
+func checkGolden(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	if *update {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	var rendered []string
+	for name := range files {
+		rendered = append(rendered, name)
+	}
+	slices.Sort(rendered)
+	if !slices.Equal(names, rendered) {
+		t.Errorf("rendered files %v, want %v in %s", rendered, names, dir)
+	}
+	for _, name := range names {
+		want, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, ok := files[name]; ok && got != string(want) {
+			t.Errorf("%s differs from the golden file, run go test -update to accept it:\n%s", filepath.Join(dir, name), got)
+		}
+	}
+}
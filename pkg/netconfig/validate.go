@@ -0,0 +1,311 @@
+package netconfig
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+var (
+	// ErrLoop is returned when devices are stacked on each other in a cycle.
+	ErrLoop = errors.New("device loop")
+	// ErrMissingParent is returned when a bond, bridge, VLAN or route refers to
+	// a device that does not exist.
+	ErrMissingParent = errors.New("missing parent device")
+	// ErrRouteOverlap is returned for two routes to the same destination in the
+	// same table with the same metric, of which the kernel keeps one.
+	ErrRouteOverlap = errors.New("overlapping routes")
+)
+
+// Validate checks the devices and routes of a NetworkConfiguration. The
+// returned error joins one error per problem.
+func Validate(nc *v1alpha1.NetworkConfiguration) error {
+	devices := Devices(nc)
+	byName := map[string]*Device{}
+	var errs []error
+	for _, d := range devices {
+		if err := validateName(d.Name); err != nil {
+			errs = append(errs, fmt.Errorf("%s %q: %w", d.Kind, d.Name, err))
+		}
+		if prev, ok := byName[d.Name]; ok {
+			errs = append(errs, fmt.Errorf("%s %s: name is already used by a %s", d.Kind, d.Name, prev.Kind))
+			continue
+		}
+		byName[d.Name] = d
+	}
+
+	errs = append(errs, validateLinks(devices, byName)...)
+	errs = append(errs, validateLoops(devices, byName)...)
+	errs = append(errs, validateAddressing(devices, byName)...)
+	errs = append(errs, validateRoutes(nc.Spec.Routes, devices, byName)...)
+	return errors.Join(errs...)
+}
+
+// validateName checks a Linux interface name.
+func validateName(name string) error {
+	switch {
+	case name == "" || name == "." || name == "..":
+		return errors.New("invalid name")
+	case len(name) > 15:
+		return errors.New("name is longer than 15 characters")
+	case strings.ContainsAny(name, "/: \t\n"):
+		return errors.New("name contains '/', ':' or whitespace")
+	}
+	return nil
+}
+
+// validateLinks checks that members and parents exist, are of a kind that
+// can be stacked there, and are enslaved at most once.
+func validateLinks(devices []*Device, byName map[string]*Device) []error {
+	var errs []error
+	master := map[string]string{}
+	vlanIDs := map[string]string{}
+	for _, d := range devices {
+		switch d.Kind {
+		case KindBond, KindBridge:
+			seen := map[string]bool{}
+			for _, member := range d.Lower {
+				m, ok := byName[member]
+				switch {
+				case seen[member]:
+					errs = append(errs, fmt.Errorf("%s %s: member %s is listed twice", d.Kind, d.Name, member))
+					continue
+				case !ok:
+					errs = append(errs, fmt.Errorf("%s %s: %w %s", d.Kind, d.Name, ErrMissingParent, member))
+				case d.Kind == KindBond && m.Kind != KindInterface:
+					errs = append(errs, fmt.Errorf("bond %s: member %s is a %s, bonds take network interfaces", d.Name, member, m.Kind))
+				case d.Kind == KindBridge && m.Kind == KindBridge:
+					errs = append(errs, fmt.Errorf("bridge %s: member %s is a bridge", d.Name, member))
+				}
+				seen[member] = true
+				if prev, ok := master[member]; ok {
+					errs = append(errs, fmt.Errorf("%s %s: member %s is already in %s", d.Kind, d.Name, member, prev))
+					continue
+				}
+				master[member] = d.Name
+			}
+			if d.Bond != nil {
+				errs = append(errs, validateBond(d.Bond)...)
+			}
+		case KindVLAN:
+			link, ok := byName[d.VLAN.Link]
+			switch {
+			case !ok:
+				errs = append(errs, fmt.Errorf("vlan %s: %w %s", d.Name, ErrMissingParent, d.VLAN.Link))
+			case link.Kind == KindVLAN:
+				errs = append(errs, fmt.Errorf("vlan %s: link %s is a vlan, stacked VLANs are not supported", d.Name, link.Name))
+			}
+			key := fmt.Sprintf("%s/%d", d.VLAN.Link, d.VLAN.ID)
+			if prev, ok := vlanIDs[key]; ok {
+				errs = append(errs, fmt.Errorf("vlan %s: VLAN %d on %s is already %s", d.Name, d.VLAN.ID, d.VLAN.Link, prev))
+				continue
+			}
+			vlanIDs[key] = d.Name
+		}
+	}
+	// A device in a bond or bridge hands its traffic to the master, so a VLAN
+	// on it would never see a frame.
+	for _, d := range devices {
+		if d.Kind == KindVLAN {
+			if m, ok := master[d.VLAN.Link]; ok {
+				errs = append(errs, fmt.Errorf("vlan %s: link %s is a member of %s, put the VLAN on %s", d.Name, d.VLAN.Link, m, m))
+			}
+		}
+	}
+	return errs
+}
+
+func validateBond(b *v1alpha1.NetworkConfigurationBond) []error {
+	var errs []error
+	mode := b.Mode
+	if mode == "" {
+		mode = v1alpha1.BondModeLACP
+	}
+	if b.LACPRate != "" && mode != v1alpha1.BondModeLACP {
+		errs = append(errs, fmt.Errorf("bond %s: lacpRate needs mode %s, not %s", b.Name, v1alpha1.BondModeLACP, mode))
+	}
+	if b.TransmitHashPolicy != "" && mode != v1alpha1.BondModeLACP && mode != v1alpha1.BondModeBalanceXOR {
+		errs = append(errs, fmt.Errorf("bond %s: transmitHashPolicy needs mode %s or %s, not %s", b.Name, v1alpha1.BondModeLACP, v1alpha1.BondModeBalanceXOR, mode))
+	}
+	if b.Primary != "" {
+		switch {
+		case mode != v1alpha1.BondModeActiveBackup && mode != v1alpha1.BondModeBalanceTLB && mode != v1alpha1.BondModeBalanceALB:
+			errs = append(errs, fmt.Errorf("bond %s: primary is not used in mode %s", b.Name, mode))
+		case !slices.Contains(b.Interfaces, b.Primary):
+			errs = append(errs, fmt.Errorf("bond %s: primary %s is not a member", b.Name, b.Primary))
+		}
+	}
+	return errs
+}
+
+// validateLoops reports every cycle of lower devices once, e.g.
+// "br0 -> bond0 -> br0".
+func validateLoops(devices []*Device, byName map[string]*Device) []error {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+	var errs []error
+	var path []string
+	var visit func(name string)
+	visit = func(name string) {
+		d, ok := byName[name]
+		if !ok {
+			return
+		}
+		switch state[name] {
+		case visiting:
+			i := slices.Index(path, name)
+			cycle := append(slices.Clone(path[i:]), name)
+			errs = append(errs, fmt.Errorf("%w: %s", ErrLoop, strings.Join(cycle, " -> ")))
+			return
+		case done:
+			return
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, lower := range d.Lower {
+			visit(lower)
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+	}
+	for _, d := range devices {
+		if state[d.Name] == unvisited {
+			visit(d.Name)
+		}
+	}
+	return errs
+}
+
+// validateAddressing checks addresses, gateways and MTUs.
+func validateAddressing(devices []*Device, byName map[string]*Device) []error {
+	var errs []error
+	owner := map[netip.Addr]string{}
+	for _, d := range devices {
+		if byName[d.Name] != d {
+			continue
+		}
+		a := &d.Addressing
+		if d.Master != "" {
+			if len(a.IPv4Addresses)+len(a.IPv6Addresses) > 0 || a.IPv4Gateway != "" || a.IPv6Gateway != "" || a.DHCPReserved {
+				errs = append(errs, fmt.Errorf("%s %s: member of %s and cannot carry addresses, gateways or DHCP", d.Kind, d.Name, d.Master))
+			}
+			if m := byName[d.Master]; m.MTU() > d.MTU() {
+				errs = append(errs, fmt.Errorf("%s %s: MTU %d is below the MTU %d of %s", d.Kind, d.Name, d.MTU(), m.MTU(), m.Name))
+			}
+		}
+		if d.Kind == KindVLAN {
+			if link, ok := byName[d.VLAN.Link]; ok && d.MTU() > link.MTU() {
+				errs = append(errs, fmt.Errorf("vlan %s: MTU %d is above the MTU %d of %s", d.Name, d.MTU(), link.MTU(), link.Name))
+			}
+		}
+
+		prefixes, err := Addresses(a)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", d.Kind, d.Name, err))
+			continue
+		}
+		for _, p := range prefixes {
+			if prev, ok := owner[p.Addr()]; ok {
+				errs = append(errs, fmt.Errorf("%s %s: address %s is already on %s", d.Kind, d.Name, p.Addr(), prev))
+				continue
+			}
+			owner[p.Addr()] = d.Name
+		}
+		for _, gw := range []struct {
+			value string
+			v4    bool
+		}{{a.IPv4Gateway, true}, {a.IPv6Gateway, false}} {
+			if gw.value == "" {
+				continue
+			}
+			addr, err := netip.ParseAddr(gw.value)
+			if err != nil || addr.Is4() != gw.v4 {
+				errs = append(errs, fmt.Errorf("%s %s: invalid gateway %q", d.Kind, d.Name, gw.value))
+				continue
+			}
+			if !a.DHCPReserved && !reachable(addr, prefixes) {
+				errs = append(errs, fmt.Errorf("%s %s: gateway %s is not in a subnet of the device", d.Kind, d.Name, addr))
+			}
+		}
+	}
+	return errs
+}
+
+// validateRoutes checks routes and reports overlapping ones. A device gateway
+// is a default route in the main table with metric 0.
+func validateRoutes(routes []v1alpha1.NetworkConfigurationRoute, devices []*Device, byName map[string]*Device) []error {
+	type key struct {
+		table  int64
+		to     netip.Prefix
+		metric int32
+	}
+	var errs []error
+	seen := map[key]string{}
+	claim := func(k key, what string) {
+		if prev, ok := seen[k]; ok {
+			errs = append(errs, fmt.Errorf("%w: %s and %s both go to %s with metric %d", ErrRouteOverlap, prev, what, k.to, k.metric))
+			return
+		}
+		seen[k] = what
+	}
+	for _, d := range devices {
+		if byName[d.Name] != d || d.Master != "" {
+			continue
+		}
+		if d.Addressing.IPv4Gateway != "" {
+			claim(key{0, netip.MustParsePrefix("0.0.0.0/0"), 0}, "the IPv4 gateway of "+d.Name)
+		}
+		if d.Addressing.IPv6Gateway != "" {
+			claim(key{0, netip.MustParsePrefix("::/0"), 0}, "the IPv6 gateway of "+d.Name)
+		}
+	}
+
+	for i := range routes {
+		r := &routes[i]
+		what := fmt.Sprintf("route %d to %s", i, r.To)
+		to, err := RouteDestination(r)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", what, err))
+			continue
+		}
+		var via netip.Addr
+		if r.Via != "" {
+			if via, err = netip.ParseAddr(r.Via); err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid gateway %q", what, r.Via))
+				continue
+			}
+			if via.Is4() != to.Addr().Is4() {
+				errs = append(errs, fmt.Errorf("%s: gateway %s is not of the destination's family", what, via))
+				continue
+			}
+		}
+		switch d, ok := byName[r.Interface]; {
+		case r.Interface == "" && r.Via == "":
+			errs = append(errs, fmt.Errorf("%s: needs a gateway or an interface", what))
+		case r.Interface == "":
+		case !ok:
+			errs = append(errs, fmt.Errorf("%s: %w %s", what, ErrMissingParent, r.Interface))
+		case d.Master != "":
+			errs = append(errs, fmt.Errorf("%s: interface %s is a member of %s", what, r.Interface, d.Master))
+		case via.IsValid() && !r.OnLink && !d.Addressing.DHCPReserved:
+			if prefixes, err := Addresses(&d.Addressing); err == nil && !reachable(via, prefixes) {
+				errs = append(errs, fmt.Errorf("%s: gateway %s is not in a subnet of %s, set onLink if it is reachable", what, via, d.Name))
+			}
+		}
+		claim(key{r.Table, to, r.Metric}, what)
+	}
+	return errs
+}
+
+// reachable reports whether an address is in one of the prefixes.
+func reachable(addr netip.Addr, prefixes []netip.Prefix) bool {
+	return slices.ContainsFunc(prefixes, func(p netip.Prefix) bool { return p.Masked().Contains(addr) })
+}
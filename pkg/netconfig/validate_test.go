@@ -0,0 +1,244 @@
+package netconfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+func nics(names ...string) []v1alpha1.NetworkConfigurationInterface {
+	var out []v1alpha1.NetworkConfigurationInterface
+	for _, name := range names {
+		out = append(out, v1alpha1.NetworkConfigurationInterface{Name: name})
+	}
+	return out
+}
+
+func static(addresses ...string) v1alpha1.NetworkConfigurationAddressing {
+	return v1alpha1.NetworkConfigurationAddressing{IPv4Addresses: addresses}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		spec v1alpha1.NetworkConfigurationSpec
+		// is is the sentinel the error wraps, if any.
+		is error
+		// want is part of the error, "" for none.
+		want string
+	}{
+		{
+			name: "bond with a VLAN",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: nics("eth0", "eth1"),
+				Bonds:             []v1alpha1.NetworkConfigurationBond{{Name: "bond0", Interfaces: []string{"eth0", "eth1"}, Mode: v1alpha1.BondModeActiveBackup, Primary: "eth0"}},
+				VLANs:             []v1alpha1.NetworkConfigurationVLAN{{Name: "bond0.10", ID: 10, Link: "bond0", NetworkConfigurationAddressing: static("10.0.10.5/24")}},
+			},
+		},
+		{
+			name: "loop",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				Bridges: []v1alpha1.NetworkConfigurationBridge{{Name: "br0", Interfaces: []string{"br0.10"}}},
+				VLANs:   []v1alpha1.NetworkConfigurationVLAN{{Name: "br0.10", ID: 10, Link: "br0"}},
+			},
+			is:   ErrLoop,
+			want: "device loop: br0 -> br0.10 -> br0",
+		},
+		{
+			name: "missing bond member",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: nics("eth0"),
+				Bonds:             []v1alpha1.NetworkConfigurationBond{{Name: "bond0", Interfaces: []string{"eth0", "eth1"}}},
+			},
+			is:   ErrMissingParent,
+			want: "bond bond0: missing parent device eth1",
+		},
+		{
+			name: "missing VLAN link",
+			spec: v1alpha1.NetworkConfigurationSpec{VLANs: []v1alpha1.NetworkConfigurationVLAN{{Name: "eth0.10", ID: 10, Link: "eth0"}}},
+			is:   ErrMissingParent,
+			want: "vlan eth0.10: missing parent device eth0",
+		},
+		{
+			name: "missing route interface",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: nics("eth0"),
+				Routes:            []v1alpha1.NetworkConfigurationRoute{{To: "10.20.0.0/16", Interface: "eth1"}},
+			},
+			is:   ErrMissingParent,
+			want: "route 0 to 10.20.0.0/16: missing parent device eth1",
+		},
+		{
+			name: "overlapping routes",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: nics("eth0"),
+				Routes: []v1alpha1.NetworkConfigurationRoute{
+					{To: "10.20.0.0/16", Interface: "eth0", Metric: 100},
+					{To: "10.20.0.0/16", Interface: "eth0", Metric: 200},
+					{To: "10.20.0.0/16", Interface: "eth0", Metric: 100, Table: 10},
+					{To: "10.20.1.0/16", Interface: "eth0", Metric: 100},
+				},
+			},
+			is:   ErrRouteOverlap,
+			want: "route 0 to 10.20.0.0/16 and route 3 to 10.20.1.0/16 both go to 10.20.0.0/16 with metric 100",
+		},
+		{
+			name: "default route next to a gateway",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: []v1alpha1.NetworkConfigurationInterface{{Name: "eth0", IPv4Addresses: []string{"10.0.0.5/24"}, IPv4Gateway: "10.0.0.1"}},
+				Routes:            []v1alpha1.NetworkConfigurationRoute{{To: "default", Via: "10.0.0.254"}},
+			},
+			is:   ErrRouteOverlap,
+			want: "the IPv4 gateway of eth0 and route 0 to default both go to 0.0.0.0/0 with metric 0",
+		},
+		{
+			name: "member MTU below the bond",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: []v1alpha1.NetworkConfigurationInterface{{Name: "eth0", MTU: 9000}, {Name: "eth1"}},
+				Bonds: []v1alpha1.NetworkConfigurationBond{{Name: "bond0", Interfaces: []string{"eth0", "eth1"},
+					NetworkConfigurationAddressing: v1alpha1.NetworkConfigurationAddressing{MTU: 9000}}},
+			},
+			want: "interface eth1: MTU 1500 is below the MTU 9000 of bond0",
+		},
+		{
+			name: "VLAN MTU above the link",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: nics("eth0"),
+				VLANs: []v1alpha1.NetworkConfigurationVLAN{{Name: "eth0.10", ID: 10, Link: "eth0",
+					NetworkConfigurationAddressing: v1alpha1.NetworkConfigurationAddressing{MTU: 9000}}},
+			},
+			want: "vlan eth0.10: MTU 9000 is above the MTU 1500 of eth0",
+		},
+		{
+			name: "lacpRate outside LACP",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: nics("eth0"),
+				Bonds:             []v1alpha1.NetworkConfigurationBond{{Name: "bond0", Interfaces: []string{"eth0"}, Mode: v1alpha1.BondModeActiveBackup, LACPRate: "fast"}},
+			},
+			want: "bond bond0: lacpRate needs mode 802.3ad, not active-backup",
+		},
+		{
+			name: "transmitHashPolicy in active-backup",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: nics("eth0"),
+				Bonds:             []v1alpha1.NetworkConfigurationBond{{Name: "bond0", Interfaces: []string{"eth0"}, Mode: v1alpha1.BondModeActiveBackup, TransmitHashPolicy: "layer3+4"}},
+			},
+			want: "bond bond0: transmitHashPolicy needs mode 802.3ad or balance-xor, not active-backup",
+		},
+		{
+			name: "primary in LACP",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: nics("eth0"),
+				Bonds:             []v1alpha1.NetworkConfigurationBond{{Name: "bond0", Interfaces: []string{"eth0"}, Primary: "eth0"}},
+			},
+			want: "bond bond0: primary is not used in mode 802.3ad",
+		},
+		{
+			name: "primary not a member",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: nics("eth0", "eth1"),
+				Bonds:             []v1alpha1.NetworkConfigurationBond{{Name: "bond0", Interfaces: []string{"eth0"}, Mode: v1alpha1.BondModeActiveBackup, Primary: "eth1"}},
+			},
+			want: "bond bond0: primary eth1 is not a member",
+		},
+		{
+			name: "gateway outside the subnets",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: []v1alpha1.NetworkConfigurationInterface{{Name: "eth0", IPv4Addresses: []string{"10.0.0.5/24"}, IPv4Gateway: "10.0.1.1"}},
+			},
+			want: "interface eth0: gateway 10.0.1.1 is not in a subnet of the device",
+		},
+		{
+			name: "gateway of a DHCP reserved interface",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: []v1alpha1.NetworkConfigurationInterface{{Name: "eth0", DHCPReserved: true, IPv4Gateway: "10.0.1.1"}},
+			},
+		},
+		{
+			name: "gateway of the wrong family",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: []v1alpha1.NetworkConfigurationInterface{{Name: "eth0", IPv6Addresses: []string{"2001:db8::5/64"}, IPv6Gateway: "10.0.0.1"}},
+			},
+			want: `interface eth0: invalid gateway "10.0.0.1"`,
+		},
+		{
+			name: "route gateway outside the subnets",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: []v1alpha1.NetworkConfigurationInterface{{Name: "eth0", IPv4Addresses: []string{"10.0.0.5/24"}}},
+				Routes:            []v1alpha1.NetworkConfigurationRoute{{To: "10.20.0.0/16", Via: "10.0.1.1", Interface: "eth0"}},
+			},
+			want: "route 0 to 10.20.0.0/16: gateway 10.0.1.1 is not in a subnet of eth0, set onLink if it is reachable",
+		},
+		{
+			name: "route gateway on the link",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: []v1alpha1.NetworkConfigurationInterface{{Name: "eth0", IPv4Addresses: []string{"10.0.0.5/24"}}},
+				Routes:            []v1alpha1.NetworkConfigurationRoute{{To: "10.20.0.0/16", Via: "10.0.1.1", Interface: "eth0", OnLink: true}},
+			},
+		},
+		{
+			name: "route gateway of the wrong family",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: nics("eth0"),
+				Routes:            []v1alpha1.NetworkConfigurationRoute{{To: "10.20.0.0/16", Via: "2001:db8::1", Interface: "eth0"}},
+			},
+			want: "route 0 to 10.20.0.0/16: gateway 2001:db8::1 is not of the destination's family",
+		},
+		{
+			name: "route on a bond member",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: nics("eth0"),
+				Bonds:             []v1alpha1.NetworkConfigurationBond{{Name: "bond0", Interfaces: []string{"eth0"}}},
+				Routes:            []v1alpha1.NetworkConfigurationRoute{{To: "10.20.0.0/16", Interface: "eth0"}},
+			},
+			want: "route 0 to 10.20.0.0/16: interface eth0 is a member of bond0",
+		},
+		{
+			name: "address on a bond member",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: []v1alpha1.NetworkConfigurationInterface{{Name: "eth0", IPv4Addresses: []string{"10.0.0.5/24"}}},
+				Bonds:             []v1alpha1.NetworkConfigurationBond{{Name: "bond0", Interfaces: []string{"eth0"}}},
+			},
+			want: "interface eth0: member of bond0 and cannot carry addresses, gateways or DHCP",
+		},
+		{
+			name: "address used twice",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: []v1alpha1.NetworkConfigurationInterface{
+					{Name: "eth0", IPv4Addresses: []string{"10.0.0.5/24"}},
+					{Name: "eth1", IPv4Addresses: []string{"10.0.0.5/16"}},
+				},
+			},
+			want: "interface eth1: address 10.0.0.5 is already on eth0",
+		},
+		{
+			name: "stacked VLAN",
+			spec: v1alpha1.NetworkConfigurationSpec{
+				NetworkInterfaces: nics("eth0"),
+				VLANs: []v1alpha1.NetworkConfigurationVLAN{
+					{Name: "eth0.10", ID: 10, Link: "eth0"},
+					{Name: "eth0.10.20", ID: 20, Link: "eth0.10"},
+				},
+			},
+			want: "vlan eth0.10.20: link eth0.10 is a vlan, stacked VLANs are not supported",
+		},
+		{
+			name: "name too long",
+			spec: v1alpha1.NetworkConfigurationSpec{NetworkInterfaces: nics("enp0s31f6-uplink")},
+			want: `interface "enp0s31f6-uplink": name is longer than 15 characters`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&v1alpha1.NetworkConfiguration{Spec: tt.spec})
+			if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Fatalf("error = %v, want %q", err, tt.want)
+			}
+			if tt.is != nil && !errors.Is(err, tt.is) {
+				t.Errorf("error = %v, want it to wrap %v", err, tt.is)
+			}
+		})
+	}
+}
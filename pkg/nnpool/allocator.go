@@ -0,0 +1,413 @@
+package nnpool
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vitistack/crds/pkg/ipam"
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxProbes bounds the addresses tried after the hashed egress IP before
+// falling back to the lowest free address.
+const maxProbes = 256
+
+// Allocator hands out the VLAN IDs and egress IPs of one pool. It is safe for
+// concurrent use.
+type Allocator struct {
+	mu         sync.Mutex
+	datacenter string
+	vlans      []vlanRange
+	vlanOwner  map[int32]string
+	egress     *ipam.Allocator // nil without egress CIDRs
+	owned      map[string]v1alpha1.NetworkNamespaceAllocation
+}
+
+// New builds the allocator of a pool from the allocations in its status. The
+// NetworkNamespaces of the datacenter are adopted next: a VLAN ID or egress
+// IP in their status that the pool does not know of is recorded for them,
+// which restores the allocations after the pool was restored from an older
+// backup. Allocations that conflict with each other or with the pool are
+// reported in the returned error, which joins one error per allocation. The
+// allocator is still returned in that case, without those allocations.
+func New(pool *v1alpha1.NetworkNamespacePool, namespaces []v1alpha1.NetworkNamespace) (*Allocator, error) {
+	vlans, err := parseVLANRanges(pool.Spec.VLANRanges)
+	if err != nil {
+		return nil, fmt.Errorf("NetworkNamespacePool %s: %w", pool.Name, err)
+	}
+	a := &Allocator{
+		datacenter: pool.Spec.DatacenterIdentifier,
+		vlans:      vlans,
+		vlanOwner:  map[int32]string{},
+		owned:      map[string]v1alpha1.NetworkNamespaceAllocation{},
+	}
+	if len(pool.Spec.EgressCIDRs) > 0 {
+		if a.egress, err = ipam.New(ipam.Config{CIDRs: pool.Spec.EgressCIDRs, ExcludedRanges: pool.Spec.ExcludedRanges}); err != nil {
+			return nil, fmt.Errorf("NetworkNamespacePool %s: %w", pool.Name, err)
+		}
+	}
+
+	var errs []error
+	for _, al := range pool.Status.Allocations {
+		if err := a.record(al); err != nil {
+			errs = append(errs, fmt.Errorf("NetworkNamespace %s/%s: %w", al.NetworkNamespace.Namespace, al.NetworkNamespace.Name, err))
+		}
+	}
+	for i := range namespaces {
+		nn := &namespaces[i]
+		if nn.Spec.DatacenterIdentifier != a.datacenter || nn.Status.VlanID == 0 {
+			continue
+		}
+		if _, ok := a.owned[owner(nn.Namespace, nn.Name)]; ok {
+			continue
+		}
+		if err := a.record(allocationOf(nn)); err != nil {
+			errs = append(errs, fmt.Errorf("NetworkNamespace %s/%s: %w", nn.Namespace, nn.Name, err))
+		}
+	}
+	return a, errors.Join(errs...)
+}
+
+// Assign allocates a VLAN ID, and an egress IP per family of the pool, to a
+// NetworkNamespace and returns the allocation. A NetworkNamespace keeps what
+// it holds, or what its status holds when that is free. Otherwise it gets the
+// first free VLAN ID and egress IP from a position derived from its namespace
+// and name. Unless that position is taken, the result does not depend on the
+// order namespaces are assigned in.
+func (a *Allocator) Assign(nn *v1alpha1.NetworkNamespace) (v1alpha1.NetworkNamespaceAllocation, error) {
+	if nn.Spec.DatacenterIdentifier != a.datacenter {
+		return v1alpha1.NetworkNamespaceAllocation{}, fmt.Errorf("NetworkNamespace %s/%s is in datacenter %s, the pool serves %s", nn.Namespace, nn.Name, nn.Spec.DatacenterIdentifier, a.datacenter)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	own := owner(nn.Namespace, nn.Name)
+	prev, held := a.owned[own]
+	if !held && nn.Status.VlanID != 0 {
+		// Adopt what the status holds, or at least its VLAN ID when the
+		// egress IPs are taken.
+		status := allocationOf(nn)
+		if a.record(status) != nil {
+			status.IPv4EgressIP, status.IPv6EgressIP = "", ""
+			_ = a.record(status)
+		}
+	}
+	al, ok := a.owned[own]
+	if !ok {
+		vlan, err := a.hashedVLAN(own)
+		if err != nil {
+			return v1alpha1.NetworkNamespaceAllocation{}, fmt.Errorf("NetworkNamespace %s: %w", own, err)
+		}
+		al = v1alpha1.NetworkNamespaceAllocation{
+			NetworkNamespace: v1alpha1.NamespacedNetworkNamespaceReference{Namespace: nn.Namespace, Name: nn.Name},
+			VlanID:           vlan,
+		}
+		a.vlanOwner[vlan] = own
+	}
+	al.ClusterIdentifier = nn.Spec.ClusterIdentifier
+	a.owned[own] = al
+	if err := a.complete(own, &al); err != nil {
+		a.release(own)
+		if held {
+			// Re-adding what the owner held before cannot conflict.
+			_ = a.record(prev)
+		}
+		return v1alpha1.NetworkNamespaceAllocation{}, err
+	}
+	return al, nil
+}
+
+// complete allocates the missing egress IPs of an allocation. The caller
+// holds the lock.
+func (a *Allocator) complete(own string, al *v1alpha1.NetworkNamespaceAllocation) error {
+	if a.egress == nil {
+		return nil
+	}
+	for _, family := range a.egress.Families() {
+		ip := &al.IPv4EgressIP
+		if family == v1alpha1.IPFamilyIPv6 {
+			ip = &al.IPv6EgressIP
+		}
+		if *ip != "" {
+			continue
+		}
+		addr, err := a.hashedAddress(own, family)
+		if err != nil {
+			return fmt.Errorf("%s egress IP for NetworkNamespace %s: %w", family, own, err)
+		}
+		*ip = addr.String()
+	}
+	a.owned[own] = *al
+	return nil
+}
+
+// hashedVLAN returns the first free VLAN ID from the position of owner. The
+// caller holds the lock.
+func (a *Allocator) hashedVLAN(own string) (int32, error) {
+	total := a.vlanTotal()
+	start := int32(hash(own) % uint64(total))
+	for i := range total {
+		id := a.vlanAt((start + i) % total)
+		if _, taken := a.vlanOwner[id]; !taken {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("%w in %s", ErrExhausted, a.rangesString())
+}
+
+// hashedAddress allocates the first free egress IP from the position of
+// owner in the first CIDR of the family, or the lowest free one after
+// maxProbes addresses. The caller holds the lock.
+func (a *Allocator) hashedAddress(own, family string) (netip.Addr, error) {
+	for _, p := range a.egress.Prefixes() {
+		if family != familyOf(p.Addr()) {
+			continue
+		}
+		size := new(big.Int).Lsh(big.NewInt(1), uint(p.Addr().BitLen()-p.Bits()))
+		offset := new(big.Int).Mod(new(big.Int).SetUint64(hash(own)), size)
+		for i := int64(0); i < maxProbes && big.NewInt(i).Cmp(size) < 0; i++ {
+			n := new(big.Int).Add(offset, big.NewInt(i))
+			addr := addrAt(p, n.Mod(n, size))
+			if err := a.egress.AllocateAddress(own, addr); err == nil {
+				return addr, nil
+			}
+		}
+		break
+	}
+	return a.egress.Allocate(own, family)
+}
+
+// Release frees the VLAN ID and egress IPs of a NetworkNamespace and returns
+// what it held. Call it when the NetworkNamespace is deleted, before removing
+// NetworkNamespaceAllocationFinalizer.
+func (a *Allocator) Release(namespace, name string) (v1alpha1.NetworkNamespaceAllocation, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.release(owner(namespace, name))
+}
+
+// ReleaseOrphans frees the allocations of NetworkNamespaces that no longer
+// exist and returns them. namespaces must hold every NetworkNamespace of the
+// datacenter.
+func (a *Allocator) ReleaseOrphans(namespaces []v1alpha1.NetworkNamespace) []v1alpha1.NetworkNamespaceAllocation {
+	live := map[string]bool{}
+	for i := range namespaces {
+		live[owner(namespaces[i].Namespace, namespaces[i].Name)] = true
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var released []v1alpha1.NetworkNamespaceAllocation
+	for _, al := range a.sorted() {
+		if own := owner(al.NetworkNamespace.Namespace, al.NetworkNamespace.Name); !live[own] {
+			a.release(own)
+			released = append(released, al)
+		}
+	}
+	return released
+}
+
+// Allocation returns the allocation of a NetworkNamespace.
+func (a *Allocator) Allocation(namespace, name string) (v1alpha1.NetworkNamespaceAllocation, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	al, ok := a.owned[owner(namespace, name)]
+	return al, ok
+}
+
+// Status returns the pool status with the allocations and usage of the
+// allocator. Write it with the resourceVersion the allocator was built from;
+// on a conflict, rebuild the allocator and retry.
+func (a *Allocator) Status(pool *v1alpha1.NetworkNamespacePool) v1alpha1.NetworkNamespacePoolStatus {
+	status := *pool.Status.DeepCopy()
+	status.ObservedGeneration = pool.Generation
+	status.IPv4, status.IPv6 = nil, nil
+	var exhausted []string
+	if a.egress != nil {
+		status.IPv4 = a.egress.Usage(v1alpha1.IPFamilyIPv4)
+		status.IPv6 = a.egress.Usage(v1alpha1.IPFamilyIPv6)
+		for _, f := range a.egress.Families() {
+			if a.egress.Usage(f).Free == 0 {
+				exhausted = append(exhausted, f+" egress IPs")
+			}
+		}
+	}
+
+	a.mu.Lock()
+	status.Allocations = a.sorted()
+	total, used := a.vlanTotal(), int32(len(a.vlanOwner))
+	a.mu.Unlock()
+	status.VLANs = &v1alpha1.VLANUsage{Total: total, Used: used, Free: total - used}
+	if total == used {
+		exhausted = append([]string{"VLAN IDs"}, exhausted...)
+	}
+
+	status.Phase = v1alpha1.NetworkNamespacePoolPhaseReady
+	status.Message = ""
+	if len(exhausted) > 0 {
+		status.Phase = v1alpha1.NetworkNamespacePoolPhaseExhausted
+		status.Message = "no free " + strings.Join(exhausted, " or ")
+	}
+	return status
+}
+
+// Apply writes an allocation into the status of its NetworkNamespace and sets
+// the Allocated condition.
+func Apply(nn *v1alpha1.NetworkNamespace, pool string, al v1alpha1.NetworkNamespaceAllocation, now metav1.Time) {
+	nn.Status.VlanID = int(al.VlanID)
+	nn.Status.IPv4EgressIP = al.IPv4EgressIP
+	nn.Status.IPv6EgressIP = al.IPv6EgressIP
+	nn.Status.Pool = pool
+	meta.SetStatusCondition(&nn.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.NetworkNamespaceConditionAllocated,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Allocated",
+		Message:            fmt.Sprintf("VLAN %d from NetworkNamespacePool %s", al.VlanID, pool),
+		ObservedGeneration: nn.Generation,
+		LastTransitionTime: now,
+	})
+}
+
+// record adds an existing allocation of an owner without one. The caller
+// holds the lock, or is New.
+func (a *Allocator) record(al v1alpha1.NetworkNamespaceAllocation) error {
+	own := owner(al.NetworkNamespace.Namespace, al.NetworkNamespace.Name)
+	if _, ok := a.owned[own]; ok {
+		return errors.New("listed twice")
+	}
+	if !a.vlanInRange(al.VlanID) {
+		return fmt.Errorf("VLAN %d is not in %s", al.VlanID, a.rangesString())
+	}
+	if prev, ok := a.vlanOwner[al.VlanID]; ok && prev != own {
+		return fmt.Errorf("%w: VLAN %d is held by %s", ErrConflict, al.VlanID, prev)
+	}
+	var addrs []netip.Addr
+	for _, s := range []string{al.IPv4EgressIP, al.IPv6EgressIP} {
+		if s == "" {
+			continue
+		}
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return fmt.Errorf("invalid egress IP %q", s)
+		}
+		if a.egress == nil {
+			return fmt.Errorf("egress IP %s, but the pool has no egress CIDRs", s)
+		}
+		addrs = append(addrs, addr.Unmap())
+	}
+	for i, addr := range addrs {
+		if err := a.egress.AllocateAddress(own, addr); err != nil {
+			if i > 0 {
+				// The owner holds nothing else yet.
+				a.egress.Release(own)
+			}
+			return fmt.Errorf("egress IP %s: %w", addr, err)
+		}
+	}
+	a.vlanOwner[al.VlanID] = own
+	a.owned[own] = al
+	return nil
+}
+
+// release frees the allocation of owner. The caller holds the lock.
+func (a *Allocator) release(own string) (v1alpha1.NetworkNamespaceAllocation, bool) {
+	al, ok := a.owned[own]
+	if !ok {
+		return al, false
+	}
+	delete(a.owned, own)
+	if a.vlanOwner[al.VlanID] == own {
+		delete(a.vlanOwner, al.VlanID)
+	}
+	if a.egress != nil {
+		a.egress.Release(own)
+	}
+	return al, true
+}
+
+// sorted returns the allocations ordered by VLAN ID. The caller holds the lock.
+func (a *Allocator) sorted() []v1alpha1.NetworkNamespaceAllocation {
+	out := make([]v1alpha1.NetworkNamespaceAllocation, 0, len(a.owned))
+	for _, al := range a.owned {
+		out = append(out, al)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].VlanID < out[j].VlanID })
+	return out
+}
+
+func (a *Allocator) vlanTotal() int32 {
+	var total int32
+	for _, r := range a.vlans {
+		total += r.last - r.first + 1
+	}
+	return total
+}
+
+// vlanAt returns the i-th VLAN ID of the ranges.
+func (a *Allocator) vlanAt(i int32) int32 {
+	for _, r := range a.vlans {
+		if n := r.last - r.first + 1; i >= n {
+			i -= n
+			continue
+		}
+		return r.first + i
+	}
+	return 0
+}
+
+func (a *Allocator) vlanInRange(id int32) bool {
+	for _, r := range a.vlans {
+		if id >= r.first && id <= r.last {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Allocator) rangesString() string {
+	parts := make([]string, len(a.vlans))
+	for i, r := range a.vlans {
+		parts[i] = fmt.Sprintf("%d-%d", r.first, r.last)
+	}
+	return "VLAN ranges " + strings.Join(parts, ", ")
+}
+
+// allocationOf returns the allocation recorded in the status of a
+// NetworkNamespace.
+func allocationOf(nn *v1alpha1.NetworkNamespace) v1alpha1.NetworkNamespaceAllocation {
+	return v1alpha1.NetworkNamespaceAllocation{
+		NetworkNamespace:  v1alpha1.NamespacedNetworkNamespaceReference{Namespace: nn.Namespace, Name: nn.Name},
+		ClusterIdentifier: nn.Spec.ClusterIdentifier,
+		VlanID:            int32(nn.Status.VlanID),
+		IPv4EgressIP:      nn.Status.IPv4EgressIP,
+		IPv6EgressIP:      nn.Status.IPv6EgressIP,
+	}
+}
+
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// addrAt returns the n-th address of a prefix.
+func addrAt(p netip.Prefix, n *big.Int) netip.Addr {
+	base := new(big.Int).SetBytes(p.Masked().Addr().AsSlice())
+	b := base.Add(base, n).FillBytes(make([]byte, p.Addr().BitLen()/8))
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
+
+func familyOf(addr netip.Addr) string {
+	if addr.Is4() {
+		return v1alpha1.IPFamilyIPv4
+	}
+	return v1alpha1.IPFamilyIPv6
+}
@@ -0,0 +1,243 @@
+package nnpool
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/vitistack/crds/pkg/ipam"
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newPool(vlans []string, egress ...string) *v1alpha1.NetworkNamespacePool {
+	return &v1alpha1.NetworkNamespacePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool"},
+		Spec:       v1alpha1.NetworkNamespacePoolSpec{DatacenterIdentifier: "no-west-az1", VLANRanges: vlans, EgressCIDRs: egress},
+	}
+}
+
+func newNamespace(name string) *v1alpha1.NetworkNamespace {
+	return &v1alpha1.NetworkNamespace{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       v1alpha1.NetworkNamespaceSpec{DatacenterIdentifier: "no-west-az1", ClusterIdentifier: "cluster-a"},
+	}
+}
+
+func allocation(name string, vlan int32, ipv4, ipv6 string) v1alpha1.NetworkNamespaceAllocation {
+	return v1alpha1.NetworkNamespaceAllocation{
+		NetworkNamespace: v1alpha1.NamespacedNetworkNamespaceReference{Namespace: "default", Name: name},
+		VlanID:           vlan,
+		IPv4EgressIP:     ipv4,
+		IPv6EgressIP:     ipv6,
+	}
+}
+
+func mustNew(t *testing.T, pool *v1alpha1.NetworkNamespacePool) *Allocator {
+	t.Helper()
+	a, err := New(pool, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestAssignDoesNotDependOnOrder(t *testing.T) {
+	pool := newPool([]string{"100-1099"}, "10.0.0.0/16", "2001:db8::/64")
+	names := []string{"web", "api", "db", "cache", "queue"}
+
+	first := map[string]v1alpha1.NetworkNamespaceAllocation{}
+	a := mustNew(t, pool)
+	for _, name := range names {
+		al, err := a.Assign(newNamespace(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if al.IPv4EgressIP == "" || al.IPv6EgressIP == "" || al.ClusterIdentifier != "cluster-a" {
+			t.Errorf("allocation of %s = %+v, want both egress IPs and the cluster", name, al)
+		}
+		first[name] = al
+	}
+
+	b := mustNew(t, pool)
+	for i := len(names) - 1; i >= 0; i-- {
+		al, err := b.Assign(newNamespace(names[i]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if al != first[names[i]] {
+			t.Errorf("%s got %+v in reverse order, %+v in order", names[i], al, first[names[i]])
+		}
+	}
+
+	// Assigning again keeps the allocation.
+	if al, err := a.Assign(newNamespace("web")); err != nil || al != first["web"] {
+		t.Errorf("second assignment = %+v, %v, want %+v", al, err, first["web"])
+	}
+}
+
+func TestAssignAdoptsStatus(t *testing.T) {
+	a := mustNew(t, newPool([]string{"100-109"}, "10.0.0.0/29"))
+	taken, err := a.Assign(newNamespace("taken"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nn := newNamespace("restored")
+	nn.Status.VlanID = 105
+	nn.Status.IPv4EgressIP = "10.0.0.3"
+	if al, err := a.Assign(nn); err != nil || al.VlanID != 105 || al.IPv4EgressIP != "10.0.0.3" {
+		t.Errorf("assign = %+v, %v, want the VLAN and egress IP of the status", al, err)
+	}
+
+	// A status egress IP held by another namespace is replaced, the VLAN ID
+	// is kept.
+	nn = newNamespace("partly")
+	nn.Status.VlanID = 107
+	nn.Status.IPv4EgressIP = taken.IPv4EgressIP
+	al, err := a.Assign(nn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if al.VlanID != 107 || al.IPv4EgressIP == "" || al.IPv4EgressIP == taken.IPv4EgressIP {
+		t.Errorf("assign = %+v, want VLAN 107 and a new egress IP", al)
+	}
+}
+
+func TestAssignKeepsAllocationOnError(t *testing.T) {
+	// The pool got an IPv6 CIDR after web was allocated, and its two
+	// addresses are taken.
+	pool := newPool([]string{"100-109"}, "10.0.0.0/29", "2001:db8::/127")
+	pool.Status.Allocations = []v1alpha1.NetworkNamespaceAllocation{
+		allocation("web", 100, "10.0.0.2", ""),
+		allocation("a", 101, "10.0.0.3", "2001:db8::"),
+		allocation("b", 102, "10.0.0.4", "2001:db8::1"),
+	}
+	a := mustNew(t, pool)
+	before := a.Status(pool)
+
+	for _, name := range []string{"web", "new"} {
+		if _, err := a.Assign(newNamespace(name)); !errors.Is(err, ipam.ErrExhausted) {
+			t.Errorf("assign %s: %v, want ErrExhausted", name, err)
+		}
+	}
+	if al, ok := a.Allocation("default", "web"); !ok || al != pool.Status.Allocations[0] {
+		t.Errorf("allocation of web = %+v, %v, want it kept", al, ok)
+	}
+	if _, ok := a.Allocation("default", "new"); ok {
+		t.Error("a failed assignment must not leave an allocation")
+	}
+	after := a.Status(pool)
+	if *after.VLANs != *before.VLANs || *after.IPv4 != *before.IPv4 || *after.IPv6 != *before.IPv6 {
+		t.Errorf("usage changed from %+v %+v %+v to %+v %+v %+v",
+			*before.VLANs, *before.IPv4, *before.IPv6, *after.VLANs, *after.IPv4, *after.IPv6)
+	}
+}
+
+func TestAssignConcurrent(t *testing.T) {
+	a := mustNew(t, newPool([]string{"100-149", "200-249"}, "10.0.0.0/24"))
+	const namespaces = 120
+
+	var wg sync.WaitGroup
+	results := make([]v1alpha1.NetworkNamespaceAllocation, namespaces)
+	errs := make([]error, namespaces)
+	for i := range namespaces {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = a.Assign(newNamespace(fmt.Sprintf("ns-%d", i)))
+		}()
+	}
+	wg.Wait()
+
+	vlans := map[int32]bool{}
+	ips := map[string]bool{}
+	exhausted := 0
+	for i := range namespaces {
+		if errs[i] != nil {
+			if !errors.Is(errs[i], ErrExhausted) {
+				t.Fatalf("ns-%d: %v", i, errs[i])
+			}
+			exhausted++
+			continue
+		}
+		if vlans[results[i].VlanID] || ips[results[i].IPv4EgressIP] {
+			t.Errorf("ns-%d got VLAN %d and egress IP %s, which are already handed out", i, results[i].VlanID, results[i].IPv4EgressIP)
+		}
+		vlans[results[i].VlanID] = true
+		ips[results[i].IPv4EgressIP] = true
+	}
+	if len(vlans) != 100 || exhausted != namespaces-100 {
+		t.Errorf("%d namespaces got a VLAN and %d none, want 100 and %d", len(vlans), exhausted, namespaces-100)
+	}
+	status := a.Status(newPool([]string{"100-149", "200-249"}, "10.0.0.0/24"))
+	if status.Phase != v1alpha1.NetworkNamespacePoolPhaseExhausted || status.VLANs.Free != 0 || status.IPv4.Used != 100 {
+		t.Errorf("status = %s %q, VLANs %+v, IPv4 %+v", status.Phase, status.Message, *status.VLANs, *status.IPv4)
+	}
+}
+
+func TestNew(t *testing.T) {
+	pool := newPool([]string{"100-109"}, "10.0.0.0/29")
+	pool.Status.Allocations = []v1alpha1.NetworkNamespaceAllocation{
+		allocation("web", 100, "10.0.0.2", ""),
+		allocation("api", 100, "", ""),
+		allocation("db", 200, "", ""),
+		allocation("cache", 101, "10.0.0.2", ""),
+		allocation("web", 102, "", ""),
+	}
+	restored := newNamespace("restored")
+	restored.Status.VlanID = 103
+	restored.Status.IPv4EgressIP = "10.0.0.5"
+	elsewhere := newNamespace("elsewhere")
+	elsewhere.Spec.DatacenterIdentifier = "no-east-az1"
+	elsewhere.Status.VlanID = 104
+
+	a, err := New(pool, []v1alpha1.NetworkNamespace{*restored, *elsewhere})
+	if a == nil {
+		t.Fatalf("no allocator: %v", err)
+	}
+	if !errors.Is(err, ErrConflict) || !errors.Is(err, ipam.ErrConflict) {
+		t.Errorf("error = %v, want the VLAN and egress IP conflicts", err)
+	}
+	var got []string
+	for _, al := range a.Status(pool).Allocations {
+		got = append(got, fmt.Sprintf("%s:%d", al.NetworkNamespace.Name, al.VlanID))
+	}
+	if want := "[web:100 restored:103]"; fmt.Sprint(got) != want {
+		t.Errorf("allocations = %v, want %s", got, want)
+	}
+
+	if _, err := New(newPool([]string{"100-199", "150"}), nil); err == nil {
+		t.Error("overlapping VLAN ranges must be rejected")
+	}
+}
+
+func TestReleaseOrphans(t *testing.T) {
+	pool := newPool([]string{"100-109"}, "10.0.0.0/29")
+	pool.Status.Allocations = []v1alpha1.NetworkNamespaceAllocation{
+		allocation("web", 100, "10.0.0.2", ""),
+		allocation("gone", 101, "10.0.0.3", ""),
+	}
+	a := mustNew(t, pool)
+
+	live := []v1alpha1.NetworkNamespace{*newNamespace("web")}
+	released := a.ReleaseOrphans(live)
+	if len(released) != 1 || released[0] != pool.Status.Allocations[1] {
+		t.Errorf("released %+v, want the allocation of gone", released)
+	}
+	if _, ok := a.Allocation("default", "web"); !ok {
+		t.Error("the allocation of a live namespace was released")
+	}
+	if released := a.ReleaseOrphans(live); len(released) != 0 {
+		t.Errorf("second run released %+v, want nothing", released)
+	}
+
+	// The VLAN ID and egress IP are free again.
+	nn := newNamespace("gone")
+	nn.Status.VlanID = 101
+	nn.Status.IPv4EgressIP = "10.0.0.3"
+	if al, err := a.Assign(nn); err != nil || al.VlanID != 101 || al.IPv4EgressIP != "10.0.0.3" {
+		t.Errorf("assign = %+v, %v, want VLAN 101 and 10.0.0.3", al, err)
+	}
+}
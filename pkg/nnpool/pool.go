@@ -0,0 +1,137 @@
+// Package nnpool allocates VLAN IDs and egress IPs to NetworkNamespaces from
+// the NetworkNamespacePool of their datacenter. New allocations are derived
+// from a hash of the NetworkNamespace, so a namespace restored without its
+// status usually gets the same VLAN and egress IPs back, and allocations a
+// namespace already records in its status are adopted by the pool.
+package nnpool
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+var (
+	// ErrNoPool is returned when no NetworkNamespacePool serves a datacenter.
+	ErrNoPool = errors.New("no NetworkNamespace pool")
+	// ErrExhausted is returned when a pool has no free VLAN ID.
+	ErrExhausted = errors.New("no free VLAN ID")
+	// ErrConflict is returned when a VLAN ID or egress IP is held by another
+	// NetworkNamespace.
+	ErrConflict = errors.New("already allocated")
+)
+
+// vlanRange is an inclusive range of VLAN IDs.
+type vlanRange struct {
+	first, last int32
+}
+
+// parseVLANRanges parses VLAN ranges such as "100-199" or "300". IDs are in
+// 1-4094 and ranges may not overlap.
+func parseVLANRanges(ranges []string) ([]vlanRange, error) {
+	var out []vlanRange
+	for _, s := range ranges {
+		first, last, isRange := strings.Cut(strings.TrimSpace(s), "-")
+		if !isRange {
+			last = first
+		}
+		a, errA := strconv.ParseInt(strings.TrimSpace(first), 10, 32)
+		b, errB := strconv.ParseInt(strings.TrimSpace(last), 10, 32)
+		if errA != nil || errB != nil || a < 1 || b > 4094 || a > b {
+			return nil, fmt.Errorf("invalid VLAN range %q, want IDs in 1-4094", s)
+		}
+		r := vlanRange{int32(a), int32(b)}
+		for _, o := range out {
+			if r.first <= o.last && o.first <= r.last {
+				return nil, fmt.Errorf("VLAN range %q overlaps %d-%d", s, o.first, o.last)
+			}
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].first < out[j].first })
+	return out, nil
+}
+
+// SelectPool returns the NetworkNamespacePool of the datacenter of a
+// NetworkNamespace. Pools being deleted do not count.
+func SelectPool(nn *v1alpha1.NetworkNamespace, pools []v1alpha1.NetworkNamespacePool) (*v1alpha1.NetworkNamespacePool, error) {
+	var found *v1alpha1.NetworkNamespacePool
+	for i := range pools {
+		p := &pools[i]
+		if p.Spec.DatacenterIdentifier != nn.Spec.DatacenterIdentifier || p.DeletionTimestamp != nil {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("NetworkNamespacePools %s and %s both serve datacenter %s", found.Name, p.Name, nn.Spec.DatacenterIdentifier)
+		}
+		found = p
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w for datacenter %s", ErrNoPool, nn.Spec.DatacenterIdentifier)
+	}
+	return found, nil
+}
+
+// ValidatePools checks the VLAN ranges of every pool and that each datacenter
+// has at most one pool.
+func ValidatePools(pools []v1alpha1.NetworkNamespacePool) error {
+	var errs []error
+	byDatacenter := map[string]string{}
+	for i := range pools {
+		p := &pools[i]
+		if _, err := parseVLANRanges(p.Spec.VLANRanges); err != nil {
+			errs = append(errs, fmt.Errorf("NetworkNamespacePool %s: %w", p.Name, err))
+		}
+		if prev, ok := byDatacenter[p.Spec.DatacenterIdentifier]; ok {
+			errs = append(errs, fmt.Errorf("NetworkNamespacePools %s and %s both serve datacenter %s", prev, p.Name, p.Spec.DatacenterIdentifier))
+			continue
+		}
+		byDatacenter[p.Spec.DatacenterIdentifier] = p.Name
+	}
+	return errors.Join(errs...)
+}
+
+// Conflicts reports NetworkNamespaces of one datacenter whose status holds
+// the same VLAN ID or egress IP, whoever wrote it. It suits an audit or an
+// admission webhook given the NetworkNamespaces of the cluster.
+func Conflicts(namespaces []v1alpha1.NetworkNamespace) error {
+	type key struct {
+		datacenter, kind, value string
+	}
+	holder := map[key]string{}
+	var errs []error
+	for i := range namespaces {
+		nn := &namespaces[i]
+		own := owner(nn.Namespace, nn.Name)
+		for _, k := range []key{
+			{nn.Spec.DatacenterIdentifier, "VLAN ID", vlanString(nn.Status.VlanID)},
+			{nn.Spec.DatacenterIdentifier, "egress IP", nn.Status.IPv4EgressIP},
+			{nn.Spec.DatacenterIdentifier, "egress IP", nn.Status.IPv6EgressIP},
+		} {
+			if k.value == "" {
+				continue
+			}
+			if prev, ok := holder[k]; ok {
+				errs = append(errs, fmt.Errorf("%w: %s %s in datacenter %s is held by NetworkNamespaces %s and %s", ErrConflict, k.kind, k.value, k.datacenter, prev, own))
+				continue
+			}
+			holder[k] = own
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func vlanString(id int) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.Itoa(id)
+}
+
+func owner(namespace, name string) string {
+	return namespace + "/" + name
+}
@@ -0,0 +1,95 @@
+package nnpool
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+func TestParseVLANRanges(t *testing.T) {
+	tests := []struct {
+		ranges  []string
+		want    []vlanRange
+		wantErr bool
+	}{
+		{ranges: []string{"300", " 100 - 199 "}, want: []vlanRange{{100, 199}, {300, 300}}},
+		{ranges: []string{"1-4094"}, want: []vlanRange{{1, 4094}}},
+		{ranges: []string{"0-10"}, wantErr: true},
+		{ranges: []string{"4000-4095"}, wantErr: true},
+		{ranges: []string{"200-100"}, wantErr: true},
+		{ranges: []string{"vlan"}, wantErr: true},
+		{ranges: []string{"100-199", "199-250"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseVLANRanges(tt.ranges)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseVLANRanges(%q) error = %v, want error %v", tt.ranges, err, tt.wantErr)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("parseVLANRanges(%q) = %v, want %v", tt.ranges, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("parseVLANRanges(%q) = %v, want %v", tt.ranges, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	nn := func(name, datacenter string, vlan int, ipv4 string) v1alpha1.NetworkNamespace {
+		n := newNamespace(name)
+		n.Spec.DatacenterIdentifier = datacenter
+		n.Status.VlanID = vlan
+		n.Status.IPv4EgressIP = ipv4
+		return *n
+	}
+	if err := Conflicts([]v1alpha1.NetworkNamespace{
+		nn("a", "no-west-az1", 100, "10.0.0.2"),
+		nn("b", "no-east-az1", 100, "10.0.0.2"),
+		nn("c", "no-west-az1", 0, ""),
+		nn("d", "no-west-az1", 0, ""),
+	}); err != nil {
+		t.Errorf("namespaces in different datacenters and without allocations: %v", err)
+	}
+
+	err := Conflicts([]v1alpha1.NetworkNamespace{
+		nn("a", "no-west-az1", 100, "10.0.0.2"),
+		nn("b", "no-west-az1", 100, "10.0.0.3"),
+		nn("c", "no-west-az1", 101, "10.0.0.2"),
+	})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("error = %v, want ErrConflict", err)
+	}
+	for _, want := range []string{"VLAN ID 100", "egress IP 10.0.0.2"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %v, want the conflict on %s", err, want)
+		}
+	}
+}
+
+func TestSelectPool(t *testing.T) {
+	pools := []v1alpha1.NetworkNamespacePool{*newPool([]string{"100"}), *newPool([]string{"200"})}
+	pools[1].Name = "east"
+	pools[1].Spec.DatacenterIdentifier = "no-east-az1"
+
+	if p, err := SelectPool(newNamespace("web"), pools); err != nil || p.Name != "pool" {
+		t.Errorf("SelectPool = %v, %v, want pool", p, err)
+	}
+	nn := newNamespace("web")
+	nn.Spec.DatacenterIdentifier = "se-north-az1"
+	if _, err := SelectPool(nn, pools); !errors.Is(err, ErrNoPool) {
+		t.Errorf("SelectPool for a datacenter without a pool: %v, want ErrNoPool", err)
+	}
+	pools[1].Spec.DatacenterIdentifier = "no-west-az1"
+	if _, err := SelectPool(newNamespace("web"), pools); err == nil {
+		t.Error("two pools for one datacenter must be rejected")
+	}
+	if err := ValidatePools(pools); err == nil {
+		t.Error("ValidatePools must reject two pools for one datacenter")
+	}
+}
@@ -0,0 +1,187 @@
+// Package osimage resolves the operating system of a Machine to the image ID
+// it boots from on a MachineProvider. It tries the exact image ID of the
+// machine, then the newest image of its image family or operating system, then
+// the default image of a matching operating system in the provider's
+// capabilities. Architecture aliases such as x86_64 and amd64 are treated as
+// the same.
+package osimage
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+var (
+	// ErrNoImage is wrapped by errors of machines without a matching image.
+	ErrNoImage = errors.New("no image")
+	// ErrArchitecture is wrapped by errors of images or operating systems
+	// built for another architecture than the machine.
+	ErrArchitecture = errors.New("architecture mismatch")
+)
+
+// Source tells how the image was found.
+type Source string
+
+const (
+	// SourceImageID is the image ID set on the machine.
+	SourceImageID Source = "ImageID"
+	// SourceImageFamily is the newest image of the image family, or of the
+	// operating system when the machine sets no image family.
+	SourceImageFamily Source = "ImageFamily"
+	// SourceDefault is the default image of the operating system in the
+	// capabilities of the provider.
+	SourceDefault Source = "DefaultImage"
+)
+
+// Resolution is the image of a machine on a provider.
+type Resolution struct {
+	ImageID string
+	Source  Source
+	// Image is nil when the provider does not list the image in its status.
+	Image *v1alpha1.ImageInfo
+	// Architecture is the normalized architecture of the machine, or "" if
+	// unset.
+	Architecture string
+}
+
+// Resolve returns the image of a machine's operating system on a provider:
+//
+//  1. The image ID, when set. The provider must list it, unless it lists no
+//     images at all, and it must be built for the machine's architecture.
+//  2. The newest image in status.availableResources.images of the image
+//     family, or else of the distribution, with the family, version and
+//     architecture of the machine where set. An image belongs to a family
+//     when its name is the family or starts with the family and a dash.
+//     Images without a creation date count as oldest.
+//  3. The defaultImageID of the first operating system in
+//     spec.capabilities.operatingSystems with the distribution, family and
+//     version of the machine, when it supports the machine's architecture.
+//
+// The error wraps ErrNoImage or ErrArchitecture.
+func Resolve(os v1alpha1.MachineOS, mp *v1alpha1.MachineProvider) (*Resolution, error) {
+	arch := NormalizeArchitecture(os.Architecture)
+	images := mp.Status.AvailableResources.Images
+
+	if os.ImageID != "" {
+		res := &Resolution{ImageID: os.ImageID, Source: SourceImageID, Architecture: arch}
+		if len(images) == 0 {
+			return res, nil
+		}
+		i := slices.IndexFunc(images, func(img v1alpha1.ImageInfo) bool { return img.ID == os.ImageID })
+		if i < 0 {
+			return nil, fmt.Errorf("%w: MachineProvider %s has no image %q", ErrNoImage, mp.Name, os.ImageID)
+		}
+		res.Image = &images[i]
+		if imageArch := NormalizeArchitecture(res.Image.Architecture); arch != "" && imageArch != "" && imageArch != arch {
+			return nil, fmt.Errorf("%w: image %q is built for %s, the machine runs %s", ErrArchitecture, os.ImageID, imageArch, arch)
+		}
+		return res, nil
+	}
+
+	if os.ImageFamily == "" && os.Distribution == "" {
+		return nil, fmt.Errorf("%w: the machine sets no image ID, image family or distribution", ErrNoImage)
+	}
+
+	var candidates []*v1alpha1.ImageInfo
+	for i := range images {
+		if img := &images[i]; matchImage(img, &os, arch) {
+			candidates = append(candidates, img)
+		}
+	}
+	if len(candidates) > 0 {
+		img := slices.MinFunc(candidates, newer)
+		return &Resolution{ImageID: img.ID, Source: SourceImageFamily, Image: img, Architecture: arch}, nil
+	}
+
+	var archErr error
+	for i := range mp.Spec.Capabilities.OperatingSystems {
+		info := &mp.Spec.Capabilities.OperatingSystems[i]
+		if !matchOS(info, &os) || info.DefaultImageID == "" {
+			continue
+		}
+		if arch != "" && !slices.ContainsFunc(info.Architectures, func(a string) bool { return NormalizeArchitecture(a) == arch }) {
+			archErr = fmt.Errorf("%w: MachineProvider %s supports %s only on %s, the machine runs %s",
+				ErrArchitecture, mp.Name, info.Distribution, strings.Join(info.Architectures, ", "), arch)
+			continue
+		}
+		return &Resolution{ImageID: info.DefaultImageID, Source: SourceDefault, Architecture: arch}, nil
+	}
+	if archErr != nil {
+		return nil, archErr
+	}
+	return nil, fmt.Errorf("%w: MachineProvider %s has no image for %s", ErrNoImage, mp.Name, describe(&os, arch))
+}
+
+// NormalizeArchitecture maps common CPU architecture aliases to the names used
+// by the API (amd64, arm64). Unknown values are returned lower-cased.
+func NormalizeArchitecture(arch string) string {
+	switch a := strings.ToLower(strings.TrimSpace(arch)); a {
+	case "x86_64", "x86-64", "x64", "amd64":
+		return "amd64"
+	case "aarch64", "arm64", "armv8":
+		return "arm64"
+	default:
+		return a
+	}
+}
+
+// InFamily tells whether an image belongs to an image family: its name is the
+// family or starts with the family and a dash, as in ubuntu-2404 and
+// ubuntu-2404-20250101.
+func InFamily(img *v1alpha1.ImageInfo, family string) bool {
+	return img.Name == family || strings.HasPrefix(img.Name, family+"-")
+}
+
+func matchImage(img *v1alpha1.ImageInfo, os *v1alpha1.MachineOS, arch string) bool {
+	switch {
+	case os.ImageFamily != "" && !InFamily(img, os.ImageFamily),
+		os.Distribution != "" && !strings.EqualFold(img.OSDistribution, os.Distribution),
+		os.Family != "" && img.OSFamily != "" && !strings.EqualFold(img.OSFamily, os.Family),
+		os.Version != "" && img.OSVersion != os.Version,
+		arch != "" && NormalizeArchitecture(img.Architecture) != arch:
+		return false
+	}
+	return true
+}
+
+func matchOS(info *v1alpha1.OSInfo, os *v1alpha1.MachineOS) bool {
+	switch {
+	case os.Distribution == "" || !strings.EqualFold(info.Distribution, os.Distribution),
+		os.Family != "" && info.Family != "" && !strings.EqualFold(info.Family, os.Family),
+		os.Version != "" && !slices.Contains(info.Versions, os.Version):
+		return false
+	}
+	return true
+}
+
+// newer orders images newest first, images without a creation date last.
+func newer(a, b *v1alpha1.ImageInfo) int {
+	switch {
+	case a.CreationDate == nil && b.CreationDate == nil:
+	case a.CreationDate == nil:
+		return 1
+	case b.CreationDate == nil:
+		return -1
+	case !a.CreationDate.Equal(b.CreationDate):
+		return b.CreationDate.Compare(a.CreationDate.Time)
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+func describe(os *v1alpha1.MachineOS, arch string) string {
+	var parts []string
+	if os.ImageFamily != "" {
+		parts = append(parts, "image family "+os.ImageFamily)
+	}
+	for _, s := range []string{os.Distribution, os.Version, arch} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,194 @@
+package osimage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func date(s string) *metav1.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return &metav1.Time{Time: t}
+}
+
+func newProvider() *v1alpha1.MachineProvider {
+	mp := &v1alpha1.MachineProvider{ObjectMeta: metav1.ObjectMeta{Name: "onprem"}}
+	mp.Status.AvailableResources.Images = []v1alpha1.ImageInfo{
+		{ID: "img-1", Name: "ubuntu-2404-20250101", OSDistribution: "ubuntu", OSVersion: "24.04", Architecture: "amd64", CreationDate: date("2025-01-01")},
+		{ID: "img-2", Name: "ubuntu-2404-20250301", OSDistribution: "ubuntu", OSVersion: "24.04", Architecture: "x86_64", CreationDate: date("2025-03-01")},
+		{ID: "img-3", Name: "ubuntu-2404-20250401", OSDistribution: "ubuntu", OSVersion: "24.04", Architecture: "aarch64", CreationDate: date("2025-04-01")},
+		{ID: "img-4", Name: "ubuntu-2404", OSDistribution: "ubuntu", OSVersion: "24.04", Architecture: "amd64"},
+		{ID: "img-5", Name: "ubuntu-2204-20240101", OSDistribution: "ubuntu", OSVersion: "22.04", Architecture: "amd64", CreationDate: date("2024-01-01")},
+		{ID: "img-6", Name: "ubuntu-24040", OSDistribution: "ubuntu", OSVersion: "24.04", Architecture: "amd64", CreationDate: date("2026-01-01")},
+	}
+	mp.Spec.Capabilities.OperatingSystems = []v1alpha1.OSInfo{
+		{Distribution: "debian", Versions: []string{"12"}, Architectures: []string{"x86_64"}, DefaultImageID: "debian-12"},
+		{Distribution: "rocky", Versions: []string{"9"}, Architectures: []string{"arm64"}, DefaultImageID: "rocky-9-arm"},
+	}
+	return mp
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name string
+		os   v1alpha1.MachineOS
+		// images replaces the images of the provider when not nil.
+		images     []v1alpha1.ImageInfo
+		wantID     string
+		wantSource Source
+		// is is the sentinel the error wraps, nil for none.
+		is error
+		// want is part of the error, "" for none.
+		want string
+	}{
+		{
+			name:       "image ID",
+			os:         v1alpha1.MachineOS{ImageID: "img-2", Architecture: "amd64"},
+			wantID:     "img-2",
+			wantSource: SourceImageID,
+		},
+		{
+			name: "image ID not listed",
+			os:   v1alpha1.MachineOS{ImageID: "img-9", Architecture: "amd64"},
+			is:   ErrNoImage,
+			want: `MachineProvider onprem has no image "img-9"`,
+		},
+		{
+			name:       "image ID of a provider without images",
+			os:         v1alpha1.MachineOS{ImageID: "img-9", Architecture: "amd64"},
+			images:     []v1alpha1.ImageInfo{},
+			wantID:     "img-9",
+			wantSource: SourceImageID,
+		},
+		{
+			name: "image ID of another architecture",
+			os:   v1alpha1.MachineOS{ImageID: "img-3", Architecture: "x64"},
+			is:   ErrArchitecture,
+			want: `image "img-3" is built for arm64, the machine runs amd64`,
+		},
+		{
+			name:       "image family newest first",
+			os:         v1alpha1.MachineOS{ImageFamily: "ubuntu-2404", Architecture: "amd64"},
+			wantID:     "img-2",
+			wantSource: SourceImageFamily,
+		},
+		{
+			name:       "image family with an architecture alias",
+			os:         v1alpha1.MachineOS{ImageFamily: "ubuntu-2404", Architecture: "ARM64"},
+			wantID:     "img-3",
+			wantSource: SourceImageFamily,
+		},
+		{
+			name:       "distribution and version",
+			os:         v1alpha1.MachineOS{Distribution: "Ubuntu", Version: "22.04", Architecture: "x86-64"},
+			wantID:     "img-5",
+			wantSource: SourceImageFamily,
+		},
+		{
+			name: "images without a creation date count as oldest",
+			os:   v1alpha1.MachineOS{ImageFamily: "ubuntu-2404", Architecture: "amd64"},
+			images: []v1alpha1.ImageInfo{
+				{ID: "img-b", Name: "ubuntu-2404", Architecture: "amd64"},
+				{ID: "img-a", Name: "ubuntu-2404-1", Architecture: "amd64"},
+				{ID: "img-c", Name: "ubuntu-2404-2", Architecture: "amd64", CreationDate: date("2020-01-01")},
+			},
+			wantID:     "img-c",
+			wantSource: SourceImageFamily,
+		},
+		{
+			name:       "default image with an architecture alias",
+			os:         v1alpha1.MachineOS{Distribution: "debian", Version: "12", Architecture: "amd64"},
+			wantID:     "debian-12",
+			wantSource: SourceDefault,
+		},
+		{
+			name: "default image of another architecture",
+			os:   v1alpha1.MachineOS{Distribution: "rocky", Architecture: "x86_64"},
+			is:   ErrArchitecture,
+			want: "MachineProvider onprem supports rocky only on arm64, the machine runs amd64",
+		},
+		{
+			name: "no matching image",
+			os:   v1alpha1.MachineOS{ImageFamily: "ubuntu-2604", Distribution: "ubuntu", Architecture: "amd64"},
+			is:   ErrNoImage,
+			want: "MachineProvider onprem has no image for image family ubuntu-2604 ubuntu amd64",
+		},
+		{
+			name: "no matching version",
+			os:   v1alpha1.MachineOS{Distribution: "debian", Version: "13"},
+			is:   ErrNoImage,
+			want: "MachineProvider onprem has no image for debian 13",
+		},
+		{
+			name: "nothing set",
+			os:   v1alpha1.MachineOS{Architecture: "amd64"},
+			is:   ErrNoImage,
+			want: "the machine sets no image ID, image family or distribution",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mp := newProvider()
+			if tt.images != nil {
+				mp.Status.AvailableResources.Images = tt.images
+			}
+			res, err := Resolve(tt.os, mp)
+			if tt.want != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Fatalf("error = %v, want %q", err, tt.want)
+				}
+				if !errors.Is(err, tt.is) {
+					t.Errorf("error = %v, want it to wrap %v", err, tt.is)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.ImageID != tt.wantID || res.Source != tt.wantSource {
+				t.Errorf("image = %s from %s, want %s from %s", res.ImageID, res.Source, tt.wantID, tt.wantSource)
+			}
+			if want := NormalizeArchitecture(tt.os.Architecture); res.Architecture != want {
+				t.Errorf("architecture = %q, want %q", res.Architecture, want)
+			}
+		})
+	}
+}
+
+func TestNormalizeArchitecture(t *testing.T) {
+	for in, want := range map[string]string{
+		"x86_64":  "amd64",
+		"X86-64":  "amd64",
+		"x64":     "amd64",
+		" amd64 ": "amd64",
+		"aarch64": "arm64",
+		"ARMv8":   "arm64",
+		"arm64":   "arm64",
+		"PPC64LE": "ppc64le",
+		"":        "",
+	} {
+		if got := NormalizeArchitecture(in); got != want {
+			t.Errorf("NormalizeArchitecture(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestInFamily(t *testing.T) {
+	for name, want := range map[string]bool{
+		"ubuntu-2404":          true,
+		"ubuntu-2404-20250101": true,
+		"ubuntu-24040":         false,
+		"ubuntu":               false,
+	} {
+		if got := InFamily(&v1alpha1.ImageInfo{Name: name}, "ubuntu-2404"); got != want {
+			t.Errorf("InFamily(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,402 @@
+// Package scheduler selects the MachineProvider a new Machine or
+// KubernetesProvider lands on among the machine providers of a Vitistack.
+// Providers are filtered by health, capabilities, zone and quota headroom and
+// ranked by their Vitistack priority and cost. Every decision explains why
+// each provider was passed over, for events and status messages.
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/vitistack/crds/pkg/clusternet"
+	"github.com/vitistack/crds/pkg/instancetype"
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+// ConfigZones is the key of the configuration of a Vitistack provider
+// reference that limits the Vitistack to some zones of the provider, comma
+// separated.
+const ConfigZones = "zones"
+
+// ErrNoProvider is returned when no provider can take a request.
+var ErrNoProvider = errors.New("no suitable MachineProvider")
+
+// Request is what is to be placed.
+type Request struct {
+	// Zones the request may land in, in order of preference. Empty means the
+	// zone of the Vitistack, or any zone when it has none.
+	Zones []string
+	// Instances to create.
+	Instances []Instance
+	// GPU, IPv6, PublicIP and LoadBalancers are required provider features.
+	// Every instance needs a GPU: the provider has GPU support, or each
+	// instance has an instance type with a GPU.
+	GPU           bool
+	IPv6          bool
+	PublicIP      bool
+	LoadBalancers bool
+	// NetworkFeatures the provider must list in its capabilities.
+	NetworkFeatures []string
+}
+
+// Instance is a group of identical machines.
+type Instance struct {
+	// InstanceType the provider must offer, if any. Its size counts when it is
+	// larger than the resources below.
+	InstanceType string
+	// CPUs, Memory in bytes and StorageGB of one machine.
+	CPUs      int
+	Memory    int64
+	StorageGB int
+	// Count of machines, default 1.
+	Count int
+}
+
+func (i *Instance) count() int {
+	return max(i.Count, 1)
+}
+
+// MachineRequest returns the request of a Machine.
+func MachineRequest(m *v1alpha1.Machine) Request {
+	inst := Instance{
+		InstanceType: m.Spec.InstanceType,
+		CPUs:         instancetype.VCPUs(m.Spec.CPU),
+		Memory:       m.Spec.Memory,
+	}
+	for _, d := range m.Spec.Disks {
+		inst.StorageGB += int(d.SizeGB)
+	}
+	r := Request{Instances: []Instance{inst}, PublicIP: m.Spec.Network.AssignPublicIP}
+	if m.Spec.ProviderConfig.Zone != "" {
+		r.Zones = []string{m.Spec.ProviderConfig.Zone}
+	}
+	return r
+}
+
+// KubernetesRequest returns the request of a KubernetesProvider: the desired
+// nodes of its node pools, or their minimum when no size is desired. The
+// cluster needs IPv6 unless it can fall back to IPv4, see clusternet.Resolve.
+func KubernetesRequest(kp *v1alpha1.KubernetesProvider) Request {
+	r := Request{Zones: kp.Spec.Zones}
+	for _, pool := range kp.Spec.NodePools {
+		r.Instances = append(r.Instances, Instance{
+			InstanceType: pool.InstanceType,
+			StorageGB:    pool.NodeConfig.Disk.SizeGB,
+			Count:        max(pool.DesiredNodes, pool.MinNodes, 1),
+		})
+	}
+	cfg := &kp.Spec.Network
+	if n, err := clusternet.Resolve(cfg, nil); err == nil && slices.Contains(n.Families, v1alpha1.IPFamilyIPv6) {
+		fallback := cfg.IPFamilyPolicy == clusternet.PolicyPreferDualStack && n.Families[0] == v1alpha1.IPFamilyIPv4
+		r.IPv6 = !fallback
+	}
+	return r
+}
+
+// Candidate is a provider that can take a request.
+type Candidate struct {
+	Provider  *v1alpha1.MachineProvider
+	Reference v1alpha1.VitistackProviderReference
+	// Zone the request lands in, empty when the provider chooses.
+	Zone string
+	// CostPerHour of the request, when every instance type has a cost.
+	CostPerHour float64
+	CostKnown   bool
+	// Degraded providers rank after healthy ones of the same priority.
+	Degraded bool
+}
+
+// Rejection is a provider that cannot take a request, with every reason.
+type Rejection struct {
+	Provider string
+	Reasons  []string
+}
+
+func (r *Rejection) String() string {
+	return fmt.Sprintf("%s: %s", r.Provider, strings.Join(r.Reasons, ", "))
+}
+
+// Decision is the outcome of Select.
+type Decision struct {
+	// Candidates best first.
+	Candidates []Candidate
+	// Rejections in the order of the Vitistack.
+	Rejections []Rejection
+}
+
+// Selected returns the best candidate, nil when there is none.
+func (d *Decision) Selected() *Candidate {
+	if len(d.Candidates) == 0 {
+		return nil
+	}
+	return &d.Candidates[0]
+}
+
+// Message explains the decision in one line, for an event or a status
+// message.
+func (d *Decision) Message() string {
+	var parts []string
+	if c := d.Selected(); c != nil {
+		details := []string{fmt.Sprintf("priority %s", priority(c.Reference.Priority))}
+		if c.Zone != "" {
+			details = append(details, "zone "+c.Zone)
+		}
+		if c.CostKnown {
+			details = append(details, fmt.Sprintf("%.2f/h", c.CostPerHour))
+		}
+		if c.Degraded {
+			details = append(details, "degraded")
+		}
+		parts = append(parts, fmt.Sprintf("selected MachineProvider %s (%s)", c.Provider.Name, strings.Join(details, ", ")))
+		for _, other := range d.Candidates[1:] {
+			parts = append(parts, fmt.Sprintf("%s: ranked lower, priority %s", other.Provider.Name, priority(other.Reference.Priority)))
+		}
+	}
+	for i := range d.Rejections {
+		parts = append(parts, d.Rejections[i].String())
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Select filters the enabled machine providers of a Vitistack for a request
+// and ranks the others:
+//
+//  1. by priority, 1 first and unset last
+//  2. healthy before degraded
+//  3. by cost, unknown cost last
+//  4. by name
+//
+// A provider is rejected when it is disabled in the Vitistack, missing from
+// providers, Failed, Offline or Unhealthy, lacks an instance type or feature,
+// offers none of the zones, or has not enough quota or machine capacity left.
+// Without candidates the error wraps ErrNoProvider and explains every
+// rejection.
+func Select(vs *v1alpha1.Vitistack, providers []v1alpha1.MachineProvider, req Request) (*Decision, error) {
+	if len(req.Zones) == 0 && vs.Spec.Zone != "" {
+		req.Zones = []string{vs.Spec.Zone}
+	}
+	d := &Decision{}
+	for _, ref := range vs.Spec.MachineProviders {
+		i := slices.IndexFunc(providers, func(mp v1alpha1.MachineProvider) bool { return mp.Name == ref.Name })
+		var reasons []string
+		var c Candidate
+		switch {
+		case !ref.Enabled:
+			reasons = []string{fmt.Sprintf("disabled in Vitistack %s", vs.Name)}
+		case i < 0:
+			reasons = []string{"not found"}
+		default:
+			c = Candidate{Provider: &providers[i], Reference: ref}
+			reasons = check(&c, &req)
+		}
+		if len(reasons) > 0 {
+			d.Rejections = append(d.Rejections, Rejection{Provider: ref.Name, Reasons: reasons})
+			continue
+		}
+		d.Candidates = append(d.Candidates, c)
+	}
+	slices.SortStableFunc(d.Candidates, rank)
+	if len(d.Candidates) == 0 {
+		return d, fmt.Errorf("%w in Vitistack %s: %s", ErrNoProvider, vs.Name, d.Message())
+	}
+	return d, nil
+}
+
+// check fills in a candidate and returns why it cannot take the request.
+func check(c *Candidate, req *Request) []string {
+	mp := c.Provider
+	var reasons []string
+	addf := func(format string, args ...any) {
+		reasons = append(reasons, fmt.Sprintf(format, args...))
+	}
+
+	switch mp.Status.Phase {
+	case v1alpha1.MachineProviderPhaseFailed, v1alpha1.MachineProviderPhaseOffline:
+		addf("is %s", mp.Status.Phase)
+	}
+	switch mp.Status.Health.Status {
+	case v1alpha1.ProviderHealthStatusUnhealthy:
+		addf("is %s", mp.Status.Health.Status)
+	case v1alpha1.ProviderHealthStatusDegraded:
+		c.Degraded = true
+	}
+
+	zone, reason := selectZone(c, req.Zones)
+	if reason != "" {
+		reasons = append(reasons, reason)
+	}
+	c.Zone = zone
+
+	caps := &mp.Spec.Capabilities
+	if req.IPv6 && !mp.Spec.Network.IPv6Support {
+		addf("has no IPv6")
+	}
+	if req.PublicIP && !mp.Spec.Network.PublicIPSupport {
+		addf("has no public IPs")
+	}
+	if req.LoadBalancers && !caps.LoadBalancers && !mp.Spec.Network.LoadBalancerSupport {
+		addf("has no load balancers")
+	}
+	for _, f := range req.NetworkFeatures {
+		if !slices.Contains(caps.NetworkFeatures, f) {
+			addf("lacks network feature %s", f)
+		}
+	}
+
+	var cpus, storageGB, machines int
+	var memory int64
+	var cost float64
+	costKnown := len(req.Instances) > 0
+	gpuTypes := len(req.Instances) > 0
+	for i := range req.Instances {
+		inst := &req.Instances[i]
+		n := inst.count()
+		instCPUs, instMemory := inst.CPUs, inst.Memory
+		priced, instGPU := false, false
+		if name := inst.InstanceType; name != "" {
+			info := instancetype.Find(caps.InstanceTypes, name)
+			switch {
+			case info == nil && len(caps.InstanceTypes) > 0:
+				addf("does not offer instance type %s", name)
+			case info != nil:
+				mem, _ := instancetype.MemoryBytes(info)
+				instCPUs, instMemory = max(instCPUs, info.VCPUs), max(instMemory, mem)
+				instGPU = info.GPU
+				if price, err := strconv.ParseFloat(info.CostPerHour, 64); err == nil {
+					cost += price * float64(n)
+					priced = true
+				}
+			}
+			if available := mp.Status.AvailableResources.InstanceTypes; len(available) > 0 && !slices.Contains(available, name) {
+				addf("instance type %s is currently not available", name)
+			}
+		}
+		costKnown = costKnown && priced
+		gpuTypes = gpuTypes && instGPU
+		if limit := mp.Spec.Compute.MaxCPUs; limit > 0 && instCPUs > limit {
+			addf("allows %d CPUs per machine, %d needed", limit, instCPUs)
+		}
+		if limit := instancetype.MaxMemory(mp); limit > 0 && instMemory > limit {
+			addf("allows %s memory per machine, %s needed", instancetype.FormatMemory(limit), instancetype.FormatMemory(instMemory))
+		}
+		cpus += instCPUs * n
+		memory += instMemory * int64(n)
+		storageGB += inst.StorageGB * n
+		machines += n
+	}
+	if costKnown {
+		c.CostPerHour, c.CostKnown = cost, true
+	}
+	if req.GPU && !mp.Spec.Compute.GPUSupport && !gpuTypes {
+		addf("has no GPU")
+	}
+
+	q := &mp.Status.Quota
+	for _, r := range []struct {
+		what              string
+		quota, used, need float64
+	}{
+		{"CPUs", float64(q.CPUQuota), float64(q.CPUUsed), float64(cpus)},
+		{"GiB memory", float64(q.MemoryQuotaGB), float64(q.MemoryUsedGB), float64(memory) / instancetype.GiB},
+		{"GB storage", float64(q.StorageQuotaGB), float64(q.StorageUsedGB), float64(storageGB)},
+		{"instances", float64(q.InstanceQuota), float64(q.InstanceUsed), float64(machines)},
+	} {
+		if r.quota > 0 && r.used+r.need > r.quota {
+			addf("has %s %s of quota left, %s needed", number(max(r.quota-r.used, 0)), r.what, number(r.need))
+		}
+	}
+	if limit := caps.MaxMachines; limit > 0 && mp.Status.ActiveMachines+machines > limit {
+		addf("has room for %d of %d machines", max(limit-mp.Status.ActiveMachines, 0), machines)
+	}
+	return reasons
+}
+
+// selectZone returns the first wanted zone the provider offers and has not
+// reported unhealthy. A provider without zones takes any zone.
+func selectZone(c *Candidate, wanted []string) (string, string) {
+	mp := c.Provider
+	offered := mp.Status.AvailableResources.Zones
+	if len(offered) == 0 {
+		offered = mp.Spec.Zones
+	}
+	if limit, ok := c.Reference.Configuration[ConfigZones]; ok {
+		var allowed []string
+		for _, z := range strings.Split(limit, ",") {
+			if z = strings.TrimSpace(z); z != "" && (len(offered) == 0 || slices.Contains(offered, z)) {
+				allowed = append(allowed, z)
+			}
+		}
+		offered = allowed
+		if len(offered) == 0 {
+			return "", fmt.Sprintf("offers none of the zones %s configured in the Vitistack", limit)
+		}
+	}
+	var usable []string
+	for _, z := range offered {
+		if mp.Status.Health.ServiceAvailability[z] != v1alpha1.ProviderHealthStatusUnhealthy {
+			usable = append(usable, z)
+		}
+	}
+	switch {
+	case len(offered) == 0:
+		if len(wanted) > 0 {
+			return wanted[0], ""
+		}
+		return "", ""
+	case len(usable) == 0:
+		return "", "has no healthy zone"
+	case len(wanted) == 0:
+		return "", ""
+	}
+	for _, z := range wanted {
+		if slices.Contains(usable, z) {
+			return z, ""
+		}
+	}
+	return "", fmt.Sprintf("offers none of the zones %s (offers %s)", strings.Join(wanted, ", "), strings.Join(usable, ", "))
+}
+
+func rank(a, b Candidate) int {
+	pa, pb := a.Reference.Priority, b.Reference.Priority
+	switch {
+	case pa != pb && (pa == 0 || pb == 0):
+		// Unset priorities come last.
+		if pa == 0 {
+			return 1
+		}
+		return -1
+	case pa != pb:
+		return int(pa - pb)
+	case a.Degraded != b.Degraded:
+		if a.Degraded {
+			return 1
+		}
+		return -1
+	case a.CostKnown != b.CostKnown:
+		if a.CostKnown {
+			return -1
+		}
+		return 1
+	case a.CostPerHour != b.CostPerHour:
+		if a.CostPerHour < b.CostPerHour {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a.Provider.Name, b.Provider.Name)
+}
+
+func priority(p int32) string {
+	if p == 0 {
+		return "unset"
+	}
+	return strconv.Itoa(int(p))
+}
+
+func number(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
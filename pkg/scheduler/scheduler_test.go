@@ -0,0 +1,308 @@
+package scheduler
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func provider(name string, zones ...string) v1alpha1.MachineProvider {
+	mp := v1alpha1.MachineProvider{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	mp.Spec.Zones = zones
+	mp.Spec.Capabilities.InstanceTypes = []v1alpha1.InstanceTypeInfo{
+		{Name: "small", VCPUs: 2, MemoryGB: "4", CostPerHour: "0.05"},
+		{Name: "large", VCPUs: 8, MemoryGB: "32", CostPerHour: "0.40"},
+		{Name: "gpu", VCPUs: 8, MemoryGB: "32", GPU: true},
+	}
+	return mp
+}
+
+func newVitistack(refs ...v1alpha1.VitistackProviderReference) *v1alpha1.Vitistack {
+	vs := &v1alpha1.Vitistack{ObjectMeta: metav1.ObjectMeta{Name: "prod"}}
+	vs.Spec.MachineProviders = refs
+	return vs
+}
+
+func ref(name string, priority int32) v1alpha1.VitistackProviderReference {
+	return v1alpha1.VitistackProviderReference{Name: name, Priority: priority, Enabled: true}
+}
+
+func instances(types ...string) []Instance {
+	var insts []Instance
+	for _, t := range types {
+		insts = append(insts, Instance{InstanceType: t})
+	}
+	return insts
+}
+
+func names(d *Decision) []string {
+	var names []string
+	for _, c := range d.Candidates {
+		names = append(names, c.Provider.Name)
+	}
+	return names
+}
+
+func TestSelectFilters(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(vs *v1alpha1.Vitistack, mp *v1alpha1.MachineProvider)
+		req    Request
+		// want is the reason b is rejected, "" when it is a candidate.
+		want string
+	}{
+		{name: "candidate", req: Request{Instances: instances("small")}},
+		{
+			name:   "disabled",
+			mutate: func(vs *v1alpha1.Vitistack, _ *v1alpha1.MachineProvider) { vs.Spec.MachineProviders[1].Enabled = false },
+			want:   "disabled in Vitistack prod",
+		},
+		{
+			name:   "not found",
+			mutate: func(vs *v1alpha1.Vitistack, _ *v1alpha1.MachineProvider) { vs.Spec.MachineProviders[1].Name = "c" },
+			want:   "c: not found",
+		},
+		{
+			name: "offline",
+			mutate: func(_ *v1alpha1.Vitistack, mp *v1alpha1.MachineProvider) {
+				mp.Status.Phase = v1alpha1.MachineProviderPhaseOffline
+			},
+			want: "is Offline",
+		},
+		{
+			name: "unhealthy",
+			mutate: func(_ *v1alpha1.Vitistack, mp *v1alpha1.MachineProvider) {
+				mp.Status.Health.Status = v1alpha1.ProviderHealthStatusUnhealthy
+			},
+			want: "is Unhealthy",
+		},
+		{name: "unknown instance type", req: Request{Instances: instances("xlarge")}, want: "does not offer instance type xlarge"},
+		{
+			name: "instance type not available",
+			mutate: func(_ *v1alpha1.Vitistack, mp *v1alpha1.MachineProvider) {
+				mp.Status.AvailableResources.InstanceTypes = []string{"small"}
+			},
+			req:  Request{Instances: instances("large")},
+			want: "instance type large is currently not available",
+		},
+		{name: "no GPU", req: Request{GPU: true, Instances: instances("small")}, want: "has no GPU"},
+		{name: "GPU instance type", req: Request{GPU: true, Instances: instances("gpu")}},
+		{
+			name: "GPU instance type for only one instance",
+			req:  Request{GPU: true, Instances: instances("gpu", "small")},
+			want: "has no GPU",
+		},
+		{
+			name:   "GPU support",
+			mutate: func(_ *v1alpha1.Vitistack, mp *v1alpha1.MachineProvider) { mp.Spec.Compute.GPUSupport = true },
+			req:    Request{GPU: true, Instances: instances("gpu", "small")},
+		},
+		{name: "no IPv6", req: Request{IPv6: true}, want: "has no IPv6"},
+		{name: "no public IPs", req: Request{PublicIP: true}, want: "has no public IPs"},
+		{name: "no load balancers", req: Request{LoadBalancers: true}, want: "has no load balancers"},
+		{name: "network feature", req: Request{NetworkFeatures: []string{"sriov"}}, want: "lacks network feature sriov"},
+		{
+			name:   "CPU limit",
+			mutate: func(_ *v1alpha1.Vitistack, mp *v1alpha1.MachineProvider) { mp.Spec.Compute.MaxCPUs = 4 },
+			req:    Request{Instances: instances("large")},
+			want:   "allows 4 CPUs per machine, 8 needed",
+		},
+		{
+			name:   "memory limit",
+			mutate: func(_ *v1alpha1.Vitistack, mp *v1alpha1.MachineProvider) { mp.Spec.Compute.MaxMemoryGB = 16 },
+			req:    Request{Instances: instances("large")},
+			want:   "allows 16GiB memory per machine, 32GiB needed",
+		},
+		{
+			name: "CPU quota",
+			mutate: func(_ *v1alpha1.Vitistack, mp *v1alpha1.MachineProvider) {
+				mp.Status.Quota.CPUQuota, mp.Status.Quota.CPUUsed = 10, 6
+			},
+			req:  Request{Instances: []Instance{{InstanceType: "small", Count: 3}}},
+			want: "has 4 CPUs of quota left, 6 needed",
+		},
+		{
+			name: "instance quota",
+			mutate: func(_ *v1alpha1.Vitistack, mp *v1alpha1.MachineProvider) {
+				mp.Status.Quota.InstanceQuota, mp.Status.Quota.InstanceUsed = 5, 5
+			},
+			req:  Request{Instances: instances("small")},
+			want: "has 0 instances of quota left, 1 needed",
+		},
+		{
+			name: "machine capacity",
+			mutate: func(_ *v1alpha1.Vitistack, mp *v1alpha1.MachineProvider) {
+				mp.Spec.Capabilities.MaxMachines, mp.Status.ActiveMachines = 10, 9
+			},
+			req:  Request{Instances: []Instance{{InstanceType: "small", Count: 2}}},
+			want: "has room for 1 of 2 machines",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vs := newVitistack(ref("a", 1), ref("b", 2))
+			providers := []v1alpha1.MachineProvider{provider("a"), provider("b")}
+			// a never takes the request, so b decides the outcome.
+			providers[0].Status.Phase = v1alpha1.MachineProviderPhaseFailed
+			if tt.mutate != nil {
+				tt.mutate(vs, &providers[1])
+			}
+			d, err := Select(vs, providers, tt.req)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("error = %v, want none", err)
+				}
+				if got := names(d); !slices.Equal(got, []string{"b"}) {
+					t.Errorf("candidates = %v, want [b]", got)
+				}
+				return
+			}
+			if !errors.Is(err, ErrNoProvider) {
+				t.Fatalf("error = %v, want %v", err, ErrNoProvider)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+			if !strings.Contains(err.Error(), "a: is Failed") {
+				t.Errorf("error = %v, want the rejection of a", err)
+			}
+		})
+	}
+}
+
+func TestSelectZone(t *testing.T) {
+	tests := []struct {
+		name         string
+		vsZone       string
+		zones        []string
+		offered      []string
+		available    []string
+		config       string
+		unhealthy    []string
+		wantZone     string
+		wantRejected string
+	}{
+		{name: "first wanted zone offered", zones: []string{"az3", "az2"}, offered: []string{"az1", "az2", "az3"}, wantZone: "az3"},
+		{name: "zone of the Vitistack", vsZone: "az2", offered: []string{"az1", "az2"}, wantZone: "az2"},
+		{name: "request overrides the Vitistack", vsZone: "az2", zones: []string{"az1"}, offered: []string{"az1", "az2"}, wantZone: "az1"},
+		{name: "provider chooses", offered: []string{"az1"}},
+		{name: "provider without zones takes any", zones: []string{"az9"}, wantZone: "az9"},
+		{name: "available zones before spec", zones: []string{"az1"}, offered: []string{"az1"}, available: []string{"az2"}, wantRejected: "offers none of the zones az1 (offers az2)"},
+		{name: "unhealthy zone skipped", zones: []string{"az1", "az2"}, offered: []string{"az1", "az2"}, unhealthy: []string{"az1"}, wantZone: "az2"},
+		{name: "every zone unhealthy", offered: []string{"az1"}, unhealthy: []string{"az1"}, wantRejected: "has no healthy zone"},
+		{name: "configured zones", zones: []string{"az1", "az2"}, offered: []string{"az1", "az2"}, config: "az2, az3", wantZone: "az2"},
+		{name: "configured zones not offered", offered: []string{"az1"}, config: "az3", wantRejected: "offers none of the zones az3 configured in the Vitistack"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := ref("a", 1)
+			if tt.config != "" {
+				r.Configuration = map[string]string{ConfigZones: tt.config}
+			}
+			vs := newVitistack(r)
+			vs.Spec.Zone = tt.vsZone
+			mp := provider("a", tt.offered...)
+			mp.Status.AvailableResources.Zones = tt.available
+			for _, z := range tt.unhealthy {
+				if mp.Status.Health.ServiceAvailability == nil {
+					mp.Status.Health.ServiceAvailability = map[string]string{}
+				}
+				mp.Status.Health.ServiceAvailability[z] = v1alpha1.ProviderHealthStatusUnhealthy
+			}
+			d, err := Select(vs, []v1alpha1.MachineProvider{mp}, Request{Zones: tt.zones})
+			if tt.wantRejected != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantRejected) {
+					t.Fatalf("error = %v, want %q", err, tt.wantRejected)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := d.Selected().Zone; got != tt.wantZone {
+				t.Errorf("zone = %q, want %q", got, tt.wantZone)
+			}
+		})
+	}
+}
+
+func TestSelectRanking(t *testing.T) {
+	cheap, pricey, unpriced := provider("cheap"), provider("pricey"), provider("unpriced")
+	pricey.Spec.Capabilities.InstanceTypes[0].CostPerHour = "0.50"
+	unpriced.Spec.Capabilities.InstanceTypes[0].CostPerHour = ""
+	degraded := provider("degraded")
+	degraded.Status.Health.Status = v1alpha1.ProviderHealthStatusDegraded
+	providers := []v1alpha1.MachineProvider{cheap, pricey, unpriced, degraded, provider("unset"), provider("first")}
+	req := Request{Instances: instances("small")}
+
+	tests := []struct {
+		name string
+		refs []v1alpha1.VitistackProviderReference
+		want []string
+	}{
+		{
+			name: "priority first, unset last",
+			refs: []v1alpha1.VitistackProviderReference{ref("unset", 0), ref("pricey", 2), ref("first", 1)},
+			want: []string{"first", "pricey", "unset"},
+		},
+		{
+			name: "healthy before degraded",
+			refs: []v1alpha1.VitistackProviderReference{ref("degraded", 1), ref("pricey", 1), ref("unset", 2)},
+			want: []string{"pricey", "degraded", "unset"},
+		},
+		{
+			name: "cheaper first, unknown cost last",
+			refs: []v1alpha1.VitistackProviderReference{ref("unpriced", 1), ref("pricey", 1), ref("cheap", 1)},
+			want: []string{"cheap", "pricey", "unpriced"},
+		},
+		{
+			name: "by name",
+			refs: []v1alpha1.VitistackProviderReference{ref("first", 1), ref("cheap", 1)},
+			want: []string{"cheap", "first"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Select(newVitistack(tt.refs...), providers, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := names(d); !slices.Equal(got, tt.want) {
+				t.Errorf("candidates = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecisionMessage(t *testing.T) {
+	degraded := provider("a", "az1")
+	degraded.Status.Health.Status = v1alpha1.ProviderHealthStatusDegraded
+	offline := provider("c")
+	offline.Status.Phase = v1alpha1.MachineProviderPhaseOffline
+	providers := []v1alpha1.MachineProvider{degraded, provider("b"), offline}
+	vs := newVitistack(ref("a", 1), ref("b", 0), ref("c", 2), ref("d", 3))
+
+	d, err := Select(vs, providers, Request{Zones: []string{"az1"}, Instances: []Instance{{InstanceType: "small", Count: 2}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "selected MachineProvider a (priority 1, zone az1, 0.10/h, degraded); b: ranked lower, priority unset; c: is Offline; d: not found"
+	if got := d.Message(); got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+
+	d, err = Select(newVitistack(ref("c", 1)), providers, Request{})
+	if !errors.Is(err, ErrNoProvider) {
+		t.Fatalf("error = %v, want %v", err, ErrNoProvider)
+	}
+	if d.Selected() != nil {
+		t.Errorf("selected %s, want none", d.Selected().Provider.Name)
+	}
+	if want := "no suitable MachineProvider in Vitistack prod: c: is Offline"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+}
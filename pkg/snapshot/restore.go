@@ -0,0 +1,105 @@
+package snapshot
+
+import (
+	"fmt"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Mode returns the restore mode, applying the API default.
+func Mode(restore *v1alpha1.MachineRestore) string {
+	if restore.Spec.Mode == "" {
+		return v1alpha1.MachineRestoreModeInPlace
+	}
+	return restore.Spec.Mode
+}
+
+// TargetMachineName returns the name of the machine the restore writes to.
+func TargetMachineName(restore *v1alpha1.MachineRestore, snapshot *v1alpha1.MachineSnapshot) string {
+	if Mode(restore) == v1alpha1.MachineRestoreModeNewMachine {
+		return restore.Spec.NewMachineName
+	}
+	return snapshot.Spec.MachineRef.Name
+}
+
+// ValidateRestore checks that the restore can be carried out from the snapshot.
+func ValidateRestore(restore *v1alpha1.MachineRestore, snapshot *v1alpha1.MachineSnapshot) error {
+	if snapshot == nil {
+		return fmt.Errorf("MachineSnapshot %q not found", restore.Spec.SnapshotRef.Name)
+	}
+	if snapshot.Name != restore.Spec.SnapshotRef.Name || snapshot.Namespace != restore.Namespace {
+		return fmt.Errorf("MachineSnapshot %s/%s was given but %s/%s is referenced",
+			snapshot.Namespace, snapshot.Name, restore.Namespace, restore.Spec.SnapshotRef.Name)
+	}
+	if !snapshot.Status.ReadyToUse {
+		return fmt.Errorf("MachineSnapshot %q is not ready to use", snapshot.Name)
+	}
+	if restore.Spec.RestoreMemory && !snapshot.Status.MemoryIncluded {
+		return fmt.Errorf("restoreMemory is set but MachineSnapshot %q has no memory state", snapshot.Name)
+	}
+
+	switch Mode(restore) {
+	case v1alpha1.MachineRestoreModeInPlace:
+		if restore.Spec.NewMachineName != "" {
+			return fmt.Errorf("newMachineName is only allowed with mode %s", v1alpha1.MachineRestoreModeNewMachine)
+		}
+	case v1alpha1.MachineRestoreModeNewMachine:
+		if restore.Spec.NewMachineName == "" {
+			return fmt.Errorf("newMachineName is required with mode %s", v1alpha1.MachineRestoreModeNewMachine)
+		}
+		if restore.Spec.NewMachineName == snapshot.Spec.MachineRef.Name {
+			return fmt.Errorf("newMachineName %q is the source machine, use mode %s instead",
+				restore.Spec.NewMachineName, v1alpha1.MachineRestoreModeInPlace)
+		}
+	default:
+		return fmt.Errorf("unknown restore mode %q", restore.Spec.Mode)
+	}
+	return nil
+}
+
+// NewMachine builds the machine created by a NewMachine restore from the
+// source machine of the snapshot. Static addresses are cleared so the copy
+// does not collide with the source, and labels that would let a MachineSet
+// adopt the copy are dropped. The machine is not owned by the restore, so
+// deleting the MachineRestore afterwards keeps it.
+func NewMachine(restore *v1alpha1.MachineRestore, snapshot *v1alpha1.MachineSnapshot, source *v1alpha1.Machine) (*v1alpha1.Machine, error) {
+	if err := ValidateRestore(restore, snapshot); err != nil {
+		return nil, err
+	}
+	if Mode(restore) != v1alpha1.MachineRestoreModeNewMachine {
+		return nil, fmt.Errorf("MachineRestore %q restores in place", restore.Name)
+	}
+	if source == nil || source.Name != snapshot.Spec.MachineRef.Name || source.Namespace != snapshot.Namespace {
+		return nil, fmt.Errorf("source machine %s/%s of MachineSnapshot %q was not given",
+			snapshot.Namespace, snapshot.Spec.MachineRef.Name, snapshot.Name)
+	}
+
+	spec := *source.Spec.DeepCopy()
+	spec.Name = restore.Spec.NewMachineName
+	spec.Network.PrivateIP = ""
+	spec.Network.PublicIP = ""
+
+	machineLabels := map[string]string{}
+	for k, v := range source.Labels {
+		switch k {
+		case v1alpha1.MachineSetNameLabel, v1alpha1.MachineDeploymentNameLabel, v1alpha1.MachineTemplateHashLabel:
+			continue
+		}
+		machineLabels[k] = v
+	}
+
+	return &v1alpha1.Machine{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "Machine",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        restore.Spec.NewMachineName,
+			Namespace:   restore.Namespace,
+			Labels:      machineLabels,
+			Annotations: map[string]string{v1alpha1.MachineRestoredFromAnnotation: snapshot.Name},
+		},
+		Spec: spec,
+	}, nil
+}
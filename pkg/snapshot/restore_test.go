@@ -0,0 +1,94 @@
+package snapshot
+
+import (
+	"strings"
+	"testing"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newRestore(mode, newMachineName string) *v1alpha1.MachineRestore {
+	r := restore("default", "snap", "")
+	r.Spec.Mode, r.Spec.NewMachineName = mode, newMachineName
+	return &r
+}
+
+func TestValidateRestore(t *testing.T) {
+	ready := snapshot("snap", 1)
+	pending := withPhase(snapshot("snap", 1), v1alpha1.MachineSnapshotPhasePending)
+	other := snapshot("other", 1)
+	memory := newRestore("", "")
+	memory.Spec.RestoreMemory = true
+	tests := []struct {
+		name     string
+		restore  *v1alpha1.MachineRestore
+		snapshot *v1alpha1.MachineSnapshot
+		// want is part of the error, "" for none.
+		want string
+	}{
+		{name: "in place by default", restore: newRestore("", ""), snapshot: &ready},
+		{name: "new machine", restore: newRestore(v1alpha1.MachineRestoreModeNewMachine, "web-copy"), snapshot: &ready},
+		{name: "not found", restore: newRestore("", ""), want: `MachineSnapshot "snap" not found`},
+		{name: "other snapshot", restore: newRestore("", ""), snapshot: &other, want: "default/other was given but default/snap is referenced"},
+		{name: "not ready", restore: newRestore("", ""), snapshot: &pending, want: "is not ready to use"},
+		{name: "no memory state", restore: memory, snapshot: &ready, want: "has no memory state"},
+		{name: "new machine name in place", restore: newRestore(v1alpha1.MachineRestoreModeInPlace, "web-copy"), snapshot: &ready, want: "newMachineName is only allowed with mode NewMachine"},
+		{name: "new machine without name", restore: newRestore(v1alpha1.MachineRestoreModeNewMachine, ""), snapshot: &ready, want: "newMachineName is required"},
+		{name: "new machine over the source", restore: newRestore(v1alpha1.MachineRestoreModeNewMachine, "web"), snapshot: &ready, want: "is the source machine"},
+		{name: "unknown mode", restore: newRestore("Clone", ""), snapshot: &ready, want: `unknown restore mode "Clone"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRestore(tt.restore, tt.snapshot)
+			if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMachine(t *testing.T) {
+	snap := snapshot("snap", 1)
+	source := newMachine(7)
+	source.Labels = map[string]string{
+		"app":                               "web",
+		v1alpha1.MachineSetNameLabel:        "web-abc",
+		v1alpha1.MachineDeploymentNameLabel: "web",
+		v1alpha1.MachineTemplateHashLabel:   "abc",
+	}
+	source.Spec.Name = "web"
+	source.Spec.Network = v1alpha1.MachineNetwork{PrivateIP: "10.0.0.5", PublicIP: "192.0.2.5", AssignPublicIP: true}
+
+	m, err := NewMachine(newRestore(v1alpha1.MachineRestoreModeNewMachine, "web-copy"), &snap, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "web-copy" || m.Namespace != "default" || m.Spec.Name != "web-copy" || m.Kind != "Machine" {
+		t.Errorf("machine %s/%s named %q of kind %q, want default/web-copy", m.Namespace, m.Name, m.Spec.Name, m.Kind)
+	}
+	if len(m.Labels) != 1 || m.Labels["app"] != "web" {
+		t.Errorf("labels = %v, want only app", m.Labels)
+	}
+	if m.Annotations[v1alpha1.MachineRestoredFromAnnotation] != "snap" {
+		t.Errorf("annotations = %v, want restored from snap", m.Annotations)
+	}
+	if n := m.Spec.Network; n.PrivateIP != "" || n.PublicIP != "" || !n.AssignPublicIP {
+		t.Errorf("network = %+v, want the static addresses cleared", n)
+	}
+	if len(m.OwnerReferences) != 0 {
+		t.Errorf("owner references = %v, want none", m.OwnerReferences)
+	}
+	if source.Spec.Network.PrivateIP != "10.0.0.5" || source.Spec.Name != "web" {
+		t.Error("building the machine changed the source")
+	}
+
+	inPlace := newRestore("", "")
+	if _, err := NewMachine(inPlace, &snap, source); err == nil || !strings.Contains(err.Error(), "restores in place") {
+		t.Errorf("in place restore: %v", err)
+	}
+	other := &v1alpha1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}}
+	if _, err := NewMachine(newRestore(v1alpha1.MachineRestoreModeNewMachine, "web-copy"), &snap, other); err == nil || !strings.Contains(err.Error(), "was not given") {
+		t.Errorf("wrong source machine: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+// Package snapshot applies machine backup retention to MachineSnapshots and
+// checks MachineRestores against the snapshot they restore.
+package snapshot
+
+import (
+	"sort"
+	"time"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+// PruneResult lists the snapshots of a machine that are past retention.
+type PruneResult struct {
+	// Delete are the snapshots to delete, oldest first.
+	Delete []*v1alpha1.MachineSnapshot
+	// RecheckAfter is the time until the next snapshot expires, or zero when
+	// no snapshot is due to expire.
+	RecheckAfter time.Duration
+}
+
+// TakenAt returns the time the snapshot was taken, falling back to the
+// creation time of the object while the provider has not reported one.
+func TakenAt(s *v1alpha1.MachineSnapshot) time.Time {
+	if s.Status.CreationTime != nil {
+		return s.Status.CreationTime.Time
+	}
+	return s.CreationTimestamp.Time
+}
+
+// ExpiresAt returns the time a snapshot falls out of a retention of the given
+// number of days, or the zero time when retentionDays is not positive.
+func ExpiresAt(s *v1alpha1.MachineSnapshot, retentionDays int) time.Time {
+	if retentionDays <= 0 {
+		return time.Time{}
+	}
+	return TakenAt(s).AddDate(0, 0, retentionDays)
+}
+
+// Prune applies spec.backup.retentionDays of the machine to its snapshots.
+//
+// Only snapshots of the machine are considered. Nothing is pruned when the
+// retention is not set. A snapshot is kept when it has the Retain policy, is
+// still being taken, is used by a restore that has not finished, or is the
+// newest snapshot that is ready to use, so a machine never loses its last
+// restore point.
+func Prune(machine *v1alpha1.Machine, snapshots []v1alpha1.MachineSnapshot, restores []v1alpha1.MachineRestore, now time.Time) *PruneResult {
+	result := &PruneResult{}
+	retentionDays := machine.Spec.Backup.RetentionDays
+	if retentionDays <= 0 {
+		return result
+	}
+
+	inUse := map[string]bool{}
+	for i := range restores {
+		r := &restores[i]
+		if r.Namespace == machine.Namespace && !isRestoreFinished(r) {
+			inUse[r.Spec.SnapshotRef.Name] = true
+		}
+	}
+
+	var owned []*v1alpha1.MachineSnapshot
+	for i := range snapshots {
+		s := &snapshots[i]
+		if s.Namespace == machine.Namespace && s.Spec.MachineRef.Name == machine.Name && s.DeletionTimestamp == nil {
+			owned = append(owned, s)
+		}
+	}
+	sort.SliceStable(owned, func(i, j int) bool {
+		ti, tj := TakenAt(owned[i]), TakenAt(owned[j])
+		if ti.Equal(tj) {
+			return owned[i].Name < owned[j].Name
+		}
+		return ti.Before(tj)
+	})
+
+	var lastRestorePoint *v1alpha1.MachineSnapshot
+	for _, s := range owned {
+		if s.Status.ReadyToUse {
+			lastRestorePoint = s
+		}
+	}
+
+	for _, s := range owned {
+		if s == lastRestorePoint || inUse[s.Name] || isInProgress(s) ||
+			s.Spec.RetentionPolicy == v1alpha1.MachineSnapshotRetentionRetain {
+			continue
+		}
+		remaining := ExpiresAt(s, retentionDays).Sub(now)
+		if remaining <= 0 {
+			result.Delete = append(result.Delete, s)
+			continue
+		}
+		if result.RecheckAfter == 0 || remaining < result.RecheckAfter {
+			result.RecheckAfter = remaining
+		}
+	}
+	return result
+}
+
+func isInProgress(s *v1alpha1.MachineSnapshot) bool {
+	switch s.Status.Phase {
+	case v1alpha1.MachineSnapshotPhaseReady, v1alpha1.MachineSnapshotPhaseFailed:
+		return false
+	}
+	return !s.Status.ReadyToUse
+}
+
+func isRestoreFinished(r *v1alpha1.MachineRestore) bool {
+	return r.Status.Phase == v1alpha1.MachineRestorePhaseCompleted || r.Status.Phase == v1alpha1.MachineRestorePhaseFailed
+}
@@ -0,0 +1,137 @@
+package snapshot
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var now = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+func newMachine(retentionDays int) *v1alpha1.Machine {
+	return &v1alpha1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1alpha1.MachineSpec{Backup: v1alpha1.MachineBackup{Enabled: true, RetentionDays: retentionDays}},
+	}
+}
+
+// snapshot returns a ready snapshot of the machine web taken days ago.
+func snapshot(name string, days int) v1alpha1.MachineSnapshot {
+	return v1alpha1.MachineSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(now.AddDate(0, 0, -days))},
+		Spec:       v1alpha1.MachineSnapshotSpec{MachineRef: v1alpha1.MachineReference{Name: "web"}},
+		Status:     v1alpha1.MachineSnapshotStatus{Phase: v1alpha1.MachineSnapshotPhaseReady, ReadyToUse: true},
+	}
+}
+
+func withPhase(s v1alpha1.MachineSnapshot, phase string) v1alpha1.MachineSnapshot {
+	s.Status.Phase, s.Status.ReadyToUse = phase, false
+	return s
+}
+
+func restore(namespace, snapshot, phase string) v1alpha1.MachineRestore {
+	return v1alpha1.MachineRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "restore-" + snapshot, Namespace: namespace},
+		Spec:       v1alpha1.MachineRestoreSpec{SnapshotRef: v1alpha1.MachineSnapshotReference{Name: snapshot}},
+		Status:     v1alpha1.MachineRestoreStatus{Phase: phase},
+	}
+}
+
+func TestPrune(t *testing.T) {
+	retained := snapshot("retained", 10)
+	retained.Spec.RetentionPolicy = v1alpha1.MachineSnapshotRetentionRetain
+	otherMachine := snapshot("other-machine", 10)
+	otherMachine.Spec.MachineRef.Name = "db"
+	otherNamespace := snapshot("other-namespace", 10)
+	otherNamespace.Namespace = "tenant"
+	deleting := snapshot("deleting", 10)
+	deleting.DeletionTimestamp = &metav1.Time{Time: now}
+	taken := snapshot("taken", 1)
+	taken.Status.CreationTime = &metav1.Time{Time: now.AddDate(0, 0, -10)}
+
+	tests := []struct {
+		name          string
+		retentionDays int
+		snapshots     []v1alpha1.MachineSnapshot
+		restores      []v1alpha1.MachineRestore
+		want          []string
+		wantRecheck   time.Duration
+	}{
+		{
+			name:      "no retention",
+			snapshots: []v1alpha1.MachineSnapshot{snapshot("old", 100), snapshot("new", 1)},
+		},
+		{
+			name:          "expired, oldest first",
+			retentionDays: 7,
+			snapshots:     []v1alpha1.MachineSnapshot{snapshot("b", 8), snapshot("new", 1), snapshot("a", 10), snapshot("mid", 3)},
+			want:          []string{"a", "b"},
+			// The newest snapshot never expires, mid expires first.
+			wantRecheck: 4 * 24 * time.Hour,
+		},
+		{
+			name:          "keeps the last restore point",
+			retentionDays: 7,
+			snapshots:     []v1alpha1.MachineSnapshot{snapshot("last", 10), withPhase(snapshot("failed", 9), v1alpha1.MachineSnapshotPhaseFailed)},
+			want:          []string{"failed"},
+		},
+		{
+			name:          "in progress",
+			retentionDays: 7,
+			snapshots: []v1alpha1.MachineSnapshot{
+				snapshot("a", 10),
+				withPhase(snapshot("pending", 9), v1alpha1.MachineSnapshotPhasePending),
+				withPhase(snapshot("creating", 9), v1alpha1.MachineSnapshotPhaseCreating),
+				snapshot("last", 8),
+			},
+			want: []string{"a"},
+		},
+		{
+			name:          "retain policy",
+			retentionDays: 7,
+			snapshots:     []v1alpha1.MachineSnapshot{retained, snapshot("new", 1)},
+		},
+		{
+			name:          "used by a restore",
+			retentionDays: 7,
+			snapshots:     []v1alpha1.MachineSnapshot{snapshot("a", 12), snapshot("b", 11), snapshot("c", 10), snapshot("d", 9), snapshot("new", 1)},
+			restores: []v1alpha1.MachineRestore{
+				restore("default", "a", v1alpha1.MachineRestorePhaseRestoring),
+				restore("default", "b", v1alpha1.MachineRestorePhasePending),
+				restore("default", "c", v1alpha1.MachineRestorePhaseCompleted),
+				restore("tenant", "d", v1alpha1.MachineRestorePhaseRestoring),
+			},
+			want: []string{"c", "d"},
+		},
+		{
+			name:          "other machines and deleted snapshots",
+			retentionDays: 7,
+			snapshots:     []v1alpha1.MachineSnapshot{otherMachine, otherNamespace, deleting, snapshot("new", 1)},
+		},
+		{
+			name:          "taken at the time the provider reports",
+			retentionDays: 7,
+			snapshots:     []v1alpha1.MachineSnapshot{taken, snapshot("mid", 2), snapshot("new", 1)},
+			want:          []string{"taken"},
+			wantRecheck:   5 * 24 * time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Prune(newMachine(tt.retentionDays), tt.snapshots, tt.restores, now)
+			var got []string
+			for _, s := range res.Delete {
+				got = append(got, s.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("delete %q, want %q", got, tt.want)
+			}
+			if res.RecheckAfter != tt.wantRecheck {
+				t.Errorf("recheck after %v, want %v", res.RecheckAfter, tt.wantRecheck)
+			}
+		})
+	}
+}
@@ -144,3 +144,171 @@ func LoadBalancerFromUnstructured(u *metav1unstructured.Unstructured) (*v1alpha1
 	}
 	return out, nil
 }
+
+func MachineSetToUnstructured(in *v1alpha1.MachineSet) (*metav1unstructured.Unstructured, error) {
+	return ToUnstructured(in)
+}
+
+func MachineSetFromUnstructured(u *metav1unstructured.Unstructured) (*v1alpha1.MachineSet, error) {
+	out := new(v1alpha1.MachineSet)
+	if err := FromUnstructured(u, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func MachineDeploymentToUnstructured(in *v1alpha1.MachineDeployment) (*metav1unstructured.Unstructured, error) {
+	return ToUnstructured(in)
+}
+
+func MachineDeploymentFromUnstructured(u *metav1unstructured.Unstructured) (*v1alpha1.MachineDeployment, error) {
+	out := new(v1alpha1.MachineDeployment)
+	if err := FromUnstructured(u, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func MachineClassToUnstructured(in *v1alpha1.MachineClass) (*metav1unstructured.Unstructured, error) {
+	return ToUnstructured(in)
+}
+
+func MachineClassFromUnstructured(u *metav1unstructured.Unstructured) (*v1alpha1.MachineClass, error) {
+	out := new(v1alpha1.MachineClass)
+	if err := FromUnstructured(u, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func MachineTemplateToUnstructured(in *v1alpha1.MachineTemplate) (*metav1unstructured.Unstructured, error) {
+	return ToUnstructured(in)
+}
+
+func MachineTemplateFromUnstructured(u *metav1unstructured.Unstructured) (*v1alpha1.MachineTemplate, error) {
+	out := new(v1alpha1.MachineTemplate)
+	if err := FromUnstructured(u, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func MachineHealthCheckToUnstructured(in *v1alpha1.MachineHealthCheck) (*metav1unstructured.Unstructured, error) {
+	return ToUnstructured(in)
+}
+
+func MachineHealthCheckFromUnstructured(u *metav1unstructured.Unstructured) (*v1alpha1.MachineHealthCheck, error) {
+	out := new(v1alpha1.MachineHealthCheck)
+	if err := FromUnstructured(u, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func MachineSnapshotToUnstructured(in *v1alpha1.MachineSnapshot) (*metav1unstructured.Unstructured, error) {
+	return ToUnstructured(in)
+}
+
+func MachineSnapshotFromUnstructured(u *metav1unstructured.Unstructured) (*v1alpha1.MachineSnapshot, error) {
+	out := new(v1alpha1.MachineSnapshot)
+	if err := FromUnstructured(u, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func MachineRestoreToUnstructured(in *v1alpha1.MachineRestore) (*metav1unstructured.Unstructured, error) {
+	return ToUnstructured(in)
+}
+
+func MachineRestoreFromUnstructured(u *metav1unstructured.Unstructured) (*v1alpha1.MachineRestore, error) {
+	out := new(v1alpha1.MachineRestore)
+	if err := FromUnstructured(u, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func MachineMigrationToUnstructured(in *v1alpha1.MachineMigration) (*metav1unstructured.Unstructured, error) {
+	return ToUnstructured(in)
+}
+
+func MachineMigrationFromUnstructured(u *metav1unstructured.Unstructured) (*v1alpha1.MachineMigration, error) {
+	out := new(v1alpha1.MachineMigration)
+	if err := FromUnstructured(u, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func IPPoolToUnstructured(in *v1alpha1.IPPool) (*metav1unstructured.Unstructured, error) {
+	return ToUnstructured(in)
+}
+
+func IPPoolFromUnstructured(u *metav1unstructured.Unstructured) (*v1alpha1.IPPool, error) {
+	out := new(v1alpha1.IPPool)
+	if err := FromUnstructured(u, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func IPAddressClaimToUnstructured(in *v1alpha1.IPAddressClaim) (*metav1unstructured.Unstructured, error) {
+	return ToUnstructured(in)
+}
+
+func IPAddressClaimFromUnstructured(u *metav1unstructured.Unstructured) (*v1alpha1.IPAddressClaim, error) {
+	out := new(v1alpha1.IPAddressClaim)
+	if err := FromUnstructured(u, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func IPAddressToUnstructured(in *v1alpha1.IPAddress) (*metav1unstructured.Unstructured, error) {
+	return ToUnstructured(in)
+}
+
+func IPAddressFromUnstructured(u *metav1unstructured.Unstructured) (*v1alpha1.IPAddress, error) {
+	out := new(v1alpha1.IPAddress)
+	if err := FromUnstructured(u, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func VIPPoolToUnstructured(in *v1alpha1.VIPPool) (*metav1unstructured.Unstructured, error) {
+	return ToUnstructured(in)
+}
+
+func VIPPoolFromUnstructured(u *metav1unstructured.Unstructured) (*v1alpha1.VIPPool, error) {
+	out := new(v1alpha1.VIPPool)
+	if err := FromUnstructured(u, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func NetworkNamespacePoolToUnstructured(in *v1alpha1.NetworkNamespacePool) (*metav1unstructured.Unstructured, error) {
+	return ToUnstructured(in)
+}
+
+func NetworkNamespacePoolFromUnstructured(u *metav1unstructured.Unstructured) (*v1alpha1.NetworkNamespacePool, error) {
+	out := new(v1alpha1.NetworkNamespacePool)
+	if err := FromUnstructured(u, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func DNSRecordToUnstructured(in *v1alpha1.DNSRecord) (*metav1unstructured.Unstructured, error) {
+	return ToUnstructured(in)
+}
+
+func DNSRecordFromUnstructured(u *metav1unstructured.Unstructured) (*v1alpha1.DNSRecord, error) {
+	out := new(v1alpha1.DNSRecord)
+	if err := FromUnstructured(u, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
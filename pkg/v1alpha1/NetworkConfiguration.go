@@ -48,19 +48,19 @@ type NetworkConfigurationSpec struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:validation:MinLength=2
 	// +kubebuilder:validation:MaxLength=32
-	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_-]+$`
+	// +kubebuilder:validation:Pattern=`^[a-z]{2}-[a-z0-9]+-[a-z0-9]+$`
 	DatacenterIdentifier string `json:"datacenterIdentifier,omitempty"`
 
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:validation:MinLength=2
 	// +kubebuilder:validation:MaxLength=32
-	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_-]+$`
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`
 	SupervisorIdentifier string `json:"supervisorIdentifier,omitempty"`
 
 	// +kubebuilder:validation:Optional
-	// +kubebuilder:validation:MinLength=2
+	// +kubebuilder:validation:MinLength=3
 	// +kubebuilder:validation:MaxLength=32
-	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_-]+$`
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`
 	ClusterIdentifier string `json:"clusterIdentifier,omitempty"`
 
 	// +kubebuilder:validation:Optional
@@ -70,6 +70,22 @@ type NetworkConfigurationSpec struct {
 	Provider string `json:"provider,omitempty"`
 
 	NetworkInterfaces []NetworkConfigurationInterface `json:"networkInterfaces,omitempty"`
+
+	// Bonds of network interfaces
+	// +kubebuilder:validation:Optional
+	Bonds []NetworkConfigurationBond `json:"bonds,omitempty"`
+
+	// Bridges of network interfaces, bonds or VLANs
+	// +kubebuilder:validation:Optional
+	Bridges []NetworkConfigurationBridge `json:"bridges,omitempty"`
+
+	// Tagged VLAN sub-interfaces
+	// +kubebuilder:validation:Optional
+	VLANs []NetworkConfigurationVLAN `json:"vlans,omitempty"`
+
+	// Static routes
+	// +kubebuilder:validation:Optional
+	Routes []NetworkConfigurationRoute `json:"routes,omitempty"`
 }
 
 type NetworkConfigurationStatus struct {
@@ -79,6 +95,21 @@ type NetworkConfigurationStatus struct {
 	Message           string                          `json:"message,omitempty"`
 	Created           metav1.Time                     `json:"created,omitempty"`
 	NetworkInterfaces []NetworkConfigurationInterface `json:"networkInterfaces,omitempty"`
+
+	// Bonds as applied on the host
+	Bonds []NetworkConfigurationBond `json:"bonds,omitempty"`
+
+	// Bridges as applied on the host
+	Bridges []NetworkConfigurationBridge `json:"bridges,omitempty"`
+
+	// VLAN sub-interfaces as applied on the host
+	VLANs []NetworkConfigurationVLAN `json:"vlans,omitempty"`
+
+	// Static routes as applied on the host
+	Routes []NetworkConfigurationRoute `json:"routes,omitempty"`
+
+	// Generation of the NetworkConfiguration most recently applied
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
 type NetworkConfigurationInterface struct {
@@ -104,8 +135,155 @@ type NetworkConfigurationInterface struct {
 	DNS []string `json:"dns,omitempty"`
 	// DHCP reserved in dchp server(s)
 	DHCPReserved bool `json:"dhcpReserved,omitempty"`
+	// MTU, 1500 when unset
+	// +kubebuilder:validation:Minimum=68
+	// +kubebuilder:validation:Maximum=65535
+	MTU int32 `json:"mtu,omitempty"`
+}
+
+// NetworkConfigurationAddressing is the layer 3 configuration of a bond,
+// bridge or VLAN. Addresses are given with their prefix length, e.g.
+// 10.0.0.5/24, or without it when the matching subnet is set.
+type NetworkConfigurationAddressing struct {
+	// IPv4 addresses
+	IPv4Addresses []string `json:"ipv4Addresses,omitempty"`
+	// IPv6 addresses
+	IPv6Addresses []string `json:"ipv6Addresses,omitempty"`
+	// Subnet
+	IPv4Subnet string `json:"ipv4Subnet,omitempty"`
+	// Subnet for ipv6
+	IPv6Subnet string `json:"ipv6Subnet,omitempty"`
+	// Gateway
+	IPv4Gateway string `json:"ipv4Gateway,omitempty"`
+	// Gateway for ipv6
+	IPv6Gateway string `json:"ipv6Gateway,omitempty"`
+	// DNS
+	DNS []string `json:"dns,omitempty"`
+	// Address reserved in the DHCP server(s) and obtained by DHCP
+	DHCPReserved bool `json:"dhcpReserved,omitempty"`
+	// MTU, 1500 when unset
+	// +kubebuilder:validation:Minimum=68
+	// +kubebuilder:validation:Maximum=65535
+	MTU int32 `json:"mtu,omitempty"`
+}
+
+// NetworkConfigurationBond aggregates network interfaces
+type NetworkConfigurationBond struct {
+	// Name of the bond device, e.g. bond0
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=15
+	Name string `json:"name"`
+
+	// Names of the network interfaces in the bond
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Interfaces []string `json:"interfaces"`
+
+	// Bonding mode, 802.3ad is LACP
+	// +kubebuilder:validation:Enum=balance-rr;active-backup;balance-xor;broadcast;"802.3ad";balance-tlb;balance-alb
+	// +kubebuilder:default="802.3ad"
+	Mode string `json:"mode,omitempty"`
+
+	// LACP rate (slow, fast), only for 802.3ad
+	// +kubebuilder:validation:Enum=slow;fast
+	LACPRate string `json:"lacpRate,omitempty"`
+
+	// Transmit hash policy, only for 802.3ad and balance-xor
+	// +kubebuilder:validation:Enum=layer2;layer2+3;layer3+4;encap2+3;encap3+4
+	TransmitHashPolicy string `json:"transmitHashPolicy,omitempty"`
+
+	// Link monitoring interval in milliseconds (default 100)
+	// +kubebuilder:validation:Minimum=0
+	MIIMonitorInterval int32 `json:"miiMonitorInterval,omitempty"`
+
+	// Preferred interface, only for active-backup, balance-tlb and balance-alb
+	Primary string `json:"primary,omitempty"`
+
+	NetworkConfigurationAddressing `json:",inline"`
+}
+
+// NetworkConfigurationBridge is a software switch
+type NetworkConfigurationBridge struct {
+	// Name of the bridge device, e.g. br0
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=15
+	Name string `json:"name"`
+
+	// Names of the network interfaces, bonds or VLANs in the bridge, may be empty
+	Interfaces []string `json:"interfaces,omitempty"`
+
+	// Whether the spanning tree protocol is enabled
+	STP bool `json:"stp,omitempty"`
+
+	// Forward delay in seconds when STP is enabled
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Maximum=30
+	ForwardDelay int32 `json:"forwardDelay,omitempty"`
+
+	NetworkConfigurationAddressing `json:",inline"`
 }
 
+// NetworkConfigurationVLAN is a tagged VLAN sub-interface
+type NetworkConfigurationVLAN struct {
+	// Name of the VLAN device, e.g. bond0.100
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=15
+	Name string `json:"name"`
+
+	// VLAN ID
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4094
+	ID int32 `json:"id"`
+
+	// Name of the parent network interface, bond or bridge
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Link string `json:"link"`
+
+	NetworkConfigurationAddressing `json:",inline"`
+}
+
+// NetworkConfigurationRoute is a static route
+type NetworkConfigurationRoute struct {
+	// Destination CIDR, or "default" for 0.0.0.0/0, or ::/0 with an IPv6 via
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	To string `json:"to"`
+
+	// Gateway, on-link when unset
+	Via string `json:"via,omitempty"`
+
+	// Device the route uses, required without via
+	Interface string `json:"interface,omitempty"`
+
+	// Route metric
+	// +kubebuilder:validation:Minimum=0
+	Metric int32 `json:"metric,omitempty"`
+
+	// Routing table, the main table when unset
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=4294967295
+	Table int64 `json:"table,omitempty"`
+
+	// Whether the gateway is reachable on the device without a matching subnet
+	OnLink bool `json:"onLink,omitempty"`
+}
+
+// NetworkConfiguration bond modes
+const (
+	BondModeBalanceRR    = "balance-rr"
+	BondModeActiveBackup = "active-backup"
+	BondModeBalanceXOR   = "balance-xor"
+	BondModeBroadcast    = "broadcast"
+	BondModeLACP         = "802.3ad"
+	BondModeBalanceTLB   = "balance-tlb"
+	BondModeBalanceALB   = "balance-alb"
+)
+
 func init() {
 	SchemeBuilder.Register(&NetworkConfiguration{}, &NetworkConfigurationList{})
 }
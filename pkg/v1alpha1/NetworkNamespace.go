@@ -4,6 +4,13 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// Common NetworkNamespace condition types
+const (
+	// NetworkNamespaceConditionAllocated is True when the VLAN ID and egress
+	// IPs in the status are allocated from a NetworkNamespacePool
+	NetworkNamespaceConditionAllocated = "Allocated"
+)
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -36,19 +43,19 @@ type NetworkNamespaceSpec struct {
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MinLength=2
 	// +kubebuilder:validation:MaxLength=32
-	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_-]+$`
+	// +kubebuilder:validation:Pattern=`^[a-z]{2}-[a-z0-9]+-[a-z0-9]+$`
 	DatacenterIdentifier string `json:"datacenterIdentifier,omitempty"` // <country>-<region>-<availability zone> ex: no-west-az1
 
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MinLength=2
 	// +kubebuilder:validation:MaxLength=32
-	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_-]+$`
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`
 	SupervisorIdentifier string `json:"supervisorIdentifier,omitempty"` // <unique name per datacenter> ex: my-namespace
 
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MinLength=3
 	// +kubebuilder:validation:MaxLength=32
-	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_-]+$`
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`
 	ClusterIdentifier string `json:"clusterIdentifier,omitempty"` // <unique name per availability zone> ex: my-name
 
 	// +kubebuilder:validation:Required
@@ -77,9 +84,15 @@ type NetworkNamespaceStatus struct {
 	ClusterIdentifier    string `json:"clusterIdentifier,omitempty"`
 	IPv4Prefix           string `json:"ipv4Prefix,omitempty"`
 	IPv6Prefix           string `json:"ipv6Prefix,omitempty"`
-	IPv4EgressIP         string `json:"ipv4EgressIp,omitempty"`
-	IPv6EgressIP         string `json:"ipv6EgressIp,omitempty"`
-	VlanID               int    `json:"vlanId,omitempty"`
+
+	// IPv4 egress IP, allocated from the NetworkNamespacePool of the datacenter
+	IPv4EgressIP string `json:"ipv4EgressIp,omitempty"`
+	// IPv6 egress IP, allocated from the NetworkNamespacePool of the datacenter
+	IPv6EgressIP string `json:"ipv6EgressIp,omitempty"`
+	// VLAN ID, allocated from the NetworkNamespacePool of the datacenter
+	VlanID int `json:"vlanId,omitempty"`
+	// NetworkNamespacePool the VLAN ID and egress IPs are allocated from
+	Pool string `json:"pool,omitempty"`
 
 	AssociatedKubernetesClusterIDs []string `json:"associatedKubernetesClusterIds,omitempty"`
 }
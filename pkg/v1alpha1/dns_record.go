@@ -0,0 +1,137 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DNS record types
+const (
+	DNSRecordTypeA     = "A"
+	DNSRecordTypeAAAA  = "AAAA"
+	DNSRecordTypeCNAME = "CNAME"
+	DNSRecordTypePTR   = "PTR"
+)
+
+// Common DNSRecord phases
+const (
+	DNSRecordPhasePending   = "Pending"
+	DNSRecordPhasePublished = "Published"
+	DNSRecordPhaseFailed    = "Failed"
+)
+
+// Common DNSRecord condition types
+const (
+	DNSRecordConditionPublished = "Published"
+)
+
+// DNSRecordSourceLabel is set on generated DNSRecords to the kind of the
+// object they are generated from, e.g. "Machine".
+const DNSRecordSourceLabel = "vitistack.io/dns-source"
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DNSRecord is the Schema for the DNSRecords API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=dnsrecords,scope=Cluster,shortName=dnsr
+// +kubebuilder:printcolumn:name="Zone",type=string,JSONPath=`.spec.zone`
+// +kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+// +kubebuilder:printcolumn:name="Values",type=string,JSONPath=`.spec.values`
+// +kubebuilder:printcolumn:name="TTL",type=integer,JSONPath=`.spec.ttl`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type DNSRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DNSRecordSpec   `json:"spec,omitempty"`
+	Status DNSRecordStatus `json:"status,omitempty"`
+}
+
+// DNSRecordSpec defines one resource record set: every value of one type at
+// one name
+type DNSRecordSpec struct {
+	// Zone the record belongs to, e.g. example.com or 1.0.10.in-addr.arpa
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^([a-z0-9_]([a-z0-9_-]*[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]*[a-z0-9])?\.?$`
+	Zone string `json:"zone"`
+
+	// Name of the record relative to the zone, "@" for the zone apex
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^(@|([a-z0-9_*]([a-z0-9_-]*[a-z0-9])?)(\.[a-z0-9_]([a-z0-9_-]*[a-z0-9])?)*)$`
+	Name string `json:"name"`
+
+	// Type of the record (A, AAAA, CNAME, PTR)
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=A;AAAA;CNAME;PTR
+	Type string `json:"type"`
+
+	// Values of the record: addresses for A and AAAA, a single absolute domain
+	// name for CNAME and PTR
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	// +listType=set
+	Values []string `json:"values"`
+
+	// Time to live in seconds
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=2147483647
+	// +kubebuilder:default=300
+	TTL int32 `json:"ttl,omitempty"`
+
+	// Object the record is generated from, empty for records managed by hand
+	// +kubebuilder:validation:Optional
+	Source *DNSRecordSource `json:"source,omitempty"`
+}
+
+// DNSRecordSource references the object a DNSRecord is generated from
+type DNSRecordSource struct {
+	// Kind of the object (Machine, LoadBalancer, KubernetesProvider)
+	// +kubebuilder:validation:Required
+	Kind string `json:"kind"`
+
+	// Namespace of the object, empty for cluster-scoped objects
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the object
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
+// DNSRecordStatus defines the observed state of DNSRecord
+type DNSRecordStatus struct {
+	// Current phase of the record (Pending, Published, Failed)
+	Phase string `json:"phase,omitempty"`
+
+	// Human-readable status message
+	Message string `json:"message,omitempty"`
+
+	// Fully qualified name of the record
+	FQDN string `json:"fqdn,omitempty"`
+
+	// Serial of the zone that last published the record
+	Serial uint32 `json:"serial,omitempty"`
+
+	// Generation of the DNSRecord most recently published
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// DNSRecordList contains a list of DNSRecord
+type DNSRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DNSRecord `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DNSRecord{}, &DNSRecordList{})
+}
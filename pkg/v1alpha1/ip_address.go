@@ -0,0 +1,72 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IPPoolNameLabel is set on IPAddresses with the name of the pool they were allocated from
+const IPPoolNameLabel = "vitistack.io/ip-pool"
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// IPAddress is the Schema for the IPAddresses API. An IPAddress is an
+// allocation from an IPPool. Its name is derived from the pool and the
+// address, so the API server rejects a second allocation of the same address.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=ipaddresses,scope=Namespaced,shortName=ipa
+// +kubebuilder:printcolumn:name="Address",type=string,JSONPath=`.spec.address`
+// +kubebuilder:printcolumn:name="Prefix",type=integer,JSONPath=`.spec.prefix`
+// +kubebuilder:printcolumn:name="Pool",type=string,JSONPath=`.spec.poolRef.name`
+// +kubebuilder:printcolumn:name="Claim",type=string,JSONPath=`.spec.claimRef.name`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type IPAddress struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec IPAddressSpec `json:"spec,omitempty"`
+}
+
+// IPAddressSpec describes an allocated address
+type IPAddressSpec struct {
+	// Allocated address
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=2
+	Address string `json:"address"`
+
+	// Prefix length of the network the address belongs to
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=128
+	Prefix int32 `json:"prefix"`
+
+	// Gateway of the network, if any
+	Gateway string `json:"gateway,omitempty"`
+
+	// Pool the address was allocated from
+	// +kubebuilder:validation:Required
+	PoolRef IPPoolReference `json:"poolRef"`
+
+	// Claim the address was allocated for
+	// +kubebuilder:validation:Required
+	ClaimRef IPAddressClaimReference `json:"claimRef"`
+}
+
+// IPAddressReference references an IPAddress in the same namespace
+type IPAddressReference struct {
+	// Name of the IPAddress
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// IPAddressList contains a list of IPAddress
+type IPAddressList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IPAddress `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IPAddress{}, &IPAddressList{})
+}
@@ -0,0 +1,93 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Common IPAddressClaim phases
+const (
+	IPAddressClaimPhasePending = "Pending"
+	IPAddressClaimPhaseBound   = "Bound"
+	IPAddressClaimPhaseFailed  = "Failed"
+)
+
+// Common IPAddressClaim condition types
+const (
+	IPAddressClaimConditionBound = "Bound"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// IPAddressClaim is the Schema for the IPAddressClaims API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=ipaddressclaims,scope=Namespaced,shortName=ipc
+// +kubebuilder:printcolumn:name="Pool",type=string,JSONPath=`.spec.poolRef.name`
+// +kubebuilder:printcolumn:name="Family",type=string,JSONPath=`.spec.family`
+// +kubebuilder:printcolumn:name="Address",type=string,JSONPath=`.status.address`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type IPAddressClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IPAddressClaimSpec   `json:"spec,omitempty"`
+	Status IPAddressClaimStatus `json:"status,omitempty"`
+}
+
+// IPAddressClaimSpec defines the desired state of IPAddressClaim
+type IPAddressClaimSpec struct {
+	// Pool to allocate from
+	// +kubebuilder:validation:Required
+	PoolRef IPPoolReference `json:"poolRef"`
+
+	// Address family to allocate (IPv4, IPv6)
+	// +kubebuilder:validation:Enum=IPv4;IPv6
+	// +kubebuilder:default=IPv4
+	Family string `json:"family,omitempty"`
+
+	// Specific address to allocate, the next free address when unset
+	RequestedAddress string `json:"requestedAddress,omitempty"`
+}
+
+// IPAddressClaimStatus defines the observed state of IPAddressClaim
+type IPAddressClaimStatus struct {
+	// Current phase of the claim (Pending, Bound, Failed)
+	Phase string `json:"phase,omitempty"`
+
+	// Human-readable status message
+	Message string `json:"message,omitempty"`
+
+	// IPAddress object holding the allocation
+	AddressRef *IPAddressReference `json:"addressRef,omitempty"`
+
+	// Allocated address
+	Address string `json:"address,omitempty"`
+
+	// Generation of the IPAddressClaim most recently observed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// IPAddressClaimReference references an IPAddressClaim in the same namespace
+type IPAddressClaimReference struct {
+	// Name of the IPAddressClaim
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// IPAddressClaimList contains a list of IPAddressClaim
+type IPAddressClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IPAddressClaim `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IPAddressClaim{}, &IPAddressClaimList{})
+}
@@ -0,0 +1,144 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IP address families
+const (
+	IPFamilyIPv4 = "IPv4"
+	IPFamilyIPv6 = "IPv6"
+)
+
+// Common IPPool phases
+const (
+	IPPoolPhasePending   = "Pending"
+	IPPoolPhaseReady     = "Ready"
+	IPPoolPhaseExhausted = "Exhausted"
+	IPPoolPhaseFailed    = "Failed"
+)
+
+// Common IPPool condition types
+const (
+	IPPoolConditionReady = "Ready"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// IPPool is the Schema for the IPPools API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=ippools,scope=Namespaced,shortName=ipp
+// +kubebuilder:printcolumn:name="CIDRs",type=string,JSONPath=`.status.cidrs`
+// +kubebuilder:printcolumn:name="IPv4 Free",type=integer,JSONPath=`.status.ipv4.free`
+// +kubebuilder:printcolumn:name="IPv6 Free",type=integer,JSONPath=`.status.ipv6.free`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type IPPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IPPoolSpec   `json:"spec,omitempty"`
+	Status IPPoolStatus `json:"status,omitempty"`
+}
+
+// IPPoolSpec defines the address space of an IPPool. Either cidrs or
+// networkNamespaceRef must be set.
+type IPPoolSpec struct {
+	// IPv4 and IPv6 CIDRs to allocate from (e.g., 10.0.0.0/24, 2001:db8::/64)
+	CIDRs []string `json:"cidrs,omitempty"`
+
+	// NetworkNamespace whose status.ipv4Prefix and status.ipv6Prefix are used when cidrs is empty
+	NetworkNamespaceRef *NetworkNamespaceReference `json:"networkNamespaceRef,omitempty"`
+
+	// Addresses that are never allocated, as CIDRs, ranges (10.0.0.1-10.0.0.9) or single addresses
+	ExcludedRanges []string `json:"excludedRanges,omitempty"`
+
+	// IPv4 gateway handed out with IPv4 addresses, never allocated
+	IPv4Gateway string `json:"ipv4Gateway,omitempty"`
+
+	// IPv6 gateway handed out with IPv6 addresses, never allocated
+	IPv6Gateway string `json:"ipv6Gateway,omitempty"`
+
+	// Addresses held back for a specific claim or for infrastructure
+	Reservations []IPPoolReservation `json:"reservations,omitempty"`
+}
+
+// IPPoolReservation holds an address of the pool back
+type IPPoolReservation struct {
+	// Reserved address
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=2
+	Address string `json:"address"`
+
+	// Name of the IPAddressClaim that gets this address, reserved for infrastructure when empty
+	ClaimName string `json:"claimName,omitempty"`
+
+	// Why the address is reserved
+	Description string `json:"description,omitempty"`
+}
+
+// IPPoolStatus defines the observed state of IPPool
+type IPPoolStatus struct {
+	// Current phase of the pool (Pending, Ready, Exhausted, Failed)
+	Phase string `json:"phase,omitempty"`
+
+	// Human-readable status message
+	Message string `json:"message,omitempty"`
+
+	// CIDRs the pool allocates from, after resolving the NetworkNamespace
+	CIDRs []string `json:"cidrs,omitempty"`
+
+	// IPv4 address usage
+	IPv4 *IPPoolUsage `json:"ipv4,omitempty"`
+
+	// IPv6 address usage
+	IPv6 *IPPoolUsage `json:"ipv6,omitempty"`
+
+	// Generation of the IPPool most recently observed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// IPPoolUsage counts the addresses of one family. Counts of large IPv6 pools saturate at the int64 maximum.
+type IPPoolUsage struct {
+	// Allocatable addresses, without excluded, gateway and reserved addresses
+	Total int64 `json:"total"`
+
+	// Allocated addresses
+	Used int64 `json:"used"`
+
+	// Addresses still free
+	Free int64 `json:"free"`
+}
+
+// IPPoolReference references an IPPool in the same namespace
+type IPPoolReference struct {
+	// Name of the IPPool
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// NetworkNamespaceReference references a NetworkNamespace in the same namespace
+type NetworkNamespaceReference struct {
+	// Name of the NetworkNamespace
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// IPPoolList contains a list of IPPool
+type IPPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IPPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IPPool{}, &IPPoolList{})
+}
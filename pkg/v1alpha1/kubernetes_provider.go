@@ -314,13 +314,54 @@ type KubernetesNetworkConfig struct {
 	// CNI plugin (calico, flannel, weave, cilium, antrea)
 	CNIPlugin string `json:"cniPlugin,omitempty"`
 
-	// Pod CIDR
+	// Deprecated: use podCIDRs. Pod CIDR of a single-stack cluster, treated as
+	// the podCIDRs entry of its family.
 	PodCIDR string `json:"podCIDR,omitempty"`
 
-	// Service CIDR
+	// Deprecated: use serviceCIDRs. Service CIDR of a single-stack cluster,
+	// treated as the serviceCIDRs entry of its family.
 	ServiceCIDR string `json:"serviceCIDR,omitempty"`
 
-	// DNS service IP
+	// IP family policy of the cluster. SingleStack uses the first of
+	// ipFamilies, RequireDualStack both, and PreferDualStack both when the
+	// machine provider supports IPv6. Defaults to SingleStack for one family
+	// and RequireDualStack for two.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=SingleStack;PreferDualStack;RequireDualStack
+	IPFamilyPolicy string `json:"ipFamilyPolicy,omitempty"`
+
+	// IP families of the cluster (IPv4, IPv6), primary first. Defaults to the
+	// families of the pod CIDRs, or IPv4. IPv6-only clusters set IPv6 alone.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=2
+	// +kubebuilder:validation:items:Enum=IPv4;IPv6
+	// +kubebuilder:validation:XValidation:rule="self.size() < 2 || self[0] != self[1]",message="an IP family may only be listed once"
+	// +listType=atomic
+	IPFamilies []string `json:"ipFamilies,omitempty"`
+
+	// Pod CIDRs, at most one per family. Empty leaves them to the provider.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=2
+	PodCIDRs []string `json:"podCIDRs,omitempty"`
+
+	// Service CIDRs, at most one per family. Empty leaves them to the provider.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=2
+	ServiceCIDRs []string `json:"serviceCIDRs,omitempty"`
+
+	// Prefix length of the IPv4 pod CIDR of each node, 24 when unset
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=8
+	// +kubebuilder:validation:Maximum=30
+	NodeCIDRMaskSizeIPv4 int32 `json:"nodeCIDRMaskSizeIPv4,omitempty"`
+
+	// Prefix length of the IPv6 pod CIDR of each node, 64 when unset
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=16
+	// +kubebuilder:validation:Maximum=126
+	NodeCIDRMaskSizeIPv6 int32 `json:"nodeCIDRMaskSizeIPv6,omitempty"`
+
+	// DNS service IP, inside the service CIDR of the primary family
 	DNSServiceIP string `json:"dnsServiceIP,omitempty"`
 
 	// Network policy support
@@ -34,14 +34,23 @@ type LoadBalancerList struct {
 	Items           []LoadBalancer `json:"items"`
 }
 
+// +kubebuilder:validation:XValidation:rule="has(self.members) || has(self.poolMembers)",message="at least one of members and poolMembers must be set"
 type LoadBalancerSpec struct {
 	// +kubebuilder:validation:Required
-	DatacenterIdentifier string `json:"datacenterIdentifier,omitempty"`
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:Pattern=`^[a-z]{2}-[a-z0-9]+-[a-z0-9]+$`
+	DatacenterIdentifier string `json:"datacenterIdentifier,omitempty"` // <country>-<region>-<availability zone> ex: no-west-az1
 
 	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=3
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`
 	ClusterIdentifier string `json:"clusterIdentifier,omitempty"`
 
 	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=2
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`
 	SupervisorIdentifier string `json:"supervisorIdentifier,omitempty"`
 
 	// +kubebuilder:validation:Required
@@ -49,14 +58,219 @@ type LoadBalancerSpec struct {
 
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default:=first-alive
-	// +kubebuilder:validation:Enum=round-robin;least-session;first-alive
-	// round-robin, least-session, first-alive
+	// +kubebuilder:validation:Enum=round-robin;least-session;first-alive;weighted-round-robin;source-hash
+	// round-robin, least-session, first-alive, weighted-round-robin, source-hash
 	Method string `json:"method,omitempty"`
 
-	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:MinItems:=1
+	// Deprecated: use members. Addresses as "ip", "ip:port" or "[ipv6]:port",
+	// treated as enabled members with weight 1.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MinItems=1
 	// example control plane ips
 	PoolMembers []string `json:"poolMembers,omitempty"`
+
+	// IP families of the VIPs (IPv4, IPv6), one VIP per family. Defaults to the
+	// families of spec.vips, or IPv4.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=2
+	// +kubebuilder:validation:items:Enum=IPv4;IPv6
+	// +listType=set
+	IPFamilies []string `json:"ipFamilies,omitempty"`
+
+	// Static VIPs to use, at most one per family. Other families get a VIP from the pool.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=2
+	VIPs []string `json:"vips,omitempty"`
+
+	// VIPPool to allocate VIPs from, chosen by datacenter and supervisor when unset
+	// +kubebuilder:validation:Optional
+	VIPPoolRef *VIPPoolReference `json:"vipPoolRef,omitempty"`
+
+	// Pool members traffic is forwarded to. At least one of members and
+	// poolMembers must be set.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MinItems=1
+	Members []LoadBalancerPoolMember `json:"members,omitempty"`
+
+	// Listeners exposed on the load balancer VIPs
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=name
+	Listeners []LoadBalancerListener `json:"listeners,omitempty"`
+
+	// Health check for listeners without their own
+	// +kubebuilder:validation:Optional
+	HealthCheck *LoadBalancerHealthCheck `json:"healthCheck,omitempty"`
+
+	// Timeouts for listeners without their own
+	// +kubebuilder:validation:Optional
+	Timeouts *LoadBalancerTimeouts `json:"timeouts,omitempty"`
+}
+
+// LoadBalancerPoolMember is a backend of the load balancer. Exactly one of
+// address and machineRef must be set.
+// +kubebuilder:validation:XValidation:rule="has(self.address) != has(self.machineRef)",message="exactly one of address and machineRef must be set"
+type LoadBalancerPoolMember struct {
+	// IP address or host name of the member
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MinLength=1
+	Address string `json:"address,omitempty"`
+
+	// Machine in the same namespace, its IP address is used
+	// +kubebuilder:validation:Optional
+	MachineRef *MachineReference `json:"machineRef,omitempty"`
+
+	// Port on the member, overrides the backend port of every listener
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+
+	// Relative share of traffic for weighted methods, 0 takes no new connections (default 1)
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=256
+	Weight *int32 `json:"weight,omitempty"`
+
+	// Only receives traffic when no other member is healthy
+	Backup bool `json:"backup,omitempty"`
+
+	// Enabled, Drain (no new connections) or Disabled (no traffic)
+	// +kubebuilder:validation:Enum=Enabled;Drain;Disabled
+	// +kubebuilder:default=Enabled
+	AdminState string `json:"adminState,omitempty"`
+}
+
+// LoadBalancerListener is a frontend port forwarded to the pool members
+// +kubebuilder:validation:XValidation:rule="!has(self.protocol) || self.protocol != 'UDP' || !has(self.healthCheck) || !has(self.healthCheck.type) || !(self.healthCheck.type in ['HTTP', 'HTTPS'])",message="a UDP listener cannot have an HTTP or HTTPS health check"
+type LoadBalancerListener struct {
+	// Name of the listener, unique within the load balancer
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// Protocol of the listener (TCP, UDP, HTTP, HTTPS)
+	// +kubebuilder:validation:Enum=TCP;UDP;HTTP;HTTPS
+	// +kubebuilder:default=TCP
+	Protocol string `json:"protocol,omitempty"`
+
+	// Frontend port on the VIP
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+
+	// Port on the pool members, same as port when unset
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	BackendPort int32 `json:"backendPort,omitempty"`
+
+	// Health check of the pool members for this listener, overrides spec.healthCheck
+	HealthCheck *LoadBalancerHealthCheck `json:"healthCheck,omitempty"`
+
+	// Timeouts for this listener, override spec.timeouts
+	Timeouts *LoadBalancerTimeouts `json:"timeouts,omitempty"`
+
+	// TLS termination, only for HTTPS and TCP listeners. HTTPS listeners
+	// without it pass TLS through to the pool members.
+	TLS *LoadBalancerTLS `json:"tls,omitempty"`
+}
+
+// LoadBalancerTLS terminates TLS on a listener
+type LoadBalancerTLS struct {
+	// Secret of type kubernetes.io/tls with the default certificate chain and key
+	// +kubebuilder:validation:Required
+	SecretRef SecretReference `json:"secretRef"`
+
+	// Host names the default certificate must cover
+	// +kubebuilder:validation:Optional
+	Hostnames []string `json:"hostnames,omitempty"`
+
+	// Certificates selected by SNI host name, the default certificate is used otherwise
+	// +kubebuilder:validation:Optional
+	SNI []LoadBalancerTLSSNI `json:"sni,omitempty"`
+
+	// Minimum TLS version (1.0, 1.1, 1.2, 1.3), 1.3 for the Modern cipher policy and 1.2 otherwise
+	// +kubebuilder:validation:Enum="1.0";"1.1";"1.2";"1.3"
+	MinVersion string `json:"minVersion,omitempty"`
+
+	// Cipher policy (Modern, Intermediate, Legacy) after the Mozilla server side TLS profiles
+	// +kubebuilder:validation:Enum=Modern;Intermediate;Legacy
+	// +kubebuilder:default=Intermediate
+	CipherPolicy string `json:"cipherPolicy,omitempty"`
+}
+
+// LoadBalancerTLSSNI maps SNI host names to a certificate
+type LoadBalancerTLSSNI struct {
+	// Host names served with this certificate, e.g. api.example.com or *.apps.example.com
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Hostnames []string `json:"hostnames"`
+
+	// Secret of type kubernetes.io/tls with the certificate chain and key
+	// +kubebuilder:validation:Required
+	SecretRef SecretReference `json:"secretRef"`
+}
+
+// SecretReference references a Secret in the same namespace
+type SecretReference struct {
+	// Name of the Secret
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// LoadBalancerHealthCheck probes pool members. Unset fields take the value from
+// spec.healthCheck, then the defaults.
+type LoadBalancerHealthCheck struct {
+	// Probe type (TCP, HTTP, HTTPS, None), HTTP for HTTP listeners, None for UDP and TCP otherwise
+	// +kubebuilder:validation:Enum=TCP;HTTP;HTTPS;None
+	Type string `json:"type,omitempty"`
+
+	// Request path for HTTP and HTTPS probes
+	// +kubebuilder:validation:Pattern=`^/`
+	Path string `json:"path,omitempty"`
+
+	// Port to probe, the backend port when unset
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+
+	// HTTP status codes counted as healthy, e.g. 200 or 200-399
+	// +kubebuilder:validation:Pattern=`^[1-5][0-9]{2}(-[1-5][0-9]{2})?$`
+	ExpectedStatus string `json:"expectedStatus,omitempty"`
+
+	// Time between probes (default 5s)
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Time to wait for a probe response (default 3s, or the interval if shorter)
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// Consecutive successful probes before a member is healthy (default 2)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	HealthyThreshold int32 `json:"healthyThreshold,omitempty"`
+
+	// Consecutive failed probes before a member is unhealthy (default 3)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	UnhealthyThreshold int32 `json:"unhealthyThreshold,omitempty"`
+}
+
+// LoadBalancerTimeouts bounds connections through a listener. Unset fields take
+// the value from spec.timeouts, then the defaults.
+type LoadBalancerTimeouts struct {
+	// Time to establish a connection to a pool member (default 5s)
+	Connect *metav1.Duration `json:"connect,omitempty"`
+
+	// Idle time on the client side of a connection (default 50s)
+	Client *metav1.Duration `json:"client,omitempty"`
+
+	// Idle time on the pool member side of a connection (default 50s)
+	Server *metav1.Duration `json:"server,omitempty"`
+
+	// Idle time of upgraded connections such as WebSockets (default 1h)
+	Tunnel *metav1.Duration `json:"tunnel,omitempty"`
 }
 
 type LoadBalancerStatus struct {
@@ -72,8 +286,138 @@ type LoadBalancerStatus struct {
 	LoadBalancerIps      []string `json:"loadBalancerIps,omitempty"`
 	Method               string   `json:"method,omitempty"`
 	PoolMembers          []string `json:"poolMembers,omitempty"`
+
+	// VIPPool the VIPs in loadBalancerIps are allocated from
+	VIPPool string `json:"vipPool,omitempty"`
+
+	// Listeners as served, with the VIP and port they were assigned
+	Listeners []LoadBalancerListenerStatus `json:"listeners,omitempty"`
+
+	// Health of every pool member, per listener
+	Members []LoadBalancerMemberStatus `json:"members,omitempty"`
+
+	// Certificates served by TLS listeners
+	Certificates []LoadBalancerCertificateStatus `json:"certificates,omitempty"`
 }
 
+// LoadBalancerCertificateStatus is the observed state of a served certificate
+type LoadBalancerCertificateStatus struct {
+	// Listener serving the certificate
+	Listener string `json:"listener"`
+
+	// Secret holding the certificate
+	SecretName string `json:"secretName"`
+
+	// DNS names in the leaf certificate
+	DNSNames []string `json:"dnsNames,omitempty"`
+
+	// Start of the validity of the leaf certificate
+	NotBefore *metav1.Time `json:"notBefore,omitempty"`
+
+	// Expiry of the leaf certificate
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+
+	// Whether the chain, key and host names passed validation
+	Valid bool `json:"valid,omitempty"`
+
+	// Human-readable status message, the validation error if any
+	Message string `json:"message,omitempty"`
+}
+
+// LoadBalancerMemberStatus is the observed health of a pool member for a listener
+type LoadBalancerMemberStatus struct {
+	// Address the member was resolved to
+	Address string `json:"address"`
+
+	// Machine the member refers to, if any
+	Machine string `json:"machine,omitempty"`
+
+	// Listener the health applies to, empty when the load balancer has no listeners
+	Listener string `json:"listener,omitempty"`
+
+	// Port probed on the member
+	Port int32 `json:"port,omitempty"`
+
+	// Healthy, Unhealthy or Unknown
+	Health string `json:"health,omitempty"`
+
+	// Admin state of the member
+	AdminState string `json:"adminState,omitempty"`
+
+	// Last time the health changed
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// Human-readable status message, e.g. the last probe error
+	Message string `json:"message,omitempty"`
+}
+
+// LoadBalancerListenerStatus is the observed state of a listener
+type LoadBalancerListenerStatus struct {
+	// Name of the listener
+	Name string `json:"name"`
+
+	// Protocol of the listener
+	Protocol string `json:"protocol,omitempty"`
+
+	// VIP the listener is served on
+	VIP string `json:"vip,omitempty"`
+
+	// Port the listener is served on
+	Port int32 `json:"port,omitempty"`
+
+	// Whether the listener accepts traffic
+	Ready bool `json:"ready,omitempty"`
+
+	// Human-readable status message
+	Message string `json:"message,omitempty"`
+}
+
+// LoadBalancer methods
+const (
+	LoadBalancerMethodRoundRobin         = "round-robin"
+	LoadBalancerMethodLeastSession       = "least-session"
+	LoadBalancerMethodFirstAlive         = "first-alive"
+	LoadBalancerMethodWeightedRoundRobin = "weighted-round-robin"
+	LoadBalancerMethodSourceHash         = "source-hash"
+)
+
+// LoadBalancer pool member admin states
+const (
+	LoadBalancerMemberEnabled  = "Enabled"
+	LoadBalancerMemberDrain    = "Drain"
+	LoadBalancerMemberDisabled = "Disabled"
+)
+
+// LoadBalancer pool member health
+const (
+	LoadBalancerMemberHealthy   = "Healthy"
+	LoadBalancerMemberUnhealthy = "Unhealthy"
+	LoadBalancerMemberUnknown   = "Unknown"
+)
+
+// LoadBalancer listener protocols
+const (
+	LoadBalancerProtocolTCP   = "TCP"
+	LoadBalancerProtocolUDP   = "UDP"
+	LoadBalancerProtocolHTTP  = "HTTP"
+	LoadBalancerProtocolHTTPS = "HTTPS"
+)
+
+// LoadBalancer TLS cipher policies
+const (
+	LoadBalancerCipherPolicyModern       = "Modern"
+	LoadBalancerCipherPolicyIntermediate = "Intermediate"
+	LoadBalancerCipherPolicyLegacy       = "Legacy"
+)
+
+// LoadBalancer health check types
+const (
+	LoadBalancerHealthCheckTCP   = "TCP"
+	LoadBalancerHealthCheckHTTP  = "HTTP"
+	LoadBalancerHealthCheckHTTPS = "HTTPS"
+	LoadBalancerHealthCheckNone  = "None"
+)
+
 func init() {
 	SchemeBuilder.Register(&LoadBalancer{}, &LoadBalancerList{})
 }
@@ -36,6 +36,12 @@ type MachineSpec struct {
 	// The provider-specific machine type override
 	MachineType string `json:"machineType,omitempty"`
 
+	// Reference to a MachineClass providing the machine shape
+	ClassRef *MachineClassReference `json:"classRef,omitempty"`
+
+	// Reference to a MachineTemplate providing default spec values
+	TemplateRef *MachineTemplateReference `json:"templateRef,omitempty"`
+
 	// CPU configuration
 	CPU MachineCPU `json:"cpu,omitempty"`
 
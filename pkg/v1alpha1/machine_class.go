@@ -0,0 +1,103 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Common MachineClass phases
+const (
+	MachineClassPhasePending = "Pending"
+	MachineClassPhaseBound   = "Bound"
+	MachineClassPhaseFailed  = "Failed"
+)
+
+// Common MachineClass condition types
+const (
+	MachineClassConditionInstanceTypeBound = "InstanceTypeBound"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineClass is the Schema for the MachineClasses API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=machineclasses,scope=Cluster,shortName=mc
+// +kubebuilder:printcolumn:name="Provider",type=string,JSONPath=`.spec.providerRef.name`
+// +kubebuilder:printcolumn:name="Instance Type",type=string,JSONPath=`.spec.instanceType`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type MachineClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MachineClassSpec   `json:"spec,omitempty"`
+	Status MachineClassStatus `json:"status,omitempty"`
+}
+
+// MachineClassSpec defines a named machine shape
+type MachineClassSpec struct {
+	// Human-readable description of the class
+	// +kubebuilder:validation:MaxLength=256
+	Description string `json:"description,omitempty"`
+
+	// Machine provider offering the instance type
+	// +kubebuilder:validation:Required
+	ProviderRef MachineProviderReference `json:"providerRef"`
+
+	// Name of an instance type in the provider's capabilities
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	InstanceType string `json:"instanceType"`
+
+	// The provider-specific machine type override
+	MachineType string `json:"machineType,omitempty"`
+
+	// CPU configuration
+	CPU MachineCPU `json:"cpu,omitempty"`
+
+	// Memory configuration in bytes
+	// +kubebuilder:validation:Minimum=0
+	Memory int64 `json:"memory,omitempty"`
+
+	// Disk configuration
+	Disks []MachineSpecDisk `json:"disks,omitempty"`
+}
+
+// MachineClassStatus defines the observed state of MachineClass
+type MachineClassStatus struct {
+	// Current phase of the class (Pending, Bound, Failed)
+	Phase string `json:"phase,omitempty"`
+
+	// Human-readable status message
+	Message string `json:"message,omitempty"`
+
+	// Instance type resolved from the provider's capabilities
+	InstanceType *InstanceTypeInfo `json:"instanceType,omitempty"`
+
+	// Generation of the MachineClass most recently observed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// MachineClassReference references a cluster scoped MachineClass
+type MachineClassReference struct {
+	// Name of the MachineClass
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// MachineClassList contains a list of MachineClass
+type MachineClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineClass `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MachineClass{}, &MachineClassList{})
+}
@@ -0,0 +1,170 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// MachineDeployment strategy types
+const (
+	MachineDeploymentStrategyRollingUpdate = "RollingUpdate"
+	MachineDeploymentStrategyRecreate      = "Recreate"
+)
+
+// Common MachineDeployment phases
+const (
+	MachineDeploymentPhaseScalingUp   = "ScalingUp"
+	MachineDeploymentPhaseScalingDown = "ScalingDown"
+	MachineDeploymentPhaseRollingOut  = "RollingOut"
+	MachineDeploymentPhaseRunning     = "Running"
+	MachineDeploymentPhaseFailed      = "Failed"
+)
+
+// Common MachineDeployment condition types
+const (
+	MachineDeploymentConditionAvailable   = "Available"
+	MachineDeploymentConditionProgressing = "Progressing"
+)
+
+// Common MachineDeployment condition reasons
+const (
+	MachineDeploymentReasonMinimumReplicasAvailable   = "MinimumReplicasAvailable"
+	MachineDeploymentReasonMinimumReplicasUnavailable = "MinimumReplicasUnavailable"
+	MachineDeploymentReasonNewMachineSetCreated       = "NewMachineSetCreated"
+	MachineDeploymentReasonMachineSetUpdated          = "MachineSetUpdated"
+	MachineDeploymentReasonNewMachineSetAvailable     = "NewMachineSetAvailable"
+	MachineDeploymentReasonProgressDeadlineExceeded   = "ProgressDeadlineExceeded"
+	MachineDeploymentReasonPaused                     = "DeploymentPaused"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineDeployment is the Schema for the MachineDeployments API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
+// +kubebuilder:resource:path=machinedeployments,scope=Namespaced,shortName=md
+// +kubebuilder:printcolumn:name="Desired",type=integer,JSONPath=`.spec.replicas`
+// +kubebuilder:printcolumn:name="Updated",type=integer,JSONPath=`.status.updatedReplicas`
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.readyReplicas`
+// +kubebuilder:printcolumn:name="Available",type=integer,JSONPath=`.status.availableReplicas`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type MachineDeployment struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MachineDeploymentSpec   `json:"spec,omitempty"`
+	Status MachineDeploymentStatus `json:"status,omitempty"`
+}
+
+// MachineDeploymentSpec defines the desired state of MachineDeployment
+type MachineDeploymentSpec struct {
+	// Number of desired machines
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Label selector for machines owned by this deployment, must match the template labels
+	// +kubebuilder:validation:Required
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// Template used to create new machines
+	// +kubebuilder:validation:Required
+	Template MachineTemplateSpec `json:"template"`
+
+	// Strategy used to replace old machines with new ones
+	Strategy MachineDeploymentStrategy `json:"strategy,omitempty"`
+
+	// Minimum number of seconds a machine must be ready before it is counted as available
+	// +kubebuilder:validation:Minimum=0
+	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
+
+	// Number of old MachineSets to keep for rollback
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// Whether the rollout is paused
+	Paused bool `json:"paused,omitempty"`
+
+	// Seconds a rollout may make no progress before it is reported as failed
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=600
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+}
+
+// MachineDeploymentStrategy describes how to replace existing machines with new ones
+type MachineDeploymentStrategy struct {
+	// Type of rollout (RollingUpdate, Recreate)
+	// +kubebuilder:validation:Enum=RollingUpdate;Recreate
+	// +kubebuilder:default=RollingUpdate
+	Type string `json:"type,omitempty"`
+
+	// Rolling update parameters, only used when type is RollingUpdate
+	RollingUpdate *MachineRollingUpdateDeployment `json:"rollingUpdate,omitempty"`
+}
+
+// MachineRollingUpdateDeployment controls the pace of a rolling update
+type MachineRollingUpdateDeployment struct {
+	// Maximum number of machines that can be unavailable during the update (absolute or percentage, rounded down)
+	// +kubebuilder:validation:XIntOrString
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// Maximum number of machines that can be created above the desired count (absolute or percentage, rounded up)
+	// +kubebuilder:validation:XIntOrString
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+
+	// Policy used to pick machines to delete from old MachineSets (Random, Newest, Oldest)
+	// +kubebuilder:validation:Enum=Random;Newest;Oldest
+	DeletePolicy string `json:"deletePolicy,omitempty"`
+}
+
+// MachineDeploymentStatus defines the observed state of MachineDeployment
+type MachineDeploymentStatus struct {
+	// Current phase of the deployment (ScalingUp, ScalingDown, RollingOut, Running, Failed)
+	Phase string `json:"phase,omitempty"`
+
+	// Human-readable status message
+	Message string `json:"message,omitempty"`
+
+	// Serialized label selector, used by the scale subresource
+	Selector string `json:"selector,omitempty"`
+
+	// Total number of machines targeted by this deployment
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// Number of machines created from the current template
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// Number of machines with the Ready condition set to True
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// Number of machines available for at least MinReadySeconds
+	AvailableReplicas int32 `json:"availableReplicas,omitempty"`
+
+	// Number of machines still required for the deployment to be fully available
+	UnavailableReplicas int32 `json:"unavailableReplicas,omitempty"`
+
+	// Revision of the current template
+	Revision string `json:"revision,omitempty"`
+
+	// Generation of the MachineDeployment most recently observed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// MachineDeploymentList contains a list of MachineDeployment
+type MachineDeploymentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineDeployment `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MachineDeployment{}, &MachineDeploymentList{})
+}
@@ -0,0 +1,138 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// MachineHealthCheck remediation strategies
+const (
+	MachineRemediationReboot       = "Reboot"
+	MachineRemediationRecreate     = "Recreate"
+	MachineRemediationAnnotateOnly = "AnnotateOnly"
+)
+
+// MachineUnhealthyAnnotation is set on machines found unhealthy by a MachineHealthCheck,
+// the value is the reason
+const MachineUnhealthyAnnotation = "vitistack.io/unhealthy"
+
+// MachineRemediatedAnnotation records when a MachineHealthCheck last rebooted or
+// deleted a machine, as an RFC 3339 timestamp
+const MachineRemediatedAnnotation = "vitistack.io/remediated-at"
+
+// Common MachineHealthCheck condition types
+const (
+	MachineHealthCheckConditionRemediationAllowed = "RemediationAllowed"
+)
+
+// Machine condition set by a MachineHealthCheck on the machines it targets
+const (
+	MachineConditionHealthCheckSucceeded = "HealthCheckSucceeded"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineHealthCheck is the Schema for the MachineHealthChecks API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=machinehealthchecks,scope=Namespaced,shortName=mhc
+// +kubebuilder:printcolumn:name="Strategy",type=string,JSONPath=`.spec.remediation.type`
+// +kubebuilder:printcolumn:name="Max Unhealthy",type=string,JSONPath=`.spec.maxUnhealthy`
+// +kubebuilder:printcolumn:name="Expected",type=integer,JSONPath=`.status.expectedMachines`
+// +kubebuilder:printcolumn:name="Healthy",type=integer,JSONPath=`.status.currentHealthy`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type MachineHealthCheck struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MachineHealthCheckSpec   `json:"spec,omitempty"`
+	Status MachineHealthCheckStatus `json:"status,omitempty"`
+}
+
+// MachineHealthCheckSpec defines the desired state of MachineHealthCheck
+type MachineHealthCheckSpec struct {
+	// Label selector for the machines to check
+	// +kubebuilder:validation:Required
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// Conditions that mark a machine unhealthy once they have held for the timeout
+	// +kubebuilder:validation:MinItems=1
+	UnhealthyConditions []UnhealthyCondition `json:"unhealthyConditions,omitempty"`
+
+	// Maximum number (or percentage) of unhealthy machines before remediation stops
+	// +kubebuilder:validation:XIntOrString
+	// +kubebuilder:default="100%"
+	MaxUnhealthy *intstr.IntOrString `json:"maxUnhealthy,omitempty"`
+
+	// Time a new machine may take to report a Ready condition before it is considered unhealthy
+	// +kubebuilder:default="10m"
+	StartupTimeout *metav1.Duration `json:"startupTimeout,omitempty"`
+
+	// Remediation applied to unhealthy machines
+	Remediation MachineRemediationStrategy `json:"remediation,omitempty"`
+}
+
+// UnhealthyCondition marks a machine unhealthy when a condition has had the given status for longer than the timeout
+type UnhealthyCondition struct {
+	// Machine condition type (e.g., Ready, NetworkReady, InfrastructureReady)
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Type string `json:"type"`
+
+	// Condition status that is considered unhealthy (True, False, Unknown)
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=True;False;Unknown
+	Status string `json:"status"`
+
+	// How long the condition must hold before the machine is unhealthy
+	// +kubebuilder:validation:Required
+	Timeout metav1.Duration `json:"timeout"`
+}
+
+// MachineRemediationStrategy describes what to do with unhealthy machines
+type MachineRemediationStrategy struct {
+	// Remediation type (Reboot, Recreate, AnnotateOnly)
+	// +kubebuilder:validation:Enum=Reboot;Recreate;AnnotateOnly
+	// +kubebuilder:default=Recreate
+	Type string `json:"type,omitempty"`
+}
+
+// MachineHealthCheckStatus defines the observed state of MachineHealthCheck
+type MachineHealthCheckStatus struct {
+	// Number of machines matched by the selector
+	ExpectedMachines int32 `json:"expectedMachines,omitempty"`
+
+	// Number of healthy machines
+	CurrentHealthy int32 `json:"currentHealthy,omitempty"`
+
+	// Number of further machines that may be remediated before maxUnhealthy is reached
+	RemediationsAllowed int32 `json:"remediationsAllowed,omitempty"`
+
+	// Names of the machines matched by the selector
+	Targets []string `json:"targets,omitempty"`
+
+	// Names of the machines currently considered unhealthy
+	UnhealthyMachines []string `json:"unhealthyMachines,omitempty"`
+
+	// Last time a remediation was started
+	LastRemediationTime *metav1.Time `json:"lastRemediationTime,omitempty"`
+
+	// Generation of the MachineHealthCheck most recently observed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// MachineHealthCheckList contains a list of MachineHealthCheck
+type MachineHealthCheckList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineHealthCheck `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MachineHealthCheck{}, &MachineHealthCheckList{})
+}
@@ -0,0 +1,182 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Common MachineMigration phases
+const (
+	MachineMigrationPhasePending      = "Pending"
+	MachineMigrationPhaseValidating   = "Validating"
+	MachineMigrationPhasePreparing    = "Preparing"
+	MachineMigrationPhaseTransferring = "Transferring"
+	MachineMigrationPhaseSwitchover   = "Switchover"
+	MachineMigrationPhaseCompleted    = "Completed"
+	MachineMigrationPhaseFailed       = "Failed"
+)
+
+// MachineMigration modes
+const (
+	MachineMigrationModeLive = "Live"
+	MachineMigrationModeCold = "Cold"
+)
+
+// Common MachineMigration condition types
+const (
+	MachineMigrationConditionTargetCompatible = "TargetCompatible"
+	MachineMigrationConditionCompleted        = "Completed"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineMigration is the Schema for the MachineMigrations API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=machinemigrations,scope=Namespaced,shortName=mmig
+// +kubebuilder:printcolumn:name="Machine",type=string,JSONPath=`.spec.machineRef.name`
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.mode`
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.target.providerRef.name`
+// +kubebuilder:printcolumn:name="Progress",type=integer,JSONPath=`.status.progress.percent`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type MachineMigration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MachineMigrationSpec   `json:"spec,omitempty"`
+	Status MachineMigrationStatus `json:"status,omitempty"`
+}
+
+// MachineMigrationSpec defines the desired state of MachineMigration
+type MachineMigrationSpec struct {
+	// Machine to migrate, in the migration's namespace
+	// +kubebuilder:validation:Required
+	MachineRef MachineReference `json:"machineRef"`
+
+	// Where to move the machine
+	Target MachineMigrationTarget `json:"target,omitempty"`
+
+	// Migrate while running (Live) or stopped (Cold). Live migration requires the machine to stay on its provider.
+	// +kubebuilder:validation:Enum=Live;Cold
+	// +kubebuilder:default=Cold
+	Mode string `json:"mode,omitempty"`
+
+	// Bandwidth and downtime limits for the transfer
+	Limits MachineMigrationLimits `json:"limits,omitempty"`
+}
+
+// MachineMigrationTarget is the destination of a migration. Unset fields keep the machine's current value.
+type MachineMigrationTarget struct {
+	// Machine provider to move to, the machine's current provider when unset
+	ProviderRef *MachineProviderReference `json:"providerRef,omitempty"`
+
+	// Availability zone on the target provider
+	Zone string `json:"zone,omitempty"`
+
+	// Host on the target provider, chosen by the provider when unset
+	Host string `json:"host,omitempty"`
+
+	// Instance type on the target provider, the machine's instance type when unset
+	InstanceType string `json:"instanceType,omitempty"`
+
+	// Storage type for all disks on the target provider, each disk's type when unset
+	StorageType string `json:"storageType,omitempty"`
+}
+
+// MachineMigrationLimits bounds the resources a migration may use
+type MachineMigrationLimits struct {
+	// Maximum transfer bandwidth in Mbit/s, unlimited when unset
+	// +kubebuilder:validation:Minimum=1
+	MaxBandwidthMbps int32 `json:"maxBandwidthMbps,omitempty"`
+
+	// Maximum downtime during the switchover of a live migration in milliseconds
+	// +kubebuilder:validation:Minimum=1
+	MaxDowntimeMs int32 `json:"maxDowntimeMs,omitempty"`
+
+	// Time after which the migration is aborted, unlimited when unset
+	CompletionTimeout *metav1.Duration `json:"completionTimeout,omitempty"`
+}
+
+// MachineMigrationStatus defines the observed state of MachineMigration
+type MachineMigrationStatus struct {
+	// Current phase of the migration (Pending, Validating, Preparing, Transferring, Switchover, Completed, Failed)
+	Phase string `json:"phase,omitempty"`
+
+	// Human-readable status message
+	Message string `json:"message,omitempty"`
+
+	// Where the machine was when the migration started
+	Source MachineMigrationLocation `json:"source,omitempty"`
+
+	// Where the machine is being moved to, as resolved by the planner
+	Target MachineMigrationLocation `json:"target,omitempty"`
+
+	// Transfer progress
+	Progress MachineMigrationProgress `json:"progress,omitempty"`
+
+	// Reasons the target cannot run the machine
+	Incompatibilities []string `json:"incompatibilities,omitempty"`
+
+	// Time the migration started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Time the migration finished
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Generation of the MachineMigration most recently observed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// MachineMigrationLocation identifies where a machine runs
+type MachineMigrationLocation struct {
+	// Name of the MachineProvider
+	Provider string `json:"provider,omitempty"`
+
+	// Availability zone
+	Zone string `json:"zone,omitempty"`
+
+	// Host
+	Host string `json:"host,omitempty"`
+
+	// Instance type
+	InstanceType string `json:"instanceType,omitempty"`
+
+	// Image ID
+	ImageID string `json:"imageID,omitempty"`
+}
+
+// MachineMigrationProgress reports how far the transfer has come
+type MachineMigrationProgress struct {
+	// Completed share of the transfer in percent
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Percent int32 `json:"percent,omitempty"`
+
+	// Bytes transferred so far
+	TransferredBytes int64 `json:"transferredBytes,omitempty"`
+
+	// Total bytes to transfer
+	TotalBytes int64 `json:"totalBytes,omitempty"`
+
+	// Current transfer rate in Mbit/s
+	CurrentMbps int32 `json:"currentMbps,omitempty"`
+
+	// Number of memory copy passes of a live migration
+	Iterations int32 `json:"iterations,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// MachineMigrationList contains a list of MachineMigration
+type MachineMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineMigration `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MachineMigration{}, &MachineMigrationList{})
+}
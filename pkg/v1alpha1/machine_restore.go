@@ -0,0 +1,107 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Common MachineRestore phases
+const (
+	MachineRestorePhasePending   = "Pending"
+	MachineRestorePhaseRestoring = "Restoring"
+	MachineRestorePhaseCompleted = "Completed"
+	MachineRestorePhaseFailed    = "Failed"
+)
+
+// MachineRestore target modes
+const (
+	MachineRestoreModeInPlace    = "InPlace"
+	MachineRestoreModeNewMachine = "NewMachine"
+)
+
+// MachineRestoredFromAnnotation is set on machines created by a MachineRestore,
+// the value is the name of the snapshot
+const MachineRestoredFromAnnotation = "vitistack.io/restored-from"
+
+// Common MachineRestore condition types
+const (
+	MachineRestoreConditionCompleted = "Completed"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineRestore is the Schema for the MachineRestores API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=machinerestores,scope=Namespaced,shortName=mrestore
+// +kubebuilder:printcolumn:name="Snapshot",type=string,JSONPath=`.spec.snapshotRef.name`
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.mode`
+// +kubebuilder:printcolumn:name="Machine",type=string,JSONPath=`.status.machineName`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type MachineRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MachineRestoreSpec   `json:"spec,omitempty"`
+	Status MachineRestoreStatus `json:"status,omitempty"`
+}
+
+// MachineRestoreSpec defines the desired state of MachineRestore
+type MachineRestoreSpec struct {
+	// Snapshot to restore from
+	// +kubebuilder:validation:Required
+	SnapshotRef MachineSnapshotReference `json:"snapshotRef"`
+
+	// Restore over the source machine (InPlace) or into a new machine (NewMachine)
+	// +kubebuilder:validation:Enum=InPlace;NewMachine
+	// +kubebuilder:default=InPlace
+	Mode string `json:"mode,omitempty"`
+
+	// Name of the machine to create, required for NewMachine
+	// +kubebuilder:validation:MaxLength=253
+	NewMachineName string `json:"newMachineName,omitempty"`
+
+	// Whether to resume from the captured memory state, if the snapshot has one
+	RestoreMemory bool `json:"restoreMemory,omitempty"`
+
+	// Whether to start the machine after the restore
+	// +kubebuilder:default=true
+	PowerOn *bool `json:"powerOn,omitempty"`
+}
+
+// MachineRestoreStatus defines the observed state of MachineRestore
+type MachineRestoreStatus struct {
+	// Current phase of the restore (Pending, Restoring, Completed, Failed)
+	Phase string `json:"phase,omitempty"`
+
+	// Human-readable status message
+	Message string `json:"message,omitempty"`
+
+	// Name of the restored machine
+	MachineName string `json:"machineName,omitempty"`
+
+	// Time the restore started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Time the restore finished
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Generation of the MachineRestore most recently observed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// MachineRestoreList contains a list of MachineRestore
+type MachineRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MachineRestore{}, &MachineRestoreList{})
+}
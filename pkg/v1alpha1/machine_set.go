@@ -0,0 +1,149 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Labels and annotations used to tie Machines to their owning MachineSet and MachineDeployment
+const (
+	// MachineSetNameLabel is set on every Machine created by a MachineSet
+	MachineSetNameLabel = "vitistack.io/machine-set-name"
+	// MachineDeploymentNameLabel is set on MachineSets and Machines created for a MachineDeployment
+	MachineDeploymentNameLabel = "vitistack.io/machine-deployment-name"
+	// MachineTemplateHashLabel holds the hash of the template a MachineSet was created from
+	MachineTemplateHashLabel = "vitistack.io/machine-template-hash"
+	// MachineDeploymentRevisionAnnotation holds the rollout revision of a MachineSet
+	MachineDeploymentRevisionAnnotation = "vitistack.io/revision"
+	// MachineDeleteAnnotation marks a Machine as preferred for deletion on scale down
+	MachineDeleteAnnotation = "vitistack.io/delete-machine"
+)
+
+// MachineSet delete policies
+const (
+	MachineSetDeletePolicyRandom = "Random"
+	MachineSetDeletePolicyNewest = "Newest"
+	MachineSetDeletePolicyOldest = "Oldest"
+)
+
+// Common MachineSet phases
+const (
+	MachineSetPhaseProvisioning = "Provisioning"
+	MachineSetPhaseScalingUp    = "ScalingUp"
+	MachineSetPhaseScalingDown  = "ScalingDown"
+	MachineSetPhaseRunning      = "Running"
+	MachineSetPhaseFailed       = "Failed"
+)
+
+// Common MachineSet condition types
+const (
+	MachineSetConditionMachinesCreated = "MachinesCreated"
+	MachineSetConditionMachinesReady   = "MachinesReady"
+	MachineSetConditionResized         = "Resized"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineSet is the Schema for the MachineSets API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
+// +kubebuilder:resource:path=machinesets,scope=Namespaced,shortName=ms
+// +kubebuilder:printcolumn:name="Desired",type=integer,JSONPath=`.spec.replicas`
+// +kubebuilder:printcolumn:name="Replicas",type=integer,JSONPath=`.status.replicas`
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.readyReplicas`
+// +kubebuilder:printcolumn:name="Available",type=integer,JSONPath=`.status.availableReplicas`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type MachineSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MachineSetSpec   `json:"spec,omitempty"`
+	Status MachineSetStatus `json:"status,omitempty"`
+}
+
+// MachineSetSpec defines the desired state of MachineSet
+type MachineSetSpec struct {
+	// Number of desired machines
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Minimum number of seconds a machine must be ready before it is counted as available
+	// +kubebuilder:validation:Minimum=0
+	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
+
+	// Policy used to pick machines to delete when scaling down (Random, Newest, Oldest)
+	// +kubebuilder:validation:Enum=Random;Newest;Oldest
+	// +kubebuilder:default=Random
+	DeletePolicy string `json:"deletePolicy,omitempty"`
+
+	// Label selector for machines owned by this set, must match the template labels
+	// +kubebuilder:validation:Required
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// Template used to create new machines
+	// +kubebuilder:validation:Required
+	Template MachineTemplateSpec `json:"template"`
+}
+
+// MachineTemplateSpec describes the machines created from a MachineSet or MachineDeployment
+type MachineTemplateSpec struct {
+	// Metadata applied to every machine created from the template
+	Metadata MachineTemplateMetadata `json:"metadata,omitempty"`
+
+	// Spec of the machines created from the template
+	Spec MachineSpec `json:"spec,omitempty"`
+}
+
+// MachineTemplateMetadata is the subset of object metadata copied onto templated machines
+type MachineTemplateMetadata struct {
+	// Labels applied to every machine
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations applied to every machine
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// MachineSetStatus defines the observed state of MachineSet
+type MachineSetStatus struct {
+	// Current phase of the set (Provisioning, ScalingUp, ScalingDown, Running, Failed)
+	Phase string `json:"phase,omitempty"`
+
+	// Human-readable status message
+	Message string `json:"message,omitempty"`
+
+	// Serialized label selector, used by the scale subresource
+	Selector string `json:"selector,omitempty"`
+
+	// Number of machines currently owned by the set
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// Number of machines whose labels match the template labels
+	FullyLabeledReplicas int32 `json:"fullyLabeledReplicas,omitempty"`
+
+	// Number of machines with the Ready condition set to True
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// Number of machines that have been ready for at least MinReadySeconds
+	AvailableReplicas int32 `json:"availableReplicas,omitempty"`
+
+	// Generation of the MachineSet most recently observed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// MachineSetList contains a list of MachineSet
+type MachineSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MachineSet{}, &MachineSetList{})
+}
@@ -0,0 +1,139 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Common MachineSnapshot phases
+const (
+	MachineSnapshotPhasePending  = "Pending"
+	MachineSnapshotPhaseCreating = "Creating"
+	MachineSnapshotPhaseReady    = "Ready"
+	MachineSnapshotPhaseFailed   = "Failed"
+	MachineSnapshotPhaseDeleting = "Deleting"
+)
+
+// MachineSnapshot retention policies
+const (
+	MachineSnapshotRetentionDelete = "Delete"
+	MachineSnapshotRetentionRetain = "Retain"
+)
+
+// Common MachineSnapshot condition types
+const (
+	MachineSnapshotConditionReady = "Ready"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineSnapshot is the Schema for the MachineSnapshots API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=machinesnapshots,scope=Namespaced,shortName=msnap
+// +kubebuilder:printcolumn:name="Machine",type=string,JSONPath=`.spec.machineRef.name`
+// +kubebuilder:printcolumn:name="Memory",type=boolean,JSONPath=`.spec.includeMemory`
+// +kubebuilder:printcolumn:name="Ready",type=boolean,JSONPath=`.status.readyToUse`
+// +kubebuilder:printcolumn:name="Size",type=integer,JSONPath=`.status.sizeBytes`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type MachineSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MachineSnapshotSpec   `json:"spec,omitempty"`
+	Status MachineSnapshotStatus `json:"status,omitempty"`
+}
+
+// MachineSnapshotSpec defines the desired state of MachineSnapshot
+type MachineSnapshotSpec struct {
+	// Machine to snapshot, in the snapshot's namespace
+	// +kubebuilder:validation:Required
+	MachineRef MachineReference `json:"machineRef"`
+
+	// Whether to include the memory state of a running machine
+	IncludeMemory bool `json:"includeMemory,omitempty"`
+
+	// Names of the machine disks to include, all disks when empty
+	Disks []string `json:"disks,omitempty"`
+
+	// Whether the snapshot may be pruned by the machine's backup retention (Delete, Retain)
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +kubebuilder:default=Delete
+	RetentionPolicy string `json:"retentionPolicy,omitempty"`
+}
+
+// MachineSnapshotStatus defines the observed state of MachineSnapshot
+type MachineSnapshotStatus struct {
+	// Current phase of the snapshot (Pending, Creating, Ready, Failed, Deleting)
+	Phase string `json:"phase,omitempty"`
+
+	// Human-readable status message
+	Message string `json:"message,omitempty"`
+
+	// Whether the snapshot can be used to restore a machine
+	ReadyToUse bool `json:"readyToUse,omitempty"`
+
+	// Time the provider took the snapshot
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+
+	// Total size of the snapshot in bytes
+	// +kubebuilder:validation:Minimum=0
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+
+	// Whether the memory state was captured
+	MemoryIncluded bool `json:"memoryIncluded,omitempty"`
+
+	// Provider-specific snapshot identifier
+	ProviderSnapshotID string `json:"providerSnapshotID,omitempty"`
+
+	// Per-disk snapshot details
+	Disks []MachineSnapshotDiskStatus `json:"disks,omitempty"`
+
+	// Generation of the MachineSnapshot most recently observed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// MachineSnapshotDiskStatus describes the snapshot of a single disk
+type MachineSnapshotDiskStatus struct {
+	// Name of the machine disk
+	Name string `json:"name"`
+
+	// Size of the disk snapshot in bytes
+	// +kubebuilder:validation:Minimum=0
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+
+	// Provider-specific identifier of the disk snapshot
+	ProviderSnapshotID string `json:"providerSnapshotID,omitempty"`
+}
+
+// MachineReference references a Machine in the same namespace
+type MachineReference struct {
+	// Name of the Machine
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// MachineSnapshotReference references a MachineSnapshot in the same namespace
+type MachineSnapshotReference struct {
+	// Name of the MachineSnapshot
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// MachineSnapshotList contains a list of MachineSnapshot
+type MachineSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineSnapshot `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MachineSnapshot{}, &MachineSnapshotList{})
+}
@@ -0,0 +1,53 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineTemplate is the Schema for the MachineTemplates API
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=machinetemplates,scope=Namespaced,shortName=mt
+// +kubebuilder:printcolumn:name="Class",type=string,JSONPath=`.spec.template.spec.classRef.name`
+// +kubebuilder:printcolumn:name="Instance Type",type=string,JSONPath=`.spec.template.spec.instanceType`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type MachineTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MachineTemplateResourceSpec `json:"spec,omitempty"`
+}
+
+// MachineTemplateResourceSpec defines a reusable machine spec template
+type MachineTemplateResourceSpec struct {
+	// Human-readable description of the template
+	// +kubebuilder:validation:MaxLength=256
+	Description string `json:"description,omitempty"`
+
+	// Template for machines referencing this MachineTemplate. Only the fields
+	// that are set are applied, see docs/machine-template-crd.md for the merge order.
+	// +kubebuilder:validation:Required
+	Template MachineTemplateSpec `json:"template"`
+}
+
+// MachineTemplateReference references a MachineTemplate in the machine's namespace
+type MachineTemplateReference struct {
+	// Name of the MachineTemplate
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// MachineTemplateList contains a list of MachineTemplate
+type MachineTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MachineTemplate{}, &MachineTemplateList{})
+}
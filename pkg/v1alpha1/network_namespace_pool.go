@@ -0,0 +1,157 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Common NetworkNamespacePool phases
+const (
+	NetworkNamespacePoolPhasePending   = "Pending"
+	NetworkNamespacePoolPhaseReady     = "Ready"
+	NetworkNamespacePoolPhaseExhausted = "Exhausted"
+	NetworkNamespacePoolPhaseFailed    = "Failed"
+)
+
+// Common NetworkNamespacePool condition types
+const (
+	NetworkNamespacePoolConditionReady = "Ready"
+)
+
+// NetworkNamespaceAllocationFinalizer is set on NetworkNamespaces holding a
+// VLAN or egress IPs from a NetworkNamespacePool, so they are released before
+// the NetworkNamespace is gone.
+const NetworkNamespaceAllocationFinalizer = "vitistack.io/network-release"
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NetworkNamespacePool is the Schema for the NetworkNamespacePools API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=networknamespacepools,scope=Cluster,shortName=nnp
+// +kubebuilder:printcolumn:name="Datacenter",type=string,JSONPath=`.spec.datacenterIdentifier`
+// +kubebuilder:printcolumn:name="VLANs",type=string,JSONPath=`.spec.vlanRanges`
+// +kubebuilder:printcolumn:name="VLANs Free",type=integer,JSONPath=`.status.vlans.free`
+// +kubebuilder:printcolumn:name="IPv4 Free",type=integer,JSONPath=`.status.ipv4.free`
+// +kubebuilder:printcolumn:name="IPv6 Free",type=integer,JSONPath=`.status.ipv6.free`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type NetworkNamespacePool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NetworkNamespacePoolSpec   `json:"spec,omitempty"`
+	Status NetworkNamespacePoolStatus `json:"status,omitempty"`
+}
+
+// NetworkNamespacePoolSpec defines the VLAN IDs and egress IPs handed out to
+// the NetworkNamespaces of a datacenter
+type NetworkNamespacePoolSpec struct {
+	// Datacenter of the NetworkNamespaces, <country>-<region>-<availability zone>
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:Pattern=`^[a-z]{2}-[a-z0-9]+-[a-z0-9]+$`
+	DatacenterIdentifier string `json:"datacenterIdentifier"`
+
+	// VLAN IDs to allocate from, as ranges (100-199) or single IDs
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	VLANRanges []string `json:"vlanRanges"`
+
+	// IPv4 and IPv6 CIDRs to allocate egress IPs from; a NetworkNamespace gets one egress IP per family
+	// +kubebuilder:validation:Optional
+	EgressCIDRs []string `json:"egressCidrs,omitempty"`
+
+	// Egress addresses that are never allocated, as CIDRs, ranges (10.0.0.1-10.0.0.9) or single addresses
+	// +kubebuilder:validation:Optional
+	ExcludedRanges []string `json:"excludedRanges,omitempty"`
+}
+
+// NetworkNamespacePoolStatus defines the observed state of
+// NetworkNamespacePool. The allocations are the source of truth for which
+// VLAN and egress IPs belong to which NetworkNamespace.
+type NetworkNamespacePoolStatus struct {
+	// Current phase of the pool (Pending, Ready, Exhausted, Failed)
+	Phase string `json:"phase,omitempty"`
+
+	// Human-readable status message
+	Message string `json:"message,omitempty"`
+
+	// VLAN ID usage
+	VLANs *VLANUsage `json:"vlans,omitempty"`
+
+	// IPv4 egress address usage
+	IPv4 *IPPoolUsage `json:"ipv4,omitempty"`
+
+	// IPv6 egress address usage
+	IPv6 *IPPoolUsage `json:"ipv6,omitempty"`
+
+	// VLANs and egress IPs allocated to NetworkNamespaces, sorted by VLAN ID
+	Allocations []NetworkNamespaceAllocation `json:"allocations,omitempty"`
+
+	// Generation of the NetworkNamespacePool most recently observed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// VLANUsage counts the VLAN IDs of a pool
+type VLANUsage struct {
+	// VLAN IDs in the ranges
+	Total int32 `json:"total"`
+
+	// Allocated VLAN IDs
+	Used int32 `json:"used"`
+
+	// VLAN IDs still free
+	Free int32 `json:"free"`
+}
+
+// NetworkNamespaceAllocation records the VLAN and egress IPs held by a
+// NetworkNamespace
+type NetworkNamespaceAllocation struct {
+	// NetworkNamespace holding the allocation
+	// +kubebuilder:validation:Required
+	NetworkNamespace NamespacedNetworkNamespaceReference `json:"networkNamespace"`
+
+	// Cluster of the NetworkNamespace
+	ClusterIdentifier string `json:"clusterIdentifier,omitempty"`
+
+	// Allocated VLAN ID
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4094
+	VlanID int32 `json:"vlanId"`
+
+	// Allocated IPv4 egress IP
+	IPv4EgressIP string `json:"ipv4EgressIp,omitempty"`
+
+	// Allocated IPv6 egress IP
+	IPv6EgressIP string `json:"ipv6EgressIp,omitempty"`
+}
+
+// NamespacedNetworkNamespaceReference references a NetworkNamespace in any namespace
+type NamespacedNetworkNamespaceReference struct {
+	// Namespace of the NetworkNamespace
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// Name of the NetworkNamespace
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// NetworkNamespacePoolList contains a list of NetworkNamespacePool
+type NetworkNamespacePoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NetworkNamespacePool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NetworkNamespacePool{}, &NetworkNamespacePoolList{})
+}
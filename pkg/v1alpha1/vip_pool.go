@@ -0,0 +1,144 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Common VIPPool phases
+const (
+	VIPPoolPhasePending   = "Pending"
+	VIPPoolPhaseReady     = "Ready"
+	VIPPoolPhaseExhausted = "Exhausted"
+	VIPPoolPhaseFailed    = "Failed"
+)
+
+// Common VIPPool condition types
+const (
+	VIPPoolConditionReady = "Ready"
+)
+
+// LoadBalancerVIPFinalizer is set on LoadBalancers holding VIPs from a VIPPool,
+// so the VIPs are released before the LoadBalancer is gone.
+const LoadBalancerVIPFinalizer = "vitistack.io/vip-release"
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VIPPool is the Schema for the VIPPools API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=vippools,scope=Cluster,shortName=vipp
+// +kubebuilder:printcolumn:name="Datacenter",type=string,JSONPath=`.spec.datacenterIdentifier`
+// +kubebuilder:printcolumn:name="Supervisor",type=string,JSONPath=`.spec.supervisorIdentifier`
+// +kubebuilder:printcolumn:name="CIDRs",type=string,JSONPath=`.spec.cidrs`
+// +kubebuilder:printcolumn:name="IPv4 Free",type=integer,JSONPath=`.status.ipv4.free`
+// +kubebuilder:printcolumn:name="IPv6 Free",type=integer,JSONPath=`.status.ipv6.free`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type VIPPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VIPPoolSpec   `json:"spec,omitempty"`
+	Status VIPPoolStatus `json:"status,omitempty"`
+}
+
+// VIPPoolSpec defines the VIP address space of a datacenter, or of one
+// supervisor in it
+type VIPPoolSpec struct {
+	// Datacenter the VIPs are routed in, <country>-<region>-<availability zone>
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:Pattern=`^[a-z]{2}-[a-z0-9]+-[a-z0-9]+$`
+	DatacenterIdentifier string `json:"datacenterIdentifier"`
+
+	// Supervisor the pool is dedicated to, shared by all supervisors of the datacenter when empty
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MinLength=2
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`
+	SupervisorIdentifier string `json:"supervisorIdentifier,omitempty"`
+
+	// IPv4 and IPv6 CIDRs to allocate VIPs from, without their network and IPv4 broadcast addresses except for /31, /32, /127 and /128
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	CIDRs []string `json:"cidrs"`
+
+	// Addresses that are never allocated, as CIDRs, ranges (10.0.0.1-10.0.0.9) or single addresses
+	ExcludedRanges []string `json:"excludedRanges,omitempty"`
+}
+
+// VIPPoolStatus defines the observed state of VIPPool. The allocations are
+// the source of truth for which VIP belongs to which LoadBalancer.
+type VIPPoolStatus struct {
+	// Current phase of the pool (Pending, Ready, Exhausted, Failed)
+	Phase string `json:"phase,omitempty"`
+
+	// Human-readable status message
+	Message string `json:"message,omitempty"`
+
+	// IPv4 address usage
+	IPv4 *IPPoolUsage `json:"ipv4,omitempty"`
+
+	// IPv6 address usage
+	IPv6 *IPPoolUsage `json:"ipv6,omitempty"`
+
+	// VIPs allocated to LoadBalancers, sorted by address
+	Allocations []VIPAllocation `json:"allocations,omitempty"`
+
+	// Generation of the VIPPool most recently observed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// VIPAllocation records a VIP held by a LoadBalancer
+type VIPAllocation struct {
+	// Allocated VIP
+	// +kubebuilder:validation:Required
+	Address string `json:"address"`
+
+	// LoadBalancer holding the VIP
+	// +kubebuilder:validation:Required
+	LoadBalancer NamespacedLoadBalancerReference `json:"loadBalancer"`
+
+	// Cluster of the LoadBalancer
+	ClusterIdentifier string `json:"clusterIdentifier,omitempty"`
+
+	// Whether the LoadBalancer requested this VIP in spec.vips
+	Static bool `json:"static,omitempty"`
+}
+
+// NamespacedLoadBalancerReference references a LoadBalancer in any namespace
+type NamespacedLoadBalancerReference struct {
+	// Namespace of the LoadBalancer
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// Name of the LoadBalancer
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// VIPPoolReference references a VIPPool
+type VIPPoolReference struct {
+	// Name of the VIPPool
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// VIPPoolList contains a list of VIPPool
+type VIPPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VIPPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VIPPool{}, &VIPPoolList{})
+}
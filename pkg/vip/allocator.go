@@ -0,0 +1,204 @@
+package vip
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vitistack/crds/pkg/ipam"
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+// Allocator hands out the VIPs of one pool. It is safe for concurrent use.
+type Allocator struct {
+	mu    sync.Mutex
+	ipam  *ipam.Allocator
+	owned map[netip.Addr]v1alpha1.VIPAllocation
+}
+
+// New builds the allocator of a pool and records the allocations in its
+// status. Allocations that conflict with each other or with the pool are
+// reported in the returned error, which joins one error per allocation. The
+// allocator is still returned in that case, without those allocations.
+func New(pool *v1alpha1.VIPPool) (*Allocator, error) {
+	alloc, err := ipam.New(ipam.Config{CIDRs: pool.Spec.CIDRs, ExcludedRanges: pool.Spec.ExcludedRanges})
+	if err != nil {
+		return nil, fmt.Errorf("VIPPool %s: %w", pool.Name, err)
+	}
+	a := &Allocator{ipam: alloc, owned: map[netip.Addr]v1alpha1.VIPAllocation{}}
+
+	var errs []error
+	for _, al := range pool.Status.Allocations {
+		addr, err := netip.ParseAddr(al.Address)
+		if err == nil {
+			addr = addr.Unmap()
+			err = a.ipam.AllocateAddress(owner(al.LoadBalancer.Namespace, al.LoadBalancer.Name), addr)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("VIP %s of LoadBalancer %s/%s: %w", al.Address, al.LoadBalancer.Namespace, al.LoadBalancer.Name, err))
+			continue
+		}
+		al.Address = addr.String()
+		a.owned[addr] = al
+	}
+	return a, errors.Join(errs...)
+}
+
+// Assign allocates one VIP per family of a LoadBalancer and returns them,
+// IPv4 first. Static VIPs from spec.vips are used as requested; for other
+// families the LoadBalancer keeps the VIP it holds, or gets the lowest free
+// one. VIPs of families the LoadBalancer no longer needs are released. On
+// error, the LoadBalancer keeps the VIPs it held before.
+func (a *Allocator) Assign(lb *v1alpha1.LoadBalancer) ([]string, error) {
+	static, err := StaticVIPs(lb)
+	if err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	own := owner(lb.Namespace, lb.Name)
+	prev := a.release(own)
+	held := map[string]v1alpha1.VIPAllocation{}
+	for _, al := range prev {
+		held[family(netip.MustParseAddr(al.Address))] = al
+	}
+
+	var vips []string
+	for _, f := range Families(lb) {
+		addr, isStatic := static[f]
+		var err error
+		switch {
+		case isStatic:
+			err = a.ipam.AllocateAddress(own, addr)
+		case held[f].Address != "":
+			addr = netip.MustParseAddr(held[f].Address)
+			err = a.ipam.AllocateAddress(own, addr)
+		default:
+			addr, err = a.ipam.Allocate(own, f)
+		}
+		if err != nil {
+			a.release(own)
+			for _, al := range prev {
+				// Re-adding what the owner held before cannot conflict.
+				_ = a.ipam.AllocateAddress(own, netip.MustParseAddr(al.Address))
+				a.owned[netip.MustParseAddr(al.Address)] = al
+			}
+			return nil, fmt.Errorf("%s VIP for LoadBalancer %s: %w", f, own, err)
+		}
+		a.owned[addr] = v1alpha1.VIPAllocation{
+			Address:           addr.String(),
+			LoadBalancer:      v1alpha1.NamespacedLoadBalancerReference{Namespace: lb.Namespace, Name: lb.Name},
+			ClusterIdentifier: lb.Spec.ClusterIdentifier,
+			Static:            isStatic,
+		}
+		vips = append(vips, addr.String())
+	}
+	return vips, nil
+}
+
+// Release frees the VIPs of a LoadBalancer and returns them. Call it when the
+// LoadBalancer is deleted, before removing LoadBalancerVIPFinalizer.
+func (a *Allocator) Release(namespace, name string) []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var vips []string
+	for _, al := range a.release(owner(namespace, name)) {
+		vips = append(vips, al.Address)
+	}
+	return vips
+}
+
+// ReleaseOrphans frees the VIPs of LoadBalancers that no longer exist, e.g.
+// when a finalizer was removed by hand, and returns them. lbs must hold every
+// LoadBalancer that may use the pool.
+func (a *Allocator) ReleaseOrphans(lbs []v1alpha1.LoadBalancer) []string {
+	live := map[string]bool{}
+	for i := range lbs {
+		live[owner(lbs[i].Namespace, lbs[i].Name)] = true
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var released []string
+	for _, al := range a.sorted() {
+		if own := owner(al.LoadBalancer.Namespace, al.LoadBalancer.Name); !live[own] {
+			for _, r := range a.release(own) {
+				released = append(released, r.Address)
+			}
+		}
+	}
+	return released
+}
+
+// VIPs returns the VIPs held by a LoadBalancer, IPv4 first.
+func (a *Allocator) VIPs(namespace, name string) []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var vips []string
+	for _, al := range a.sorted() {
+		if al.LoadBalancer.Namespace == namespace && al.LoadBalancer.Name == name {
+			vips = append(vips, al.Address)
+		}
+	}
+	return vips
+}
+
+// Status returns the pool status with the allocations and usage of the
+// allocator. Write it with the resourceVersion the allocator was built from;
+// on a conflict, rebuild the allocator and retry.
+func (a *Allocator) Status(pool *v1alpha1.VIPPool) v1alpha1.VIPPoolStatus {
+	status := *pool.Status.DeepCopy()
+	status.ObservedGeneration = pool.Generation
+	status.IPv4 = a.ipam.Usage(v1alpha1.IPFamilyIPv4)
+	status.IPv6 = a.ipam.Usage(v1alpha1.IPFamilyIPv6)
+
+	a.mu.Lock()
+	status.Allocations = a.sorted()
+	a.mu.Unlock()
+
+	status.Phase = v1alpha1.VIPPoolPhaseReady
+	status.Message = ""
+	var exhausted []string
+	for _, f := range a.ipam.Families() {
+		if a.ipam.Usage(f).Free == 0 {
+			exhausted = append(exhausted, f)
+		}
+	}
+	if len(exhausted) > 0 {
+		status.Phase = v1alpha1.VIPPoolPhaseExhausted
+		status.Message = fmt.Sprintf("no free %s VIPs", strings.Join(exhausted, " or "))
+	}
+	return status
+}
+
+// release frees the VIPs of owner and returns their allocations. The caller
+// holds the lock.
+func (a *Allocator) release(own string) []v1alpha1.VIPAllocation {
+	var released []v1alpha1.VIPAllocation
+	for _, addr := range a.ipam.Release(own) {
+		released = append(released, a.owned[addr])
+		delete(a.owned, addr)
+	}
+	return released
+}
+
+// sorted returns the allocations ordered by address. The caller holds the lock.
+func (a *Allocator) sorted() []v1alpha1.VIPAllocation {
+	addrs := make([]netip.Addr, 0, len(a.owned))
+	for addr := range a.owned {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].Less(addrs[j]) })
+	out := make([]v1alpha1.VIPAllocation, len(addrs))
+	for i, addr := range addrs {
+		out[i] = a.owned[addr]
+	}
+	return out
+}
+
+func owner(namespace, name string) string {
+	return namespace + "/" + name
+}
@@ -0,0 +1,169 @@
+package vip
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/vitistack/crds/pkg/ipam"
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newPool(cidrs ...string) *v1alpha1.VIPPool {
+	return &v1alpha1.VIPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Generation: 3},
+		Spec:       v1alpha1.VIPPoolSpec{DatacenterIdentifier: "no-west-az1", CIDRs: cidrs},
+	}
+}
+
+func newLB(namespace, name string, families ...string) *v1alpha1.LoadBalancer {
+	return &v1alpha1.LoadBalancer{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: v1alpha1.LoadBalancerSpec{
+			DatacenterIdentifier: "no-west-az1",
+			ClusterIdentifier:    "cluster-a",
+			IPFamilies:           families,
+		},
+	}
+}
+
+func mustNew(t *testing.T, pool *v1alpha1.VIPPool) *Allocator {
+	t.Helper()
+	a, err := New(pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func mustAssign(t *testing.T, a *Allocator, lb *v1alpha1.LoadBalancer, want ...string) {
+	t.Helper()
+	got, err := a.Assign(lb)
+	if err != nil {
+		t.Fatalf("assign %s/%s: %v", lb.Namespace, lb.Name, err)
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("assign %s/%s = %v, want %v", lb.Namespace, lb.Name, got, want)
+	}
+}
+
+func TestAssign(t *testing.T) {
+	a := mustNew(t, newPool("10.0.0.0/29", "2001:db8::/120"))
+	web := newLB("default", "web", v1alpha1.IPFamilyIPv6, v1alpha1.IPFamilyIPv4)
+	mustAssign(t, a, web, "10.0.0.1", "2001:db8::1")
+	mustAssign(t, a, newLB("default", "api"), "10.0.0.2")
+
+	// Assigning again keeps the VIPs, dropping a family releases its VIP.
+	mustAssign(t, a, web, "10.0.0.1", "2001:db8::1")
+	web.Spec.IPFamilies = []string{v1alpha1.IPFamilyIPv4}
+	mustAssign(t, a, web, "10.0.0.1")
+
+	// A static VIP replaces the dynamic one.
+	web.Spec.VIPs = []string{"10.0.0.5"}
+	mustAssign(t, a, web, "10.0.0.5")
+
+	status := a.Status(newPool("10.0.0.0/29", "2001:db8::/120"))
+	want := []v1alpha1.VIPAllocation{
+		{Address: "10.0.0.2", LoadBalancer: v1alpha1.NamespacedLoadBalancerReference{Namespace: "default", Name: "api"}, ClusterIdentifier: "cluster-a"},
+		{Address: "10.0.0.5", LoadBalancer: v1alpha1.NamespacedLoadBalancerReference{Namespace: "default", Name: "web"}, ClusterIdentifier: "cluster-a", Static: true},
+	}
+	if !slices.Equal(status.Allocations, want) {
+		t.Errorf("allocations = %+v, want %+v", status.Allocations, want)
+	}
+	if status.ObservedGeneration != 3 || status.IPv4.Used != 2 || status.IPv6.Used != 0 {
+		t.Errorf("status = %+v", status)
+	}
+}
+
+func TestAssignKeepsVIPsOnError(t *testing.T) {
+	// Two IPv6 VIPs, both taken by other load balancers.
+	a := mustNew(t, newPool("10.0.0.0/29", "2001:db8::/127"))
+	mustAssign(t, a, newLB("default", "a", v1alpha1.IPFamilyIPv6), "2001:db8::")
+	mustAssign(t, a, newLB("default", "b", v1alpha1.IPFamilyIPv6), "2001:db8::1")
+	other := newLB("default", "other")
+	other.Spec.VIPs = []string{"10.0.0.6"}
+	mustAssign(t, a, other, "10.0.0.6")
+
+	web := newLB("default", "web")
+	web.Spec.VIPs = []string{"10.0.0.4"}
+	mustAssign(t, a, web, "10.0.0.4")
+	before := a.Status(newPool())
+
+	tests := []struct {
+		name   string
+		change func(lb *v1alpha1.LoadBalancer)
+		want   error
+	}{
+		{"no free VIP of a new family", func(lb *v1alpha1.LoadBalancer) {
+			lb.Spec.IPFamilies = []string{v1alpha1.IPFamilyIPv4, v1alpha1.IPFamilyIPv6}
+		}, ipam.ErrExhausted},
+		{"static VIP of another load balancer", func(lb *v1alpha1.LoadBalancer) {
+			lb.Spec.VIPs = []string{"10.0.0.6"}
+		}, ipam.ErrConflict},
+		{"static VIP outside the pool", func(lb *v1alpha1.LoadBalancer) {
+			lb.Spec.VIPs = []string{"10.0.1.4"}
+		}, ipam.ErrOutOfRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lb := web.DeepCopy()
+			tt.change(lb)
+			if _, err := a.Assign(lb); !errors.Is(err, tt.want) {
+				t.Fatalf("assign: %v, want %v", err, tt.want)
+			}
+			if got := a.VIPs("default", "web"); !slices.Equal(got, []string{"10.0.0.4"}) {
+				t.Errorf("VIPs after the failed assignment = %v, want [10.0.0.4]", got)
+			}
+			after := a.Status(newPool())
+			if !slices.Equal(after.Allocations, before.Allocations) || *after.IPv4 != *before.IPv4 || *after.IPv6 != *before.IPv6 {
+				t.Errorf("status changed:\n%+v\nwant\n%+v", after, before)
+			}
+		})
+	}
+}
+
+func TestReleaseOrphans(t *testing.T) {
+	pool := newPool("10.0.0.0/29", "2001:db8::/120")
+	pool.Status.Allocations = []v1alpha1.VIPAllocation{
+		{Address: "10.0.0.1", LoadBalancer: v1alpha1.NamespacedLoadBalancerReference{Namespace: "default", Name: "web"}},
+		{Address: "2001:db8::1", LoadBalancer: v1alpha1.NamespacedLoadBalancerReference{Namespace: "default", Name: "web"}},
+		{Address: "10.0.0.2", LoadBalancer: v1alpha1.NamespacedLoadBalancerReference{Namespace: "default", Name: "gone"}},
+		{Address: "2001:db8::2", LoadBalancer: v1alpha1.NamespacedLoadBalancerReference{Namespace: "default", Name: "gone"}},
+		{Address: "10.0.0.3", LoadBalancer: v1alpha1.NamespacedLoadBalancerReference{Namespace: "other", Name: "web"}},
+	}
+	a := mustNew(t, pool)
+
+	live := []v1alpha1.LoadBalancer{*newLB("default", "web"), *newLB("default", "new")}
+	released := a.ReleaseOrphans(live)
+	if want := []string{"10.0.0.2", "2001:db8::2", "10.0.0.3"}; !slices.Equal(released, want) {
+		t.Errorf("released %v, want %v", released, want)
+	}
+	if got := a.VIPs("default", "web"); !slices.Equal(got, []string{"10.0.0.1", "2001:db8::1"}) {
+		t.Errorf("VIPs of a live load balancer = %v, want them kept", got)
+	}
+	if released := a.ReleaseOrphans(live); len(released) != 0 {
+		t.Errorf("second run released %v, want nothing", released)
+	}
+	// The released VIPs are free again.
+	mustAssign(t, a, newLB("default", "new"), "10.0.0.2")
+}
+
+func TestNewReportsConflictingAllocations(t *testing.T) {
+	pool := newPool("10.0.0.0/29")
+	pool.Status.Allocations = []v1alpha1.VIPAllocation{
+		{Address: "10.0.0.1", LoadBalancer: v1alpha1.NamespacedLoadBalancerReference{Namespace: "default", Name: "web"}},
+		{Address: "10.0.0.1", LoadBalancer: v1alpha1.NamespacedLoadBalancerReference{Namespace: "default", Name: "api"}},
+		{Address: "10.0.1.1", LoadBalancer: v1alpha1.NamespacedLoadBalancerReference{Namespace: "default", Name: "db"}},
+	}
+	a, err := New(pool)
+	if a == nil {
+		t.Fatalf("no allocator: %v", err)
+	}
+	if !errors.Is(err, ipam.ErrConflict) || !errors.Is(err, ipam.ErrOutOfRange) {
+		t.Errorf("error = %v, want the conflict and the address outside the pool", err)
+	}
+	if got := a.Status(pool).Allocations; len(got) != 1 || got[0].LoadBalancer.Name != "web" {
+		t.Errorf("allocations = %+v, want only the first one", got)
+	}
+}
@@ -0,0 +1,192 @@
+// Package vip allocates LoadBalancer VIPs from VIPPools. A VIPPool serves one
+// datacenter, or one supervisor in it. A LoadBalancer gets one VIP per IP
+// family, or the static VIPs it asks for, and keeps them until it is deleted.
+package vip
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+	"strings"
+
+	v1alpha1 "github.com/vitistack/crds/pkg/v1alpha1"
+)
+
+var (
+	// ErrNoPool is returned when no VIPPool serves a LoadBalancer.
+	ErrNoPool = errors.New("no VIP pool")
+	// ErrOverlap is returned for VIPPools of a datacenter with overlapping CIDRs.
+	ErrOverlap = errors.New("VIP pools overlap")
+)
+
+// Families returns the IP families a LoadBalancer needs VIPs for: spec.ipFamilies,
+// else the families of spec.vips, else IPv4. IPv4 comes first.
+func Families(lb *v1alpha1.LoadBalancer) []string {
+	families := slices.Clone(lb.Spec.IPFamilies)
+	if len(families) == 0 {
+		for _, s := range lb.Spec.VIPs {
+			if addr, err := netip.ParseAddr(s); err == nil && !slices.Contains(families, family(addr)) {
+				families = append(families, family(addr))
+			}
+		}
+	}
+	if len(families) == 0 {
+		families = []string{v1alpha1.IPFamilyIPv4}
+	}
+	slices.Sort(families) // "IPv4" < "IPv6"
+	return slices.Compact(families)
+}
+
+// StaticVIPs returns the VIPs requested in spec.vips by family. It fails for
+// invalid addresses, two VIPs of one family, and families not in spec.ipFamilies.
+func StaticVIPs(lb *v1alpha1.LoadBalancer) (map[string]netip.Addr, error) {
+	static := map[string]netip.Addr{}
+	families := Families(lb)
+	var errs []error
+	for _, s := range lb.Spec.VIPs {
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid VIP %q", s))
+			continue
+		}
+		addr = addr.Unmap()
+		f := family(addr)
+		if prev, ok := static[f]; ok {
+			errs = append(errs, fmt.Errorf("VIPs %s and %s are both %s, at most one per family", prev, addr, f))
+			continue
+		}
+		if !slices.Contains(families, f) {
+			errs = append(errs, fmt.Errorf("VIP %s is %s, which is not in spec.ipFamilies", addr, f))
+			continue
+		}
+		static[f] = addr
+	}
+	return static, errors.Join(errs...)
+}
+
+// SelectPool returns the VIPPool of a LoadBalancer: spec.vipPoolRef when set,
+// otherwise a pool of the LoadBalancer's datacenter that serves every needed
+// family and contains the static VIPs. Pools dedicated to the LoadBalancer's
+// supervisor win over shared ones; ties go to the first name.
+func SelectPool(lb *v1alpha1.LoadBalancer, pools []v1alpha1.VIPPool) (*v1alpha1.VIPPool, error) {
+	if lb.Spec.VIPPoolRef != nil {
+		for i := range pools {
+			if pools[i].Name == lb.Spec.VIPPoolRef.Name {
+				if err := servable(lb, &pools[i]); err != nil {
+					return nil, fmt.Errorf("VIPPool %s: %w", pools[i].Name, err)
+				}
+				return &pools[i], nil
+			}
+		}
+		return nil, fmt.Errorf("%w: VIPPool %s not found", ErrNoPool, lb.Spec.VIPPoolRef.Name)
+	}
+
+	var candidates []*v1alpha1.VIPPool
+	var reasons []string
+	for i := range pools {
+		p := &pools[i]
+		if p.Spec.DatacenterIdentifier != lb.Spec.DatacenterIdentifier {
+			continue
+		}
+		if err := servable(lb, p); err != nil {
+			reasons = append(reasons, fmt.Sprintf("%s: %v", p.Name, err))
+			continue
+		}
+		candidates = append(candidates, p)
+	}
+	if len(candidates) == 0 {
+		err := fmt.Errorf("%w for datacenter %s", ErrNoPool, lb.Spec.DatacenterIdentifier)
+		if len(reasons) > 0 {
+			sort.Strings(reasons)
+			err = fmt.Errorf("%w (%s)", err, strings.Join(reasons, "; "))
+		}
+		return nil, err
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		di, dj := candidates[i].Spec.SupervisorIdentifier != "", candidates[j].Spec.SupervisorIdentifier != ""
+		if di != dj {
+			return di
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	return candidates[0], nil
+}
+
+// servable reports why a pool cannot serve a LoadBalancer.
+func servable(lb *v1alpha1.LoadBalancer, p *v1alpha1.VIPPool) error {
+	if p.DeletionTimestamp != nil {
+		return errors.New("pool is being deleted")
+	}
+	if p.Spec.DatacenterIdentifier != lb.Spec.DatacenterIdentifier {
+		return fmt.Errorf("pool serves datacenter %s, not %s", p.Spec.DatacenterIdentifier, lb.Spec.DatacenterIdentifier)
+	}
+	if p.Spec.SupervisorIdentifier != "" && p.Spec.SupervisorIdentifier != lb.Spec.SupervisorIdentifier {
+		return fmt.Errorf("pool is dedicated to supervisor %s", p.Spec.SupervisorIdentifier)
+	}
+	prefixes, err := prefixes(p)
+	if err != nil {
+		return err
+	}
+	for _, f := range Families(lb) {
+		if !slices.ContainsFunc(prefixes, func(pfx netip.Prefix) bool { return family(pfx.Addr()) == f }) {
+			return fmt.Errorf("pool has no %s CIDR", f)
+		}
+	}
+	static, _ := StaticVIPs(lb)
+	for _, addr := range static {
+		if !slices.ContainsFunc(prefixes, func(pfx netip.Prefix) bool { return pfx.Contains(addr) }) {
+			return fmt.Errorf("static VIP %s is not in the pool", addr)
+		}
+	}
+	return nil
+}
+
+// ValidatePools checks that no two pools of a datacenter overlap. Pools of
+// one datacenter share a routing domain, so an overlap could hand out a VIP
+// twice.
+func ValidatePools(pools []v1alpha1.VIPPool) error {
+	type owned struct {
+		pool   string
+		prefix netip.Prefix
+	}
+	byDatacenter := map[string][]owned{}
+	var errs []error
+	for i := range pools {
+		p := &pools[i]
+		pfxs, err := prefixes(p)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("VIPPool %s: %w", p.Name, err))
+			continue
+		}
+		for _, pfx := range pfxs {
+			for _, o := range byDatacenter[p.Spec.DatacenterIdentifier] {
+				if o.prefix.Overlaps(pfx) {
+					errs = append(errs, fmt.Errorf("%w: %s of VIPPool %s and %s of VIPPool %s", ErrOverlap, o.prefix, o.pool, pfx, p.Name))
+				}
+			}
+			byDatacenter[p.Spec.DatacenterIdentifier] = append(byDatacenter[p.Spec.DatacenterIdentifier], owned{p.Name, pfx})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func prefixes(p *v1alpha1.VIPPool) ([]netip.Prefix, error) {
+	var out []netip.Prefix
+	for _, s := range p.Spec.CIDRs {
+		pfx, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q", s)
+		}
+		out = append(out, pfx.Masked())
+	}
+	return out, nil
+}
+
+func family(addr netip.Addr) string {
+	if addr.Unmap().Is4() {
+		return v1alpha1.IPFamilyIPv4
+	}
+	return v1alpha1.IPFamilyIPv6
+}